@@ -0,0 +1,366 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Hanaasagi/magonote/internal/logger"
+	"github.com/Hanaasagi/magonote/internal/shell"
+	"github.com/adrg/xdg"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+const appName = "magonote"
+
+const (
+	defaultScreenTimeout  = 5 * time.Second
+	defaultCommandTimeout = 10 * time.Second
+	// hardcopyPoll is how often the hardcopy file is checked for, screen
+	// writes it after `screen -X` returns
+	hardcopyPoll = 20 * time.Millisecond
+)
+
+var (
+	appDir = filepath.Join(xdg.StateHome, appName)
+	// exchangeFile holds the selection read into the paste buffer of screen
+	exchangeFile = filepath.Join(appDir, "screen-exchange")
+)
+
+// windowPattern matches a window of `screen -Q windows`, its number followed
+// by its flags: * for the current window, - for the previous one
+var windowPattern = regexp.MustCompile(`(?:^|\s)(\d+)((?:[-*$!@&Z]|\(L\))*)\s`)
+
+func init() {
+	if err := os.MkdirAll(appDir, 0755); err != nil {
+		panic(fmt.Sprintf("Error creating log directory: %v\n", err))
+	}
+
+	logFilePath := filepath.Join(appDir, appName+".log")
+
+	logLevel := os.Getenv("MAGONOTE_LOG")
+	if logLevel == "" {
+		logLevel = "info"
+	}
+
+	logger.InitLogger(logFilePath, logLevel)
+}
+
+// Config holds all configuration for magonote execution
+type Config struct {
+	Dir string
+	// Command runs after the selection is read into the paste buffer, with
+	// {} replaced by the selection
+	Command        string
+	CommandTimeout time.Duration
+	// Scrollback captures the scrollback of the window too
+	Scrollback bool
+	// Args are given to magonote
+	Args []string
+}
+
+// Selection is what was picked in magonote
+type Selection struct {
+	Upcase bool // Picked with an uppercase hint
+	Texts  []string
+}
+
+// Magonote runs magonote on the content of a GNU screen window
+type Magonote struct {
+	config Config
+	// window is the window picked from, the previous one of the window
+	// magonote-screen runs in
+	window string
+	tmpDir string
+}
+
+// New creates a new Magonote instance with the given configuration
+func New(config Config) *Magonote {
+	return &Magonote{config: config}
+}
+
+// Run executes the complete magonote workflow
+func (m *Magonote) Run() error {
+	if os.Getenv("STY") == "" {
+		return errors.New("not running inside GNU screen, $STY is unset")
+	}
+
+	window, err := m.previousWindow()
+	if err != nil {
+		return fmt.Errorf("finding the window to pick from: %w", err)
+	}
+	m.window = window
+
+	m.tmpDir, err = os.MkdirTemp("", appName+"-screen-")
+	if err != nil {
+		return fmt.Errorf("creating temporary directory: %w", err)
+	}
+	defer os.RemoveAll(m.tmpDir) // nolint: errcheck
+
+	input, err := m.hardcopy()
+	if err != nil {
+		return fmt.Errorf("capturing window %s: %w", m.window, err)
+	}
+
+	selection, err := m.pick(input)
+	if err != nil {
+		return fmt.Errorf("running magonote: %w", err)
+	}
+
+	// Go back to the window picked from, the magonote window closes as
+	// soon as we exit
+	if _, err := m.screenCommand("-X", "select", m.window); err != nil {
+		slog.Warn("Failed to select window", "window", m.window, "error", err)
+	}
+	if selection == nil {
+		slog.Info("No selection made by user")
+		return nil
+	}
+
+	slog.Info("User made selection", "texts", selection.Texts, "upcase", selection.Upcase)
+	return m.pasteBack(selection)
+}
+
+// previousWindow returns the number of the window that was current before
+// the one magonote-screen was started in
+func (m *Magonote) previousWindow() (string, error) {
+	output, err := m.screenCommand("-Q", "windows")
+	if err != nil {
+		return "", err
+	}
+	return parsePreviousWindow(output)
+}
+
+// parsePreviousWindow returns the window flagged as previous in the output
+// of `screen -Q windows`
+func parsePreviousWindow(output string) (string, error) {
+	for _, match := range windowPattern.FindAllStringSubmatch(output+" ", -1) {
+		if strings.Contains(match[2], "-") {
+			return match[1], nil
+		}
+	}
+	return "", fmt.Errorf("no previous window in %q", strings.TrimSpace(output))
+}
+
+// hardcopy writes the content of the window to a file and returns its path
+func (m *Magonote) hardcopy() (string, error) {
+	path := filepath.Join(m.tmpDir, "hardcopy")
+	args := []string{"-p", m.window, "-X", "hardcopy"}
+	if m.config.Scrollback {
+		args = append(args, "-h")
+	}
+	if _, err := m.screenCommand(append(args, path)...); err != nil {
+		return "", err
+	}
+
+	deadline := time.Now().Add(defaultScreenTimeout)
+	for {
+		if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+			return path, nil
+		}
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("screen didn't write %s", path)
+		}
+		time.Sleep(hardcopyPoll)
+	}
+}
+
+// pick runs magonote on the terminal of the current window and returns the
+// selection, nil when nothing was selected
+func (m *Magonote) pick(input string) (*Selection, error) {
+	target := filepath.Join(m.tmpDir, "selection")
+	args := []string{"-f", "%U:%Q", "-t", target, "-i", input}
+	// The hardcopy holds the lines as wrapped by the window, which is as
+	// wide as the one magonote-screen runs in
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+		args = append(args, "--wrap-width", strconv.Itoa(width))
+	}
+	args = append(args, m.config.Args...)
+
+	cmd := exec.Command(filepath.Join(m.config.Dir, appName), args...)
+	cmd.Env = append(os.Environ(), logger.RunIDEnv+"="+logger.RunID())
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(target)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading selection: %w", err)
+	}
+	return parseSelection(string(content)), nil
+}
+
+// parseSelection parses the `%U:%Q` lines written by magonote, nil when
+// there are none. The texts are quoted so that the line breaks of
+// multi-line matches don't split them
+func parseSelection(content string) *Selection {
+	var selection Selection
+	for _, item := range strings.Split(strings.TrimSpace(content), "\n") {
+		upcase, quoted, ok := strings.Cut(item, ":")
+		if !ok {
+			continue
+		}
+		text, err := strconv.Unquote(strings.TrimRight(quoted, " "))
+		if err != nil {
+			continue
+		}
+		selection.Upcase = selection.Upcase || upcase == "true"
+		selection.Texts = append(selection.Texts, strings.TrimRight(text, " "))
+	}
+	if len(selection.Texts) == 0 {
+		return nil
+	}
+	return &selection
+}
+
+// pasteBack reads the selection into the paste buffer of screen through the
+// exchange file, pasting it into the window when picked with an uppercase
+// hint or several at once, then runs the command
+func (m *Magonote) pasteBack(selection *Selection) error {
+	text := strings.Join(selection.Texts, " ")
+	if err := os.WriteFile(exchangeFile, []byte(text), 0o600); err != nil {
+		return fmt.Errorf("writing exchange file: %w", err)
+	}
+	if _, err := m.screenCommand("-X", "readbuf", exchangeFile); err != nil {
+		return fmt.Errorf("reading exchange file: %w", err)
+	}
+
+	if selection.Upcase || len(selection.Texts) > 1 {
+		if _, err := m.screenCommand("-p", m.window, "-X", "paste", "."); err != nil {
+			return fmt.Errorf("pasting selection: %w", err)
+		}
+	}
+	if _, err := m.screenCommand("-X", "echo", "Copied "+text); err != nil {
+		slog.Warn("Failed to display message", "error", err)
+	}
+
+	if m.config.Command == "" {
+		return nil
+	}
+	return m.executeFinalCommand(selection.Texts...)
+}
+
+// executeFinalCommand executes the command template with the selected
+// texts, which are escaped so the shell never interprets them
+func (m *Magonote) executeFinalCommand(texts ...string) error {
+	finalCommand := shell.Expand(m.config.Command, texts...)
+	slog.Info("Executing final command", "texts", texts, "command", finalCommand)
+	if _, err := run(m.config.CommandTimeout, "bash", "-c", finalCommand); err != nil {
+		slog.Error("Final command execution failed", "error", err)
+		return err
+	}
+	return nil
+}
+
+// screenCommand sends a command to the screen session of $STY and returns
+// its output
+func (m *Magonote) screenCommand(args ...string) (string, error) {
+	output, err := run(defaultScreenTimeout, "screen", append([]string{"-S", os.Getenv("STY")}, args...)...)
+	if err != nil {
+		return "", fmt.Errorf("screen command failed: %w", err)
+	}
+	return strings.TrimRight(output, "\n"), nil
+}
+
+// run runs the command with the given timeout and returns its stdout, a zero
+// timeout waits indefinitely
+func run(timeout time.Duration, name string, args ...string) (string, error) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return stdout.String(), fmt.Errorf("running %s: %w (stderr: %s)", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// parseCommandLineArgs parses command line arguments and returns configuration
+func parseCommandLineArgs() Config {
+	var config Config
+
+	rootCmd := &cobra.Command{
+		Use:   "magonote-screen [flags] [-- magonote flags]",
+		Short: "GNU screen integration for magonote",
+		Long: `GNU screen integration for magonote, picking from the previous window.
+
+Bind it to a key in ~/.screenrc:
+
+  bind g screen -t magonote /path/to/magonote-screen -- --alphabet colemak
+
+Hints in lowercase copy the selection to the paste buffer, in uppercase they
+also paste it into the window.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			config.Args = args
+		},
+	}
+
+	rootCmd.Flags().StringVar(&config.Dir, "dir", "", "Directory of the magonote binary, that of magonote-screen by default")
+	rootCmd.Flags().StringVar(&config.Command, "command", "",
+		"Command to execute after copying the selection, {} being replaced by it")
+	rootCmd.Flags().DurationVar(&config.CommandTimeout, "command-timeout", defaultCommandTimeout,
+		"Kill the command if it runs longer than this (0 to disable)")
+	rootCmd.Flags().BoolVar(&config.Scrollback, "scrollback", false,
+		"Pick from the scrollback of the window too")
+
+	if err := rootCmd.Execute(); err != nil {
+		slog.Error("Failed to parse command line arguments", "error", err)
+		os.Exit(1)
+	}
+	if rootCmd.Flags().Changed("help") {
+		os.Exit(0)
+	}
+
+	return config
+}
+
+func main() {
+	config := parseCommandLineArgs()
+
+	if config.Dir == "" {
+		execPath, err := os.Executable()
+		if err != nil {
+			slog.Error("Failed to determine magonote binary directory", "error", err)
+			os.Exit(1)
+		}
+		config.Dir = filepath.Dir(execPath)
+	}
+
+	slog.Info("Starting magonote-screen",
+		"dir", config.Dir,
+		"command", config.Command,
+		"commandTimeout", config.CommandTimeout,
+		"scrollback", config.Scrollback,
+		"args", config.Args)
+
+	if err := New(config).Run(); err != nil {
+		slog.Error("Magonote execution failed", "error", err)
+		fmt.Fprintf(os.Stderr, "magonote-screen: %v\n", err)
+		// Leave the error on screen before the window closes
+		time.Sleep(2 * time.Second)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePreviousWindow(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "previous window after the current one",
+			output: "0$ bash  1-$ vim  2*$ magonote",
+			want:   "1",
+		},
+		{
+			name:   "previous window with a title holding spaces",
+			output: "0*$ magonote  12-(L) tail -f log",
+			want:   "12",
+		},
+		{
+			name:   "previous window with several flags",
+			output: "3-$@ build  4*$ magonote",
+			want:   "3",
+		},
+		{
+			name:    "no previous window",
+			output:  "0*$ magonote",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePreviousWindow(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePreviousWindow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parsePreviousWindow() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSelection(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    *Selection
+	}{
+		{
+			name:    "single selection",
+			content: "false:\"10.0.0.1\"\n",
+			want:    &Selection{Texts: []string{"10.0.0.1"}},
+		},
+		{
+			name:    "uppercase hint",
+			content: "true:\"/tmp/foo.txt  \"",
+			want:    &Selection{Upcase: true, Texts: []string{"/tmp/foo.txt"}},
+		},
+		{
+			name:    "multiple selections keeping colons",
+			content: "false:\"http://localhost:8080\"\nfalse:\"abc123\"",
+			want:    &Selection{Texts: []string{"http://localhost:8080", "abc123"}},
+		},
+		{
+			name:    "multi-line selection",
+			content: "false:\"-----BEGIN KEY-----\\nabc\\n-----END KEY-----\"\nfalse:\"x\"",
+			want:    &Selection{Texts: []string{"-----BEGIN KEY-----\nabc\n-----END KEY-----", "x"}},
+		},
+		{
+			name:    "no selection",
+			content: "",
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseSelection(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSelection() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultTmuxTimeout    = 5 * time.Second
+	defaultCommandTimeout = 10 * time.Second
+	defaultMaxConcurrent  = 4
+)
+
+// internalEnvPrefix marks environment variables that only configure magonote
+// itself and must not leak into user commands
+const internalEnvPrefix = "MAGONOTE_"
+
+// ErrTimeout is returned when a process is killed after exceeding its timeout
+var ErrTimeout = errors.New("process timed out")
+
+// Executor runs external processes with a timeout, a scrubbed environment
+// and a limit on the number of concurrent processes
+type Executor struct {
+	timeout time.Duration
+	sem     chan struct{}
+	env     []string
+}
+
+// ExecutorOption configures an Executor
+type ExecutorOption func(*Executor)
+
+// WithDefaultTimeout sets the timeout used when none is given, zero disables it
+func WithDefaultTimeout(timeout time.Duration) ExecutorOption {
+	return func(e *Executor) {
+		e.timeout = timeout
+	}
+}
+
+// WithMaxConcurrent limits the number of processes running at the same time
+func WithMaxConcurrent(n int) ExecutorOption {
+	return func(e *Executor) {
+		if n > 0 {
+			e.sem = make(chan struct{}, n)
+		}
+	}
+}
+
+// WithEnviron sets the base environment, it is scrubbed before use
+func WithEnviron(env []string) ExecutorOption {
+	return func(e *Executor) {
+		e.env = scrubEnv(env)
+	}
+}
+
+// NewExecutor creates a new Executor
+func NewExecutor(opts ...ExecutorOption) *Executor {
+	e := &Executor{
+		timeout: defaultTmuxTimeout,
+		sem:     make(chan struct{}, defaultMaxConcurrent),
+		env:     scrubEnv(os.Environ()),
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// scrubEnv removes magonote internal variables from env
+func scrubEnv(env []string) []string {
+	scrubbed := make([]string, 0, len(env))
+	for _, kv := range env {
+		if strings.HasPrefix(kv, internalEnvPrefix) {
+			continue
+		}
+		scrubbed = append(scrubbed, kv)
+	}
+	return scrubbed
+}
+
+// Output runs the command with the default timeout and returns its stdout
+func (e *Executor) Output(name string, args ...string) (string, error) {
+	return e.OutputTimeout(e.timeout, name, args...)
+}
+
+// OutputTimeout runs the command with the given timeout and returns its stdout,
+// a zero timeout waits indefinitely
+func (e *Executor) OutputTimeout(timeout time.Duration, name string, args ...string) (string, error) {
+	e.sem <- struct{}{}
+	defer func() { <-e.sem }()
+
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = e.env
+	// Run in its own process group so that shells and their children are
+	// killed together on timeout
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = time.Second
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		slog.Warn("Process killed after timeout", "name", name, "timeout", timeout)
+		return stdout.String(), fmt.Errorf("running %s: %w", name, ErrTimeout)
+	}
+	if err != nil {
+		slog.Debug("Process failed", "name", name, "error", err, "stderr", stderr.String())
+		return stdout.String(), fmt.Errorf("running %s: %w (stderr: %s)", name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	slog.Debug("Process completed", "name", name, "duration_ms", time.Since(start).Milliseconds())
+	return stdout.String(), nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestScrubEnv(t *testing.T) {
+	env := []string{"HOME=/root", "MAGONOTE_LOG=debug", "PATH=/bin", "MAGONOTE_DEBUG=1"}
+	want := []string{"HOME=/root", "PATH=/bin"}
+
+	if got := scrubEnv(env); !reflect.DeepEqual(got, want) {
+		t.Errorf("scrubEnv() = %v, want %v", got, want)
+	}
+}
+
+func TestExecutor_Output(t *testing.T) {
+	e := NewExecutor(WithEnviron([]string{"FOO=bar", "MAGONOTE_LOG=debug"}))
+
+	output, err := e.Output("sh", "-c", `echo "$FOO:$MAGONOTE_LOG"`)
+	if err != nil {
+		t.Fatalf("Executor.Output() error = %v", err)
+	}
+	if got := strings.TrimSpace(output); got != "bar:" {
+		t.Errorf("Executor.Output() = %q, want %q", got, "bar:")
+	}
+}
+
+func TestExecutor_Timeout(t *testing.T) {
+	e := NewExecutor()
+
+	start := time.Now()
+	// The background child must be killed together with the shell
+	_, err := e.OutputTimeout(100*time.Millisecond, "sh", "-c", "sleep 10 & sleep 10")
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("Executor.OutputTimeout() error = %v, want %v", err, ErrTimeout)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Executor.OutputTimeout() took %v, expected the process to be killed", elapsed)
+	}
+}
+
+func TestExecutor_MaxConcurrent(t *testing.T) {
+	e := NewExecutor(WithMaxConcurrent(1))
+
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := e.Output("sleep", "0.1"); err != nil {
+				t.Errorf("Executor.Output() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+		t.Errorf("Expected processes to run one at a time, all finished in %v", elapsed)
+	}
+}
@@ -1,11 +1,9 @@
 package main
 
 import (
-	"bytes"
 	"fmt"
 	"log/slog"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strconv"
@@ -13,6 +11,7 @@ import (
 	"time"
 
 	"github.com/Hanaasagi/magonote/internal/logger"
+	"github.com/Hanaasagi/magonote/internal/shell"
 	"github.com/Hanaasagi/magonote/pkg/clipboard"
 	"github.com/adrg/xdg"
 	"github.com/spf13/cobra"
@@ -20,6 +19,20 @@ import (
 
 const appName = "magonote"
 
+// Capture modes of --capture
+const (
+	capturePane     = "pane"      // The active pane
+	captureAllPanes = "all-panes" // Every pane of the current window
+)
+
+// rerunPrompt is shown when the selection is no longer in the pane, confirming
+// runs the magonote-pick alias set up by magonote.tmux
+const rerunPrompt = "magonote: the pane changed and the selection is gone, pick again? (y/n)"
+
+// failureMessage is displayed when magonote exits with an error, such as a
+// recovered panic of its interface
+const failureMessage = "magonote: failed, see the crash and log files in $XDG_STATE_HOME/magonote"
+
 var (
 	appDir  = filepath.Join(xdg.StateHome, appName)
 	tmpFile = filepath.Join(appDir, appName+".state")
@@ -47,6 +60,9 @@ type PaneInfo struct {
 	ScrollPosition int    // Current scroll position (only valid when InMode is true)
 	InMode         bool   // Whether pane is in copy/scroll mode
 	Zoomed         bool   // Whether the pane is zoomed
+	CursorY        int    // Cursor line relative to the visible pane, -1 if unknown
+	Command        string // Command running in the pane, like "ssh"
+	PID            int    // PID of the first process of the pane, 0 if unknown
 }
 
 // HasScrollData returns true if the pane has valid scroll information
@@ -60,17 +76,35 @@ type Config struct {
 	Command       string
 	UpcaseCommand string
 	MultiCommand  string
+	MultiConfirm  bool
 	OSC52         bool
+	// Revalidate checks that the selection is still in the pane before
+	// running the pick command, the pane may have changed meanwhile. The
+	// visible text of the selection is looked for, see missingSelections
+	Revalidate bool
+	// Capture selects the panes whose content is shown, capturePane or
+	// captureAllPanes
+	Capture string
+	// Last reopens the last picker instead of capturing the panes
+	Last bool
+
+	// CommandTimeout bounds the final pick command, WaitTimeout bounds the
+	// time the user may spend in the magonote window. Zero disables them
+	CommandTimeout time.Duration
+	WaitTimeout    time.Duration
 }
 
 // Magonote orchestrates the complete tmux-magonote workflow
 type Magonote struct {
-	config Config
-	signal string
+	config   Config
+	signal   string
+	executor *Executor
 
 	// Runtime state
 	activePaneInfo *PaneInfo
+	panes          []*PaneInfo // Panes of the current window, in layout order
 	magonotePaneID string
+	zoomed         bool // Whether the magonote pane was zoomed by us
 }
 
 // New creates a new Magonote instance with the given configuration
@@ -79,8 +113,9 @@ func New(config Config) *Magonote {
 	signal := fmt.Sprintf("%s-finished-%d", appName, sinceEpoch)
 
 	return &Magonote{
-		config: config,
-		signal: signal,
+		config:   config,
+		signal:   signal,
+		executor: NewExecutor(),
 	}
 }
 
@@ -96,6 +131,13 @@ func (m *Magonote) Run() error {
 		return fmt.Errorf("creating magonote window: %w", err)
 	}
 
+	// Restore the layout even if a later step fails or times out
+	defer func() {
+		if err := m.cleanup(); err != nil {
+			slog.Warn("Cleanup failed", "error", err)
+		}
+	}()
+
 	if err := m.showMagonoteInterface(); err != nil {
 		return fmt.Errorf("showing magonote interface: %w", err)
 	}
@@ -108,19 +150,15 @@ func (m *Magonote) Run() error {
 		return fmt.Errorf("processing user selection: %w", err)
 	}
 
-	if err := m.cleanup(); err != nil {
-		slog.Warn("Cleanup failed", "error", err)
-	}
-
 	slog.Debug("Magonote workflow completed successfully")
 	return nil
 }
 
 // captureActivePane identifies and stores comprehensive information about the currently active pane
 func (m *Magonote) captureActivePane() error {
-	// Format: #{pane_id}:#{?pane_in_mode,1,0}:#{pane_height}:#{scroll_position}:#{window_zoomed_flag}:#{?pane_active,active,nope}
+	// Format: #{pane_id}:#{?pane_in_mode,1,0}:#{pane_height}:#{scroll_position}:#{window_zoomed_flag}:#{?pane_active,active,nope}:#{cursor_y}:#{pane_pid}:#{pane_current_command}
 	output, err := m.tmuxCommand("list-panes", "-F",
-		"#{pane_id}:#{?pane_in_mode,1,0}:#{pane_height}:#{scroll_position}:#{window_zoomed_flag}:#{?pane_active,active,nope}")
+		"#{pane_id}:#{?pane_in_mode,1,0}:#{pane_height}:#{scroll_position}:#{window_zoomed_flag}:#{?pane_active,active,nope}:#{cursor_y}:#{pane_pid}:#{pane_current_command}")
 	if err != nil {
 		return fmt.Errorf("listing panes: %w", err)
 	}
@@ -136,25 +174,26 @@ func (m *Magonote) captureActivePane() error {
 			continue
 		}
 
-		// Check if this is the active pane
-		if parts[5] != "active" {
-			continue
-		}
-
 		paneInfo, err := m.parsePaneInfo(parts)
 		if err != nil {
 			slog.Warn("Failed to parse pane info", "error", err, "line", line)
 			continue
 		}
+		m.panes = append(m.panes, paneInfo)
 
-		m.activePaneInfo = paneInfo
-		slog.Debug("Captured active pane", "paneID", m.activePaneInfo.ID,
-			"height", m.activePaneInfo.Height, "inMode", m.activePaneInfo.InMode,
-			"scrollPosition", m.activePaneInfo.ScrollPosition, "zoomed", m.activePaneInfo.Zoomed)
-		return nil
+		// Check if this is the active pane
+		if parts[5] == "active" {
+			m.activePaneInfo = paneInfo
+			slog.Debug("Captured active pane", "paneID", m.activePaneInfo.ID,
+				"height", m.activePaneInfo.Height, "inMode", m.activePaneInfo.InMode,
+				"scrollPosition", m.activePaneInfo.ScrollPosition, "zoomed", m.activePaneInfo.Zoomed)
+		}
 	}
 
-	return fmt.Errorf("no active pane found")
+	if m.activePaneInfo == nil {
+		return fmt.Errorf("no active pane found")
+	}
+	return nil
 }
 
 // parsePaneInfo parses pane information from tmux list-panes output
@@ -164,7 +203,8 @@ func (m *Magonote) parsePaneInfo(parts []string) (*PaneInfo, error) {
 	}
 
 	paneInfo := &PaneInfo{
-		ID: parts[0],
+		ID:      parts[0],
+		CursorY: -1,
 	}
 
 	// Parse pane_in_mode (1 = in mode, 0 = normal)
@@ -199,43 +239,98 @@ func (m *Magonote) parsePaneInfo(parts []string) (*PaneInfo, error) {
 		paneInfo.Zoomed = (zoomed == 1)
 	}
 
+	// Parse cursor line, older formats don't include it
+	if len(parts) > 6 {
+		if cursorY, err := strconv.Atoi(parts[6]); err == nil {
+			paneInfo.CursorY = cursorY
+		}
+	}
+
+	// Parse the process of the pane, the command may contain colons
+	if len(parts) > 8 {
+		if pid, err := strconv.Atoi(parts[7]); err == nil {
+			paneInfo.PID = pid
+		}
+		paneInfo.Command = strings.Join(parts[8:], ":")
+	}
+
 	return paneInfo, nil
 }
 
+// cursorLine returns the 1-based line of the cursor in the captured text,
+// or 0 when the cursor is unknown or outside of the capture
+func (m *Magonote) cursorLine() int {
+	if m.activePaneInfo == nil || m.activePaneInfo.CursorY < 0 {
+		return 0
+	}
+
+	// In copy mode the capture starts scroll_position lines above the screen
+	line := m.activePaneInfo.CursorY + m.activePaneInfo.ScrollPosition
+	if line >= m.activePaneInfo.Height {
+		return 0
+	}
+	return line + 1
+}
+
 // buildScrollParams generates tmux capture-pane scroll parameters based on pane state
 func (m *Magonote) buildScrollParams() string {
-	if m.activePaneInfo == nil || !m.activePaneInfo.HasScrollData() {
+	return scrollParams(m.activePaneInfo)
+}
+
+// scrollParams generates tmux capture-pane scroll parameters for the
+// visible region of pane
+func scrollParams(pane *PaneInfo) string {
+	if pane == nil || !pane.HasScrollData() {
 		return ""
 	}
 
 	// Following tmux-thumbs logic: -S -scroll_position -E pane_height-scroll_position-1
-	startLine := -m.activePaneInfo.ScrollPosition
-	endLine := m.activePaneInfo.Height - m.activePaneInfo.ScrollPosition - 1
+	startLine := -pane.ScrollPosition
+	endLine := pane.Height - pane.ScrollPosition - 1
 
 	return fmt.Sprintf(" -S %d -E %d", startLine, endLine)
 }
 
 // buildCaptureCommand generates the tmux capture-pane command with proper scroll handling
 func (m *Magonote) buildCaptureCommand() string {
-	scrollParams := m.buildScrollParams()
+	if m.config.Capture != captureAllPanes {
+		return paneCaptureCommand(m.activePaneInfo)
+	}
 
+	// Every pane is preceded by a line naming it, grouped so that the
+	// output is piped to magonote as a whole
+	commands := make([]string, 0, 2*len(m.panes))
+	for _, pane := range m.panes {
+		commands = append(commands,
+			fmt.Sprintf("printf '%%s\\n' %s", shellQuote(paneBoundary(pane))),
+			paneCaptureCommand(pane))
+	}
+	return "{ " + strings.Join(commands, "; ") + "; }"
+}
+
+// paneCaptureCommand generates the tmux capture-pane command of the visible
+// region of pane
+func paneCaptureCommand(pane *PaneInfo) string {
 	// Base capture command with ANSI escape sequences and join lines
-	captureCmd := fmt.Sprintf("tmux capture-pane -J -t %s -p -e",
-		m.activePaneInfo.ID)
+	captureCmd := fmt.Sprintf("tmux capture-pane -J -t %s -p -e", pane.ID)
 
 	// Append scroll params only when present to avoid trailing spaces
-	if scrollParams != "" {
-		captureCmd += scrollParams
-	}
+	captureCmd += scrollParams(pane)
 
 	// Add tail to limit output height when we have scroll data
-	if m.activePaneInfo.HasScrollData() {
-		captureCmd += fmt.Sprintf(" | tail -n %d", m.activePaneInfo.Height)
+	if pane.HasScrollData() {
+		captureCmd += fmt.Sprintf(" | tail -n %d", pane.Height)
 	}
 
 	return captureCmd
 }
 
+// paneBoundary is the line annotating the start of a pane when capturing
+// all panes
+func paneBoundary(pane *PaneInfo) string {
+	return fmt.Sprintf("──── pane %s ────", pane.ID)
+}
+
 // createMagonoteWindow creates a new tmux window running the magonote command
 func (m *Magonote) createMagonoteWindow() error {
 	slog.Debug("Creating magonote window")
@@ -245,20 +340,41 @@ func (m *Magonote) createMagonoteWindow() error {
 		return fmt.Errorf("building magonote arguments: %w", err)
 	}
 
-	// Build the command that will keep the pane alive after magonote completes
-	captureCmd := m.buildCaptureCommand()
+	// Build the command that will keep the pane alive after magonote completes,
+	// the wrapper is signaled even if magonote fails so that cleanup runs
+	captureCmd := m.buildCaptureCommand() + " |"
+	if m.config.MultiConfirm {
+		args = append(args, "--confirm-command", shellQuote(m.config.MultiCommand))
+	}
+	switch {
+	case m.config.Last:
+		// The last picker brings its own input and cursor line
+		captureCmd = ""
+		args = append(args, "--last")
+	case m.config.Capture != captureAllPanes:
+		// The cursor line is relative to the active pane alone
+		if line := m.cursorLine(); line > 0 {
+			args = append(args, "--cursor-line", strconv.Itoa(line))
+		}
+		if host := m.remoteHost(); host != "" {
+			args = append(args, "--remote-host", shellQuote(host))
+		}
+	}
 	command := fmt.Sprintf(
-		"%s | %s/magonote -f '%%U:%%H' -t %s %s; tmux wait-for -S %s; sleep infinity",
+		"%s %s=%s %s/magonote -f '%%U:%%Q:%%V' -t %s %s || tmux display-message %s; tmux wait-for -S %s; sleep infinity",
 		captureCmd,
+		logger.RunIDEnv,
+		logger.RunID(),
 		m.config.Dir,
 		tmpFile,
 		strings.Join(args, " "),
+		shellQuote(failureMessage),
 		m.signal,
 	)
 
 	slog.Debug("Executing magonote command", "command", command)
 
-	output, err := m.tmuxCommand("new-window", "-P", "-F", "#{pane_id}", "-d", "-n", "[magonote]", command)
+	output, err := m.tmuxCommand(m.newWindowArgs(command)...)
 	if err != nil {
 		return fmt.Errorf("creating new window: %w", err)
 	}
@@ -268,6 +384,16 @@ func (m *Magonote) createMagonoteWindow() error {
 	return nil
 }
 
+// newWindowArgs returns the tmux arguments creating the window running
+// command, in the directory of the current pane, the picked one, so that
+// the apps and actions run from the picker start where its text came from
+func (m *Magonote) newWindowArgs(command string) []string {
+	return []string{
+		"new-window", "-P", "-F", "#{pane_id}", "-d", "-n", "[magonote]",
+		"-c", "#{pane_current_path}", command,
+	}
+}
+
 // buildMagonoteArgs extracts and formats magonote arguments from tmux options
 func (m *Magonote) buildMagonoteArgs() ([]string, error) {
 	output, err := m.tmuxCommand("show", "-g")
@@ -275,6 +401,11 @@ func (m *Magonote) buildMagonoteArgs() ([]string, error) {
 		return nil, fmt.Errorf("showing global options: %w", err)
 	}
 
+	return m.parseMagonoteOptions(output), nil
+}
+
+// parseMagonoteOptions converts `tmux show -g` output into magonote arguments
+func (m *Magonote) parseMagonoteOptions(output string) []string {
 	pattern := regexp.MustCompile(`^@magonote-([\w\-0-9]+)\s+"?([^"]+)"?$`)
 	var args []string
 
@@ -288,20 +419,47 @@ func (m *Magonote) buildMagonoteArgs() ([]string, error) {
 
 		switch {
 		case m.isBooleanParam(name):
-			args = append(args, fmt.Sprintf("--%s", name))
+			if isTruthy(value) {
+				args = append(args, fmt.Sprintf("--%s", name))
+			}
 		case m.isStringParam(name):
 			args = append(args, fmt.Sprintf("--%s", name), fmt.Sprintf("'%s'", value))
+		case name == "also" || strings.HasPrefix(name, "also-"):
+			args = append(args, "--also", shellQuote(value))
+		case strings.HasPrefix(name, "exclude-text-"):
+			args = append(args, "--exclude-text", shellQuote(value))
+		case strings.HasPrefix(name, "exclude-regex-"):
+			args = append(args, "--exclude-regex", shellQuote(strings.ReplaceAll(value, "\\\\", "\\")))
+		case strings.HasPrefix(name, "regexp-name-"):
+			patternName := strings.TrimPrefix(name, "regexp-name-")
+			pattern := strings.ReplaceAll(value, "\\\\", "\\")
+			args = append(args, "--regexp-named", fmt.Sprintf("'%s:%s'", patternName, pattern))
 		case strings.HasPrefix(name, "regexp"):
 			args = append(args, "--regexp", fmt.Sprintf("'%s'", strings.ReplaceAll(value, "\\\\", "\\")))
 		}
 	}
 
-	return args, nil
+	return args
+}
+
+// isTruthy reports whether the value of a boolean option turns it on, as
+// tmux options are set with 1, on, true or yes
+func isTruthy(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "1", "on", "true", "yes":
+		return true
+	}
+	return false
+}
+
+// shellQuote wraps s in single quotes so it is passed to the shell verbatim
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
 }
 
 // isBooleanParam checks if the parameter is a boolean type
 func (m *Magonote) isBooleanParam(name string) bool {
-	booleanParams := []string{"reverse", "unique", "contrast"}
+	booleanParams := []string{"reverse", "unique", "contrast", "proximity", "prefix-select", "hint-pages", "status-bar", "tabledetection", "colordetection", "no-auto-detection", "multiline"}
 	for _, param := range booleanParams {
 		if param == name {
 			return true
@@ -315,6 +473,9 @@ func (m *Magonote) isStringParam(name string) bool {
 	stringParams := []string{
 		"alphabet", "position", "fg-color", "bg-color", "hint-bg-color",
 		"hint-fg-color", "select-fg-color", "select-bg-color", "multi-fg-color", "multi-bg-color",
+		"scope", "mode", "word-min-length", "theme", "profile", "socket",
+		"tabledetection-min-lines", "tabledetection-confidence", "browser",
+		"bidi",
 	}
 	for _, param := range stringParams {
 		if param == name {
@@ -332,6 +493,15 @@ func (m *Magonote) showMagonoteInterface() error {
 		return fmt.Errorf("swapping panes: %w", err)
 	}
 
+	// The content of the whole window doesn't fit in a single pane
+	if m.config.Capture == captureAllPanes && len(m.panes) > 1 && !m.activePaneInfo.Zoomed {
+		if _, err := m.tmuxCommand("resize-pane", "-Z", "-t", m.magonotePaneID); err != nil {
+			slog.Warn("Failed to zoom magonote pane", "error", err)
+		} else {
+			m.zoomed = true
+		}
+	}
+
 	slog.Debug("Magonote interface displayed successfully")
 	return nil
 }
@@ -340,7 +510,7 @@ func (m *Magonote) showMagonoteInterface() error {
 func (m *Magonote) waitForUserInteraction() error {
 	slog.Debug("Waiting for user interaction", "signal", m.signal)
 
-	if _, err := m.tmuxCommand("wait-for", m.signal); err != nil {
+	if _, err := m.executor.OutputTimeout(m.config.WaitTimeout, "tmux", "wait-for", m.signal); err != nil {
 		return fmt.Errorf("waiting for signal: %w", err)
 	}
 
@@ -395,9 +565,100 @@ func (m *Magonote) processUserSelection() error {
 	}
 
 	slog.Info("User made selection", "result", result)
+
+	if m.config.Revalidate {
+		captured, err := m.recapturePane()
+		if err != nil {
+			return fmt.Errorf("recapturing pane: %w", err)
+		}
+		if missing := missingSelections(captured, result); len(missing) > 0 {
+			slog.Warn("Selection no longer in the pane, skipping pick command", "missing", missing)
+			return m.offerRerun()
+		}
+	}
+
 	return m.executeSelectionCommand(result)
 }
 
+// recapturePane returns the plain text of the regions of the panes magonote
+// was shown
+func (m *Magonote) recapturePane() (string, error) {
+	panes := []*PaneInfo{m.activePaneInfo}
+	if m.config.Capture == captureAllPanes {
+		panes = m.panes
+	}
+
+	captured := make([]string, 0, len(panes))
+	for _, pane := range panes {
+		args := []string{"capture-pane", "-J", "-p", "-t", pane.ID}
+		if pane.HasScrollData() {
+			args = append(args,
+				"-S", strconv.Itoa(-pane.ScrollPosition),
+				"-E", strconv.Itoa(pane.Height-pane.ScrollPosition-1))
+		}
+		output, err := m.tmuxCommand(args...)
+		if err != nil {
+			return "", err
+		}
+		captured = append(captured, output)
+	}
+	return strings.Join(captured, "\n"), nil
+}
+
+// selectionItem is a selection read from the `%U:%Q:%V` lines written by
+// magonote, whose texts are quoted so that every selection takes a single
+// line
+type selectionItem struct {
+	upcase  bool
+	text    string // Text to output, such as the target of a hyperlink
+	visible string // Text as the pane shows it
+}
+
+// parseItem parses a line written by magonote into a selection item
+func parseItem(line string) (selectionItem, bool) {
+	flag, rest, ok := strings.Cut(line, ":")
+	if !ok {
+		return selectionItem{}, false
+	}
+	quoted, err := strconv.QuotedPrefix(rest)
+	if err != nil {
+		return selectionItem{}, false
+	}
+	text, _ := strconv.Unquote(quoted)
+	item := selectionItem{upcase: flag == "true", text: strings.TrimRight(text, " "), visible: text}
+	if shown, ok := strings.CutPrefix(rest[len(quoted):], ":"); ok {
+		if visible, err := strconv.Unquote(strings.TrimRight(shown, " ")); err == nil {
+			item.visible = visible
+		}
+	}
+	item.visible = strings.TrimRight(item.visible, " ")
+	return item, true
+}
+
+// missingSelections returns the visible texts of the selections of result
+// that captured no longer contains. The texts output may not be shown as
+// is, like the targets of hyperlinks and the decoded JSON strings, so the
+// `%V` field is required for the check to hold
+func missingSelections(captured, result string) []string {
+	var missing []string
+	for _, line := range strings.Split(result, "\n") {
+		item, ok := parseItem(line)
+		if !ok {
+			continue
+		}
+		if !strings.Contains(captured, item.visible) {
+			missing = append(missing, item.visible)
+		}
+	}
+	return missing
+}
+
+// offerRerun asks whether to pick again from the current pane content
+func (m *Magonote) offerRerun() error {
+	_, err := m.tmuxCommand("confirm-before", "-p", rerunPrompt, "magonote-pick")
+	return err
+}
+
 // executeSelectionCommand executes the appropriate command based on the user's selection
 func (m *Magonote) executeSelectionCommand(result string) error {
 	items := strings.Split(result, "\n")
@@ -416,31 +677,28 @@ func (m *Magonote) executeSelectionCommand(result string) error {
 // handleMultipleSelection processes multiple selected items
 func (m *Magonote) handleMultipleSelection(items []string) error {
 	var textParts []string
-	for _, item := range items {
-		if parts := strings.SplitN(item, ":", 2); len(parts) > 1 {
-			textParts = append(textParts, parts[1])
+	for _, line := range items {
+		if item, ok := parseItem(line); ok {
+			textParts = append(textParts, item.text)
 		}
 	}
 
-	text := strings.Join(textParts, " ")
-
 	if m.config.OSC52 {
-		if err := m.sendOSC52Sequence(text); err != nil {
+		if err := m.sendOSC52Sequence(strings.Join(textParts, " ")); err != nil {
 			slog.Warn("Failed to send OSC52 sequence", "error", err)
 		}
 	}
 
-	return m.executeFinalCommand(strings.TrimRight(text, " "), m.config.MultiCommand)
+	return m.executeFinalCommand(m.config.MultiCommand, textParts...)
 }
 
 // handleSingleSelection processes a single selected item
 func (m *Magonote) handleSingleSelection(item string) error {
-	parts := strings.SplitN(item, ":", 2)
-	if len(parts) != 2 {
+	selection, ok := parseItem(item)
+	if !ok {
 		return nil
 	}
-
-	upcase, text := parts[0], parts[1]
+	text := selection.text
 
 	if m.config.OSC52 {
 		time.Sleep(100 * time.Millisecond) // Wait for redraw
@@ -450,11 +708,11 @@ func (m *Magonote) handleSingleSelection(item string) error {
 	}
 
 	command := m.config.Command
-	if upcase == "true" {
+	if selection.upcase {
 		command = m.config.UpcaseCommand
 	}
 
-	return m.executeFinalCommand(strings.TrimRight(text, " "), command)
+	return m.executeFinalCommand(command, text)
 }
 
 // sendOSC52Sequence sends an OSC52 escape sequence for clipboard integration
@@ -477,19 +735,14 @@ func (m *Magonote) sendOSC52Sequence(text string) error {
 	return osc52Writer.Write(text)
 }
 
-// executeFinalCommand executes the final command with the selected text
-func (m *Magonote) executeFinalCommand(text, command string) error {
-	finalCommand := strings.ReplaceAll(command, "{}", "${magonote}")
-	slog.Info("Executing final command", "text", text, "command", finalCommand)
-	cmd := exec.Command("bash", "-c", "magonote=\"$1\"; eval \"$2\"", "--", text, finalCommand)
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	err := cmd.Run()
+// executeFinalCommand executes the command template with the selected
+// texts, which are escaped so the shell never interprets them
+func (m *Magonote) executeFinalCommand(command string, texts ...string) error {
+	finalCommand := shell.Expand(command, texts...)
+	slog.Info("Executing final command", "texts", texts, "command", finalCommand)
+	stdout, err := m.executor.OutputTimeout(m.config.CommandTimeout, "bash", "-c", finalCommand)
 	if err != nil {
-		slog.Error("Final command execution failed", "error", err, "stderr", stderr.String(), "stdout", stdout.String())
+		slog.Error("Final command execution failed", "error", err, "stdout", stdout)
 	}
 
 	return err
@@ -513,6 +766,12 @@ func (m *Magonote) cleanup() error {
 		return nil
 	}
 
+	if m.zoomed {
+		if _, err := m.tmuxCommand("resize-pane", "-Z", "-t", m.magonotePaneID); err != nil {
+			slog.Warn("Failed to unzoom magonote pane", "error", err)
+		}
+	}
+
 	// Restore original pane layout
 	slog.Debug("Restoring original pane layout")
 	if err := m.swapPanes(m.magonotePaneID, m.activePaneInfo.ID); err != nil {
@@ -552,15 +811,12 @@ func (m *Magonote) killPane(paneID string) error {
 
 // tmuxCommand executes a tmux command and returns its output
 func (m *Magonote) tmuxCommand(args ...string) (string, error) {
-	fullArgs := append([]string{"tmux"}, args...)
-	cmd := exec.Command(fullArgs[0], fullArgs[1:]...)
-
-	output, err := cmd.Output()
+	output, err := m.executor.Output("tmux", args...)
 	if err != nil {
 		return "", fmt.Errorf("tmux command failed: %w", err)
 	}
 
-	return strings.TrimRight(string(output), "\n"), nil
+	return strings.TrimRight(output, "\n"), nil
 }
 
 // parseCommandLineArgs parses command line arguments and returns configuration
@@ -585,8 +841,20 @@ func parseCommandLineArgs() Config {
 	rootCmd.Flags().StringVar(&config.MultiCommand, "multi-command",
 		"tmux set-buffer -- \"{}\" && tmux paste-buffer && tmux display-message \"Multi copied {}\"",
 		"Command to execute after choosing multiple hints")
+	rootCmd.Flags().BoolVar(&config.MultiConfirm, "multi-confirm", false,
+		"Review multiple selections and the resulting multi-command before running it")
 	rootCmd.Flags().BoolVar(&config.OSC52, "osc52", false,
 		"Print OSC52 copy escape sequence in addition to running the pick command")
+	rootCmd.Flags().StringVar(&config.Capture, "capture", capturePane,
+		"Content to pick from: pane for the active pane, all-panes for every pane of the current window")
+	rootCmd.Flags().BoolVar(&config.Last, "last", false,
+		"Reopen the last picker with the content it showed instead of capturing the panes")
+	rootCmd.Flags().BoolVar(&config.Revalidate, "revalidate", false,
+		"Check that the selection is still in the pane before running the pick command, offering to pick again if not")
+	rootCmd.Flags().DurationVar(&config.CommandTimeout, "command-timeout", defaultCommandTimeout,
+		"Kill the pick command if it runs longer than this (0 to disable)")
+	rootCmd.Flags().DurationVar(&config.WaitTimeout, "wait-timeout", 0,
+		"Give up waiting for a selection after this long (0 to wait indefinitely)")
 
 	if err := rootCmd.Execute(); err != nil {
 		slog.Error("Failed to parse command line arguments", "error", err)
@@ -609,6 +877,11 @@ func searchMagonoteBinaryDirectory() (string, error) {
 func main() {
 	config := parseCommandLineArgs()
 
+	if config.Capture != capturePane && config.Capture != captureAllPanes {
+		slog.Error("Invalid --capture, expected pane or all-panes", "capture", config.Capture)
+		os.Exit(1)
+	}
+
 	if config.Dir == "" {
 		slog.Error("Missing --dir flag, trying to determine magonote binary directory")
 		execDir, err := searchMagonoteBinaryDirectory()
@@ -625,7 +898,13 @@ func main() {
 		"command", config.Command,
 		"upcaseCommand", config.UpcaseCommand,
 		"multiCommand", config.MultiCommand,
-		"osc52", config.OSC52)
+		"multiConfirm", config.MultiConfirm,
+		"commandTimeout", config.CommandTimeout,
+		"waitTimeout", config.WaitTimeout,
+		"osc52", config.OSC52,
+		"capture", config.Capture,
+		"last", config.Last,
+		"revalidate", config.Revalidate)
 
 	magonote := New(config)
 	if err := magonote.Run(); err != nil {
@@ -1,8 +1,13 @@
 package main
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
+	"slices"
+	"strconv"
 	"testing"
+	"time"
 )
 
 func TestPaneInfo_HasScrollData(t *testing.T) {
@@ -72,6 +77,7 @@ func TestMagonote_parsePaneInfo(t *testing.T) {
 				ScrollPosition: 0,
 				InMode:         false,
 				Zoomed:         false,
+				CursorY:        -1,
 			},
 			wantErr: false,
 		},
@@ -84,6 +90,17 @@ func TestMagonote_parsePaneInfo(t *testing.T) {
 				ScrollPosition: 15,
 				InMode:         true,
 				Zoomed:         true,
+				CursorY:        -1,
+			},
+			wantErr: false,
+		},
+		{
+			name:  "pane with cursor",
+			parts: []string{"%3", "0", "24", "0", "0", "active", "17"},
+			want: &PaneInfo{
+				ID:      "%3",
+				Height:  24,
+				CursorY: 17,
 			},
 			wantErr: false,
 		},
@@ -115,6 +132,29 @@ func TestMagonote_parsePaneInfo(t *testing.T) {
 	}
 }
 
+func TestMagonote_cursorLine(t *testing.T) {
+	tests := []struct {
+		name string
+		pane *PaneInfo
+		want int
+	}{
+		{name: "no pane", pane: nil, want: 0},
+		{name: "unknown cursor", pane: &PaneInfo{Height: 24, CursorY: -1}, want: 0},
+		{name: "visible pane", pane: &PaneInfo{Height: 24, CursorY: 17}, want: 18},
+		{name: "scrolled up", pane: &PaneInfo{Height: 24, CursorY: 3, InMode: true, ScrollPosition: 10}, want: 14},
+		{name: "scrolled past cursor", pane: &PaneInfo{Height: 24, CursorY: 20, InMode: true, ScrollPosition: 10}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Magonote{activePaneInfo: tt.pane}
+			if got := m.cursorLine(); got != tt.want {
+				t.Errorf("Magonote.cursorLine() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestMagonote_buildScrollParams(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -204,3 +244,193 @@ func TestMagonote_buildCaptureCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestMagonote_buildCaptureCommandAllPanes(t *testing.T) {
+	m := &Magonote{
+		config: Config{Capture: captureAllPanes},
+		panes: []*PaneInfo{
+			{ID: "%1", Height: 24},
+			{ID: "%2", Height: 30, InMode: true, ScrollPosition: 10},
+		},
+	}
+
+	want := "{ printf '%s\\n' '──── pane %1 ────'; tmux capture-pane -J -t %1 -p -e; " +
+		"printf '%s\\n' '──── pane %2 ────'; tmux capture-pane -J -t %2 -p -e -S -10 -E 19 | tail -n 30; }"
+	if got := m.buildCaptureCommand(); got != want {
+		t.Errorf("Magonote.buildCaptureCommand() = %v, want %v", got, want)
+	}
+}
+
+func TestMagonote_newWindowArgs(t *testing.T) {
+	got := (&Magonote{}).newWindowArgs("magonote")
+	want := []string{"new-window", "-P", "-F", "#{pane_id}", "-d", "-n", "[magonote]", "-c", "#{pane_current_path}", "magonote"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Magonote.newWindowArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "plain string",
+			input: "echo {}",
+			want:  "'echo {}'",
+		},
+		{
+			name:  "double quotes are kept",
+			input: `tmux set-buffer -- "{}"`,
+			want:  `'tmux set-buffer -- "{}"'`,
+		},
+		{
+			name:  "single quotes are escaped",
+			input: "echo '{}'",
+			want:  `'echo '\''{}'\'''`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shellQuote(tt.input); got != tt.want {
+				t.Errorf("shellQuote() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMagonote_parseMagonoteOptions(t *testing.T) {
+	output := `@magonote-key space
+@magonote-reverse 1
+@magonote-alphabet "dvorak"
+@magonote-regexp-1 "[A-Z]+\\d+"
+@magonote-regexp-name-jira "[A-Z]+-\\d+"
+@magonote-exclude-text-1 "DEBUG"
+@magonote-exclude-regex-prompt "^\\$ "
+@magonote-also-1 "clipboard"
+@magonote-tabledetection-min-lines 4
+@magonote-tabledetection-confidence "0.6"
+@magonote-colordetection 1
+@magonote-tabledetection 0
+@magonote-unique off
+@magonote-multiline "true"
+status on`
+
+	m := &Magonote{}
+	want := []string{
+		"--reverse",
+		"--alphabet", "'dvorak'",
+		"--regexp", `'[A-Z]+\d+'`,
+		"--regexp-named", `'jira:[A-Z]+-\d+'`,
+		"--exclude-text", "'DEBUG'",
+		"--exclude-regex", `'^\$ '`,
+		"--also", "'clipboard'",
+		"--tabledetection-min-lines", "'4'",
+		"--tabledetection-confidence", "'0.6'",
+		"--colordetection",
+		"--multiline",
+	}
+
+	if got := m.parseMagonoteOptions(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("Magonote.parseMagonoteOptions() = %v, want %v", got, want)
+	}
+}
+
+func TestMissingSelections(t *testing.T) {
+	captured := "$ git log --oneline\n1a2b3c4 Fix build\n$ ls /tmp/output.log\n{\"msg\": \"Fix \\u0022build\\u0022\"}"
+
+	tests := []struct {
+		name   string
+		result string
+		want   []string
+	}{
+		{
+			name:   "single selection still present",
+			result: `false:"1a2b3c4"`,
+			want:   nil,
+		},
+		{
+			name:   "single selection gone",
+			result: `true:"9f8e7d6"`,
+			want:   []string{"9f8e7d6"},
+		},
+		{
+			name:   "multiple selections partly gone",
+			result: "false:\"/tmp/output.log\"\nfalse:\"/tmp/other.log\"",
+			want:   []string{"/tmp/other.log"},
+		},
+		{
+			name:   "text containing the separator",
+			result: `false:"https://example.com":"https://example.com"`,
+			want:   []string{"https://example.com"},
+		},
+		{
+			// The target of a hyperlink isn't shown, its text is
+			name:   "hyperlink",
+			result: `false:"https://example.com/fix/1a2b3c4":"Fix build"`,
+			want:   nil,
+		},
+		{
+			// JSON strings are output decoded
+			name:   "json string",
+			result: `false:"Fix \"build\"":"Fix \\u0022build\\u0022"`,
+			want:   nil,
+		},
+		{
+			name:   "hyperlink gone",
+			result: `false:"https://example.com/docs":"docs"`,
+			want:   []string{"docs"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := missingSelections(captured, tt.result); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("missingSelections() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteSelectionCommandMultiline(t *testing.T) {
+	pem := "-----BEGIN KEY-----\nabc\n-----END KEY-----"
+
+	tests := []struct {
+		name  string
+		texts []string
+		want  string
+	}{
+		{name: "single selection", texts: []string{pem}, want: pem + "\n"},
+		{name: "multiple selections", texts: []string{pem, "x:1"}, want: pem + "\nx:1\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "out")
+			command := "printf '%s\\n' {} > " + out
+			m := New(Config{Command: command, MultiCommand: command, CommandTimeout: 5 * time.Second})
+
+			// The lines magonote writes with -f '%U:%Q'
+			var result string
+			for i, text := range tt.texts {
+				if i > 0 {
+					result += "\n"
+				}
+				result += "false:" + strconv.Quote(text)
+			}
+			if err := m.executeSelectionCommand(result); err != nil {
+				t.Fatalf("executeSelectionCommand() error = %v", err)
+			}
+
+			got, err := os.ReadFile(out)
+			if err != nil {
+				t.Fatalf("Failed to read the command output: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("executeSelectionCommand() wrote %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// sshOptionsWithArgument are the options of the OpenSSH client taking an
+// argument, which comes before the destination
+const sshOptionsWithArgument = "BbcDEeFIiJLlmOoPpQRSWw"
+
+// procDir is where processes are read from, a variable for tests
+var procDir = "/proc"
+
+// remoteHost returns the destination of the ssh session running in the
+// active pane, empty when the pane doesn't run ssh
+func (m *Magonote) remoteHost() string {
+	if m.activePaneInfo == nil || m.activePaneInfo.Command != "ssh" || m.activePaneInfo.PID == 0 {
+		return ""
+	}
+
+	host, err := sshHost(m.activePaneInfo.PID)
+	if err != nil {
+		slog.Warn("Failed to find the ssh destination of the pane", "pid", m.activePaneInfo.PID, "error", err)
+		return ""
+	}
+	slog.Debug("Pane runs ssh", "paneID", m.activePaneInfo.ID, "host", host)
+	return host
+}
+
+// sshHost returns the destination of the first ssh client found under the
+// process pid, itself included
+func sshHost(pid int) (string, error) {
+	children, comms, err := processTree()
+	if err != nil {
+		return "", err
+	}
+
+	queue := []int{pid}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if comms[current] == "ssh" {
+			cmdline, err := os.ReadFile(filepath.Join(procDir, strconv.Itoa(current), "cmdline"))
+			if err != nil {
+				return "", fmt.Errorf("reading command line of %d: %w", current, err)
+			}
+			args := strings.Split(strings.TrimRight(string(cmdline), "\x00"), "\x00")
+			if host := sshDestination(args[1:]); host != "" {
+				return host, nil
+			}
+		}
+		queue = append(queue, children[current]...)
+	}
+	return "", fmt.Errorf("no ssh client under process %d", pid)
+}
+
+// processTree returns the children and the command name of every process
+func processTree() (map[int][]int, map[int]string, error) {
+	entries, err := os.ReadDir(procDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing processes: %w", err)
+	}
+
+	children := make(map[int][]int)
+	comms := make(map[int]string)
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		// Processes may exit meanwhile
+		stat, err := os.ReadFile(filepath.Join(procDir, entry.Name(), "stat"))
+		if err != nil {
+			continue
+		}
+		comm, ppid, ok := parseStat(string(stat))
+		if !ok {
+			continue
+		}
+		comms[pid] = comm
+		children[ppid] = append(children[ppid], pid)
+	}
+	return children, comms, nil
+}
+
+// parseStat returns the command name and the parent PID of a
+// /proc/<pid>/stat line, "pid (comm) state ppid ...". The name may hold
+// spaces and parentheses
+func parseStat(stat string) (string, int, bool) {
+	open, end := strings.IndexByte(stat, '('), strings.LastIndexByte(stat, ')')
+	if open < 0 || end < open {
+		return "", 0, false
+	}
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 2 {
+		return "", 0, false
+	}
+	ppid, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return "", 0, false
+	}
+	return stat[open+1 : end], ppid, true
+}
+
+// sshDestination returns the [user@]host an ssh client connects to given
+// its arguments, empty if there is none
+func sshDestination(args []string) string {
+	var user string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			if i+1 < len(args) {
+				return withUser(args[i+1], user)
+			}
+			return ""
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			return withUser(arg, user)
+		}
+
+		// Options may be grouped, the first one taking an argument ends
+		// the group, the argument following it or being the next one
+		for j := 1; j < len(arg); j++ {
+			if !strings.ContainsRune(sshOptionsWithArgument, rune(arg[j])) {
+				continue
+			}
+			value := arg[j+1:]
+			if value == "" && i+1 < len(args) {
+				i++
+				value = args[i]
+			}
+			if arg[j] == 'l' {
+				user = value
+			}
+			break
+		}
+	}
+	return ""
+}
+
+// withUser returns the host of destination, a [user@]host or an
+// ssh://[user@]host[:port] URI, with user unless it has its own
+func withUser(destination, user string) string {
+	if rest, ok := strings.CutPrefix(destination, "ssh://"); ok {
+		rest, _, _ = strings.Cut(rest, "/")
+		at := strings.LastIndexByte(rest, '@')
+		host := rest[at+1:]
+		if !strings.HasPrefix(host, "[") {
+			host, _, _ = strings.Cut(host, ":")
+		}
+		destination = rest[:at+1] + host
+	}
+	if user != "" && !strings.Contains(destination, "@") {
+		return user + "@" + destination
+	}
+	return destination
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSSHDestination(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"host", []string{"example.com"}, "example.com"},
+		{"user and host", []string{"deploy@10.0.0.5", "uptime"}, "deploy@10.0.0.5"},
+		{"options with arguments", []string{"-p", "2222", "-i", "~/.ssh/id", "-A", "box"}, "box"},
+		{"grouped options", []string{"-vtp2222", "box"}, "box"},
+		{"login name", []string{"-l", "root", "box"}, "root@box"},
+		{"attached login name", []string{"-lroot", "box"}, "root@box"},
+		{"uri", []string{"ssh://me@box:2222"}, "me@box"},
+		{"after double dash", []string{"-4", "--", "box"}, "box"},
+		{"no destination", []string{"-V"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sshDestination(tt.args); got != tt.want {
+				t.Errorf("sshDestination(%q) = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSSHHost(t *testing.T) {
+	dir := t.TempDir()
+	procDir = dir
+	defer func() { procDir = "/proc" }()
+
+	// A shell running sudo running ssh, next to an unrelated ssh
+	processes := map[string][2]string{
+		"10": {"10 (bash) S 1 10", "bash\x00"},
+		"11": {"11 (sudo (x)) S 10 10", "sudo\x00ssh\x00"},
+		"12": {"12 (ssh) S 11 10", "ssh\x00-p\x002222\x00me@box\x00"},
+		"20": {"20 (ssh) S 1 20", "ssh\x00other\x00"},
+	}
+	for pid, files := range processes {
+		if err := os.Mkdir(filepath.Join(dir, pid), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, pid, "stat"), []byte(files[0]), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, pid, "cmdline"), []byte(files[1]), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	host, err := sshHost(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if host != "me@box" {
+		t.Errorf("Expected me@box, got %q", host)
+	}
+	if _, err := sshHost(11 + 100); err == nil {
+		t.Error("Expected an error without an ssh client")
+	}
+}
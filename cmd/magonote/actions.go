@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/Hanaasagi/magonote/internal"
+	"github.com/Hanaasagi/magonote/internal/shell"
+)
+
+// remotePathPattern matches the remote paths of scp, [user@]host:path with
+// an absolute or home relative path
+var remotePathPattern = regexp.MustCompile(`^((?:[\w.-]+@)?[\w.-]+):([/~].*)$`)
+
+// remoteActions are the actions of the matches on another host: the remote
+// paths, and every match when the input comes from an ssh session
+type remoteActions struct {
+	actions map[string]string
+	host    string // Host of the ssh session, empty outside of one
+}
+
+// resolve returns the remote action of item with the host and path it is
+// run with, ok is false when item isn't remote or has no remote action
+func (r remoteActions) resolve(item internal.ChosenMatch) (command, host, path string, ok bool) {
+	host, path = r.host, item.Text
+	if match := remotePathPattern.FindStringSubmatch(item.Text); match != nil && !strings.HasPrefix(match[2], "//") {
+		host, path = match[1], match[2]
+	}
+	command = r.actions[item.Pattern]
+	return command, host, path, host != "" && command != ""
+}
+
+// resolveActions returns the action command templates keyed by pattern name,
+// user defined actions override the git defaults
+func resolveActions(git bool, user map[string]string) map[string]string {
+	actions := make(map[string]string, len(internal.DefaultGitActions)+len(user))
+	if git {
+		maps.Copy(actions, internal.DefaultGitActions)
+	}
+	maps.Copy(actions, user)
+	return actions
+}
+
+// runActions runs the action of every match selected with the run-action key,
+// the remote one for matches on another host, and returns the matches that
+// are left for output. The open action opens the match with opener, the
+// others run attached to commands
+func runActions(selected []internal.ChosenMatch, actions map[string]string, remote remoteActions, opener *URLOpener, commands *streams) ([]internal.ChosenMatch, error) {
+	remaining := make([]internal.ChosenMatch, 0, len(selected))
+	for _, item := range selected {
+		if !item.RunAction {
+			remaining = append(remaining, item)
+			continue
+		}
+
+		if command, host, path, ok := remote.resolve(item); ok {
+			slog.Info("Running remote action", "pattern", item.Pattern, "command", command, "match", item.Text, "host", host)
+			vars := map[string]string{"host": host, "path": path}
+			if err := runAction(command, item.Text, vars, commands); err != nil {
+				return nil, fmt.Errorf("running remote action for %s: %w", item.Pattern, err)
+			}
+			continue
+		}
+
+		command, ok := actions[item.Pattern]
+		if !ok || command == "" {
+			slog.Warn("No action configured for pattern, outputting match instead", "pattern", item.Pattern)
+			remaining = append(remaining, item)
+			continue
+		}
+
+		if command == openAction {
+			slog.Info("Opening match", "pattern", item.Pattern, "match", item.Text)
+			if err := opener.Open(item.Text); err != nil {
+				return nil, fmt.Errorf("opening %s: %w", item.Text, err)
+			}
+			continue
+		}
+
+		slog.Info("Running action", "pattern", item.Pattern, "command", command, "match", item.Text)
+		if err := runAction(command, item.Text, nil, commands); err != nil {
+			return nil, fmt.Errorf("running action for %s: %w", item.Pattern, err)
+		}
+	}
+	return remaining, nil
+}
+
+// runAction runs the command template attached to commands, {} being
+// replaced by the match and every {name} of vars by its value. They are
+// escaped so they are never interpreted by the shell
+func runAction(command, text string, vars map[string]string, commands *streams) error {
+	cmd := exec.Command("sh", "-c", shell.ExpandVars(command, vars, text))
+	commands.attach(cmd)
+
+	return cmd.Run()
+}
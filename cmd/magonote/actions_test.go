@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Hanaasagi/magonote/internal"
+)
+
+func TestResolveActions(t *testing.T) {
+	if actions := resolveActions(false, nil); len(actions) != 0 {
+		t.Errorf("Expected no actions outside a git repository, got %v", actions)
+	}
+
+	actions := resolveActions(true, map[string]string{"git_branch": "git switch {}", "url": "xdg-open {}"})
+	if actions["git_branch"] != "git switch {}" {
+		t.Errorf("Expected user action to override the default, got %q", actions["git_branch"])
+	}
+	if actions["sha"] != "git show {}" {
+		t.Errorf("Expected default sha action, got %q", actions["sha"])
+	}
+	if actions["url"] != "xdg-open {}" {
+		t.Errorf("Expected user url action, got %q", actions["url"])
+	}
+}
+
+func TestRunActions(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	actions := map[string]string{"path": "printf %s {} > " + out}
+
+	selected := []internal.ChosenMatch{
+		{Text: "a b; echo injected", Pattern: "path", RunAction: true},
+		{Text: "192.168.1.1", Pattern: "ipv4", RunAction: true},
+		{Text: "/tmp", Pattern: "path"},
+	}
+
+	remaining, err := runActions(selected, actions, remoteActions{}, NewURLOpener(""), nil)
+	if err != nil {
+		t.Fatalf("runActions() error = %v", err)
+	}
+
+	if len(remaining) != 2 || remaining[0].Text != "192.168.1.1" || remaining[1].Text != "/tmp" {
+		t.Errorf("Expected matches without action to remain, got %v", remaining)
+	}
+
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Failed to read action output: %v", err)
+	}
+	if string(got) != "a b; echo injected" {
+		t.Errorf("Expected the match to be passed verbatim, got %q", got)
+	}
+}
+
+func TestRunRemoteActions(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	remote := remoteActions{
+		actions: map[string]string{"path": "printf '%s %s\\n' {host} {path} >> " + out},
+	}
+	actions := map[string]string{"path": "printf 'local %s\\n' {} >> " + out}
+
+	selected := []internal.ChosenMatch{
+		{Text: "me@box:/var/log/syslog", Pattern: "path", RunAction: true},
+		{Text: "/etc/hosts", Pattern: "path", RunAction: true},
+		{Text: "http://example.com/a", Pattern: "path", RunAction: true},
+	}
+	if _, err := runActions(selected, actions, remote, nil, nil); err != nil {
+		t.Fatalf("runActions() error = %v", err)
+	}
+
+	// Every match of an ssh session is remote
+	remote.host = "gw"
+	if _, err := runActions(selected[1:2], actions, remote, nil, nil); err != nil {
+		t.Fatalf("runActions() error = %v", err)
+	}
+
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Failed to read action output: %v", err)
+	}
+	expected := "me@box /var/log/syslog\nlocal /etc/hosts\nlocal http://example.com/a\ngw /etc/hosts\n"
+	if string(got) != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestRunActionsStreams(t *testing.T) {
+	var out bytes.Buffer
+	commands := &streams{stdin: bytes.NewReader(nil), stdout: &out, stderr: &out}
+	selected := []internal.ChosenMatch{{Text: "/tmp", Pattern: "path", RunAction: true}}
+
+	if _, err := runActions(selected, map[string]string{"path": "printf %s {}"}, remoteActions{}, nil, commands); err != nil {
+		t.Fatalf("runActions() error = %v", err)
+	}
+	if out.String() != "/tmp" {
+		t.Errorf("Expected the action to write to the given streams, got %q", out.String())
+	}
+}
+
+func TestCommandStreams(t *testing.T) {
+	if commands, _, err := (streams{}).commandStreams(); err != nil || commands != nil {
+		t.Errorf("Expected the streams of the process outside of magonote serve, got %v, %v", commands, err)
+	}
+
+	if _, _, err := (streams{terminal: &clientTerminal{}}).commandStreams(); err == nil {
+		t.Error("Expected an error for a client of magonote serve without a terminal")
+	}
+
+	device := filepath.Join(t.TempDir(), "tty")
+	if err := os.WriteFile(device, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	commands, release, err := (streams{terminal: &clientTerminal{device: device}}).commandStreams()
+	if err != nil {
+		t.Fatalf("commandStreams() error = %v", err)
+	}
+	defer release()
+	if commands == nil || commands.stdout == nil {
+		t.Errorf("Expected the terminal of the client, got %v", commands)
+	}
+}
@@ -3,15 +3,45 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/BurntSushi/toml"
+
+	"github.com/Hanaasagi/magonote/internal"
 )
 
 type Config struct {
-	Core    CoreConfig    `toml:"core"`
-	Rules   RulesConfig   `toml:"rules"`
-	Colors  ColorConfig   `toml:"colors"`
-	Plugins PluginsConfig `toml:"plugins"`
+	Core      CoreConfig      `toml:"core"`
+	Rules     RulesConfig     `toml:"rules"`
+	Colors    ColorConfig     `toml:"colors"`
+	Plugins   PluginsConfig   `toml:"plugins"`
+	Keys      KeysConfig      `toml:"keys"`
+	Git       GitConfig       `toml:"git"`
+	Processes ProcessesConfig `toml:"processes"`
+	History   HistoryConfig   `toml:"history"`
+	Limits    LimitsConfig    `toml:"limits"`
+
+	// Alphabets defines custom hint alphabets by name, usable as core.alphabet
+	Alphabets map[string]string `toml:"alphabets"`
+
+	// Actions maps pattern names to the command run by the run-action key,
+	// {} is replaced by the match
+	Actions map[string]string `toml:"actions"`
+
+	// RemoteActions maps pattern names to the command run by the run-action
+	// key on remote paths and on the matches of an ssh session, {host} and
+	// {path} are replaced by the host and the path
+	RemoteActions map[string]string `toml:"remote_actions"`
+
+	// Patterns holds per-pattern settings keyed by pattern name
+	Patterns map[string]PatternSettings `toml:"patterns"`
+
+	// Profiles holds named sets of settings selected with --profile, see
+	// applyProfile
+	Profiles map[string]toml.Primitive `toml:"profile"`
+
+	// meta is the metadata of the config file, decoding the profiles
+	meta toml.MetaData
 }
 
 type CoreConfig struct {
@@ -22,6 +52,47 @@ type CoreConfig struct {
 	Reverse     bool   `toml:"reverse"`
 	UniqueLevel int    `toml:"unique_level"`
 	Contrast    bool   `toml:"contrast"`
+	// Proximity assigns the shortest hints to the matches closest to the cursor
+	Proximity bool `toml:"proximity"`
+	// PrefixSelect selects matches by typing the start of their text
+	PrefixSelect bool `toml:"prefix_select"`
+	// HintPages gives hints to a page of matches at a time when there are
+	// more matches than hints of two characters
+	HintPages bool `toml:"hint_pages"`
+	// StatusBar shows the mode, the number of matches, the hidden patterns
+	// and the typed keys on the bottom line of the view
+	StatusBar bool `toml:"status_bar"`
+	// ListVim navigates the list view with vim keys and row numbers
+	ListVim bool `toml:"list_vim"`
+	// Scope restricts matches to the output of the last command with
+	// "last-command", "all" keeps every line
+	Scope string `toml:"scope"`
+	// MinMatchLength drops matches shorter than this many characters
+	MinMatchLength int `toml:"min_match_length"`
+	// Mode also hints the words the patterns miss with "words" or
+	// "identifiers", or hints the lines instead with "lines". "patterns"
+	// only hints the matches of the patterns
+	Mode string `toml:"mode"`
+	// WordMinLength drops the words of Mode shorter than this many characters
+	WordMinLength int `toml:"word_min_length"`
+	// JSONInput parses the input as JSON even when it doesn't start with
+	// "{" or "[", hinting its string and number values
+	JSONInput bool `toml:"json_input"`
+	// Multiline also matches PEM blocks, shell commands continued with a
+	// trailing backslash and JSON strings holding line breaks across lines
+	Multiline bool `toml:"multiline"`
+	// RedactPrivateKeys leaves private key blocks out of the matches, the
+	// saved last session and the recordings
+	RedactPrivateKeys bool `toml:"redact_private_keys"`
+	// TabWidth is the number of columns between tab stops, tabs being
+	// drawn up to the next one. Unset is 8, negative draws them as is
+	TabWidth int `toml:"tab_width"`
+	// Browser is the command opening URLs for the open action, $BROWSER or
+	// the system opener when empty
+	Browser string `toml:"browser"`
+	// Bidi puts the hints of matches on lines with right-to-left text at the
+	// start of the line with "gutter", "inline" keeps them next to the matches
+	Bidi string `toml:"bidi"`
 }
 
 // RulesConfig unifies user-defined include (match) and exclude (filter) rules
@@ -42,10 +113,78 @@ type ColorGroup struct {
 }
 
 type ColorConfig struct {
+	// Theme is the preset of the colors that aren't configured
+	Theme string `toml:"theme"`
+
 	Match  ColorGroup `toml:"match"`
 	Hint   ColorGroup `toml:"hint"`
 	Multi  ColorGroup `toml:"multi"`
 	Select ColorGroup `toml:"select"`
+	// Status colors the status bar, see core.status_bar
+	Status ColorGroup `toml:"status"`
+
+	// Patterns overrides the hint colors per pattern name
+	Patterns map[string]ColorGroup `toml:"patterns"`
+	// Columns are the hint foregrounds of the columns of detected tables,
+	// taken in turn. Empty, the default, uses the hint colors for every
+	// column
+	Columns []string `toml:"columns"`
+
+	// explicit holds the keys of colorKeys set by the file or flags, which
+	// the theme doesn't override
+	explicit map[string]bool
+}
+
+// PatternSettings configures how matches of a single pattern are handled
+type PatternSettings struct {
+	// Matches shorter than this many characters are highlighted without a hint
+	HintMinLength int `toml:"hint_min_length"`
+	// Strip unbalanced trailing punctuation, unset keeps the pattern default
+	// (enabled for url, path and ip patterns)
+	TrimPunctuation *bool `toml:"trim_punctuation"`
+	// Matches shorter than this many characters are dropped, overriding
+	// core.min_match_length
+	MinLength int `toml:"min_length"`
+	// Matches preceded or followed by a character of these classes, such as
+	// `[\w-]`, are dropped
+	NotPrecededBy string `toml:"not_preceded_by"`
+	NotFollowedBy string `toml:"not_followed_by"`
+	// Overlapping matches of the same length go to the pattern with the
+	// highest priority
+	Priority int `toml:"priority"`
+}
+
+// KeysConfig maps actions (quit, confirm, toggle-multi, ...) to key names
+// Actions that are not listed keep their default bindings
+type KeysConfig map[string][]string
+
+// GitConfig configures the git patterns and actions
+type GitConfig struct {
+	// Enabled turns on git detection when the working directory is inside a git repository
+	Enabled bool `toml:"enabled"`
+}
+
+// ProcessesConfig configures the pid and port patterns of ss, netstat, lsof
+// and ps output
+type ProcessesConfig struct {
+	Enabled bool `toml:"enabled"`
+}
+
+// HistoryConfig configures the history of selected values that get shorter hints
+type HistoryConfig struct {
+	Enabled    bool `toml:"enabled"`
+	MaxEntries int  `toml:"max_entries"`
+}
+
+// LimitsConfig bounds the input and the matches so that huge inputs are cut
+// short instead of freezing the terminal, zero disables a limit
+type LimitsConfig struct {
+	// MaxLines is the number of input lines read, the following are dropped
+	MaxLines int `toml:"max_lines"`
+	// MaxLineLength is the number of bytes kept of every input line
+	MaxLineLength int `toml:"max_line_length"`
+	// MaxMatches is the number of matches shown, the last ones are dropped
+	MaxMatches int `toml:"max_matches"`
 }
 
 type TableDetectionPluginConfig struct {
@@ -53,21 +192,47 @@ type TableDetectionPluginConfig struct {
 	MinLines            int     `toml:"min_lines"`
 	MinColumns          int     `toml:"min_columns"`
 	ConfidenceThreshold float64 `toml:"confidence_threshold"`
+	// Columns restricts the hints of the tables having one of its columns
+	Columns *TableColumnsConfig `toml:"columns"`
+}
+
+type TableColumnsConfig struct {
+	// Names are the headers of the columns, case insensitive
+	Names []string `toml:"names"`
 }
 
 type ColorDetectionPluginConfig struct {
 	Enabled bool `toml:"enabled"`
 }
 
+type LogDetectionPluginConfig struct {
+	Enabled bool `toml:"enabled"`
+}
+
+type SecretsPluginConfig struct {
+	Enabled bool `toml:"enabled"`
+	// Action is "exclude" to give no hint to likely credentials, or "mask"
+	// to draw them as asterisks
+	Action string `toml:"action"`
+}
+
 // Rule describes a single rule item used in include/exclude lists
 type Rule struct {
 	Type    string `toml:"type"`    // "regex" or "text"
+	Name    string `toml:"name"`    // Optional pattern name for include rules, defaults to "custom"
 	Pattern string `toml:"pattern"` // The pattern or text to exclude
+	// Multiline matches include rules against the lines joined by newlines
+	Multiline bool `toml:"multiline"`
 }
 
 type PluginsConfig struct {
+	// Auto turns table and color detection on when the input looks like it
+	// needs them, unless they are configured
+	Auto           bool                        `toml:"auto"`
 	Tabledetection *TableDetectionPluginConfig `toml:"tabledetection"`
 	Colordetection *ColorDetectionPluginConfig `toml:"colordetection"`
+	Logdetection   *LogDetectionPluginConfig   `toml:"logdetection"`
+	Secrets        *SecretsPluginConfig        `toml:"secrets"`
 }
 
 func NewDefaultConfig() *Config {
@@ -80,9 +245,13 @@ func NewDefaultConfig() *Config {
 			Reverse:     false,
 			UniqueLevel: 0,
 			Contrast:    false,
+			Scope:       internal.ScopeAll,
+			Mode:        internal.ModePatterns,
+			Bidi:        internal.BidiGutter,
 		},
 		Rules: RulesConfig{Include: RulesList{Rules: []Rule{}}, Exclude: RulesList{Rules: []Rule{}}},
 		Colors: ColorConfig{
+			Theme: defaultTheme,
 			Match: ColorGroup{
 				Foreground: "green",
 				Background: "black",
@@ -99,23 +268,61 @@ func NewDefaultConfig() *Config {
 				Foreground: "blue",
 				Background: "black",
 			},
+			Status: ColorGroup{
+				Foreground: "black",
+				Background: "white",
+			},
 		},
 		Plugins: PluginsConfig{
+			Auto:           true,
 			Tabledetection: nil,
 			Colordetection: nil,
+			Logdetection:   nil,
+		},
+		Git: GitConfig{
+			Enabled: true,
+		},
+		Processes: ProcessesConfig{
+			Enabled: true,
+		},
+		History: HistoryConfig{
+			Enabled:    false,
+			MaxEntries: internal.DefaultHistorySize,
+		},
+		Limits: LimitsConfig{
+			MaxLines:      defaultMaxLines,
+			MaxLineLength: defaultMaxLineLength,
+			MaxMatches:    defaultMaxMatches,
 		},
 	}
 }
 
 func LoadConfigFromFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return NewDefaultConfig(), nil // no config file, return defaults
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+	return parseConfig(string(data))
+}
+
+// parseConfig decodes the TOML of a config file over the defaults
+func parseConfig(data string) (*Config, error) {
 	config := NewDefaultConfig()
 
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return config, nil // no config file, return defaults
+	meta, err := toml.Decode(data, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode TOML config: %w", err)
 	}
+	config.meta = meta
 
-	if _, err := toml.DecodeFile(path, config); err != nil {
-		return nil, fmt.Errorf("failed to decode TOML config: %w", err)
+	for key := range config.Colors.colorKeys() {
+		group, field, _ := strings.Cut(key, ".")
+		if meta.IsDefined("colors", group, field) {
+			config.Colors.setExplicit(key)
+		}
 	}
 	return config, nil
 }
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+)
+
+// newConfigCmd creates the config command and its subcommands
+func newConfigCmd() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Work with the config file",
+	}
+
+	configCmd.AddCommand(&cobra.Command{
+		Use:          "check [file]",
+		Short:        "Check the config file for syntax errors, unknown keys and bad regexes",
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := configFilePath("")
+			if len(args) > 0 {
+				path = args[0]
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("reading config: %w", err)
+			}
+
+			issues := checkConfig(string(data))
+			printConfigIssues(cmd.OutOrStdout(), path, issues)
+			if len(issues) > 0 {
+				return fmt.Errorf("%s has %d problem(s)", path, len(issues))
+			}
+			return nil
+		},
+	})
+
+	return configCmd
+}
+
+// configIssue is a problem of the config file, at a line starting at 1 or 0
+// when unknown
+type configIssue struct {
+	line    int
+	column  int
+	message string
+}
+
+// printConfigIssues writes the issues in the file:line:column: message form
+// of compilers, so that editors can jump to them
+func printConfigIssues(w io.Writer, path string, issues []configIssue) {
+	if len(issues) == 0 {
+		fmt.Fprintf(w, "%s: ok\n", path)
+		return
+	}
+	for _, issue := range issues {
+		switch {
+		case issue.line == 0:
+			fmt.Fprintf(w, "%s: %s\n", path, issue.message)
+		case issue.column == 0:
+			fmt.Fprintf(w, "%s:%d: %s\n", path, issue.line, issue.message)
+		default:
+			fmt.Fprintf(w, "%s:%d:%d: %s\n", path, issue.line, issue.column, issue.message)
+		}
+	}
+}
+
+// checkConfig validates a config file: its TOML syntax and value types, keys
+// that magonote doesn't know, themes and the regexes of the rules and
+// pattern settings, profiles included
+func checkConfig(data string) []configIssue {
+	config := NewDefaultConfig()
+	meta, err := toml.Decode(data, config)
+	if err != nil {
+		var parseErr toml.ParseError
+		if errors.As(err, &parseErr) {
+			return []configIssue{{line: parseErr.Position.Line, column: parseErr.Position.Col, message: parseErr.Message}}
+		}
+		return []configIssue{{message: err.Error()}}
+	}
+
+	lines := keyLines(data)
+	var issues []configIssue
+	check := func(prefix string, c *Config) {
+		issues = append(issues, checkConfigValues(prefix, c, lines)...)
+	}
+	check("", config)
+
+	for _, name := range slices.Sorted(maps.Keys(config.Profiles)) {
+		profile := &Config{}
+		if err := meta.PrimitiveDecode(config.Profiles[name], profile); err != nil {
+			issues = append(issues, configIssue{line: lines["profile."+name], message: fmt.Sprintf("profile %s: %v", name, err)})
+			continue
+		}
+		check("profile."+name+".", profile)
+	}
+
+	// Undecoded after the profiles, whose keys are only decoded then. The keys
+	// of an unknown table aren't reported on top of it
+	unknown := map[string]bool{}
+	for _, key := range meta.Undecoded() {
+		if len(key) > 1 && unknown[key[:len(key)-1].String()] {
+			unknown[key.String()] = true
+			continue
+		}
+		unknown[key.String()] = true
+		issues = append(issues, configIssue{line: lines[key.String()], message: fmt.Sprintf("unknown key %s", key)})
+	}
+
+	slices.SortStableFunc(issues, func(a, b configIssue) int {
+		return a.line - b.line
+	})
+	return issues
+}
+
+// checkConfigValues validates the values of a decoded config or profile,
+// whose keys start with prefix
+func checkConfigValues(prefix string, c *Config, lines map[string]int) []configIssue {
+	var issues []configIssue
+
+	if c.Colors.Theme != "" {
+		if _, ok := themes[c.Colors.Theme]; !ok {
+			issues = append(issues, configIssue{
+				line:    lines[prefix+"colors.theme"],
+				message: fmt.Sprintf("unknown theme %q, expected one of %s", c.Colors.Theme, strings.Join(themeNames(), ", ")),
+			})
+		}
+	}
+
+	for section, rules := range map[string][]Rule{
+		"rules.include.rules": c.Rules.Include.Rules,
+		"rules.exclude.rules": c.Rules.Exclude.Rules,
+	} {
+		for i, rule := range rules {
+			if rule.Type != "regex" {
+				continue
+			}
+			if _, err := regexp.Compile(rule.Pattern); err != nil {
+				key := fmt.Sprintf("%s%s[%d].pattern", prefix, section, i)
+				issues = append(issues, configIssue{line: lines[key], message: fmt.Sprintf("bad regex in %s: %v", key, err)})
+			}
+		}
+	}
+
+	for name, settings := range c.Patterns {
+		for field, class := range map[string]string{
+			"not_preceded_by": settings.NotPrecededBy,
+			"not_followed_by": settings.NotFollowedBy,
+		} {
+			if _, err := regexp.Compile(class); err != nil {
+				key := prefix + "patterns." + name + "." + field
+				issues = append(issues, configIssue{line: lines[key], message: fmt.Sprintf("bad regex in %s: %v", key, err)})
+			}
+		}
+	}
+	return issues
+}
+
+// keyLines maps the keys of a TOML document to the lines defining them, as
+// the TOML decoder doesn't tell. The entries of arrays of tables are keyed
+// both with their index, as in rules.include.rules[0].pattern, and without
+// for their first entry. Keys of inline tables aren't mapped
+func keyLines(data string) map[string]int {
+	lines := map[string]int{}
+	set := func(key string, line int) {
+		if _, ok := lines[key]; !ok {
+			lines[key] = line
+		}
+	}
+
+	entries := map[string]int{} // Entries seen of each array of tables
+	var table, indexedTable string
+	var closing string // Delimiter closing the multiline string being read
+	depth := 0         // Depth of the multiline array being read
+
+	for i, line := range strings.Split(data, "\n") {
+		n := i + 1
+		if closing != "" {
+			if strings.Contains(line, closing) {
+				closing = ""
+			}
+			continue
+		}
+		code := stripTOMLStrings(line)
+		if depth > 0 {
+			depth += strings.Count(code, "[") - strings.Count(code, "]")
+			continue
+		}
+
+		trimmed := strings.TrimSpace(code)
+		// The header of a table, without its comment
+		header := strings.TrimSpace(line)
+		header = header[:strings.LastIndex(header, "]")+1]
+		switch {
+		case trimmed == "":
+		case strings.HasPrefix(trimmed, "[["):
+			table = tomlKey(strings.TrimSuffix(header[2:], "]]"))
+			indexedTable = fmt.Sprintf("%s[%d]", table, entries[table])
+			entries[table]++
+			set(table, n)
+			set(indexedTable, n)
+		case strings.HasPrefix(trimmed, "["):
+			table = tomlKey(strings.TrimSuffix(header[1:], "]"))
+			indexedTable = table
+			set(table, n)
+		default:
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				continue
+			}
+			key = tomlKey(key)
+			if table != "" {
+				set(indexedTable+"."+key, n)
+				key = table + "." + key
+			}
+			set(key, n)
+
+			value = strings.TrimSpace(value)
+			for _, delim := range []string{`"""`, "'''"} {
+				if strings.HasPrefix(value, delim) && !strings.Contains(value[len(delim):], delim) {
+					closing = delim
+				}
+			}
+			depth = strings.Count(code, "[") - strings.Count(code, "]")
+		}
+	}
+	return lines
+}
+
+// tomlKey normalizes a possibly dotted and quoted TOML key to the form of
+// toml.Key.String
+func tomlKey(key string) string {
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if len(part) >= 2 && (part[0] == '"' || part[0] == '\'') && part[len(part)-1] == part[0] {
+			part = part[1 : len(part)-1]
+		}
+		parts[i] = part
+	}
+	return strings.Join(parts, ".")
+}
+
+// stripTOMLStrings removes the strings and the comment of a TOML line, so that
+// the brackets left are those of arrays and tables
+func stripTOMLStrings(line string) string {
+	var b strings.Builder
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return b.String()
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
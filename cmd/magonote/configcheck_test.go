@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCheckConfig(t *testing.T) {
+	config := `[core]
+alphabet = "qwerty" # home row
+colour = "red"
+
+[[rules.include.rules]]
+type = "regex"
+pattern = "ok"
+
+[[rules.include.rules]]
+type = "regex"
+pattern = "bad("
+
+[patterns.url]
+not_preceded_by = "[a-"
+
+[profile.work.core]
+nope = 1
+`
+	var out bytes.Buffer
+	printConfigIssues(&out, "config.toml", checkConfig(config))
+
+	want := []string{
+		"config.toml:3: unknown key core.colour",
+		"config.toml:11: bad regex in rules.include.rules[1].pattern",
+		"config.toml:14: bad regex in patterns.url.not_preceded_by",
+		"config.toml:17: unknown key profile.work.core.nope",
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("Expected %d issues, got:\n%s", len(want), out.String())
+	}
+	for i, prefix := range want {
+		if !strings.HasPrefix(lines[i], prefix) {
+			t.Errorf("Expected issue %q, got %q", prefix, lines[i])
+		}
+	}
+}
+
+func TestCheckConfigSyntaxError(t *testing.T) {
+	issues := checkConfig("[core]\nalphabet = \"qwerty\n")
+	if len(issues) != 1 || issues[0].line != 2 || issues[0].column == 0 {
+		t.Errorf("Expected a syntax error on line 2 with its column, got %+v", issues)
+	}
+
+	issues = checkConfig("[core]\nalphabet = \"qwerty\"\n")
+	if len(issues) != 0 {
+		t.Errorf("Expected no issues, got %+v", issues)
+	}
+}
+
+func TestKeyLines(t *testing.T) {
+	lines := keyLines(`# comment
+[core]
+format = """
+a = b
+"""
+[colors.patterns."url"] # quoted
+foreground = "blue"
+[[rules.exclude.rules]]
+pattern = "[x"
+[[rules.exclude.rules]]
+list = [
+  "a = b",
+]
+pattern = "y"
+`)
+
+	tests := map[string]int{
+		"core.format":                    3,
+		"colors.patterns.url.foreground": 7,
+		"rules.exclude.rules[0].pattern": 9,
+		"rules.exclude.rules[1].pattern": 14,
+		"rules.exclude.rules.pattern":    9,
+		"rules.exclude.rules[1].list":    11,
+	}
+	for key, want := range tests {
+		if got := lines[key]; got != want {
+			t.Errorf("Expected %s on line %d, got %d", key, want, got)
+		}
+	}
+	if _, ok := lines["a"]; ok {
+		t.Error("Expected keys inside strings to be skipped")
+	}
+}
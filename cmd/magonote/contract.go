@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Hanaasagi/magonote/internal"
+)
+
+// The command line flags, the --format placeholders and the result they
+// produce form the contract that frontends (tmux, editors, shells) build on.
+// It follows semantic versioning through Version: removing or changing any
+// of them requires a major bump, adding one requires a minor bump. The
+// golden files in testdata/contract pin the current surface.
+
+// formatPlaceholder is a --format token and the result field it expands to
+type formatPlaceholder struct {
+	Token       string
+	Since       string // Version the placeholder was introduced in
+	Description string
+	value       func(item internal.ChosenMatch) string
+}
+
+// formatPlaceholders lists every supported --format token.
+// Coordinates and index are 1-based like compiler and grep output
+var formatPlaceholders = []formatPlaceholder{
+	{Token: "%H", Since: "0.1.0", Description: "hint text", value: func(item internal.ChosenMatch) string {
+		return item.Text
+	}},
+	{Token: "%U", Since: "0.1.0", Description: "uppercase flag", value: func(item internal.ChosenMatch) string {
+		return strconv.FormatBool(item.Uppercase)
+	}},
+	{Token: "%P", Since: "0.2.0", Description: "pattern name", value: func(item internal.ChosenMatch) string {
+		return item.Pattern
+	}},
+	{Token: "%X", Since: "0.2.0", Description: "column", value: func(item internal.ChosenMatch) string {
+		return strconv.Itoa(item.X + 1)
+	}},
+	{Token: "%Y", Since: "0.2.0", Description: "line", value: func(item internal.ChosenMatch) string {
+		return strconv.Itoa(item.Y + 1)
+	}},
+	{Token: "%L", Since: "0.2.0", Description: "line text", value: func(item internal.ChosenMatch) string {
+		return item.Line
+	}},
+	{Token: "%N", Since: "0.2.0", Description: "match index", value: func(item internal.ChosenMatch) string {
+		return strconv.Itoa(item.Index + 1)
+	}},
+	{Token: "%J", Since: "0.2.0", Description: "JSON path", value: func(item internal.ChosenMatch) string {
+		return item.Path
+	}},
+	// Multi-line matches are written on a single line, for frontends
+	// reading one result per line
+	{Token: "%Q", Since: "0.2.0", Description: "quoted hint text", value: func(item internal.ChosenMatch) string {
+		return strconv.Quote(item.Text)
+	}},
+	// The text as the pane shows it, for frontends checking that the
+	// selection is still on screen
+	{Token: "%V", Since: "0.2.0", Description: "quoted visible text", value: func(item internal.ChosenMatch) string {
+		return strconv.Quote(item.Visible)
+	}},
+}
+
+// placeholderToken matches anything that looks like a --format placeholder
+var placeholderToken = regexp.MustCompile(`%[A-Z]`)
+
+// formatResult expands the placeholders of format for a chosen match
+func formatResult(format string, item internal.ChosenMatch) string {
+	oldnew := make([]string, 0, 2*len(formatPlaceholders))
+	for _, p := range formatPlaceholders {
+		oldnew = append(oldnew, p.Token, p.value(item))
+	}
+	return strings.NewReplacer(oldnew...).Replace(format)
+}
+
+// unknownPlaceholders returns the placeholder-like tokens of format that
+// this version doesn't support
+func unknownPlaceholders(format string) []string {
+	var unknown []string
+	for _, token := range placeholderToken.FindAllString(format, -1) {
+		known := false
+		for _, p := range formatPlaceholders {
+			if p.Token == token {
+				known = true
+				break
+			}
+		}
+		if !known {
+			unknown = append(unknown, token)
+		}
+	}
+	return unknown
+}
+
+// warnUnknownPlaceholders logs format tokens that will be output verbatim
+func warnUnknownPlaceholders(format string) {
+	if unknown := unknownPlaceholders(format); len(unknown) > 0 {
+		slog.Warn("Unsupported placeholders in format are output as is", "format", format, "placeholders", unknown, "version", Version)
+	}
+}
+
+// parseVersion parses a `MAJOR[.MINOR[.PATCH]]` version with an optional
+// leading `v`, pre-release and build suffixes are ignored
+func parseVersion(version string) ([3]int, error) {
+	var parts [3]int
+
+	v := strings.TrimPrefix(strings.TrimSpace(version), "v")
+	v, _, _ = strings.Cut(v, "+")
+	v, _, _ = strings.Cut(v, "-")
+
+	fields := strings.Split(v, ".")
+	if len(fields) > 3 {
+		return parts, fmt.Errorf("invalid version %q", version)
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return parts, fmt.Errorf("invalid version %q", version)
+		}
+		parts[i] = n
+	}
+
+	return parts, nil
+}
+
+// compareVersions returns -1, 0 or 1 when a is older, equal or newer than b
+func compareVersions(a, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// checkCompatibility returns an error unless this build provides the
+// contract of the required version: the same major version and at least
+// the required minor and patch version
+func checkCompatibility(required string) error {
+	want, err := parseVersion(required)
+	if err != nil {
+		return err
+	}
+	have, err := parseVersion(Version)
+	if err != nil {
+		return err
+	}
+
+	if have[0] != want[0] || compareVersions(have, want) < 0 {
+		return fmt.Errorf("%s %s is incompatible with required version %s", appName, Version, required)
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Hanaasagi/magonote/internal"
+	"github.com/spf13/pflag"
+)
+
+var update = flag.Bool("update", false, "update contract golden files")
+
+// assertGolden compares got with testdata/contract/<name>, rewriting it with -update
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", "contract", name)
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create golden dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatalf("Failed to update golden file: %v", err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("Contract %s changed, bump Version accordingly and run with -update\nExpected:\n%s\nGot:\n%s", name, want, got)
+	}
+}
+
+func TestContractFlags(t *testing.T) {
+	var b strings.Builder
+	newRootCmd().Flags().VisitAll(func(f *pflag.Flag) {
+		shorthand := "  "
+		if f.Shorthand != "" {
+			shorthand = "-" + f.Shorthand
+		}
+		fmt.Fprintf(&b, "%s --%s %s default=%q\n", shorthand, f.Name, f.Value.Type(), f.DefValue)
+	})
+
+	assertGolden(t, "flags.golden", b.String())
+}
+
+func TestContractFormat(t *testing.T) {
+	selected := []internal.ChosenMatch{
+		{Text: "src/main.go:12:5", Visible: "src/main.go:12:5", Pattern: "file_location", X: 6, Y: 0, Line: "error src/main.go:12:5", Index: 0},
+		{Text: "192.168.1.1", Visible: "192.168.1.1", Pattern: "ipv4", X: 0, Y: 3, Line: "192.168.1.1 up", Index: 2, Uppercase: true},
+		{Text: "42", Visible: "42", Pattern: "json", X: 8, Y: 5, Line: `  "id": 42`, Index: 3, Path: ".items[0].id"},
+		{Text: "make \\\n  all", Visible: "make \\\n  all", Pattern: "shell_continuation", X: 0, Y: 7, Line: "make \\", Index: 4},
+		{Text: "https://go.dev/doc", Visible: "docs", Pattern: "hyperlink", X: 4, Y: 9, Line: "see docs", Index: 5},
+	}
+
+	formats := []string{
+		"%H",
+		"%U:%H",
+		"%P\t%X\t%Y\t%N",
+		"%H|%L",
+		"%J=%H",
+		"%U:%Q",
+		"%Q:%V",
+		"%%H %Z %h",
+	}
+
+	var b strings.Builder
+	for _, format := range formats {
+		got, err := processResults(selected, format)
+		if err != nil {
+			t.Fatalf("processResults(%q) error = %v", format, err)
+		}
+		fmt.Fprintf(&b, "== %s\n%s\n", format, got)
+	}
+
+	assertGolden(t, "format.golden", b.String())
+}
+
+func TestContractPlaceholdersVersioned(t *testing.T) {
+	version, err := parseVersion(Version)
+	if err != nil {
+		t.Fatalf("Version %q is not a semantic version: %v", Version, err)
+	}
+
+	usage := newRootCmd().Flags().Lookup("format").Usage
+	for _, p := range formatPlaceholders {
+		since, err := parseVersion(p.Since)
+		if err != nil {
+			t.Errorf("Placeholder %s has invalid version: %v", p.Token, err)
+			continue
+		}
+		if compareVersions(since, version) > 0 {
+			t.Errorf("Placeholder %s is introduced in %s, newer than Version %s", p.Token, p.Since, Version)
+		}
+		if !strings.Contains(usage, p.Token) {
+			t.Errorf("Placeholder %s is not documented in the --format help", p.Token)
+		}
+	}
+}
+
+func TestUnknownPlaceholders(t *testing.T) {
+	got := unknownPlaceholders("%H %Z %h %W")
+	if len(got) != 2 || got[0] != "%Z" || got[1] != "%W" {
+		t.Errorf("Expected [%%Z %%W], got %v", got)
+	}
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	version, err := parseVersion(Version)
+	if err != nil {
+		t.Fatalf("Version %q is not a semantic version: %v", Version, err)
+	}
+
+	tests := []struct {
+		required string
+		wantErr  bool
+	}{
+		{required: Version},
+		{required: "v" + Version},
+		{required: fmt.Sprintf("%d", version[0])},
+		{required: fmt.Sprintf("%d.%d", version[0], version[1])},
+		{required: fmt.Sprintf("%d.%d.%d", version[0], version[1], version[2]+1), wantErr: true},
+		{required: fmt.Sprintf("%d.%d", version[0], version[1]+1), wantErr: true},
+		{required: fmt.Sprintf("%d.0.0", version[0]+1), wantErr: true},
+		{required: "latest", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.required, func(t *testing.T) {
+			err := checkCompatibility(tt.required)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkCompatibility(%q) error = %v, wantErr %v", tt.required, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Hanaasagi/magonote/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// lastRunFile records the timings of the last run for `magonote debug last-run`
+var lastRunFile = filepath.Join(appDir, "last-run.json")
+
+// newDebugCmd creates the debug command and its subcommands
+func newDebugCmd() *cobra.Command {
+	debugCmd := &cobra.Command{
+		Use:   "debug",
+		Short: "Inspect the previous runs of magonote",
+	}
+
+	debugCmd.AddCommand(&cobra.Command{
+		Use:   "last-run",
+		Short: "Print the timings of the last run",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _args []string) error {
+			run, err := logger.LoadRun(lastRunFile)
+			if err != nil {
+				return fmt.Errorf("loading last run: %w", err)
+			}
+			return printRun(cmd.OutOrStdout(), run)
+		},
+	})
+
+	return debugCmd
+}
+
+// printRun writes the spans of run as a table, in the order they ended
+func printRun(w io.Writer, run *logger.Run) error {
+	fmt.Fprintf(w, "Run %s started at %s\n\n", run.ID, run.Started.Format(time.DateTime))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STEP\tSTART\tDURATION")
+	var total time.Duration
+	for _, span := range run.Spans {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", span.Name, formatMillis(span.Start), formatMillis(span.Duration))
+		total = max(total, span.Start+span.Duration)
+	}
+	fmt.Fprintf(tw, "total\t\t%s\n", formatMillis(total))
+	return tw.Flush()
+}
+
+// formatMillis formats d in milliseconds with a single decimal
+func formatMillis(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+}
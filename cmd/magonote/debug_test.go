@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Hanaasagi/magonote/internal/logger"
+)
+
+func TestPrintRun(t *testing.T) {
+	run := &logger.Run{
+		ID:      "1a2b3c4d",
+		Started: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Spans: []logger.Span{
+			{Name: "capture", Start: 0, Duration: 12 * time.Millisecond},
+			{Name: "regex extraction", Start: 13 * time.Millisecond, Duration: 1500 * time.Microsecond},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := printRun(&buf, run); err != nil {
+		t.Fatalf("printRun() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	expected := []string{
+		"Run 1a2b3c4d started at 2025-01-02 03:04:05",
+		"",
+		"STEP START DURATION",
+		"capture 0.0ms 12.0ms",
+		"regex extraction 13.0ms 1.5ms",
+		"total 14.5ms",
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got:\n%s", len(expected), buf.String())
+	}
+	for i, line := range lines {
+		if got := strings.Join(strings.Fields(line), " "); got != expected[i] {
+			t.Errorf("Expected line %d to be %q, got %q", i, expected[i], got)
+		}
+	}
+}
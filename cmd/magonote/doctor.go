@@ -0,0 +1,308 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Hanaasagi/magonote/internal"
+	"github.com/Hanaasagi/magonote/pkg/clipboard"
+	"github.com/spf13/cobra"
+)
+
+// Oldest tmux running the plugin, and the first one requiring
+// allow-passthrough for the OSC52 sequences magonote wraps for it
+var (
+	minTmuxVersion         = tmuxVersion{3, 1}
+	passthroughTmuxVersion = tmuxVersion{3, 3}
+)
+
+// checkStatus is the outcome of a doctor check
+type checkStatus string
+
+const (
+	checkOK      checkStatus = "ok"
+	checkWarning checkStatus = "warn"
+	checkFailed  checkStatus = "fail"
+	checkSkipped checkStatus = "skip"
+)
+
+// checkResult is the outcome of a doctor check, with how to fix a problem
+type checkResult struct {
+	name   string
+	status checkStatus
+	detail string
+	fix    string
+}
+
+// newDoctorCmd creates the doctor command, checking the environment magonote
+// runs in
+func newDoctorCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:          "doctor",
+		Short:        "Check tmux, the clipboard, the config and the state directory, suggesting fixes",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _args []string) error {
+			results := runDoctor()
+			printCheckResults(cmd.OutOrStdout(), results)
+
+			failed := 0
+			for _, result := range results {
+				if result.status == checkFailed {
+					failed++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d check(s) failed", failed)
+			}
+			return nil
+		},
+	}
+}
+
+// runDoctor runs every check against the current environment
+func runDoctor() []checkResult {
+	// The prompt is captured first, before the results scroll it away
+	inTmux := os.Getenv("TMUX") != ""
+	var prompt checkResult
+	if inTmux {
+		args := []string{"capture-pane", "-p", "-J"}
+		if pane := os.Getenv("TMUX_PANE"); pane != "" {
+			args = append(args, "-t", pane)
+		}
+		out, err := tmuxOutput(args...)
+		prompt = promptResult(lastNonEmptyLine(out), err)
+	} else if ps1 := os.Getenv("PS1"); ps1 != "" {
+		prompt = promptResult(ps1, nil)
+	} else {
+		prompt = checkResult{name: "prompt", status: checkSkipped, detail: "not inside tmux and PS1 isn't exported"}
+	}
+
+	var results []checkResult
+	version, ok := tmuxVersion{}, false
+	if _, err := exec.LookPath("tmux"); err != nil {
+		results = append(results, checkResult{
+			name:   "tmux",
+			status: checkWarning,
+			detail: "tmux isn't installed",
+			fix:    "install tmux " + minTmuxVersion.String() + " or later to use the tmux plugin",
+		})
+	} else {
+		out, err := tmuxOutput("-V")
+		var result checkResult
+		version, ok, result = tmuxVersionResult(out, err)
+		results = append(results, result)
+	}
+
+	if inTmux {
+		aliases, err := tmuxOutput("show-options", "-gv", "command-alias")
+		results = append(results, pluginResult(aliases, err))
+		passthrough, err := tmuxOutput("show-options", "-gv", "allow-passthrough")
+		results = append(results, passthroughResult(version, ok, strings.TrimSpace(passthrough), err))
+	} else {
+		results = append(results,
+			checkResult{name: "tmux plugin", status: checkSkipped, detail: "not inside tmux"},
+			checkResult{name: "osc52", status: checkOK, detail: "written to the terminal directly, which has to support OSC52"},
+		)
+	}
+
+	results = append(results, clipboardResult(clipboard.SystemTool(), clipboard.SystemTools()))
+
+	path := configFilePath("")
+	data, err := os.ReadFile(path)
+	results = append(results, configResult(path, string(data), err), prompt, stateDirResult(appDir))
+	return results
+}
+
+// tmuxOutput runs tmux with args, returning its output or what it reported
+// on failure
+func tmuxOutput(args ...string) (string, error) {
+	out, err := exec.Command("tmux", args...).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		err = errors.New(strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return string(out), err
+}
+
+// tmuxVersion is the major and minor version of tmux
+type tmuxVersion struct {
+	major, minor int
+}
+
+func (v tmuxVersion) String() string {
+	return fmt.Sprintf("%d.%d", v.major, v.minor)
+}
+
+func (v tmuxVersion) less(other tmuxVersion) bool {
+	return v.major < other.major || v.major == other.major && v.minor < other.minor
+}
+
+// tmuxVersionPattern finds the version in the output of `tmux -V`, such as
+// "tmux 3.4", "tmux 3.1c" or "tmux next-3.5"
+var tmuxVersionPattern = regexp.MustCompile(`(\d+)\.(\d+)`)
+
+// tmuxVersionResult checks the output of `tmux -V`, returning the version
+// when it has one
+func tmuxVersionResult(out string, err error) (tmuxVersion, bool, checkResult) {
+	result := checkResult{name: "tmux"}
+	if err != nil {
+		result.status, result.detail = checkFailed, fmt.Sprintf("running tmux -V: %v", err)
+		result.fix = "reinstall tmux"
+		return tmuxVersion{}, false, result
+	}
+
+	out = strings.TrimSpace(out)
+	m := tmuxVersionPattern.FindStringSubmatch(out)
+	if m == nil {
+		// Development builds such as "tmux master"
+		result.status, result.detail = checkOK, out+", assumed recent"
+		return tmuxVersion{}, false, result
+	}
+
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	version := tmuxVersion{major, minor}
+	if version.less(minTmuxVersion) {
+		result.status, result.detail = checkFailed, out+" is too old for the tmux plugin"
+		result.fix = "upgrade tmux to " + minTmuxVersion.String() + " or later"
+	} else {
+		result.status, result.detail = checkOK, out
+	}
+	return version, true, result
+}
+
+// pluginResult checks the command aliases of tmux for the magonote-pick one
+// set up by magonote.tmux
+func pluginResult(aliases string, err error) checkResult {
+	result := checkResult{name: "tmux plugin"}
+	switch {
+	case err != nil:
+		result.status, result.detail = checkWarning, fmt.Sprintf("reading the tmux options: %v", err)
+	case strings.Contains(aliases, "magonote-pick="):
+		result.status, result.detail = checkOK, "magonote-pick is set up"
+	default:
+		result.status, result.detail = checkWarning, "magonote-pick isn't set up, the plugin isn't loaded"
+		result.fix = "add set -g @plugin 'Hanaasagi/tmux-magonote' to ~/.tmux.conf and reload it with tmux source-file ~/.tmux.conf"
+	}
+	return result
+}
+
+// passthroughResult checks that tmux lets the OSC52 sequences of magonote,
+// wrapped in DCS passthrough, through to the terminal
+func passthroughResult(version tmuxVersion, known bool, value string, err error) checkResult {
+	result := checkResult{name: "osc52"}
+	switch {
+	case known && version.less(passthroughTmuxVersion):
+		result.status, result.detail = checkOK, "tmux "+version.String()+" passes OSC52 through"
+	case err != nil:
+		result.status, result.detail = checkWarning, fmt.Sprintf("reading allow-passthrough: %v", err)
+	case value == "on" || value == "all":
+		result.status, result.detail = checkOK, "allow-passthrough is "+value
+	default:
+		result.status, result.detail = checkWarning, "allow-passthrough is off, tmux drops the OSC52 sequences of --also osc52 and @magonote-osc52"
+		result.fix = "add set -g allow-passthrough on to ~/.tmux.conf"
+	}
+	return result
+}
+
+// clipboardResult checks that tool, the system clipboard tool found among
+// tools, is set
+func clipboardResult(tool string, tools []string) checkResult {
+	result := checkResult{name: "clipboard"}
+	switch {
+	case tool != "":
+		result.status, result.detail = checkOK, "using "+tool
+	case len(tools) == 0:
+		result.status, result.detail = checkWarning, "no system clipboard is supported on this platform"
+		result.fix = "use --also osc52 or tmux-buffer instead"
+	default:
+		result.status, result.detail = checkWarning, "none of "+strings.Join(tools, ", ")+" is installed, --also clipboard fails"
+		result.fix = "install " + tools[0] + ", or use --also osc52 over SSH"
+	}
+	return result
+}
+
+// configResult checks the config file at path, whose content is data
+func configResult(path, data string, err error) checkResult {
+	result := checkResult{name: "config"}
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		result.status, result.detail = checkOK, "no config file at "+path+", using the defaults"
+		return result
+	case err != nil:
+		result.status, result.detail = checkFailed, fmt.Sprintf("reading config: %v", err)
+		result.fix = "make " + path + " readable"
+		return result
+	}
+
+	issues := checkConfig(data)
+	if len(issues) == 0 {
+		result.status, result.detail = checkOK, path
+		return result
+	}
+	result.status = checkFailed
+	result.detail = fmt.Sprintf("%s has %d problem(s), the first: %s", path, len(issues), issues[0].message)
+	result.fix = "run magonote config check for their lines"
+	return result
+}
+
+// promptResult checks that the last-command scope recognizes prompt, the
+// line magonote doctor was typed at or PS1
+func promptResult(prompt string, err error) checkResult {
+	result := checkResult{name: "prompt"}
+	switch {
+	case err != nil:
+		result.status, result.detail = checkSkipped, fmt.Sprintf("capturing the pane: %v", err)
+	case internal.LooksLikePrompt(prompt):
+		result.status, result.detail = checkOK, "recognized by --scope last-command"
+	default:
+		result.status, result.detail = checkWarning, fmt.Sprintf("%q isn't recognized, --scope last-command keeps every line", prompt)
+		result.fix = "end the prompt with one of " + strings.Join(strings.Split(internal.PromptMarkers, ""), " ")
+	}
+	return result
+}
+
+// stateDirResult checks that files can be written to dir, which holds the
+// history, the last session and the logs
+func stateDirResult(dir string) checkResult {
+	result := checkResult{name: "state directory"}
+	file, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		result.status, result.detail = checkFailed, fmt.Sprintf("%s isn't writable: %v", dir, err)
+		result.fix = "fix the permissions of " + dir + ", or set XDG_STATE_HOME to a writable directory"
+		return result
+	}
+	file.Close()           // nolint: errcheck
+	os.Remove(file.Name()) // nolint: errcheck
+	result.status, result.detail = checkOK, dir
+	return result
+}
+
+// lastNonEmptyLine returns the last line of text with more than spaces
+func lastNonEmptyLine(text string) string {
+	lines := strings.Split(text, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.TrimSpace(lines[i]) != "" {
+			return lines[i]
+		}
+	}
+	return ""
+}
+
+// printCheckResults writes a line per result, followed by the fix of the
+// problems
+func printCheckResults(w io.Writer, results []checkResult) {
+	for _, result := range results {
+		fmt.Fprintf(w, "%-5s %s: %s\n", result.status, result.name, result.detail)
+		if result.fix != "" {
+			fmt.Fprintf(w, "      fix: %s\n", result.fix)
+		}
+	}
+}
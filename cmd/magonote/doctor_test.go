@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTmuxVersionResult(t *testing.T) {
+	tests := []struct {
+		out     string
+		err     error
+		version tmuxVersion
+		known   bool
+		status  checkStatus
+	}{
+		{out: "tmux 3.4\n", version: tmuxVersion{3, 4}, known: true, status: checkOK},
+		{out: "tmux 3.1c\n", version: tmuxVersion{3, 1}, known: true, status: checkOK},
+		{out: "tmux next-3.5\n", version: tmuxVersion{3, 5}, known: true, status: checkOK},
+		{out: "tmux 2.9a\n", version: tmuxVersion{2, 9}, known: true, status: checkFailed},
+		{out: "tmux master\n", status: checkOK},
+		{err: errors.New("exit status 1"), status: checkFailed},
+	}
+
+	for _, tt := range tests {
+		version, known, result := tmuxVersionResult(tt.out, tt.err)
+		if version != tt.version || known != tt.known {
+			t.Errorf("Expected version %v (%v) of %q, got %v (%v)", tt.version, tt.known, tt.out, version, known)
+		}
+		if result.status != tt.status {
+			t.Errorf("Expected status %s for %q, got %s: %s", tt.status, tt.out, result.status, result.detail)
+		}
+	}
+}
+
+func TestPassthroughResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		version tmuxVersion
+		known   bool
+		value   string
+		status  checkStatus
+	}{
+		{name: "before the option", version: tmuxVersion{3, 2}, known: true, value: "", status: checkOK},
+		{name: "on", version: tmuxVersion{3, 4}, known: true, value: "on", status: checkOK},
+		{name: "off", version: tmuxVersion{3, 4}, known: true, value: "off", status: checkWarning},
+		{name: "unknown version off", value: "off", status: checkWarning},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := passthroughResult(tt.version, tt.known, tt.value, nil)
+			if result.status != tt.status {
+				t.Errorf("Expected status %s, got %s: %s", tt.status, result.status, result.detail)
+			}
+			if (result.status == checkWarning) != (result.fix != "") {
+				t.Errorf("Expected a fix for warnings alone, got %q", result.fix)
+			}
+		})
+	}
+}
+
+func TestPromptResult(t *testing.T) {
+	tests := []struct {
+		prompt string
+		status checkStatus
+	}{
+		{prompt: "me@host:~/src$ magonote doctor", status: checkOK},
+		{prompt: `\u@\h:\w\$ `, status: checkOK},
+		{prompt: "➜  src magonote doctor", status: checkOK},
+		{prompt: "host magonote doctor", status: checkWarning},
+	}
+
+	for _, tt := range tests {
+		if result := promptResult(tt.prompt, nil); result.status != tt.status {
+			t.Errorf("Expected status %s for %q, got %s", tt.status, tt.prompt, result.status)
+		}
+	}
+}
+
+func TestConfigResult(t *testing.T) {
+	if result := configResult("config.toml", "", os.ErrNotExist); result.status != checkOK {
+		t.Errorf("Expected a missing config to be ok, got %s", result.status)
+	}
+	if result := configResult("config.toml", "[core]\nalphabet = \"qwerty\"\n", nil); result.status != checkOK {
+		t.Errorf("Expected a valid config to be ok, got %s: %s", result.status, result.detail)
+	}
+	if result := configResult("config.toml", "[core]\ncolour = 1\n", nil); result.status != checkFailed || result.fix == "" {
+		t.Errorf("Expected an unknown key to fail with a fix, got %s: %s", result.status, result.detail)
+	}
+}
+
+func TestStateDirResult(t *testing.T) {
+	dir := t.TempDir()
+	if result := stateDirResult(dir); result.status != checkOK {
+		t.Errorf("Expected a writable directory to be ok, got %s: %s", result.status, result.detail)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Expected no file left behind, got %v", entries)
+	}
+	if result := stateDirResult(filepath.Join(dir, "missing")); result.status != checkFailed {
+		t.Errorf("Expected a missing directory to fail, got %s", result.status)
+	}
+}
+
+func TestPrintCheckResults(t *testing.T) {
+	var buf bytes.Buffer
+	printCheckResults(&buf, []checkResult{
+		{name: "tmux", status: checkOK, detail: "tmux 3.4"},
+		{name: "clipboard", status: checkWarning, detail: "none installed", fix: "install xclip"},
+	})
+
+	expected := "ok    tmux: tmux 3.4\n" +
+		"warn  clipboard: none installed\n" +
+		"      fix: install xclip\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// locationSuffix matches the `:line` or `:line:column` suffix of compiler
+// and grep output, or the `(line,column)` one of tsc and MSVC
+var locationSuffix = regexp.MustCompile(`(?::(\d+)(?::(\d+))?|\((\d+)(?:,(\d+))?\))$`)
+
+// FileLocation is a file path with an optional 1-based line and column,
+// zero means unset
+type FileLocation struct {
+	Path   string
+	Line   int
+	Column int
+}
+
+// ParseFileLocation splits a `path:line:column` or `path(line,column)`
+// string into its parts. Text without a location suffix is returned as a
+// plain path
+func ParseFileLocation(text string) FileLocation {
+	m := locationSuffix.FindStringSubmatchIndex(text)
+	if m == nil || m[0] == 0 {
+		return FileLocation{Path: text}
+	}
+
+	// The groups of the parenthesized suffix follow those of the colon one
+	line, column := m[2:4], m[4:6]
+	if line[0] < 0 {
+		line, column = m[6:8], m[8:10]
+	}
+	loc := FileLocation{Path: text[:m[0]]}
+	loc.Line, _ = strconv.Atoi(text[line[0]:line[1]])
+	if column[0] >= 0 {
+		loc.Column, _ = strconv.Atoi(text[column[0]:column[1]])
+	}
+	return loc
+}
+
+// String formats the location as `path:line:column`
+func (loc FileLocation) String() string {
+	switch {
+	case loc.Line <= 0:
+		return loc.Path
+	case loc.Column <= 0:
+		return fmt.Sprintf("%s:%d", loc.Path, loc.Line)
+	default:
+		return fmt.Sprintf("%s:%d:%d", loc.Path, loc.Line, loc.Column)
+	}
+}
+
+// EditorLauncher builds the command line that opens a file location in an editor
+type EditorLauncher struct {
+	// Command is the editor command, it may contain arguments like `code -w`
+	Command string
+	// streams the editor is attached to, those of the process when nil
+	streams *streams
+}
+
+// NewEditorLauncher creates a launcher for $EDITOR, falling back to defaultEditor
+func NewEditorLauncher() *EditorLauncher {
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = defaultEditor
+	}
+	return &EditorLauncher{Command: editor}
+}
+
+// Args returns the editor executable and its arguments for loc
+func (e *EditorLauncher) Args(loc FileLocation) (string, []string) {
+	fields := strings.Fields(e.Command)
+	if len(fields) == 0 {
+		fields = []string{defaultEditor}
+	}
+	name, args := fields[0], fields[1:]
+
+	if loc.Line <= 0 {
+		return name, append(args, loc.Path)
+	}
+
+	switch filepath.Base(name) {
+	case "vim", "nvim", "vi", "gvim", "mvim":
+		if loc.Column > 0 {
+			return name, append(args, fmt.Sprintf("+call cursor(%d,%d)", loc.Line, loc.Column), loc.Path)
+		}
+		return name, append(args, fmt.Sprintf("+%d", loc.Line), loc.Path)
+	case "code", "code-insiders", "codium", "cursor":
+		return name, append(args, "--goto", loc.String())
+	case "subl", "hx", "helix", "zed":
+		return name, append(args, loc.String())
+	case "emacs", "emacsclient":
+		if loc.Column > 0 {
+			return name, append(args, fmt.Sprintf("+%d:%d", loc.Line, loc.Column), loc.Path)
+		}
+		return name, append(args, fmt.Sprintf("+%d", loc.Line), loc.Path)
+	case "nano":
+		if loc.Column > 0 {
+			return name, append(args, fmt.Sprintf("+%d,%d", loc.Line, loc.Column), loc.Path)
+		}
+		return name, append(args, fmt.Sprintf("+%d", loc.Line), loc.Path)
+	default:
+		// `+line` is understood by most terminal editors
+		return name, append(args, fmt.Sprintf("+%d", loc.Line), loc.Path)
+	}
+}
+
+// Open opens the file location in the editor, attached to the terminal
+func (e *EditorLauncher) Open(loc FileLocation) error {
+	if _, err := os.Stat(loc.Path); os.IsNotExist(err) {
+		return fmt.Errorf("file does not exist: %w", err)
+	}
+
+	name, args := e.Args(loc)
+	cmd := exec.Command(name, args...)
+	e.streams.attach(cmd)
+
+	return cmd.Run()
+}
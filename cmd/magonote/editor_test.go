@@ -0,0 +1,60 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseFileLocation(t *testing.T) {
+	tests := []struct {
+		text string
+		want FileLocation
+	}{
+		{text: "foo.go", want: FileLocation{Path: "foo.go"}},
+		{text: "foo.go:123", want: FileLocation{Path: "foo.go", Line: 123}},
+		{text: "src/foo.go:123:45", want: FileLocation{Path: "src/foo.go", Line: 123, Column: 45}},
+		{text: ":12", want: FileLocation{Path: ":12"}},
+		{text: "foo.go:abc", want: FileLocation{Path: "foo.go:abc"}},
+		{text: "src/app.ts(12,5)", want: FileLocation{Path: "src/app.ts", Line: 12, Column: 5}},
+		{text: `C:\src\main.cpp(7)`, want: FileLocation{Path: `C:\src\main.cpp`, Line: 7}},
+		{text: `C:\src\main.c:3:10`, want: FileLocation{Path: `C:\src\main.c`, Line: 3, Column: 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := ParseFileLocation(tt.text); got != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestEditorLauncher_Args(t *testing.T) {
+	full := FileLocation{Path: "foo.go", Line: 123, Column: 45}
+	lineOnly := FileLocation{Path: "foo.go", Line: 123}
+
+	tests := []struct {
+		editor string
+		loc    FileLocation
+		want   []string
+	}{
+		{editor: "vim", loc: FileLocation{Path: "foo.go"}, want: []string{"vim", "foo.go"}},
+		{editor: "vim", loc: lineOnly, want: []string{"vim", "+123", "foo.go"}},
+		{editor: "/usr/bin/nvim", loc: full, want: []string{"/usr/bin/nvim", "+call cursor(123,45)", "foo.go"}},
+		{editor: "code -w", loc: full, want: []string{"code", "-w", "--goto", "foo.go:123:45"}},
+		{editor: "emacsclient -t", loc: full, want: []string{"emacsclient", "-t", "+123:45", "foo.go"}},
+		{editor: "nano", loc: full, want: []string{"nano", "+123,45", "foo.go"}},
+		{editor: "hx", loc: lineOnly, want: []string{"hx", "foo.go:123"}},
+		{editor: "kak", loc: full, want: []string{"kak", "+123", "foo.go"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.editor, func(t *testing.T) {
+			name, args := (&EditorLauncher{Command: tt.editor}).Args(tt.loc)
+			got := append([]string{name}, args...)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Hanaasagi/magonote/internal"
+	"github.com/spf13/cobra"
+)
+
+// newHistoryCmd creates the history command, picking again from the values
+// selected before
+func newHistoryCmd() *cobra.Command {
+	var (
+		configPath string
+		target     string
+		also       []string
+		multi      bool
+		printOnly  bool
+	)
+
+	historyCmd := &cobra.Command{
+		Use:          "history",
+		Short:        "Pick again from the previously selected values",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _args []string) error {
+			config := NewDefaultConfig()
+			if configPath != "NONE" {
+				var err error
+				if config, err = loadConfig(configPath); err != nil {
+					return fmt.Errorf("loading configuration: %w", err)
+				}
+			}
+
+			history, err := internal.LoadHistory(historyFile, config.History.MaxEntries)
+			if err != nil {
+				return err
+			}
+			items := history.Items()
+			if printOnly {
+				return printHistory(cmd.OutOrStdout(), items)
+			}
+			if len(items) == 0 {
+				return errors.New("no value was selected yet")
+			}
+
+			sinks, err := newExtraSinks(also, cmd.OutOrStdout(), cmd.ErrOrStderr())
+			if err != nil {
+				return err
+			}
+			selected, err := pickHistory(config, items, multi)
+			if err != nil || len(selected) == 0 {
+				return err
+			}
+
+			if config.History.Enabled {
+				for _, item := range selected {
+					history.Record(item.Pattern, item.Text)
+				}
+				if err := history.Save(); err != nil {
+					slog.Warn("Failed to save history", "file", historyFile, "error", err)
+				}
+			}
+
+			output, err := processResults(selected, "%H")
+			if err != nil {
+				return err
+			}
+			return writeOutput(target, cmd.OutOrStdout(), sinks, output, selectedTexts(selected))
+		},
+	}
+
+	historyCmd.Flags().StringVar(&configPath, "config", "", "Config file path (default: XDG config dir, use 'NONE' to disable)")
+	historyCmd.Flags().StringVarP(&target, "target", "t", "", "Stores the picked value in the specified path")
+	historyCmd.Flags().StringArrayVar(&also, "also", nil, "Also write the picked value to stdout, clipboard, tmux-buffer or osc52, can be repeated")
+	historyCmd.Flags().BoolVarP(&multi, "multi", "m", false, "Enable multi-selection")
+	historyCmd.Flags().BoolVar(&printOnly, "print", false, "Print the values with when they were last selected, their pattern and selection count instead of picking")
+
+	return historyCmd
+}
+
+// pickHistory shows items in the list view, with the colors of config, and
+// returns the picked ones
+func pickHistory(config *Config, items []internal.HistoryItem, multi bool) ([]internal.ChosenMatch, error) {
+	if err := config.Colors.applyTheme(); err != nil {
+		return nil, err
+	}
+	config.Colors.resolveAutoColors(func() (internal.RGB, bool) {
+		return internal.TerminalBackground(backgroundTimeout)
+	})
+
+	keyBindings, err := internal.ParseKeyBindings(config.Keys)
+	if err != nil {
+		return nil, fmt.Errorf("parsing key bindings: %w", err)
+	}
+	viewOpts := []internal.ViewOption{internal.WithKeyBindings(keyBindings)}
+	if len(config.Colors.Patterns) > 0 {
+		viewOpts = append(viewOpts, internal.WithPatternColors(patternColors(config.Colors.Patterns)))
+	}
+	if config.Core.ListVim {
+		viewOpts = append(viewOpts, internal.WithVimKeys())
+	}
+
+	listView := internal.NewListView(
+		internal.NewHistoryState(items, config.Core.Alphabet),
+		multi,
+		internal.GetColor(config.Colors.Select.Foreground),
+		internal.GetColor(config.Colors.Select.Background),
+		internal.GetColor(config.Colors.Multi.Foreground),
+		internal.GetColor(config.Colors.Multi.Background),
+		internal.GetColor(config.Colors.Match.Foreground),
+		internal.GetColor(config.Colors.Match.Background),
+		internal.GetColor(config.Colors.Hint.Foreground),
+		internal.GetColor(config.Colors.Hint.Background),
+		viewOpts...,
+	)
+	selected := listView.Present()
+	if err := listView.Err(); err != nil {
+		writeCrash(err)
+		return nil, err
+	}
+	return selected, nil
+}
+
+// printHistory writes items as a table, the most recently selected first
+func printHistory(w io.Writer, items []internal.HistoryItem) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "LAST USED\tPATTERN\tCOUNT\tVALUE")
+	for _, item := range items {
+		pattern := item.Pattern
+		if pattern == "" {
+			pattern = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", item.LastUsed.Local().Format(time.DateTime), pattern, item.Count, item.Text)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/Hanaasagi/magonote/internal"
+)
+
+func TestPrintHistory(t *testing.T) {
+	used := time.Date(2025, 1, 2, 3, 4, 5, 0, time.Local)
+	items := []internal.HistoryItem{
+		{Text: "10.0.0.1", HistoryEntry: internal.HistoryEntry{Count: 3, LastUsed: used, Pattern: "ipv4"}},
+		{Text: "old value", HistoryEntry: internal.HistoryEntry{Count: 1, LastUsed: used.Add(-time.Hour)}},
+	}
+
+	var buf bytes.Buffer
+	if err := printHistory(&buf, items); err != nil {
+		t.Fatalf("printHistory() error = %v", err)
+	}
+
+	expected := "LAST USED            PATTERN  COUNT  VALUE\n" +
+		"2025-01-02 03:04:05  ipv4     3      10.0.0.1\n" +
+		"2025-01-02 02:04:05  -        1      old value\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
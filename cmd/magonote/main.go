@@ -2,6 +2,9 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"cmp"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -10,7 +13,9 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime/debug"
+	"slices"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/Hanaasagi/magonote/cmd"
 	"github.com/Hanaasagi/magonote/internal"
@@ -24,30 +29,92 @@ const (
 	appName       = "magonote"
 	defaultSize   = 4096
 	defaultEditor = "vi"
+
+	// Default limits, far above a terminal screen but small enough to stay
+	// responsive on logs piped in by mistake
+	defaultMaxLines      = 100000
+	defaultMaxLineLength = 10000
+	defaultMaxMatches    = 10000
+
+	// Table detection settings of the flags when the config has none
+	defaultTableMinLines   = 3
+	defaultTableMinColumns = 3
+	defaultTableConfidence = 0.8
 )
 
 var (
-	Version     = "0.1.0"
+	Version     = "0.2.0"
 	CommitSha   = "unknown"
 	FullVersion = Version + "-" + CommitSha
 )
 
-var appDir = filepath.Join(xdg.StateHome, appName)
+var (
+	appDir      = filepath.Join(xdg.StateHome, appName)
+	historyFile = filepath.Join(appDir, "history.json")
+	crashFile   = filepath.Join(appDir, "crash")
+)
 
 type Arguments struct {
-	alphabet       string
-	format         string
-	position       string
-	regexpPatterns []string
-	multi          bool
-	reverse        bool
-	uniqueLevel    int // 0: none, 1: unique hints, 2: highlight only one duplicate
-	contrast       bool
-	target         string
-	inputFile      string
-	showVersion    bool
-	listView       bool
-	extraExclusion []string // Extra exclusion patterns from CLI
+	alphabet        string
+	format          string
+	position        string
+	regexpPatterns  []string
+	multi           bool
+	reverse         bool
+	uniqueLevel     int // 0: none, 1: unique hints, 2: highlight only one duplicate
+	contrast        bool
+	target          string
+	also            []string // Outputs written in addition to the target
+	inputFile       string
+	showVersion     bool
+	requireVersion  string // Contract version a frontend depends on
+	listView        bool
+	extraExclusion  []string // Extra exclusion patterns from CLI
+	excludeText     []string // Exclusion texts from CLI
+	excludeRegex    []string // Exclusion patterns from CLI, like extraExclusion
+	confirmCommand  string   // Command template to review multi-selections against
+	namedPatterns   []string // Custom patterns in name:pattern form
+	proximity       bool
+	prefixSelect    bool
+	hintPages       bool
+	statusBar       bool
+	listVim         bool
+	cursorLine      int // 1-based line of the cursor in the input, 0 if unknown
+	wrapWidth       int // Width the lines of the input were wrapped at, 0 if unwrapped
+	tabWidth        int
+	noHistory       bool
+	noAutoDetection bool
+	stats           bool // Print statistics of the matches after the selection
+	watchConfig     bool // Reload the colors of the view when the config file changes
+	scope           string
+	mode            string
+	wordMinLength   int
+	jsonInput       bool
+	multiline       bool
+	tableDetection  bool
+	tableMinLines   int
+	tableConfidence float64
+	colorDetection  bool
+	theme           string
+	profile         string // Profile of the config file to apply
+	maxLines        int
+	maxLineLength   int
+	maxMatches      int
+	socket          string // Socket of the magonote serve to run through
+	last            bool   // Reopen the last picker
+	record          string // File to record the view to
+	replay          string // File of a recording to replay
+	remoteHost      string // Host of the ssh session the input comes from
+	browser         string // Command of the open action
+	bidi            string
+
+	// streams aren't flags, they are set when the command runs
+	streams streams
+	// session is the picker being opened, the last one with --last
+	session *session
+	// recording is the one being recorded with --record or replayed with
+	// --replay
+	recording *recording
 
 	// colors
 	foregroundColor       string
@@ -60,6 +127,48 @@ type Arguments struct {
 	selectBackgroundColor string
 }
 
+// streams are the input and outputs of an invocation, those of the process or
+// those of a client of magonote serve
+type streams struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	// terminal of the client of magonote serve to show the view on, nil for
+	// the terminal of the process
+	terminal *clientTerminal
+}
+
+// commandStreams returns the streams the commands run for the selected
+// matches are attached to, nil for the terminal of the process. Under
+// magonote serve they run on the terminal of the client, to be released
+// with the returned function once they are done
+func (s streams) commandStreams() (*streams, func(), error) {
+	if s.terminal == nil {
+		return nil, func() {}, nil
+	}
+	if s.terminal.device == "" {
+		return nil, nil, errors.New("the client of magonote serve has no terminal to run commands on")
+	}
+
+	tty, err := os.OpenFile(s.terminal.device, os.O_RDWR, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening the terminal of the client: %w", err)
+	}
+	release := func() {
+		tty.Close() // nolint: errcheck
+	}
+	return &streams{stdin: tty, stdout: tty, stderr: tty}, release, nil
+}
+
+// attach attaches cmd to the streams, those of the process for nil streams
+func (s *streams) attach(cmd *exec.Cmd) {
+	if s == nil {
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		return
+	}
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = s.stdin, s.stdout, s.stderr
+}
+
 func init() {
 	// Initialize logging
 	if err := os.MkdirAll(appDir, 0755); err != nil {
@@ -80,26 +189,27 @@ func init() {
 	logger.InitLogger(logFilePath, logLevel)
 
 	// Initialize crash reporting
-	crashFilePath := filepath.Join(appDir, "crash")
-	if f, err := os.Create(crashFilePath); err == nil {
+	if f, err := os.Create(crashFile); err == nil {
 		_ = debug.SetCrashOutput(f, debug.CrashOptions{})
 	}
 }
 
-// readInput reads input from file or stdin with buffering
-func readInput(inputFile string) (string, error) {
+// readInput reads input from file or stdin with buffering. Lines beyond
+// limits.MaxLines and bytes beyond limits.MaxLineLength are dropped without
+// being held in memory, truncated reports whether any were
+func readInput(stdin io.Reader, inputFile string, limits LimitsConfig) (text string, truncated bool, err error) {
 	var reader io.Reader
 	var closer io.Closer
 
 	if inputFile != "" {
 		file, err := os.Open(inputFile)
 		if err != nil {
-			return "", fmt.Errorf("opening input file: %w", err)
+			return "", false, fmt.Errorf("opening input file: %w", err)
 		}
 		reader = file
 		closer = file
 	} else {
-		reader = os.Stdin
+		reader = stdin
 	}
 
 	defer func() {
@@ -111,64 +221,76 @@ func readInput(inputFile string) (string, error) {
 	bufferedReader := bufio.NewReaderSize(reader, defaultSize)
 	var content strings.Builder
 
-	for {
-		line, err := bufferedReader.ReadString('\n')
-		if err != nil && err != io.EOF {
-			return "", fmt.Errorf("reading input: %w", err)
+	for lines := 0; ; lines++ {
+		if limits.MaxLines > 0 && lines == limits.MaxLines {
+			// Anything left is another line
+			if _, err := bufferedReader.Peek(1); err == nil {
+				truncated = true
+			}
+			break
 		}
 
-		if line != "" {
-			content.WriteString(line)
+		cut, err := readLine(bufferedReader, &content, limits.MaxLineLength)
+		if err != nil && err != io.EOF {
+			return "", false, fmt.Errorf("reading input: %w", err)
 		}
+		truncated = truncated || cut
 
 		if err == io.EOF {
 			break
 		}
 	}
 
-	return strings.TrimSuffix(content.String(), "\n"), nil
+	return strings.TrimSuffix(content.String(), "\n"), truncated, nil
 }
 
-// writeOutput writes output to target file or stdout with buffering
-func writeOutput(target, content string) error {
-	if target == "" {
-		fmt.Print(content)
-		return nil
-	}
-
-	file, err := os.Create(target)
-	if err != nil {
-		return fmt.Errorf("creating target file: %w", err)
-	}
-	defer file.Close() // nolint: errcheck
+// readLine copies a line of r, with its newline, to w. Bytes beyond
+// maxLength are discarded, cutting the line at a character boundary, and
+// reported by cut. Zero or less keeps the whole line
+func readLine(r *bufio.Reader, w *strings.Builder, maxLength int) (cut bool, err error) {
+	length := 0
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if err != nil && err != bufio.ErrBufferFull && err != io.EOF {
+			return cut, err
+		}
 
-	writer := bufio.NewWriterSize(file, defaultSize)
-	defer writer.Flush() // nolint: errcheck
+		text := bytes.TrimSuffix(chunk, []byte{'\n'})
+		if cut {
+			// Drop the rest of the line
+			text = nil
+		} else if maxLength > 0 && length+len(text) > maxLength {
+			end := maxLength - length
+			for end > 0 && !utf8.RuneStart(text[end]) {
+				end--
+			}
+			text = text[:end]
+			cut = true
+		}
+		w.Write(text) // nolint: errcheck
+		length += len(text)
 
-	if _, err := writer.WriteString(content); err != nil {
-		return fmt.Errorf("writing to target file: %w", err)
+		if err == nil {
+			w.WriteByte('\n') // nolint: errcheck
+		}
+		if err != bufio.ErrBufferFull {
+			return cut, err
+		}
 	}
-
-	return nil
 }
 
-// openFileWithEditor opens the specified file with the editor
-func openFileWithEditor(filePath string) error {
-	editor := os.Getenv("EDITOR")
-	if editor == "" {
-		editor = defaultEditor
-	}
-
+// openFileWithEditor opens the specified file with the editor attached to
+// commands. A trailing `:line` or `:line:column` moves the cursor there
+// unless the file name itself contains it
+func openFileWithEditor(filePath string, commands *streams) error {
+	loc := FileLocation{Path: filePath}
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return fmt.Errorf("file does not exist: %w", err)
+		loc = ParseFileLocation(filePath)
 	}
 
-	cmd := exec.Command(editor, filePath)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	return cmd.Run()
+	editor := NewEditorLauncher()
+	editor.streams = commands
+	return editor.Open(loc)
 }
 
 // processResults processes selected items and returns formatted output
@@ -180,36 +302,25 @@ func processResults(selected []internal.ChosenMatch, format string) (string, err
 	results := make([]string, 0, len(selected))
 
 	for _, item := range selected {
-		if item.ShouldOpenFile {
-			slog.Info("Opening file with editor", "file", item.Text, "editor", os.Getenv("EDITOR"))
-			if err := openFileWithEditor(item.Text); err != nil {
-				return "", fmt.Errorf("opening file with editor: %w", err)
-			}
-			os.Exit(0)
-		}
-
-		result := strings.ReplaceAll(format, "%H", item.Text)
-		upcase := "false"
-		if item.Uppercase {
-			upcase = "true"
-		}
-		result = strings.ReplaceAll(result, "%U", upcase)
+		result := formatResult(format, item)
 		results = append(results, result)
 	}
 
 	return strings.Join(results, "\n"), nil
 }
 
-// loadConfig loads and merges configuration from multiple sources
-func loadConfig(configPath string) (*Config, error) {
-	var actualConfigPath string
-
+// configFilePath returns the given config path, or the config file of the
+// XDG config directory without one
+func configFilePath(configPath string) string {
 	if configPath != "" {
-		actualConfigPath = configPath
-	} else {
-		// Use XDG config directory
-		actualConfigPath = filepath.Join(xdg.ConfigHome, appName, "config.toml")
+		return configPath
 	}
+	return filepath.Join(xdg.ConfigHome, appName, "config.toml")
+}
+
+// loadConfig loads and merges configuration from multiple sources
+func loadConfig(configPath string) (*Config, error) {
+	actualConfigPath := configFilePath(configPath)
 
 	config, err := LoadConfigFromFile(actualConfigPath)
 	if err != nil {
@@ -219,6 +330,37 @@ func loadConfig(configPath string) (*Config, error) {
 	return config, nil
 }
 
+// applyPluginFlags enables and tunes the plugins from the CLI arguments.
+// Tuning table detection enables it
+func applyPluginFlags(cmd *cobra.Command, config *Config, args *Arguments) {
+	flags := cmd.Flags()
+	if flags.Changed("tabledetection") || flags.Changed("tabledetection-min-lines") || flags.Changed("tabledetection-confidence") {
+		tableDetection := config.Plugins.Tabledetection
+		if tableDetection == nil {
+			tableDetection = &TableDetectionPluginConfig{
+				MinLines:            defaultTableMinLines,
+				MinColumns:          defaultTableMinColumns,
+				ConfidenceThreshold: defaultTableConfidence,
+			}
+			config.Plugins.Tabledetection = tableDetection
+		}
+		tableDetection.Enabled = args.tableDetection || !flags.Changed("tabledetection")
+		if flags.Changed("tabledetection-min-lines") {
+			tableDetection.MinLines = args.tableMinLines
+		}
+		if flags.Changed("tabledetection-confidence") {
+			tableDetection.ConfidenceThreshold = args.tableConfidence
+		}
+	}
+
+	if flags.Changed("colordetection") {
+		config.Plugins.Colordetection = &ColorDetectionPluginConfig{Enabled: args.colorDetection}
+	}
+	if args.noAutoDetection {
+		config.Plugins.Auto = false
+	}
+}
+
 // applyCliOverrides applies CLI arguments to override config values
 func applyCliOverrides(cmd *cobra.Command, config *Config, args *Arguments) {
 	// Core settings
@@ -232,37 +374,98 @@ func applyCliOverrides(cmd *cobra.Command, config *Config, args *Arguments) {
 		config.Core.Position = args.position
 	}
 
-	if len(args.regexpPatterns) > 0 {
+	if cmd.Flags().Changed("proximity") {
+		config.Core.Proximity = args.proximity
+	}
+	if cmd.Flags().Changed("prefix-select") {
+		config.Core.PrefixSelect = args.prefixSelect
+	}
+	if cmd.Flags().Changed("hint-pages") {
+		config.Core.HintPages = args.hintPages
+	}
+	if cmd.Flags().Changed("status-bar") {
+		config.Core.StatusBar = args.statusBar
+	}
+	if cmd.Flags().Changed("list-vim") {
+		config.Core.ListVim = args.listVim
+	}
+	if cmd.Flags().Changed("scope") {
+		config.Core.Scope = args.scope
+	}
+	if cmd.Flags().Changed("mode") {
+		config.Core.Mode = args.mode
+	}
+	if cmd.Flags().Changed("word-min-length") {
+		config.Core.WordMinLength = args.wordMinLength
+	}
+	if cmd.Flags().Changed("json-input") {
+		config.Core.JSONInput = args.jsonInput
+	}
+	if cmd.Flags().Changed("multiline") {
+		config.Core.Multiline = args.multiline
+	}
+	if cmd.Flags().Changed("tab-width") {
+		// Zero draws the tabs as is like a negative tab_width, unset being 8
+		config.Core.TabWidth = cmp.Or(args.tabWidth, -1)
+	}
+	if cmd.Flags().Changed("browser") {
+		config.Core.Browser = args.browser
+	}
+	if cmd.Flags().Changed("bidi") {
+		config.Core.Bidi = args.bidi
+	}
+	applyPluginFlags(cmd, config, args)
+
+	if len(args.regexpPatterns) > 0 || len(args.namedPatterns) > 0 {
 		// CLI `--regexp` only accepts regex strings, map them into include rules
-		config.Rules.Include.Rules = make([]Rule, 0, len(args.regexpPatterns))
+		config.Rules.Include.Rules = make([]Rule, 0, len(args.regexpPatterns)+len(args.namedPatterns))
 		for _, p := range args.regexpPatterns {
 			config.Rules.Include.Rules = append(config.Rules.Include.Rules, Rule{Type: "regex", Pattern: p})
 		}
+		for _, p := range args.namedPatterns {
+			name, pattern, ok := strings.Cut(p, ":")
+			if !ok || name == "" || pattern == "" {
+				slog.Warn("Invalid --regexp-named, expected name:pattern; skipping", "value", p)
+				continue
+			}
+			config.Rules.Include.Rules = append(config.Rules.Include.Rules, Rule{Type: "regex", Name: name, Pattern: pattern})
+		}
 	}
 
+	if cmd.Flags().Changed("theme") {
+		config.Colors.Theme = args.theme
+	}
 	if cmd.Flags().Changed("fg-color") {
 		config.Colors.Match.Foreground = args.foregroundColor
+		config.Colors.setExplicit("match.foreground")
 	}
 	if cmd.Flags().Changed("bg-color") {
 		config.Colors.Match.Background = args.backgroundColor
+		config.Colors.setExplicit("match.background")
 	}
 	if cmd.Flags().Changed("hint-fg-color") {
 		config.Colors.Hint.Foreground = args.hintForegroundColor
+		config.Colors.setExplicit("hint.foreground")
 	}
 	if cmd.Flags().Changed("hint-bg-color") {
 		config.Colors.Hint.Background = args.hintBackgroundColor
+		config.Colors.setExplicit("hint.background")
 	}
 	if cmd.Flags().Changed("multi-fg-color") {
 		config.Colors.Multi.Foreground = args.multiForegroundColor
+		config.Colors.setExplicit("multi.foreground")
 	}
 	if cmd.Flags().Changed("multi-bg-color") {
 		config.Colors.Multi.Background = args.multiBackgroundColor
+		config.Colors.setExplicit("multi.background")
 	}
 	if cmd.Flags().Changed("select-fg-color") {
 		config.Colors.Select.Foreground = args.selectForegroundColor
+		config.Colors.setExplicit("select.foreground")
 	}
 	if cmd.Flags().Changed("select-bg-color") {
 		config.Colors.Select.Background = args.selectBackgroundColor
+		config.Colors.setExplicit("select.background")
 	}
 
 	if cmd.Flags().Changed("multi") {
@@ -278,50 +481,231 @@ func applyCliOverrides(cmd *cobra.Command, config *Config, args *Arguments) {
 		config.Core.Contrast = args.contrast
 	}
 
-	// Handle extra exclusion patterns from CLI
-	if len(args.extraExclusion) > 0 {
-		// Add CLI exclusion patterns as regex rules into unified rules.exclude
-		for _, pattern := range args.extraExclusion {
-			if _, err := regexp.Compile(pattern); err != nil {
-				slog.Warn("Invalid regex in --extra-exclusion; skipping", "pattern", pattern, "error", err)
-				continue
-			}
-			config.Rules.Exclude.Rules = append(config.Rules.Exclude.Rules, Rule{Type: "regex", Pattern: pattern})
+	if cmd.Flags().Changed("max-lines") {
+		config.Limits.MaxLines = args.maxLines
+	}
+	if cmd.Flags().Changed("max-line-length") {
+		config.Limits.MaxLineLength = args.maxLineLength
+	}
+	if cmd.Flags().Changed("max-matches") {
+		config.Limits.MaxMatches = args.maxMatches
+	}
+
+	// Handle extra exclusion patterns from CLI, they add to the configured
+	// rules.exclude rather than replace them
+	for _, text := range args.excludeText {
+		if text == "" {
+			continue
+		}
+		config.Rules.Exclude.Rules = append(config.Rules.Exclude.Rules, Rule{Type: "text", Pattern: text})
+	}
+	for _, pattern := range append(args.extraExclusion, args.excludeRegex...) {
+		if _, err := regexp.Compile(pattern); err != nil {
+			slog.Warn("Invalid exclusion regex; skipping", "pattern", pattern, "error", err)
+			continue
 		}
+		config.Rules.Exclude.Rules = append(config.Rules.Exclude.Rules, Rule{Type: "regex", Pattern: pattern})
 	}
 }
 
-// runApp runs the main application logic
-func runApp(config *Config, args *Arguments) error {
+// runApp runs the main application logic, source is nil without a config
+// file
+func runApp(config *Config, args *Arguments, source *configSource) error {
+	warnUnknownPlaceholders(config.Core.Format)
 
-	text, err := readInput(args.inputFile)
-	if err != nil {
+	if err := config.Colors.applyTheme(); err != nil {
 		return err
 	}
+	// The terminal background is kept for reloaded colors, the terminal
+	// can't be queried once the view owns it
+	var background struct {
+		rgb internal.RGB
+		ok  bool
+	}
+	config.Colors.resolveAutoColors(func() (internal.RGB, bool) {
+		background.rgb, background.ok = internal.TerminalBackground(backgroundTimeout)
+		return background.rgb, background.ok
+	})
+	config.Colors.warnUnreadableColors()
 
-	// Convert include rules to regex patterns list
+	span := logger.StartSpan("capture")
+	text, truncated := args.session.Input, false
+	var err error
+	if !args.last && args.replay == "" {
+		text, truncated, err = readInput(args.streams.stdin, args.inputFile, config.Limits)
+		if err != nil {
+			return err
+		}
+		args.session.Input = text
+		if config.Core.RedactPrivateKeys {
+			args.session.Input = internal.RedactPrivateKeys(text)
+		}
+		if err := saveSession(lastSessionFile, args.session); err != nil {
+			slog.Warn("Failed to save the session", "file", lastSessionFile, "error", err)
+		}
+	}
+	span.End("input_length", len(text), "truncated", truncated)
+
+	// Fail before taking over the screen on an invalid regex
+	if err := validatePatterns(config, validatedPatternsFile); err != nil {
+		return err
+	}
+
+	// Convert include rules to regex patterns list, named rules keep their name
 	var includePatterns []string
+	var namedPatterns, multilinePatterns []internal.MatchPattern
 	for _, r := range config.Rules.Include.Rules {
-		if r.Type == "regex" && r.Pattern != "" {
+		if r.Type != "regex" || r.Pattern == "" {
+			continue
+		}
+		if r.Multiline {
+			multilinePatterns = append(multilinePatterns, internal.MatchPattern{Name: cmp.Or(r.Name, "custom"), Pattern: r.Pattern})
+		} else if r.Name != "" {
+			namedPatterns = append(namedPatterns, internal.MatchPattern{Name: r.Name, Pattern: r.Pattern})
+		} else {
 			includePatterns = append(includePatterns, r.Pattern)
 		}
 	}
 	// Build state options based on configuration
 	var opts []internal.Option
 
+	if truncated {
+		slog.Warn("Input exceeds the limits, truncating", "max_lines", config.Limits.MaxLines, "max_line_length", config.Limits.MaxLineLength)
+		opts = append(opts, internal.WithTruncatedInput())
+	}
+	if config.Limits.MaxMatches > 0 {
+		opts = append(opts, internal.WithMaxMatches(config.Limits.MaxMatches))
+	}
+
+	// Fail before taking over the screen on an unknown or invalid alphabet
+	if _, err := internal.ResolveAlphabet(config.Core.Alphabet, config.Alphabets); err != nil {
+		return fmt.Errorf("resolving alphabet: %w", err)
+	}
+	if len(config.Alphabets) > 0 {
+		opts = append(opts, internal.WithCustomAlphabets(config.Alphabets))
+	}
+
+	if len(namedPatterns) > 0 {
+		opts = append(opts, internal.WithNamedPatterns(namedPatterns))
+	}
+
+	// Git patterns and actions only make sense inside a repository
+	git := false
+	if args.replay != "" {
+		git = args.recording.Git
+	} else if config.Git.Enabled {
+		if wd, err := os.Getwd(); err == nil && internal.IsGitRepo(wd) {
+			git = true
+		}
+	}
+	if git {
+		opts = append(opts, internal.WithGitPatterns())
+	}
+	if args.record != "" {
+		args.recording.Git = git
+	}
+	if config.Processes.Enabled {
+		opts = append(opts, internal.WithProcessPatterns())
+	}
+
+	// Previously selected values get the shortest hints
+	var history *internal.History
+	if args.replay != "" {
+		// Replays neither depend on nor change the history
+		opts = append(opts, internal.WithHistoryScores(args.recording.History))
+	} else if config.History.Enabled && !args.noHistory {
+		history, err = internal.LoadHistory(historyFile, config.History.MaxEntries)
+		if err != nil {
+			slog.Warn("Ignoring unreadable history", "file", historyFile, "error", err)
+		} else {
+			opts = append(opts, internal.WithHistoryScores(history.Scores()))
+			if args.record != "" {
+				args.recording.recordHistory(history.Scores())
+			}
+		}
+	}
+
+	sinks, err := newExtraSinks(args.also, args.streams.stdout, args.streams.stderr)
+	if err != nil {
+		return err
+	}
+
+	switch config.Core.Scope {
+	case internal.ScopeAll, "":
+	case internal.ScopeLastCommand:
+		opts = append(opts, internal.WithScope(config.Core.Scope))
+	default:
+		return fmt.Errorf("unknown scope %q, expected %s or %s", config.Core.Scope, internal.ScopeAll, internal.ScopeLastCommand)
+	}
+
+	switch config.Core.Mode {
+	case internal.ModePatterns, "":
+	case internal.ModeWords, internal.ModeIdentifiers, internal.ModeLines:
+		opts = append(opts, internal.WithMode(config.Core.Mode, config.Core.WordMinLength))
+	default:
+		return fmt.Errorf("unknown mode %q, expected %s, %s, %s or %s", config.Core.Mode,
+			internal.ModePatterns, internal.ModeWords, internal.ModeIdentifiers, internal.ModeLines)
+	}
+
+	if config.Core.JSONInput {
+		opts = append(opts, internal.WithJSONInput())
+	}
+	if config.Core.Multiline {
+		multilinePatterns = append(multilinePatterns, internal.BuiltinMultilinePatterns...)
+	}
+	if len(multilinePatterns) > 0 {
+		opts = append(opts, internal.WithMultilinePatterns(multilinePatterns))
+	}
+	if config.Core.RedactPrivateKeys {
+		opts = append(opts, internal.WithRedactedPrivateKeys())
+	}
+	if args.wrapWidth > 0 {
+		opts = append(opts, internal.WithWrapWidth(args.wrapWidth))
+	}
+	if config.Core.TabWidth != 0 {
+		opts = append(opts, internal.WithTabWidth(config.Core.TabWidth))
+	}
+
+	if config.Core.Proximity {
+		opts = append(opts, internal.WithProximity(args.cursorLine-1))
+	}
+
 	plugins := config.Plugins
 	if plugins.Tabledetection != nil && plugins.Tabledetection.Enabled {
+		if confidence := plugins.Tabledetection.ConfidenceThreshold; confidence < 0 || confidence > 1 {
+			return fmt.Errorf("table detection confidence %v out of range, expected 0 to 1", confidence)
+		}
 		opts = append(opts, internal.WithTableDetection(
 			plugins.Tabledetection.MinLines,
 			plugins.Tabledetection.MinColumns,
 			plugins.Tabledetection.ConfidenceThreshold,
 		))
+		if columns := plugins.Tabledetection.Columns; columns != nil && len(columns.Names) > 0 {
+			opts = append(opts, internal.WithTableColumns(columns.Names))
+		}
 	}
 
 	if plugins.Colordetection != nil && plugins.Colordetection.Enabled {
 		opts = append(opts, internal.WithColorDetection())
 	}
 
+	if plugins.Auto {
+		opts = append(opts, internal.WithAutoDetection(plugins.Tabledetection == nil, plugins.Colordetection == nil))
+	}
+
+	if plugins.Logdetection != nil && plugins.Logdetection.Enabled {
+		opts = append(opts, internal.WithLogDetection())
+	}
+
+	if plugins.Secrets != nil && plugins.Secrets.Enabled {
+		switch action := cmp.Or(plugins.Secrets.Action, internal.SecretsExclude); action {
+		case internal.SecretsExclude, internal.SecretsMask:
+			opts = append(opts, internal.WithSecretDetection(action))
+		default:
+			return fmt.Errorf("unknown secrets action %q, expected %s or %s", action, internal.SecretsExclude, internal.SecretsMask)
+		}
+	}
+
 	// Apply user-defined exclusion rules (unified rules section)
 	if len(config.Rules.Exclude.Rules) > 0 {
 		var rules []internal.ExclusionRule
@@ -331,12 +715,68 @@ func runApp(config *Config, args *Arguments) error {
 		opts = append(opts, internal.WithExclusionRules(rules))
 	}
 
+	if config.Core.MinMatchLength > 0 {
+		opts = append(opts, internal.WithMinMatchLength(config.Core.MinMatchLength))
+	}
+
+	if len(config.Patterns) > 0 {
+		patternConfigs := make(map[string]internal.PatternConfig, len(config.Patterns))
+		for name, settings := range config.Patterns {
+			patternConfigs[name] = internal.PatternConfig{
+				HintMinLength:   settings.HintMinLength,
+				TrimPunctuation: settings.TrimPunctuation,
+				MinLength:       settings.MinLength,
+				NotPrecededBy:   settings.NotPrecededBy,
+				NotFollowedBy:   settings.NotFollowedBy,
+				Priority:        settings.Priority,
+			}
+		}
+		opts = append(opts, internal.WithPatternConfigs(patternConfigs))
+	}
+
 	// Create state with all configured options
 	state := internal.NewState(text, config.Core.Alphabet, includePatterns, opts...)
 
+	keyBindings, err := internal.ParseKeyBindings(config.Keys)
+	if err != nil {
+		return fmt.Errorf("parsing key bindings: %w", err)
+	}
+	viewOpts := []internal.ViewOption{internal.WithKeyBindings(keyBindings)}
+
+	if len(config.Colors.Patterns) > 0 {
+		viewOpts = append(viewOpts, internal.WithPatternColors(patternColors(config.Colors.Patterns)))
+	}
+	if len(config.Colors.Columns) > 0 {
+		viewOpts = append(viewOpts, internal.WithColumnColors(columnColors(config.Colors.Columns)))
+	}
+
+	if args.record != "" || args.replay != "" {
+		if args.listView {
+			return errors.New("--record and --replay only work with the full screen view")
+		}
+		if args.replay != "" {
+			viewOpts = append(viewOpts, internal.WithReplay(args.recording.Events))
+		} else {
+			viewOpts = append(viewOpts, internal.WithRecorder(args.recording.record))
+		}
+	}
+
+	if terminal := args.streams.terminal; terminal != nil && args.replay == "" {
+		if terminal.device == "" {
+			return errors.New("the client of magonote serve has no terminal to show the view on")
+		}
+		if args.listView {
+			return errors.New("the list view can't be shown by magonote serve")
+		}
+		viewOpts = append(viewOpts, internal.WithTerminal(terminal.device, terminal.term))
+	}
+
 	var selected []internal.ChosenMatch
 
 	if args.listView {
+		if config.Core.ListVim {
+			viewOpts = append(viewOpts, internal.WithVimKeys())
+		}
 		listView := internal.NewListView(
 			state,
 			config.Core.Multi,
@@ -348,10 +788,35 @@ func runApp(config *Config, args *Arguments) error {
 			internal.GetColor(config.Colors.Match.Background),
 			internal.GetColor(config.Colors.Hint.Foreground),
 			internal.GetColor(config.Colors.Hint.Background),
+			viewOpts...,
 		)
 		selected = listView.Present()
+		err = listView.Err()
 	} else {
 		// Use full screen view
+		if args.confirmCommand != "" {
+			viewOpts = append(viewOpts, internal.WithReview(args.confirmCommand))
+		}
+		if config.Core.PrefixSelect {
+			viewOpts = append(viewOpts, internal.WithPrefixSelect())
+		}
+		if config.Core.HintPages {
+			viewOpts = append(viewOpts, internal.WithHintPages())
+		}
+		switch config.Core.Bidi {
+		case internal.BidiGutter, "":
+		case internal.BidiInline:
+			viewOpts = append(viewOpts, internal.WithBidi(config.Core.Bidi))
+		default:
+			return fmt.Errorf("unknown bidi mode %q, expected %s or %s", config.Core.Bidi, internal.BidiGutter, internal.BidiInline)
+		}
+		if config.Core.StatusBar {
+			viewOpts = append(viewOpts, internal.WithStatusBar(
+				internal.GetColor(config.Colors.Status.Foreground),
+				internal.GetColor(config.Colors.Status.Background),
+			))
+		}
+
 		viewbox := internal.NewView(
 			state,
 			config.Core.Multi,
@@ -367,8 +832,57 @@ func runApp(config *Config, args *Arguments) error {
 			internal.GetColor(config.Colors.Match.Background),
 			internal.GetColor(config.Colors.Hint.Foreground),
 			internal.GetColor(config.Colors.Hint.Background),
+			viewOpts...,
 		)
+
+		if args.watchConfig && source != nil {
+			stop, err := watchConfig(source.path, func() {
+				reloaded, err := source.load()
+				if err == nil {
+					err = reloaded.Colors.applyTheme()
+				}
+				if err != nil {
+					slog.Warn("Ignoring the changed config file", "file", source.path, "error", err)
+					return
+				}
+				reloaded.Colors.resolveAutoColors(func() (internal.RGB, bool) {
+					return background.rgb, background.ok
+				})
+				viewbox.UpdateColors(viewColors(&reloaded.Colors))
+			})
+			if err != nil {
+				slog.Warn("Not reloading the config file", "file", source.path, "error", err)
+			} else {
+				defer stop()
+			}
+		}
+
 		selected = viewbox.Present()
+		err = viewbox.Err()
+	}
+	if err != nil {
+		writeCrash(err)
+		return err
+	}
+
+	switch {
+	case args.record != "":
+		args.recording.session = *args.session
+		args.recording.Selection = selectionTexts(selected)
+		if err := args.recording.save(args.record, newRedactor(args.recording.Input)); err != nil {
+			return err
+		}
+	case args.replay != "":
+		if err := args.recording.checkReplay(selected); err != nil {
+			return err
+		}
+	}
+
+	if args.stats {
+		run := logger.CurrentRun()
+		if err := printStats(args.streams.stderr, state.Stats(), &run); err != nil {
+			slog.Warn("Failed to print statistics", "error", err)
+		}
 	}
 
 	if len(selected) == 0 {
@@ -378,17 +892,76 @@ func runApp(config *Config, args *Arguments) error {
 
 	}
 
+	if history != nil {
+		for _, item := range selected {
+			history.Record(item.Pattern, item.Text)
+		}
+		if err := history.Save(); err != nil {
+			slog.Warn("Failed to save history", "file", historyFile, "error", err)
+		}
+	}
+
+	// Replays are headless, the actions they chose are only output
+	if args.replay == "" {
+		commands, release, err := args.streams.commandStreams()
+		if err != nil {
+			return err
+		}
+		defer release()
+
+		remote := remoteActions{actions: config.RemoteActions, host: args.remoteHost}
+		opener := NewURLOpener(config.Core.Browser)
+		opener.streams, opener.Editor.streams = commands, commands
+		selected, err = runActions(selected, resolveActions(git, config.Actions), remote, opener, commands)
+		if err != nil {
+			return err
+		}
+
+		// Opening a file in the editor takes the place of the output
+		if i := slices.IndexFunc(selected, func(item internal.ChosenMatch) bool { return item.ShouldOpenFile }); i >= 0 {
+			slog.Info("Opening file with editor", "file", selected[i].Text, "editor", os.Getenv("EDITOR"))
+			if err := openFileWithEditor(selected[i].Text, commands); err != nil {
+				return fmt.Errorf("opening file with editor: %w", err)
+			}
+			return nil
+		}
+	}
+	if len(selected) == 0 {
+		return nil
+	}
+
 	output, err := processResults(selected, config.Core.Format)
 	if err != nil {
 		return err
 	}
 
-	return writeOutput(args.target, output)
+	return writeOutput(args.target, args.streams.stdout, sinks, output, selectedTexts(selected))
+}
+
+// writeCrash writes a panic recovered by the views to the crash file, as an
+// unrecovered one would be
+func writeCrash(err error) {
+	report := err.Error()
+	var panicErr *internal.PanicError
+	if errors.As(err, &panicErr) {
+		report += "\n\n" + string(panicErr.Stack)
+	}
+	if err := os.WriteFile(crashFile, []byte(report), 0o644); err != nil {
+		slog.Warn("Failed to write crash file", "file", crashFile, "error", err)
+	}
 }
 
 func main() {
 	debug.SetGCPercent(-1)
 
+	if err := newRootCmd().Execute(); err != nil {
+		slog.Error("Error executing command", "error", err)
+		os.Exit(1)
+	}
+}
+
+// newRootCmd creates the magonote command with all of its flags
+func newRootCmd() *cobra.Command {
 	var configPath string
 	args := &Arguments{}
 
@@ -400,43 +973,133 @@ func main() {
 			color.New(color.FgBlue).Sprintf("(%s)", FullVersion),
 		),
 		RunE: func(cmd *cobra.Command, _args []string) error {
-			var err error
-			var config *Config
+			args.streams = streams{
+				stdin:    cmd.InOrStdin(),
+				stdout:   cmd.OutOrStdout(),
+				stderr:   cmd.ErrOrStderr(),
+				terminal: clientTerminalFrom(cmd.Context()),
+			}
+
+			if args.requireVersion != "" {
+				if err := checkCompatibility(args.requireVersion); err != nil {
+					return err
+				}
+			}
 
 			if args.showVersion {
-				fmt.Printf("%s version: %s\n", appName, FullVersion)
+				fmt.Fprintf(args.streams.stdout, "%s version: %s\n", appName, FullVersion)
 				return nil
 			}
 
-			// Skip config loading if configPath is "NONE"
-			if configPath == "NONE" {
-				config = NewDefaultConfig()
-			} else {
-				// Load configuration from TOML and defaults
-				config, err = loadConfig(configPath)
+			// A server doesn't forward to another one
+			if args.socket != "" && args.streams.terminal == nil {
+				if handled, err := runRemote(args, os.Args[1:]); handled {
+					return err
+				}
+			}
+
+			if args.record != "" && args.replay != "" {
+				return errors.New("--record and --replay can't be combined")
+			}
+
+			// The flags of the last picker or of the recording apply before
+			// the config is loaded, they may name its profile
+			switch {
+			case args.replay != "":
+				rec, err := loadRecording(args.replay)
+				if err != nil {
+					return err
+				}
+				if err := rec.restore(cmd.Flags()); err != nil {
+					return err
+				}
+				args.session = &rec.session
+				args.recording = rec
+			case args.last:
+				saved, err := loadSession(lastSessionFile)
+				if err != nil {
+					return err
+				}
+				if err := saved.restore(cmd.Flags()); err != nil {
+					return err
+				}
+				args.session = saved
+			default:
+				args.session = &session{Flags: sessionFlags(cmd.Flags())}
+			}
+			if args.record != "" {
+				rec, err := newRecording(configPath)
 				if err != nil {
-					return fmt.Errorf("loading configuration: %w", err)
+					return err
+				}
+				args.recording = rec
+			}
+
+			load := func() (*Config, error) {
+				config := NewDefaultConfig()
+				if args.replay != "" {
+					// The config file as it was recorded
+					var err error
+					config, err = parseConfig(args.recording.Config)
+					if err != nil {
+						return nil, fmt.Errorf("loading recorded configuration: %w", err)
+					}
+				} else if configPath != "NONE" {
+					// Skip config loading if configPath is "NONE"
+					// Load configuration from TOML and defaults
+					var err error
+					config, err = loadConfig(configPath)
+					if err != nil {
+						return nil, fmt.Errorf("loading configuration: %w", err)
+					}
+				}
+
+				if err := config.applyProfile(args.profile); err != nil {
+					return nil, fmt.Errorf("loading configuration: %w", err)
 				}
 
+				// Apply CLI overrides
+				applyCliOverrides(cmd, config, args)
+				return config, nil
 			}
 
-			// Apply CLI overrides
-			applyCliOverrides(cmd, config, args)
+			config, err := load()
+			if err != nil {
+				return err
+			}
+
+			var source *configSource
+			if configPath != "NONE" && args.replay == "" {
+				source = &configSource{path: configFilePath(configPath), load: load}
+			}
 
-			return runApp(config, args)
+			err = runApp(config, args, source)
+			if saveErr := logger.SaveRun(lastRunFile); saveErr != nil {
+				slog.Warn("Failed to save the run timings", "error", saveErr)
+			}
+			return err
 		},
 	}
+	rootCmd.AddCommand(newDebugCmd())
+	rootCmd.AddCommand(newConfigCmd())
+	rootCmd.AddCommand(newServeCmd())
+	rootCmd.AddCommand(newHistoryCmd())
+	rootCmd.AddCommand(newDoctorCmd())
 
 	// Configuration
 	rootCmd.Flags().StringVar(&configPath, "config", "", "Config file path (default: XDG config dir, use 'NONE' to disable)")
+	rootCmd.Flags().StringVar(&args.socket, "socket", "", "Run through the magonote serve listening on this socket, running directly when none is")
+	rootCmd.Flags().StringVar(&args.profile, "profile", "", "Apply the settings of this [profile.<name>] of the config file")
 
 	// Core settings
 	rootCmd.Flags().StringVarP(&args.alphabet, "alphabet", "a", "qwerty", "Sets the alphabet")
-	rootCmd.Flags().StringVarP(&args.format, "format", "f", "%H", "Specifies the out format for the picked hint")
+	rootCmd.Flags().StringVarP(&args.format, "format", "f", "%H", "Specifies the out format for the picked hint (%H text, %U uppercase, %P pattern, %X column, %Y line, %L line text, %N index, %J JSON path, %Q text as a quoted string, %V shown text as a quoted string)")
 	rootCmd.Flags().StringVarP(&args.position, "position", "p", "left", "Hint position")
 	rootCmd.Flags().StringArrayVarP(&args.regexpPatterns, "regexp", "x", nil, "Use this regexp as extra pattern to match")
+	rootCmd.Flags().StringArrayVar(&args.namedPatterns, "regexp-named", nil, "Use this name:regexp as extra pattern to match, the name is available as %P in the format")
 
 	// Colors
+	rootCmd.Flags().StringVar(&args.theme, "theme", defaultTheme, "Color preset: "+strings.Join(themeNames(), ", ")+", overridden by the configured and given colors")
 	rootCmd.Flags().StringVar(&args.foregroundColor, "fg-color", "green", "Sets the foreground color for matches")
 	rootCmd.Flags().StringVar(&args.backgroundColor, "bg-color", "black", "Sets the background color for matches")
 	rootCmd.Flags().StringVar(&args.hintForegroundColor, "hint-fg-color", "yellow", "Sets the foreground color for hints")
@@ -451,22 +1114,54 @@ func main() {
 	rootCmd.Flags().BoolVarP(&args.reverse, "reverse", "r", false, "Reverse the order for assigned hints")
 	rootCmd.Flags().CountVarP(&args.uniqueLevel, "unique", "u", "Don't show duplicated hints for the same match (use -u for unique hints, -uu for unique match)")
 	rootCmd.Flags().BoolVarP(&args.contrast, "contrast", "c", false, "Put square brackets around hint for visibility")
+	rootCmd.Flags().BoolVar(&args.proximity, "proximity", false, "Assign the shortest hints to the matches closest to the cursor line instead of top to bottom")
+	rootCmd.Flags().BoolVar(&args.prefixSelect, "prefix-select", false, "Select matches by typing the start of their text instead of their hint")
+	rootCmd.Flags().BoolVar(&args.statusBar, "status-bar", false, "Show the mode, the number of matches, the hidden patterns and the typed keys on the bottom line")
+	rootCmd.Flags().BoolVar(&args.hintPages, "hint-pages", false, "Give hints to a page of matches at a time when two-character hints run out, ctrl-n shows the next page")
+	rootCmd.Flags().StringVar(&args.scope, "scope", internal.ScopeAll, "Lines to match: all, or last-command for the output of the last command before the prompt")
+	rootCmd.Flags().StringVar(&args.mode, "mode", internal.ModePatterns, "Also hint what the patterns miss: patterns, words for every whitespace delimited word, identifiers for every run of letters, digits and underscores, or lines to hint every line instead")
+	rootCmd.Flags().IntVar(&args.wordMinLength, "word-min-length", 0, "Don't hint the words of --mode shorter than this many characters")
+	rootCmd.Flags().BoolVar(&args.jsonInput, "json-input", false, "Parse the input as JSON even when it doesn't start with { or [, hinting its string and number values, whose path %J formats")
+	rootCmd.Flags().BoolVar(&args.multiline, "multiline", false, "Also match PEM blocks, shell commands continued with a trailing backslash and JSON strings spanning several lines")
+	rootCmd.Flags().BoolVar(&args.tableDetection, "tabledetection", false, "Match the cells of detected tables, like [plugins.tabledetection]")
+	rootCmd.Flags().IntVar(&args.tableMinLines, "tabledetection-min-lines", defaultTableMinLines, "Lines a table needs to be detected, implies --tabledetection")
+	rootCmd.Flags().Float64Var(&args.tableConfidence, "tabledetection-confidence", defaultTableConfidence, "Confidence from 0 to 1 a table needs to be detected, implies --tabledetection")
+	rootCmd.Flags().BoolVar(&args.colorDetection, "colordetection", false, "Match the text styled by the colors of the input, like [plugins.colordetection]")
+	rootCmd.Flags().BoolVar(&args.noAutoDetection, "no-auto-detection", false, "Don't turn table and color detection on when the input looks like it needs them")
+	rootCmd.Flags().IntVar(&args.tabWidth, "tab-width", internal.DefaultTabWidth, "Columns between the tab stops the tabs of the input are drawn up to, 0 draws them as is")
+	rootCmd.Flags().IntVar(&args.wrapWidth, "wrap-width", 0, "Width the terminal wrapped the input lines at, such as the pane width of a capture without -J, matching across the wrapped lines")
+	rootCmd.Flags().IntVar(&args.cursorLine, "cursor-line", 0, "Line of the cursor in the input (1-based) used by --proximity, defaults to the last non-empty line")
 
 	// Runtime settings
 	rootCmd.Flags().StringVarP(&args.target, "target", "t", "", "Stores the hint in the specified path")
+	rootCmd.Flags().StringArrayVar(&args.also, "also", nil, "Also write the selected text, unformatted, to stdout, clipboard, tmux-buffer or osc52, can be repeated")
 	rootCmd.Flags().StringVarP(&args.inputFile, "input-file", "i", "", "Read input from file instead of stdin")
+	rootCmd.Flags().StringVar(&args.remoteHost, "remote-host", "", "Host of the ssh session the input comes from, running the [remote_actions] of its matches")
+	rootCmd.Flags().BoolVar(&args.last, "last", false, "Reopen the last picker with its input and flags, the flags given taking precedence")
+	rootCmd.Flags().StringVar(&args.record, "record", "", "Record the input, config file, flags, keys and selection of the full screen view to this file, with the home directory and user@host replaced and likely credentials and private keys masked, review it before sharing")
+	rootCmd.Flags().StringVar(&args.replay, "replay", "", "Replay a file of --record without a terminal, failing if the selection differs from the recorded one")
 	rootCmd.Flags().BoolVarP(&args.showVersion, "version", "v", false, "Print version and exit")
+	rootCmd.Flags().StringVar(&args.requireVersion, "require-version", "", "Exit with an error unless this version is compatible with the given one (same major, at least the given minor and patch)")
 	rootCmd.Flags().StringArrayVar(&args.extraExclusion, "extra-exclusion", nil, "Additional regex patterns to exclude from matching")
+	rootCmd.Flags().StringArrayVar(&args.excludeText, "exclude-text", nil, "Don't match anything overlapping this text, can be repeated")
+	rootCmd.Flags().StringArrayVar(&args.excludeRegex, "exclude-regex", nil, "Don't match anything overlapping this regexp, can be repeated")
+	rootCmd.Flags().IntVar(&args.maxLines, "max-lines", defaultMaxLines, "Read at most this many input lines, 0 for no limit")
+	rootCmd.Flags().IntVar(&args.maxLineLength, "max-line-length", defaultMaxLineLength, "Keep at most this many bytes of every input line, 0 for no limit")
+	rootCmd.Flags().IntVar(&args.maxMatches, "max-matches", defaultMaxMatches, "Show at most this many matches, 0 for no limit")
 
 	rootCmd.Flags().BoolVar(&args.listView, "list", false, "Enable list view")
+	rootCmd.Flags().BoolVar(&args.listVim, "list-vim", false, "Navigate the list view with j/k/gg/G, choose a row by typing its number and enter, search with /")
+	rootCmd.Flags().BoolVar(&args.noHistory, "no-history", false, "Neither prioritize nor record previously selected values")
+	rootCmd.Flags().BoolVar(&args.watchConfig, "watch-config", false, "Reload the colors of the full screen view when the config file changes")
+	rootCmd.Flags().BoolVar(&args.stats, "stats", false, "Print the matches per pattern, table detection results, hints and timings to stderr after the selection")
+	rootCmd.Flags().StringVar(&args.bidi, "bidi", internal.BidiGutter, "Where the hints of matches on lines with right-to-left text go: gutter at the start of the line, or inline next to the matches")
+	rootCmd.Flags().StringVar(&args.browser, "browser", "", "Command opening URLs for the open action, $BROWSER or the system opener by default")
+	rootCmd.Flags().StringVar(&args.confirmCommand, "confirm-command", "", "Review multi-selections against this command template ({} is replaced by the selection) before output")
 
 	rootCmd.SetHelpTemplate(cmd.HelpTemplate)
 	rootCmd.SetUsageFunc(func(c *cobra.Command) error {
 		return cmd.ColorUsageFunc(c.OutOrStderr(), c)
 	})
 
-	if err := rootCmd.Execute(); err != nil {
-		slog.Error("Error executing command", "error", err)
-		os.Exit(1)
-	}
+	return rootCmd
 }
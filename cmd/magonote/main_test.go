@@ -0,0 +1,205 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Hanaasagi/magonote/internal"
+)
+
+func TestProcessResults(t *testing.T) {
+	selected := []internal.ChosenMatch{
+		{
+			Text:    "main.go",
+			Pattern: "filename",
+			X:       4,
+			Y:       2,
+			Line:    "see main.go for %H",
+			Index:   0,
+		},
+		{
+			Text:      "/tmp/a",
+			Pattern:   "path",
+			Y:         9,
+			Line:      "/tmp/a",
+			Index:     3,
+			Uppercase: true,
+		},
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: "%H", want: "main.go\n/tmp/a"},
+		{format: "%U:%H", want: "false:main.go\ntrue:/tmp/a"},
+		{format: "%P %N", want: "filename 1\npath 4"},
+		{format: "%H:%Y:%X", want: "main.go:3:5\n/tmp/a:10:1"},
+		{format: "%L", want: "see main.go for %H\n/tmp/a"},
+	}
+
+	for _, tt := range tests {
+		got, err := processResults(selected, tt.format)
+		if err != nil {
+			t.Fatalf("processResults(%q) error = %v", tt.format, err)
+		}
+		if got != tt.want {
+			t.Errorf("processResults(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestReadInputLimits(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		limits        LimitsConfig
+		want          string
+		wantTruncated bool
+	}{
+		{
+			name:  "no limits",
+			input: "one\ntwo\nthree\n",
+			want:  "one\ntwo\nthree",
+		},
+		{
+			name:          "max lines",
+			input:         "one\ntwo\nthree\n",
+			limits:        LimitsConfig{MaxLines: 2},
+			want:          "one\ntwo",
+			wantTruncated: true,
+		},
+		{
+			name:   "exactly max lines",
+			input:  "one\ntwo\n",
+			limits: LimitsConfig{MaxLines: 2},
+			want:   "one\ntwo",
+		},
+		{
+			name:          "max line length",
+			input:         "abcdef\nab\nabcd",
+			limits:        LimitsConfig{MaxLineLength: 3},
+			want:          "abc\nab\nabc",
+			wantTruncated: true,
+		},
+		{
+			name:          "cut at character boundary",
+			input:         "a日本",
+			limits:        LimitsConfig{MaxLineLength: 5},
+			want:          "a日",
+			wantTruncated: true,
+		},
+		{
+			name:          "line longer than the buffer",
+			input:         strings.Repeat("x", 3*defaultSize) + "\nnext",
+			limits:        LimitsConfig{MaxLineLength: 10},
+			want:          "xxxxxxxxxx\nnext",
+			wantTruncated: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "input")
+			if err := os.WriteFile(path, []byte(tt.input), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			got, truncated, err := readInput(nil, path, tt.limits)
+			if err != nil {
+				t.Fatalf("readInput error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+			if truncated != tt.wantTruncated {
+				t.Errorf("Expected truncated %v, got %v", tt.wantTruncated, truncated)
+			}
+		})
+	}
+}
+
+func TestExclusionFlags(t *testing.T) {
+	cmd := newRootCmd()
+	if err := cmd.ParseFlags([]string{
+		"--exclude-text", "DEBUG",
+		"--exclude-regex", `^\$ `,
+		"--exclude-regex", "(",
+		"--extra-exclusion", "INFO.*",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	args := &Arguments{}
+	args.excludeText, _ = cmd.Flags().GetStringArray("exclude-text")
+	args.excludeRegex, _ = cmd.Flags().GetStringArray("exclude-regex")
+	args.extraExclusion, _ = cmd.Flags().GetStringArray("extra-exclusion")
+
+	config := NewDefaultConfig()
+	config.Rules.Exclude.Rules = []Rule{{Type: "text", Pattern: "TRACE"}}
+	applyCliOverrides(cmd, config, args)
+
+	want := []Rule{
+		{Type: "text", Pattern: "TRACE"},
+		{Type: "text", Pattern: "DEBUG"},
+		{Type: "regex", Pattern: "INFO.*"},
+		{Type: "regex", Pattern: `^\$ `},
+	}
+	if !reflect.DeepEqual(config.Rules.Exclude.Rules, want) {
+		t.Errorf("Expected exclusion rules %v, got %v", want, config.Rules.Exclude.Rules)
+	}
+}
+
+func TestPluginFlags(t *testing.T) {
+	tests := []struct {
+		name           string
+		flags          []string
+		tableDetection *TableDetectionPluginConfig
+		colorDetection *ColorDetectionPluginConfig
+	}{
+		{name: "none"},
+		{
+			name:           "tuning enables table detection",
+			flags:          []string{"--tabledetection-min-lines", "5"},
+			tableDetection: &TableDetectionPluginConfig{Enabled: true, MinLines: 5, MinColumns: 3, ConfidenceThreshold: 0.8},
+		},
+		{
+			name:           "disabled",
+			flags:          []string{"--tabledetection=false", "--tabledetection-confidence", "0.5"},
+			tableDetection: &TableDetectionPluginConfig{MinLines: 3, MinColumns: 3, ConfidenceThreshold: 0.5},
+		},
+		{
+			name:           "color detection",
+			flags:          []string{"--colordetection"},
+			colorDetection: &ColorDetectionPluginConfig{Enabled: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newRootCmd()
+			if err := cmd.ParseFlags(tt.flags); err != nil {
+				t.Fatal(err)
+			}
+
+			args := &Arguments{}
+			args.tableDetection, _ = cmd.Flags().GetBool("tabledetection")
+			args.tableMinLines, _ = cmd.Flags().GetInt("tabledetection-min-lines")
+			args.tableConfidence, _ = cmd.Flags().GetFloat64("tabledetection-confidence")
+			args.colorDetection, _ = cmd.Flags().GetBool("colordetection")
+
+			config := NewDefaultConfig()
+			applyPluginFlags(cmd, config, args)
+
+			if !reflect.DeepEqual(config.Plugins.Tabledetection, tt.tableDetection) {
+				t.Errorf("Expected table detection %+v, got %+v", tt.tableDetection, config.Plugins.Tabledetection)
+			}
+			if !reflect.DeepEqual(config.Plugins.Colordetection, tt.colorDetection) {
+				t.Errorf("Expected color detection %+v, got %+v", tt.colorDetection, config.Plugins.Colordetection)
+			}
+		})
+	}
+}
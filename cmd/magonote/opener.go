@@ -0,0 +1,116 @@
+package main
+
+import (
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/Hanaasagi/magonote/internal"
+)
+
+// openAction is the action opening the match instead of running a command:
+// URLs in the browser and file:// URLs in the editor
+const openAction = "open"
+
+// issueShorthand matches the github.com/owner/repo#123 and
+// gitlab.com/group/project#123 references to an issue or pull request
+var issueShorthand = regexp.MustCompile(`^(?:https?://)?(github\.com|gitlab\.com)/([\w.-]+/[\w.-]+)#(\d+)$`)
+
+// schemePattern matches the scheme of a URL
+var schemePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:`)
+
+// bareHostPattern matches a URL missing its scheme, a host with a dot
+// followed by a path
+var bareHostPattern = regexp.MustCompile(`^[\w-]+(?:\.[\w-]+)+(?::\d+)?/`)
+
+// hostPortPattern matches a URL missing its scheme that starts with a host
+// and a port, which schemePattern would take for a scheme
+var hostPortPattern = regexp.MustCompile(`^([\w-]+(?:\.[\w-]+)*):\d+(?:/|$)`)
+
+// NormalizeURL returns the URL text refers to: quotes around it and trailing
+// punctuation are stripped, issue references expanded and https:// added to
+// URLs without a scheme, http:// for localhost
+func NormalizeURL(text string) string {
+	text = strings.Trim(strings.TrimSpace(text), "\"'`<>")
+	text = internal.TrimTrailingPunctuation(text)
+
+	if m := issueShorthand.FindStringSubmatch(text); m != nil {
+		if m[1] == "gitlab.com" {
+			return "https://gitlab.com/" + m[2] + "/-/issues/" + m[3]
+		}
+		return "https://github.com/" + m[2] + "/issues/" + m[3]
+	}
+	if m := hostPortPattern.FindStringSubmatch(text); m != nil {
+		// Local servers rarely serve https
+		if m[1] == "localhost" {
+			return "http://" + text
+		}
+		return "https://" + text
+	}
+	if !schemePattern.MatchString(text) && (strings.HasPrefix(text, "www.") || bareHostPattern.MatchString(text)) {
+		return "https://" + text
+	}
+	return text
+}
+
+// fileURLPath returns the local path of a file:// URL
+func fileURLPath(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "file" || (u.Host != "" && u.Host != "localhost") || u.Path == "" {
+		return "", false
+	}
+	return u.Path, true
+}
+
+// URLOpener opens URLs in the browser and file:// URLs in the editor
+type URLOpener struct {
+	// Browser is the command opening URLs, it may contain arguments
+	Browser string
+	Editor  *EditorLauncher
+	// streams the browser is attached to, those of the process when nil
+	streams *streams
+}
+
+// NewURLOpener creates an opener using browser, falling back to $BROWSER
+// and then to the opener of the system
+func NewURLOpener(browser string) *URLOpener {
+	if browser == "" {
+		// $BROWSER may list several browsers separated by colons
+		browser, _, _ = strings.Cut(os.Getenv("BROWSER"), ":")
+	}
+	return &URLOpener{Browser: browser, Editor: NewEditorLauncher()}
+}
+
+// Args returns the browser executable and its arguments for rawURL
+func (o *URLOpener) Args(rawURL string) (string, []string) {
+	fields := strings.Fields(o.Browser)
+	if len(fields) == 0 {
+		if runtime.GOOS == "darwin" {
+			return "open", []string{rawURL}
+		}
+		return "xdg-open", []string{rawURL}
+	}
+	return fields[0], append(fields[1:], rawURL)
+}
+
+// Open opens the URL text refers to, attached to the terminal for the
+// editor and terminal browsers
+func (o *URLOpener) Open(text string) error {
+	target := NormalizeURL(text)
+	if path, ok := fileURLPath(target); ok {
+		loc := FileLocation{Path: path}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			loc = ParseFileLocation(path)
+		}
+		return o.Editor.Open(loc)
+	}
+
+	name, args := o.Args(target)
+	cmd := exec.Command(name, args...)
+	o.streams.attach(cmd)
+
+	return cmd.Run()
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "https://example.com/a.", want: "https://example.com/a"},
+		{text: "(see https://example.com/a)", want: "(see https://example.com/a)"},
+		{text: "https://en.wikipedia.org/wiki/Go_(game)", want: "https://en.wikipedia.org/wiki/Go_(game)"},
+		{text: "<https://example.com/a>,", want: "https://example.com/a"},
+		{text: "\"https://example.com\"", want: "https://example.com"},
+		{text: "github.com/golang/go#12345", want: "https://github.com/golang/go/issues/12345"},
+		{text: "https://github.com/golang/go#12345.", want: "https://github.com/golang/go/issues/12345"},
+		{text: "gitlab.com/gitlab-org/gitlab#42", want: "https://gitlab.com/gitlab-org/gitlab/-/issues/42"},
+		{text: "www.example.com", want: "https://www.example.com"},
+		{text: "example.com/docs", want: "https://example.com/docs"},
+		{text: "example.com:8080/x", want: "https://example.com:8080/x"},
+		{text: "localhost:3000/a", want: "http://localhost:3000/a"},
+		{text: "mailto:me@example.com", want: "mailto:me@example.com"},
+		{text: "file:///tmp/a.txt", want: "file:///tmp/a.txt"},
+		{text: "src/main.go", want: "src/main.go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := NormalizeURL(tt.text); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestURLOpener(t *testing.T) {
+	opener := NewURLOpener("firefox --new-tab")
+	name, args := opener.Args("https://example.com")
+	if got := append([]string{name}, args...); !slices.Equal(got, []string{"firefox", "--new-tab", "https://example.com"}) {
+		t.Errorf("Expected the browser and its arguments, got %q", got)
+	}
+
+	t.Setenv("BROWSER", "w3m:lynx")
+	if opener := NewURLOpener(""); opener.Browser != "w3m" {
+		t.Errorf("Expected the first browser of $BROWSER, got %q", opener.Browser)
+	}
+
+	// Browsers get the normalized URL
+	out := filepath.Join(t.TempDir(), "out")
+	script := filepath.Join(t.TempDir(), "browser")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nprintf %s \"$1\" > "+out+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewURLOpener(script).Open("github.com/golang/go#1,"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(out); string(got) != "https://github.com/golang/go/issues/1" {
+		t.Errorf("Expected the issue URL to be opened, got %q", got)
+	}
+
+	// file:// URLs open in the editor
+	file := filepath.Join(t.TempDir(), "a b.txt")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	opener = NewURLOpener(script)
+	opener.Editor = &EditorLauncher{Command: script}
+	if err := opener.Open("file://" + filepath.ToSlash(filepath.Dir(file)) + "/a%20b.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(out); string(got) != file {
+		t.Errorf("Expected the editor to open %q, got %q", file, got)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Hanaasagi/magonote/internal"
+	"github.com/Hanaasagi/magonote/pkg/clipboard"
+)
+
+// sink is a destination of the output
+type sink interface {
+	write(content string) error
+}
+
+// stdoutSink prints the output
+type stdoutSink struct {
+	w io.Writer
+}
+
+func (s stdoutSink) write(content string) error {
+	_, err := io.WriteString(s.w, content)
+	return err
+}
+
+// fileSink stores the output in a file, for frontends such as the tmux
+// wrapper to read it
+type fileSink struct {
+	path string
+}
+
+func (s fileSink) write(content string) error {
+	file, err := os.Create(s.path)
+	if err != nil {
+		return fmt.Errorf("creating target file: %w", err)
+	}
+	defer file.Close() // nolint: errcheck
+
+	writer := bufio.NewWriterSize(file, defaultSize)
+	if _, err := writer.WriteString(content); err != nil {
+		return fmt.Errorf("writing to target file: %w", err)
+	}
+	return writer.Flush()
+}
+
+// clipboardSink copies the output with a clipboard writer
+type clipboardSink struct {
+	writer clipboard.Writer
+}
+
+func (s clipboardSink) write(content string) error {
+	return s.writer.Write(content)
+}
+
+// extraSinks are the sinks --also accepts by name, created for the stdout and
+// stderr of the invocation
+var extraSinks = map[string]func(stdout, stderr io.Writer) sink{
+	"stdout":      func(stdout, _ io.Writer) sink { return stdoutSink{stdout} },
+	"clipboard":   func(_, _ io.Writer) sink { return clipboardSink{clipboard.NewSystemWriter()} },
+	"tmux-buffer": func(_, _ io.Writer) sink { return clipboardSink{clipboard.NewTmuxWriter()} },
+	"osc52":       func(_, stderr io.Writer) sink { return clipboardSink{clipboard.NewOSC52Writer(stderr)} },
+}
+
+// extraSinkNames returns the names of extraSinks, sorted
+func extraSinkNames() []string {
+	return slices.Sorted(maps.Keys(extraSinks))
+}
+
+// extraSink is a sink named by --also
+type extraSink struct {
+	name string
+	sink
+}
+
+// newExtraSinks returns the sinks named by --also, in order
+func newExtraSinks(names []string, stdout, stderr io.Writer) ([]extraSink, error) {
+	var sinks []extraSink
+	for _, name := range names {
+		newSink, ok := extraSinks[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown output %q, expected one of %s", name, strings.Join(extraSinkNames(), ", "))
+		}
+		sinks = append(sinks, extraSink{name: name, sink: newSink(stdout, stderr)})
+	}
+	return sinks, nil
+}
+
+// selectedTexts returns the texts of the selected matches, one per line, as
+// the extra sinks get them regardless of --format
+func selectedTexts(selected []internal.ChosenMatch) string {
+	texts := make([]string, 0, len(selected))
+	for _, item := range selected {
+		texts = append(texts, item.Text)
+	}
+	return strings.Join(texts, "\n")
+}
+
+// writeOutput writes the formatted content to the target file, or stdout
+// without one, then the raw selected texts to the extra sinks. Only failing
+// to write the target is an error, so that frontends reading it aren't let
+// down by a missing clipboard tool
+func writeOutput(target string, stdout io.Writer, extra []extraSink, content, raw string) error {
+	var primary sink = stdoutSink{stdout}
+	if target != "" {
+		primary = fileSink{path: target}
+	}
+	if err := primary.write(content); err != nil {
+		return err
+	}
+
+	written := map[string]bool{}
+	if target == "" {
+		written["stdout"] = true
+	}
+	for _, s := range extra {
+		if written[s.name] {
+			continue
+		}
+		written[s.name] = true
+		if err := s.write(raw); err != nil {
+			slog.Warn("Failed to write output", "output", s.name, "error", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// recordingSink keeps what is written to it, failing with err
+type recordingSink struct {
+	written []string
+	err     error
+}
+
+func (s *recordingSink) write(content string) error {
+	s.written = append(s.written, content)
+	return s.err
+}
+
+func TestWriteOutput(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "target")
+	clipboard := &recordingSink{}
+	broken := &recordingSink{err: errors.New("no clipboard tool")}
+	extra := []extraSink{
+		{name: "osc52", sink: broken},
+		{name: "clipboard", sink: clipboard},
+		{name: "clipboard", sink: clipboard},
+	}
+
+	if err := writeOutput(target, io.Discard, extra, "a.log:12", "a.log"); err != nil {
+		t.Fatalf("writeOutput() error = %v", err)
+	}
+
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatalf("Failed to read target: %v", err)
+	}
+	if string(data) != "a.log:12" {
+		t.Errorf("Expected target to hold the formatted 'a.log:12', got %q", data)
+	}
+	if len(clipboard.written) != 1 || clipboard.written[0] != "a.log" {
+		t.Errorf("Expected the clipboard to be written the raw text once, got %q", clipboard.written)
+	}
+	if len(broken.written) != 1 {
+		t.Errorf("Expected the failing sink to be tried, got %q", broken.written)
+	}
+}
+
+func TestNewExtraSinks(t *testing.T) {
+	sinks, err := newExtraSinks([]string{"tmux-buffer", "stdout"}, io.Discard, io.Discard)
+	if err != nil {
+		t.Fatalf("newExtraSinks() error = %v", err)
+	}
+	if len(sinks) != 2 || sinks[0].name != "tmux-buffer" || sinks[1].name != "stdout" {
+		t.Errorf("Expected tmux-buffer and stdout sinks in order, got %+v", sinks)
+	}
+
+	if _, err := newExtraSinks([]string{"printer"}, io.Discard, io.Discard); err == nil {
+		t.Error("Expected an error for an unknown output")
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// validatedPatternsFile holds the digest of the regexes of the last config
+// validatePatterns accepted, so that the next runs don't compile them twice
+var validatedPatternsFile = filepath.Join(appDir, "validated-patterns")
+
+// configRegex is a regex of the config, named by the setting holding it
+type configRegex struct {
+	setting string
+	pattern string
+}
+
+// configRegexes returns the regexes of the include and exclude rules and of
+// the pattern lookarounds of config, in a stable order
+func configRegexes(config *Config) []configRegex {
+	var regexes []configRegex
+	for section, rules := range map[string][]Rule{
+		"include": config.Rules.Include.Rules,
+		"exclude": config.Rules.Exclude.Rules,
+	} {
+		for i, rule := range rules {
+			if rule.Type == "regex" && rule.Pattern != "" {
+				regexes = append(regexes, configRegex{fmt.Sprintf("rules.%s.rules[%d]", section, i), rule.Pattern})
+			}
+		}
+	}
+	for name, settings := range config.Patterns {
+		regexes = append(regexes,
+			configRegex{"patterns." + name + ".not_preceded_by", settings.NotPrecededBy},
+			configRegex{"patterns." + name + ".not_followed_by", settings.NotFollowedBy},
+		)
+	}
+	slices.SortFunc(regexes, func(a, b configRegex) int {
+		return strings.Compare(a.setting, b.setting)
+	})
+	return regexes
+}
+
+// patternsDigest returns a digest of regexes and of the version of magonote,
+// whose regexp syntax accepted them
+func patternsDigest(regexes []configRegex) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00", FullVersion)
+	for _, regex := range regexes {
+		fmt.Fprintf(hash, "%s\x00%s\x00", regex.setting, regex.pattern)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// validatePatterns checks that the regexes of config compile, so that a bad
+// one is reported before taking over the screen. The check is skipped when the
+// regexes are those last validated, as recorded in cacheFile
+func validatePatterns(config *Config, cacheFile string) error {
+	regexes := configRegexes(config)
+	if len(regexes) == 0 {
+		return nil
+	}
+
+	digest := patternsDigest(regexes)
+	if cached, err := os.ReadFile(cacheFile); err == nil && string(cached) == digest {
+		slog.Debug("Skipping validation of unchanged patterns")
+		return nil
+	}
+
+	for _, regex := range regexes {
+		if _, err := regexp.Compile(regex.pattern); err != nil {
+			return fmt.Errorf("compiling %s: %w", regex.setting, err)
+		}
+	}
+
+	if err := os.WriteFile(cacheFile, []byte(digest), 0o644); err != nil {
+		slog.Debug("Failed to record validated patterns", "file", cacheFile, "error", err)
+	}
+	return nil
+}
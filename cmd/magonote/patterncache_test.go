@@ -0,0 +1,34 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidatePatterns(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "validated-patterns")
+
+	config := NewDefaultConfig()
+	config.Rules.Include.Rules = []Rule{{Type: "regex", Pattern: `ticket-\d+`}}
+	if err := validatePatterns(config, cacheFile); err != nil {
+		t.Fatalf("validatePatterns() error = %v", err)
+	}
+	digest, err := os.ReadFile(cacheFile)
+	if err != nil || string(digest) != patternsDigest(configRegexes(config)) {
+		t.Errorf("Expected the digest of the valid patterns to be recorded, got %q (%v)", digest, err)
+	}
+
+	config.Patterns = map[string]PatternSettings{"sha": {NotPrecededBy: `[a-`}}
+	if err := validatePatterns(config, cacheFile); err == nil {
+		t.Error("Expected an error for an invalid lookaround")
+	}
+
+	// Patterns recorded as validated aren't compiled again
+	if err := os.WriteFile(cacheFile, []byte(patternsDigest(configRegexes(config))), 0o644); err != nil {
+		t.Fatalf("Failed to write cache: %v", err)
+	}
+	if err := validatePatterns(config, cacheFile); err != nil {
+		t.Errorf("Expected the recorded patterns to be skipped, got %v", err)
+	}
+}
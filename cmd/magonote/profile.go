@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// applyProfile overlays the settings of the profile [profile.<name>] on the
+// config, such as the patterns, colors and actions of a task. The rules of
+// the profile are added to the configured ones, other settings replace them
+func (c *Config) applyProfile(name string) error {
+	if name == "" {
+		return nil
+	}
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return fmt.Errorf("unknown profile %q, expected one of %s", name, strings.Join(slices.Sorted(maps.Keys(c.Profiles)), ", "))
+	}
+
+	include, exclude := c.Rules.Include.Rules, c.Rules.Exclude.Rules
+	c.Rules.Include.Rules, c.Rules.Exclude.Rules = nil, nil
+	if err := c.meta.PrimitiveDecode(profile, c); err != nil {
+		return fmt.Errorf("decoding profile %s: %w", name, err)
+	}
+	c.Rules.Include.Rules = append(include, c.Rules.Include.Rules...)
+	c.Rules.Exclude.Rules = append(exclude, c.Rules.Exclude.Rules...)
+
+	for key := range c.Colors.colorKeys() {
+		group, field, _ := strings.Cut(key, ".")
+		if c.meta.IsDefined("profile", name, "colors", group, field) {
+			c.Colors.setExplicit(key)
+		}
+	}
+	return nil
+}
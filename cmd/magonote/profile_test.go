@@ -0,0 +1,97 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const profileConfig = `
+[colors.match]
+foreground = "green"
+
+[[rules.include.rules]]
+type = "regex"
+pattern = "TICKET-\\d+"
+
+[actions]
+url = "xdg-open {}"
+
+[profile.k8s.core]
+alphabet = "colemak"
+
+[profile.k8s.colors.match]
+foreground = "cyan"
+
+[[profile.k8s.rules.include.rules]]
+type = "regex"
+name = "pod"
+pattern = "[a-z]+-[a-z0-9]{5}"
+
+[profile.k8s.actions]
+pod = "kubectl logs {}"
+
+[profile.k8s.patterns.pod]
+hint_min_length = 8
+
+[profile.git.core]
+alphabet = "dvorak"
+`
+
+func loadProfileConfig(t *testing.T) *Config {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(profileConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadConfigFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+func TestApplyProfile(t *testing.T) {
+	config := loadProfileConfig(t)
+	if err := config.applyProfile("k8s"); err != nil {
+		t.Fatalf("applyProfile() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		got      any
+		expected any
+	}{
+		{"alphabet", config.Core.Alphabet, "colemak"},
+		{"untouched core setting", config.Core.Format, "%H"},
+		{"color", config.Colors.Match.Foreground, "cyan"},
+		{"color is explicit", config.Colors.explicit["match.foreground"], true},
+		{"rules are added", len(config.Rules.Include.Rules), 2},
+		{"profile rule", config.Rules.Include.Rules[1].Name, "pod"},
+		{"configured action", config.Actions["url"], "xdg-open {}"},
+		{"profile action", config.Actions["pod"], "kubectl logs {}"},
+		{"pattern settings", config.Patterns["pod"].HintMinLength, 8},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.expected {
+			t.Errorf("%s: Expected %v, got %v", tt.name, tt.expected, tt.got)
+		}
+	}
+}
+
+func TestApplyProfileErrors(t *testing.T) {
+	config := loadProfileConfig(t)
+	if err := config.applyProfile(""); err != nil {
+		t.Errorf("Expected no profile to be a no-op, got %v", err)
+	}
+	if config.Core.Alphabet != "qwerty" {
+		t.Errorf("Expected the default alphabet, got %q", config.Core.Alphabet)
+	}
+
+	if err := config.applyProfile("docker"); err == nil {
+		t.Error("Expected an error for an unknown profile")
+	}
+	if err := NewDefaultConfig().applyProfile("git"); err == nil {
+		t.Error("Expected an error without a config file")
+	}
+}
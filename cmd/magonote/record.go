@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/user"
+	"slices"
+	"strings"
+
+	"github.com/Hanaasagi/magonote/internal"
+)
+
+// recording is a run of the full screen view saved by --record and
+// reproduced by --replay: what shaped the view, the events it got and what
+// was selected
+type recording struct {
+	session
+	Version   string                   `json:"version"`
+	Config    string                   `json:"config,omitempty"` // Content of the config file
+	Git       bool                     `json:"git,omitempty"`    // Git patterns were on
+	History   map[string]int           `json:"history,omitempty"`
+	Events    []internal.RecordedEvent `json:"events"`
+	Selection []string                 `json:"selection"`
+}
+
+// newRecording starts a recording with the content of the config file at
+// path, none when path is "NONE"
+func newRecording(path string) (*recording, error) {
+	rec := &recording{Version: FullVersion}
+	if path == "NONE" {
+		return rec, nil
+	}
+	data, err := os.ReadFile(configFilePath(path))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+	rec.Config = string(data)
+	return rec, nil
+}
+
+// record appends ev to the events of the recording
+func (r *recording) record(ev internal.RecordedEvent) {
+	r.Events = append(r.Events, ev)
+}
+
+// recordHistory keeps the history scores of the values found in the input,
+// those that can change the hints
+func (r *recording) recordHistory(scores map[string]int) {
+	for text, score := range scores {
+		if strings.Contains(r.Input, text) {
+			if r.History == nil {
+				r.History = make(map[string]int)
+			}
+			r.History[text] = score
+		}
+	}
+}
+
+// selectionTexts returns the texts of selected
+func selectionTexts(selected []internal.ChosenMatch) []string {
+	texts := make([]string, 0, len(selected))
+	for _, item := range selected {
+		texts = append(texts, item.Text)
+	}
+	return texts
+}
+
+// checkReplay tells whether selected is what was recorded
+func (r *recording) checkReplay(selected []internal.ChosenMatch) error {
+	if texts := selectionTexts(selected); !slices.Equal(texts, r.Selection) {
+		return fmt.Errorf("replayed selection %q differs from the recorded %q", texts, r.Selection)
+	}
+	return nil
+}
+
+// loadRecording reads the recording saved at path
+func loadRecording(path string) (*recording, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading recording: %w", err)
+	}
+
+	var rec recording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("decoding recording: %w", err)
+	}
+	if rec.Version != FullVersion {
+		slog.Warn("Replaying a recording of another version", "recorded", rec.Version, "version", FullVersion)
+	}
+	return &rec, nil
+}
+
+// save writes the recording to path, its texts redacted by redact
+func (r *recording) save(path string, redact func(string) string) error {
+	redacted := *r
+	redacted.Input = redact(r.Input)
+	redacted.Config = redact(r.Config)
+	redacted.Flags = make([]sessionFlag, len(r.Flags))
+	for i, flag := range r.Flags {
+		redacted.Flags[i] = sessionFlag{Name: flag.Name, Value: redact(flag.Value)}
+	}
+	redacted.Selection = make([]string, len(r.Selection))
+	for i, text := range r.Selection {
+		redacted.Selection[i] = redact(text)
+	}
+	if r.History != nil {
+		redacted.History = make(map[string]int, len(r.History))
+		for text, score := range r.History {
+			redacted.History[redact(text)] = score
+		}
+	}
+
+	data, err := json.MarshalIndent(&redacted, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding recording: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("writing recording: %w", err)
+	}
+	return nil
+}
+
+// minMaskedSecret is the length of the secrets of the input masked
+// wherever they show up, shorter ones would mask unrelated text
+const minMaskedSecret = 4
+
+// newRedactor returns the redaction of the texts of a recording of input.
+// The likely credentials and private keys are masked, at their length so
+// that the offsets of the input hold, also where they show up without
+// their context such as in the selection. Then the user@host of prompts and
+// the home directory are replaced
+func newRedactor(input string) func(string) string {
+	var secrets []string
+	for _, secret := range internal.Secrets(input) {
+		if len(secret) >= minMaskedSecret {
+			secrets = append(secrets, secret, maskText(secret))
+		}
+	}
+	masker := strings.NewReplacer(secrets...)
+
+	var pairs []string
+	if current, err := user.Current(); err == nil {
+		if host, err := os.Hostname(); err == nil {
+			pairs = append(pairs, current.Username+"@"+host, "user@host")
+		}
+	}
+	if home, err := os.UserHomeDir(); err == nil && home != "/" {
+		pairs = append(pairs, home, "~")
+	}
+	replacer := strings.NewReplacer(pairs...)
+
+	return func(text string) string {
+		return replacer.Replace(masker.Replace(internal.MaskSecrets(text)))
+	}
+}
+
+// maskText replaces the bytes of text but its line breaks with asterisks
+func maskText(text string) string {
+	masked := []byte(text)
+	for i, b := range masked {
+		if b != '\n' {
+			masked[i] = '*'
+		}
+	}
+	return string(masked)
+}
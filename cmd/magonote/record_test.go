@@ -0,0 +1,99 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Hanaasagi/magonote/internal"
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestRecordingRoundTrip(t *testing.T) {
+	rec := &recording{
+		session: session{
+			Input: "me@box:~$ ls /home/me/src\n10.0.0.1",
+			Flags: []sessionFlag{{Name: "regexp", Value: "/home/me/[a-z]+"}},
+		},
+		Version:   FullVersion,
+		Config:    "[core]\nalphabet = \"dvorak\"\n",
+		History:   map[string]int{"/home/me/src": 2},
+		Events:    []internal.RecordedEvent{{Width: 80, Height: 24}, {Key: tcell.KeyRune, Rune: "a"}},
+		Selection: []string{"/home/me/src"},
+	}
+
+	path := filepath.Join(t.TempDir(), "recording.json")
+	if err := rec.save(path, strings.NewReplacer("me@box", "user@host", "/home/me", "~").Replace); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "/home/me") || strings.Contains(string(data), "me@box") {
+		t.Errorf("Expected the recording to be redacted, got:\n%s", data)
+	}
+
+	loaded, err := loadRecording(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "user@host:~$ ls ~/src\n10.0.0.1"; loaded.Input != expected {
+		t.Errorf("Expected input %q, got %q", expected, loaded.Input)
+	}
+	if !slices.Equal(loaded.Flags, []sessionFlag{{Name: "regexp", Value: "~/[a-z]+"}}) {
+		t.Errorf("Expected redacted flags, got %v", loaded.Flags)
+	}
+	if loaded.Config != rec.Config || loaded.History["~/src"] != 2 || !slices.Equal(loaded.Events, rec.Events) {
+		t.Errorf("Expected config, history and events to round trip, got %+v", loaded)
+	}
+
+	if err := loaded.checkReplay([]internal.ChosenMatch{{Text: "~/src"}}); err != nil {
+		t.Errorf("Expected the recorded selection to match, got %v", err)
+	}
+	if err := loaded.checkReplay([]internal.ChosenMatch{{Text: "10.0.0.1"}}); err == nil {
+		t.Error("Expected an error for a different selection")
+	}
+}
+
+func TestRecordHistory(t *testing.T) {
+	rec := &recording{session: session{Input: "ping 10.0.0.1"}}
+	rec.recordHistory(map[string]int{"10.0.0.1": 3, "/elsewhere": 1})
+	if len(rec.History) != 1 || rec.History["10.0.0.1"] != 3 {
+		t.Errorf("Expected the history of the input alone, got %v", rec.History)
+	}
+}
+
+func TestRecordingMasksSecrets(t *testing.T) {
+	rec := &recording{
+		session:   session{Input: "mysql --password=hunter2 db\n10.0.0.1"},
+		Version:   FullVersion,
+		History:   map[string]int{"hunter2": 1},
+		Selection: []string{"hunter2"},
+	}
+
+	path := filepath.Join(t.TempDir(), "recording.json")
+	if err := rec.save(path, newRedactor(rec.Input)); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "hunter2") {
+		t.Errorf("Expected the password to be masked, got:\n%s", data)
+	}
+
+	loaded, err := loadRecording(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "mysql --password=******* db\n10.0.0.1"; loaded.Input != expected {
+		t.Errorf("Expected input %q, got %q", expected, loaded.Input)
+	}
+	if !slices.Equal(loaded.Selection, []string{"*******"}) || loaded.History["*******"] != 1 {
+		t.Errorf("Expected the password masked in the selection and history, got %q and %v", loaded.Selection, loaded.History)
+	}
+}
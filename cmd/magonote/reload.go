@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/Hanaasagi/magonote/internal"
+)
+
+// configSource loads the config file of the invocation again, with its
+// profile and flags applied, for --watch-config
+type configSource struct {
+	path string
+	load func() (*Config, error)
+}
+
+// watchConfig calls onChange whenever the config file at path is written,
+// until the returned stop is called. The directory is watched rather than the
+// file, so that editors replacing the file on save are followed
+func watchConfig(path string, onChange func()) (stop func(), err error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("creating config watcher: %w", err)
+	}
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close() // nolint: errcheck
+		return nil, fmt.Errorf("watching config directory: %w", err)
+	}
+
+	path = filepath.Clean(path)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) == path && event.Has(fsnotify.Write|fsnotify.Create) {
+					onChange()
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				slog.Warn("Failed to watch the config file", "file", path, "error", err)
+			}
+		}
+	}()
+
+	return func() {
+		watcher.Close() // nolint: errcheck
+		<-done
+	}, nil
+}
+
+// viewColors returns the colors of the views for the colors of a config whose
+// theme is applied and auto colors resolved
+func viewColors(c *ColorConfig) internal.ViewColors {
+	return internal.NewViewColors(
+		internal.GetColor(c.Select.Foreground),
+		internal.GetColor(c.Select.Background),
+		internal.GetColor(c.Multi.Foreground),
+		internal.GetColor(c.Multi.Background),
+		internal.GetColor(c.Match.Foreground),
+		internal.GetColor(c.Match.Background),
+		internal.GetColor(c.Hint.Foreground),
+		internal.GetColor(c.Hint.Background),
+		patternColors(c.Patterns),
+	).WithStatus(internal.GetColor(c.Status.Foreground), internal.GetColor(c.Status.Background)).
+		WithColumns(columnColors(c.Columns))
+}
+
+// patternColors converts the configured colors per pattern for the views
+func patternColors(groups map[string]ColorGroup) map[string]internal.PatternColor {
+	colors := make(map[string]internal.PatternColor, len(groups))
+	for name, group := range groups {
+		var pc internal.PatternColor
+		if group.Foreground != "" {
+			pc.Foreground = internal.GetColor(group.Foreground)
+		}
+		if group.Background != "" {
+			pc.Background = internal.GetColor(group.Background)
+		}
+		colors[name] = pc
+	}
+	return colors
+}
+
+// columnColors converts the configured colors of table columns for the views
+func columnColors(names []string) []internal.Color {
+	colors := make([]internal.Color, len(names))
+	for i, name := range names {
+		colors[i] = internal.GetColor(name)
+	}
+	return colors
+}
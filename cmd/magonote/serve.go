@@ -0,0 +1,364 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/adrg/xdg"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/Hanaasagi/magonote/internal/logger"
+)
+
+// Protocol of magonote serve: a client connects to the socket and sends a
+// serveRequest as a line of JSON, along with its terminal as SCM_RIGHTS
+// ancillary data. The server runs magonote as if the client were invoked
+// with the arguments of the request, showing the view on the terminal of the
+// client, and answers with serveMessage lines, the last one being Done
+
+// serveRequest is an invocation of a client of magonote serve
+type serveRequest struct {
+	Args  []string `json:"args"`
+	Text  string   `json:"text"` // Input, unless read from --input-file
+	Dir   string   `json:"dir"`  // Working directory the arguments are relative to
+	Term  string   `json:"term"` // $TERM of the client
+	RunID string   `json:"run_id"`
+}
+
+// serveMessage is the output of the invocation of a client, or its end
+type serveMessage struct {
+	Stdout string `json:"stdout,omitempty"`
+	Stderr string `json:"stderr,omitempty"`
+	Done   bool   `json:"done,omitempty"`
+	Error  string `json:"error,omitempty"` // Error of the invocation, when Done
+}
+
+// clientTerminal is the terminal of a client of magonote serve
+type clientTerminal struct {
+	device string // Empty when the client has no terminal
+	term   string
+}
+
+type clientTerminalKey struct{}
+
+// clientTerminalFrom returns the terminal of the client ctx runs the
+// invocation of, nil outside of magonote serve
+func clientTerminalFrom(ctx context.Context) *clientTerminal {
+	if ctx == nil {
+		return nil
+	}
+	terminal, _ := ctx.Value(clientTerminalKey{}).(*clientTerminal)
+	return terminal
+}
+
+// defaultSocketPath is the socket of magonote serve in the XDG runtime
+// directory
+func defaultSocketPath() string {
+	return filepath.Join(xdg.RuntimeDir, appName, "magonote.sock")
+}
+
+// newServeCmd creates the serve command
+func newServeCmd() *cobra.Command {
+	var socket string
+
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run magonote for the clients of a UNIX socket, keeping compiled patterns warm between runs",
+		Long: "Run magonote for the clients of a UNIX socket, keeping compiled patterns warm between runs.\n" +
+			"Clients are magonote invocations given --socket, such as those of the tmux plugin with @magonote-socket set",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _args []string) error {
+			// Unlike a single run, the server must collect its garbage
+			debug.SetGCPercent(100)
+
+			listener, err := listenSocket(socket)
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			slog.Info("Serving", "socket", socket)
+			return serve(ctx, listener)
+		},
+	}
+	serveCmd.Flags().StringVar(&socket, "socket", defaultSocketPath(), "Socket to listen on")
+
+	return serveCmd
+}
+
+// listenSocket listens on the UNIX socket at path, replacing the socket of
+// a server that is gone
+func listenSocket(path string) (*net.UnixListener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("creating socket directory: %w", err)
+	}
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close() // nolint: errcheck
+		return nil, fmt.Errorf("magonote is already serving on %s", path)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("removing stale socket: %w", err)
+	}
+
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		return nil, fmt.Errorf("listening on %s: %w", path, err)
+	}
+	return listener, nil
+}
+
+// serve handles the clients of listener until ctx is done
+func serve(ctx context.Context, listener *net.UnixListener) error {
+	go func() {
+		<-ctx.Done()
+		listener.Close() // nolint: errcheck
+	}()
+
+	// Invocations change the working directory and show a view, they are
+	// run one at a time
+	var running sync.Mutex
+	for {
+		conn, err := listener.AcceptUnix()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("accepting client: %w", err)
+		}
+		go func() {
+			defer conn.Close() // nolint: errcheck
+			running.Lock()
+			defer running.Unlock()
+			handleClient(conn)
+		}()
+	}
+}
+
+// handleClient runs the invocation of a client and sends its output
+func handleClient(conn *net.UnixConn) {
+	// Connections without a request include those checking that the
+	// server is running
+	req, tty, err := readRequest(conn)
+	if err != nil {
+		slog.Debug("Ignoring client", "error", err)
+		return
+	}
+	if tty != nil {
+		defer tty.Close() // nolint: errcheck
+	}
+
+	var mu sync.Mutex
+	encoder := json.NewEncoder(conn)
+	send := func(msg serveMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return encoder.Encode(msg)
+	}
+
+	err = func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("Recovered from a panic of a client invocation", "panic", r, "stack", string(debug.Stack()))
+				err = fmt.Errorf("magonote serve panicked: %v", r)
+			}
+		}()
+
+		stdout := messageWriter(func(p []byte) error { return send(serveMessage{Stdout: string(p)}) })
+		stderr := messageWriter(func(p []byte) error { return send(serveMessage{Stderr: string(p)}) })
+		return runRequest(req, tty, stdout, stderr)
+	}()
+
+	done := serveMessage{Done: true}
+	if err != nil {
+		done.Error = err.Error()
+	}
+	if err := send(done); err != nil {
+		slog.Warn("Failed to answer client", "error", err)
+	}
+}
+
+// messageWriter sends what is written to it as messages to a client
+type messageWriter func(p []byte) error
+
+func (w messageWriter) Write(p []byte) (int, error) {
+	if err := w(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// readRequest reads the request of a client, and its terminal if it sent
+// one
+func readRequest(conn *net.UnixConn) (serveRequest, *os.File, error) {
+	var req serveRequest
+
+	buf := make([]byte, defaultSize)
+	oob := make([]byte, syscall.CmsgSpace(4))
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		return req, nil, fmt.Errorf("reading request: %w", err)
+	}
+
+	var tty *os.File
+	messages, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil {
+		return req, nil, fmt.Errorf("reading terminal of request: %w", err)
+	}
+	for _, message := range messages {
+		fds, err := syscall.ParseUnixRights(&message)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			if tty == nil {
+				tty = os.NewFile(uintptr(fd), "tty")
+			} else {
+				syscall.Close(fd) // nolint: errcheck
+			}
+		}
+	}
+
+	decoder := json.NewDecoder(io.MultiReader(bytes.NewReader(buf[:n]), conn))
+	if err := decoder.Decode(&req); err != nil {
+		if tty != nil {
+			tty.Close() // nolint: errcheck
+		}
+		return req, nil, fmt.Errorf("decoding request: %w", err)
+	}
+	return req, tty, nil
+}
+
+// runRequest runs the invocation of a client as the root command would,
+// with the streams and terminal of the client
+func runRequest(req serveRequest, tty *os.File, stdout, stderr io.Writer) error {
+	if err := os.Chdir(req.Dir); err != nil {
+		return fmt.Errorf("changing to the directory of the client: %w", err)
+	}
+	logger.StartRun(req.RunID)
+
+	terminal := &clientTerminal{term: req.Term}
+	if tty != nil {
+		terminal.device = fmt.Sprintf("/dev/fd/%d", tty.Fd())
+	}
+
+	cmd := newRootCmd()
+	// Without arguments cobra would parse those of the server
+	cmd.SetArgs(append([]string{}, req.Args...))
+	cmd.SetIn(strings.NewReader(req.Text))
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	// The client reports the error
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	return cmd.ExecuteContext(context.WithValue(context.Background(), clientTerminalKey{}, terminal))
+}
+
+// runRemote runs the invocation given the arguments on the magonote serve
+// listening on args.socket. It reports whether a server handled it, the
+// invocation is run directly otherwise
+func runRemote(args *Arguments, arguments []string) (handled bool, err error) {
+	conn, err := net.DialUnix("unix", nil, &net.UnixAddr{Name: args.socket, Net: "unix"})
+	if err != nil {
+		slog.Debug("No magonote server, running directly", "socket", args.socket, "error", err)
+		return false, nil
+	}
+	defer conn.Close() // nolint: errcheck
+
+	req := serveRequest{
+		Args:  forwardedArgs(arguments),
+		Term:  os.Getenv("TERM"),
+		RunID: logger.RunID(),
+	}
+	if req.Dir, err = os.Getwd(); err != nil {
+		return true, fmt.Errorf("getting working directory: %w", err)
+	}
+	if args.inputFile == "" {
+		text, err := io.ReadAll(args.streams.stdin)
+		if err != nil {
+			return true, fmt.Errorf("reading input: %w", err)
+		}
+		req.Text = string(text)
+	}
+	if err := sendRequest(conn, req); err != nil {
+		return true, fmt.Errorf("sending request to %s: %w", args.socket, err)
+	}
+
+	decoder := json.NewDecoder(conn)
+	for {
+		var msg serveMessage
+		if err := decoder.Decode(&msg); err != nil {
+			return true, fmt.Errorf("reading answer of %s: %w", args.socket, err)
+		}
+		if _, err := io.WriteString(args.streams.stdout, msg.Stdout); err != nil {
+			return true, err
+		}
+		if _, err := io.WriteString(args.streams.stderr, msg.Stderr); err != nil {
+			return true, err
+		}
+		if msg.Done {
+			if msg.Error != "" {
+				return true, errors.New(msg.Error)
+			}
+			return true, nil
+		}
+	}
+}
+
+// sendRequest sends req to the server along with the terminal of the
+// process, if it has one
+func sendRequest(conn *net.UnixConn, req serveRequest) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("encoding request: %w", err)
+	}
+	data = append(data, '\n')
+
+	// The terminal device itself rather than /dev/tty, which the server
+	// would open as its own controlling terminal
+	var rights []byte
+	for _, file := range []*os.File{os.Stderr, os.Stdout, os.Stdin} {
+		if term.IsTerminal(int(file.Fd())) {
+			rights = syscall.UnixRights(int(file.Fd()))
+			break
+		}
+	}
+	if rights == nil {
+		slog.Debug("No terminal to send to the server")
+	}
+
+	n, _, err := conn.WriteMsgUnix(data, rights, nil)
+	if err == nil && n < len(data) {
+		_, err = conn.Write(data[n:])
+	}
+	return err
+}
+
+// forwardedArgs returns the arguments of the invocation without --socket
+func forwardedArgs(arguments []string) []string {
+	var forwarded []string
+	for i := 0; i < len(arguments); i++ {
+		switch {
+		case arguments[i] == "--socket":
+			i++
+		case strings.HasPrefix(arguments[i], "--socket="):
+		default:
+			forwarded = append(forwarded, arguments[i])
+		}
+	}
+	return forwarded
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestServe(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "magonote.sock")
+	listener, err := listenSocket(socket)
+	if err != nil {
+		t.Fatalf("listenSocket() error = %v", err)
+	}
+	if _, err := listenSocket(socket); err == nil {
+		t.Error("Expected an error for a socket already served")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error)
+	go func() { served <- serve(ctx, listener) }()
+	defer func() {
+		cancel()
+		if err := <-served; err != nil {
+			t.Errorf("serve() error = %v", err)
+		}
+	}()
+
+	var stdout, stderr bytes.Buffer
+	args := &Arguments{
+		socket:  socket,
+		streams: streams{stdin: strings.NewReader("a.log"), stdout: &stdout, stderr: &stderr},
+	}
+	handled, err := runRemote(args, []string{"--socket", socket, "--config", "NONE", "--scope", "everything"})
+	if !handled {
+		t.Fatal("Expected the server to handle the invocation")
+	}
+	if err == nil || !strings.Contains(err.Error(), `unknown scope "everything"`) {
+		t.Errorf("Expected the error of the invocation, got %v", err)
+	}
+}
+
+func TestRunRemoteWithoutServer(t *testing.T) {
+	args := &Arguments{socket: filepath.Join(t.TempDir(), "missing.sock")}
+	if handled, err := runRemote(args, nil); handled || err != nil {
+		t.Errorf("Expected to run directly, got handled %v and error %v", handled, err)
+	}
+}
+
+func TestForwardedArgs(t *testing.T) {
+	got := forwardedArgs([]string{"-m", "--socket", "/tmp/s", "--socket=/tmp/s", "-t", "out"})
+	want := []string{"-m", "-t", "out"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/spf13/pflag"
+)
+
+// lastSessionFile holds the input and the flags of the last picker, which
+// `magonote --last` reopens
+var lastSessionFile = filepath.Join(appDir, "last-session.json")
+
+// unsavedFlags aren't part of a session, they tell where the input comes
+// from and where the selection goes rather than how it is picked
+var unsavedFlags = []string{
+	"last", "input-file", "target", "also", "format", "socket",
+	"version", "require-version", "record", "replay", "remote-host",
+}
+
+// session is a picker as it was opened: its input and its flags
+type session struct {
+	Input string        `json:"input"`
+	Flags []sessionFlag `json:"flags"`
+}
+
+// sessionFlag is a flag given to a picker, slice flags are saved once per
+// value
+type sessionFlag struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// sessionFlags returns the flags given on the command line that shape the
+// picker
+func sessionFlags(flags *pflag.FlagSet) []sessionFlag {
+	var saved []sessionFlag
+	flags.Visit(func(flag *pflag.Flag) {
+		if slices.Contains(unsavedFlags, flag.Name) {
+			return
+		}
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			for _, value := range slice.GetSlice() {
+				saved = append(saved, sessionFlag{Name: flag.Name, Value: value})
+			}
+			return
+		}
+		saved = append(saved, sessionFlag{Name: flag.Name, Value: flag.Value.String()})
+	})
+	return saved
+}
+
+// restore sets the flags of the session on flags, but those given on the
+// command line, which take precedence
+func (s *session) restore(flags *pflag.FlagSet) error {
+	given := make(map[string]bool)
+	flags.Visit(func(flag *pflag.Flag) {
+		given[flag.Name] = true
+	})
+
+	for _, flag := range s.Flags {
+		if given[flag.Name] {
+			continue
+		}
+		if err := flags.Set(flag.Name, flag.Value); err != nil {
+			return fmt.Errorf("restoring --%s: %w", flag.Name, err)
+		}
+	}
+	return nil
+}
+
+// loadSession reads the session saved at path
+func loadSession(path string) (*session, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no previous picker to reopen")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading session: %w", err)
+	}
+
+	var s session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("decoding session: %w", err)
+	}
+	return &s, nil
+}
+
+// saveSession writes s to path, readable by the user alone since the input
+// may hold secrets. The file is replaced atomically
+func saveSession(path string, s *session) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("encoding session: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("creating session file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // nolint: errcheck
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close() // nolint: errcheck
+		return fmt.Errorf("writing session: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing session: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("replacing session file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestSessionRestore(t *testing.T) {
+	cmd := newRootCmd()
+	if err := cmd.ParseFlags([]string{"-uu", "--multi", "-x", "a+", "-x", "b+", "--mode", "words", "-t", "/tmp/out", "-i", "input.txt"}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "last-session.json")
+	if err := saveSession(path, &session{Input: "some input", Flags: sessionFlags(cmd.Flags())}); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected a session file readable by the user alone, got %v, %v", info, err)
+	}
+
+	saved, err := loadSession(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Input != "some input" {
+		t.Errorf("Expected input %q, got %q", "some input", saved.Input)
+	}
+
+	cmd = newRootCmd()
+	if err := cmd.ParseFlags([]string{"--last", "--mode", "lines"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := saved.restore(cmd.Flags()); err != nil {
+		t.Fatal(err)
+	}
+
+	flags := cmd.Flags()
+	if unique, _ := flags.GetCount("unique"); unique != 2 {
+		t.Errorf("Expected unique 2, got %d", unique)
+	}
+	if multi, _ := flags.GetBool("multi"); !multi {
+		t.Error("Expected multi to be restored")
+	}
+	if patterns, _ := flags.GetStringArray("regexp"); !slices.Equal(patterns, []string{"a+", "b+"}) {
+		t.Errorf("Expected patterns [a+ b+], got %q", patterns)
+	}
+	if mode, _ := flags.GetString("mode"); mode != "lines" {
+		t.Errorf("Expected the given mode lines, got %q", mode)
+	}
+	for _, name := range []string{"target", "input-file"} {
+		if flags.Changed(name) {
+			t.Errorf("Expected --%s not to be restored", name)
+		}
+	}
+}
+
+func TestLoadSessionMissing(t *testing.T) {
+	if _, err := loadSession(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected an error without a previous session")
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/Hanaasagi/magonote/internal"
+	"github.com/Hanaasagi/magonote/internal/logger"
+)
+
+// printStats writes the statistics of the matches and the timings of run,
+// for --stats
+func printStats(w io.Writer, stats internal.Stats, run *logger.Run) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Lines\t%d\n", stats.Lines)
+	fmt.Fprintf(tw, "Hints\t%d\n", stats.Hints)
+	fmt.Fprintf(tw, "Tables\t%d, %d cells\n", stats.Tables, stats.TableCells)
+	if stats.Conflicts > 0 {
+		fmt.Fprintf(tw, "Conflicts\t%d\n", stats.Conflicts)
+	}
+	if stats.Truncated {
+		fmt.Fprintln(tw, "Truncated\tyes")
+	}
+
+	// Patterns with the most matches first
+	patterns := slices.SortedFunc(maps.Keys(stats.Patterns), func(a, b string) int {
+		return cmp.Or(cmp.Compare(stats.Patterns[b], stats.Patterns[a]), cmp.Compare(a, b))
+	})
+	fmt.Fprintln(tw, "\nPATTERN\tMATCHES")
+	total := 0
+	for _, pattern := range patterns {
+		fmt.Fprintf(tw, "%s\t%d\n", pattern, stats.Patterns[pattern])
+		total += stats.Patterns[pattern]
+	}
+	fmt.Fprintf(tw, "total\t%d\n\n", total)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	return printRun(w, run)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Hanaasagi/magonote/internal"
+	"github.com/Hanaasagi/magonote/internal/logger"
+)
+
+func TestPrintStats(t *testing.T) {
+	stats := internal.Stats{
+		Lines:      40,
+		Patterns:   map[string]int{"path": 3, "url": 5, "sha": 3},
+		Tables:     1,
+		TableCells: 6,
+		Hints:      11,
+		Conflicts:  2,
+	}
+	run := &logger.Run{
+		ID:      "1a2b3c4d",
+		Started: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Spans:   []logger.Span{{Name: "capture", Duration: 2 * time.Millisecond}},
+	}
+
+	var buf bytes.Buffer
+	if err := printStats(&buf, stats, run); err != nil {
+		t.Fatalf("printStats() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	expected := []string{
+		"Lines 40",
+		"Hints 11",
+		"Tables 1, 6 cells",
+		"Conflicts 2",
+		"",
+		"PATTERN MATCHES",
+		"url 5",
+		"path 3",
+		"sha 3",
+		"total 11",
+		"",
+		"Run 1a2b3c4d started at 2025-01-02 03:04:05",
+		"",
+		"STEP START DURATION",
+		"capture 0.0ms 2.0ms",
+		"total 2.0ms",
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got:\n%s", len(expected), buf.String())
+	}
+	for i, line := range lines {
+		if got := strings.Join(strings.Fields(line), " "); got != expected[i] {
+			t.Errorf("Expected line %d to be %q, got %q", i, expected[i], got)
+		}
+	}
+}
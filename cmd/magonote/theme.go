@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Hanaasagi/magonote/internal"
+)
+
+const (
+	// defaultTheme is the theme of the default configuration
+	defaultTheme = "default"
+
+	// autoColor is a foreground picked among black and white for
+	// readability on its background
+	autoColor = "auto"
+
+	// backgroundTimeout bounds the wait for the terminal to tell its
+	// background color
+	backgroundTimeout = 100 * time.Millisecond
+)
+
+// themes are the color presets of colors.theme and --theme
+var themes = map[string]ColorConfig{
+	defaultTheme: {
+		Match:  ColorGroup{Foreground: "green", Background: "black"},
+		Hint:   ColorGroup{Foreground: "yellow", Background: "black"},
+		Multi:  ColorGroup{Foreground: "yellow", Background: "black"},
+		Select: ColorGroup{Foreground: "blue", Background: "black"},
+		Status: ColorGroup{Foreground: "black", Background: "white"},
+	},
+	"solarized-dark": {
+		Match:  ColorGroup{Foreground: "#859900", Background: "#002b36"},
+		Hint:   ColorGroup{Foreground: "#b58900", Background: "#073642"},
+		Multi:  ColorGroup{Foreground: "#d33682", Background: "#002b36"},
+		Select: ColorGroup{Foreground: "#268bd2", Background: "#073642"},
+		Status: ColorGroup{Foreground: "#93a1a1", Background: "#073642"},
+	},
+	"gruvbox": {
+		Match:  ColorGroup{Foreground: "#b8bb26", Background: "#282828"},
+		Hint:   ColorGroup{Foreground: "#fabd2f", Background: "#3c3836"},
+		Multi:  ColorGroup{Foreground: "#d3869b", Background: "#282828"},
+		Select: ColorGroup{Foreground: "#83a598", Background: "#3c3836"},
+		Status: ColorGroup{Foreground: "#ebdbb2", Background: "#504945"},
+	},
+	"high-contrast": {
+		Match:  ColorGroup{Foreground: "#ffffff", Background: "#000000"},
+		Hint:   ColorGroup{Foreground: "#000000", Background: "#ffff00"},
+		Multi:  ColorGroup{Foreground: "#000000", Background: "#00ffff"},
+		Select: ColorGroup{Foreground: "#000000", Background: "#ffffff"},
+		Status: ColorGroup{Foreground: "#ffffff", Background: "#0000ff"},
+	},
+}
+
+// themeNames returns the names of the themes, sorted
+func themeNames() []string {
+	return slices.Sorted(maps.Keys(themes))
+}
+
+// colorKeys returns the color settings of c by key, such as
+// "match.foreground"
+func (c *ColorConfig) colorKeys() map[string]*string {
+	return map[string]*string{
+		"match.foreground":  &c.Match.Foreground,
+		"match.background":  &c.Match.Background,
+		"hint.foreground":   &c.Hint.Foreground,
+		"hint.background":   &c.Hint.Background,
+		"multi.foreground":  &c.Multi.Foreground,
+		"multi.background":  &c.Multi.Background,
+		"select.foreground": &c.Select.Foreground,
+		"select.background": &c.Select.Background,
+		"status.foreground": &c.Status.Foreground,
+		"status.background": &c.Status.Background,
+	}
+}
+
+// setExplicit marks the color of key as set by the file or flags
+func (c *ColorConfig) setExplicit(key string) {
+	if c.explicit == nil {
+		c.explicit = map[string]bool{}
+	}
+	c.explicit[key] = true
+}
+
+// applyTheme sets the colors of the theme, except those configured in the
+// file or given as flags
+func (c *ColorConfig) applyTheme() error {
+	if c.Theme == "" {
+		return nil
+	}
+	theme, ok := themes[c.Theme]
+	if !ok {
+		return fmt.Errorf("unknown theme %q, expected one of %s", c.Theme, strings.Join(themeNames(), ", "))
+	}
+
+	themeColors := theme.colorKeys()
+	for key, value := range c.colorKeys() {
+		if !c.explicit[key] {
+			*value = *themeColors[key]
+		}
+	}
+	return nil
+}
+
+// resolveAutoColors replaces the "auto" foregrounds by black or white,
+// whichever is more readable on the background of their group. A "default"
+// background is the terminal's, from terminalBackground, assumed dark when
+// unknown
+func (c *ColorConfig) resolveAutoColors(terminalBackground func() (internal.RGB, bool)) {
+	for _, group := range []*ColorGroup{&c.Match, &c.Hint, &c.Multi, &c.Select, &c.Status} {
+		if group.Foreground != autoColor {
+			continue
+		}
+
+		bg, ok := internal.ColorRGB(internal.GetColor(group.Background))
+		if !ok {
+			bg, ok = terminalBackground()
+		}
+		if !ok {
+			slog.Debug("Unknown terminal background, assuming a dark one")
+			group.Foreground = "white"
+			continue
+		}
+		group.Foreground = internal.ContrastingColor(bg)
+	}
+}
+
+// warnUnreadableColors warns about hint colors too close to the colors they
+// are drawn on
+func (c *ColorConfig) warnUnreadableColors() {
+	type pair struct {
+		name   string
+		fg, bg string
+	}
+	pairs := []pair{
+		{"hint on hint background", c.Hint.Foreground, c.Hint.Background},
+		{"hint on match background", c.Hint.Foreground, c.Match.Background},
+	}
+	for i, column := range c.Columns {
+		pairs = append(pairs, pair{fmt.Sprintf("column %d on hint background", i+1), column, c.Hint.Background})
+	}
+
+	for _, pair := range pairs {
+		fg, fgOk := internal.ColorRGB(internal.GetColor(pair.fg))
+		bg, bgOk := internal.ColorRGB(internal.GetColor(pair.bg))
+		if !fgOk || !bgOk {
+			continue
+		}
+		if ratio := internal.ContrastRatio(fg, bg); ratio < internal.MinContrastRatio {
+			slog.Warn("Colors are hard to read", "colors", pair.name, "foreground", pair.fg, "background", pair.bg, "contrast_ratio", ratio)
+		}
+	}
+}
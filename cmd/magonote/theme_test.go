@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Hanaasagi/magonote/internal"
+)
+
+func TestApplyTheme(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	content := "[colors]\ntheme = \"gruvbox\"\n\n[colors.match]\nforeground = \"red\"\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadConfigFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newRootCmd()
+	if err := cmd.ParseFlags([]string{"--hint-fg-color", "color208"}); err != nil {
+		t.Fatal(err)
+	}
+	args := &Arguments{}
+	args.hintForegroundColor, _ = cmd.Flags().GetString("hint-fg-color")
+	applyCliOverrides(cmd, config, args)
+
+	if err := config.Colors.applyTheme(); err != nil {
+		t.Fatal(err)
+	}
+
+	gruvbox := themes["gruvbox"]
+	tests := []struct {
+		name     string
+		got      string
+		expected string
+	}{
+		{"configured color", config.Colors.Match.Foreground, "red"},
+		{"flag color", config.Colors.Hint.Foreground, "color208"},
+		{"theme color", config.Colors.Match.Background, gruvbox.Match.Background},
+		{"theme color", config.Colors.Select.Foreground, gruvbox.Select.Foreground},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.expected {
+			t.Errorf("%s: Expected %q, got %q", tt.name, tt.expected, tt.got)
+		}
+	}
+}
+
+func TestApplyUnknownTheme(t *testing.T) {
+	config := NewDefaultConfig()
+	config.Colors.Theme = "nope"
+	if err := config.Colors.applyTheme(); err == nil {
+		t.Error("Expected an error for an unknown theme")
+	}
+}
+
+func TestThemeColors(t *testing.T) {
+	for name, theme := range themes {
+		for key, value := range theme.colorKeys() {
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						t.Errorf("Theme %s has an invalid %s: %v", name, key, r)
+					}
+				}()
+				internal.GetColor(*value)
+			}()
+		}
+	}
+}
+
+func TestResolveAutoColors(t *testing.T) {
+	light := func() (internal.RGB, bool) { return internal.RGB{R: 0xfd, G: 0xf6, B: 0xe3}, true }
+	unknown := func() (internal.RGB, bool) { return internal.RGB{}, false }
+
+	tests := []struct {
+		name       string
+		background string
+		terminal   func() (internal.RGB, bool)
+		expected   string
+	}{
+		{"dark background", "#282828", light, "white"},
+		{"light background", "#ffff00", unknown, "black"},
+		{"light terminal", "default", light, "black"},
+		{"unknown terminal", "default", unknown, "white"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			colors := NewDefaultConfig().Colors
+			colors.Hint = ColorGroup{Foreground: autoColor, Background: tt.background}
+			colors.resolveAutoColors(tt.terminal)
+			if colors.Hint.Foreground != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, colors.Hint.Foreground)
+			}
+			if colors.Match.Foreground != "green" {
+				t.Errorf("Expected other colors to be kept, got %s", colors.Match.Foreground)
+			}
+		})
+	}
+}
+
+func TestWarnUnreadableColumnColors(t *testing.T) {
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	colors := NewDefaultConfig().Colors
+	if len(colors.Columns) != 0 {
+		t.Errorf("Expected no column colors by default, got %q", colors.Columns)
+	}
+
+	colors.Hint = ColorGroup{Foreground: "black", Background: "yellow"}
+	colors.Columns = []string{"yellow", "blue"}
+	colors.warnUnreadableColors()
+	if !strings.Contains(logs.String(), `colors="column 1 on hint background"`) {
+		t.Errorf("Expected a warning about the yellow column, got %q", logs.String())
+	}
+	if strings.Contains(logs.String(), "column 2") {
+		t.Errorf("Expected no warning about the blue column, got %q", logs.String())
+	}
+}
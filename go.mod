@@ -6,11 +6,15 @@ require (
 	github.com/BurntSushi/toml v1.5.0
 	github.com/adrg/xdg v0.5.3
 	github.com/fatih/color v1.18.0
+	github.com/fsnotify/fsnotify v1.10.1
 	github.com/gdamore/tcell/v2 v2.8.1
 	github.com/leaanthony/go-ansi-parser v1.6.1
 	github.com/mattn/go-runewidth v0.0.16
+	github.com/rivo/uniseg v0.4.3
 	github.com/spf13/cobra v1.9.1
+	github.com/spf13/pflag v1.0.6
 	golang.org/x/term v0.28.0
+	golang.org/x/text v0.21.0
 )
 
 require (
@@ -19,10 +23,7 @@ require (
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
-	github.com/rivo/uniseg v0.4.3 // indirect
-	github.com/spf13/pflag v1.0.6 // indirect
 	golang.org/x/sys v0.29.0 // indirect
-	golang.org/x/text v0.21.0 // indirect
 )
 
 replace github.com/leaanthony/go-ansi-parser => ./pkg/textdetection/colordetection/vendor/go-ansi-parser
@@ -2,7 +2,9 @@ package internal
 
 import (
 	"fmt"
+	"slices"
 	"strings"
+	"unicode"
 )
 
 var builtinAlphabets = []struct {
@@ -50,6 +52,45 @@ func NewBuiltinAlphabet(name string) (*Alphabet, error) {
 	return nil, fmt.Errorf("unknown alphabet: %s", name)
 }
 
+// ResolveAlphabet returns the alphabet with the given name, custom alphabets
+// take precedence over the builtin ones
+func ResolveAlphabet(name string, custom map[string]string) (*Alphabet, error) {
+	if letters, ok := custom[name]; ok {
+		if err := ValidateAlphabet(letters); err != nil {
+			return nil, fmt.Errorf("alphabet %s: %w", name, err)
+		}
+		return NewAlphabet(letters), nil
+	}
+	return NewBuiltinAlphabet(name)
+}
+
+// ValidateAlphabet checks that letters can be used as hint characters: at
+// least two distinct, printable, lowercase characters. Uppercase is reserved
+// for selecting a hint in uppercase
+func ValidateAlphabet(letters string) error {
+	seen := make([]rune, 0, len(letters))
+	for _, r := range letters {
+		switch {
+		case r == unicode.ReplacementChar:
+			return fmt.Errorf("invalid UTF-8")
+		case unicode.IsSpace(r) || !unicode.IsPrint(r):
+			return fmt.Errorf("%q is not a printable character", r)
+		case unicode.Is(unicode.Mn, r):
+			return fmt.Errorf("combining character %q", r)
+		case unicode.ToLower(r) != r:
+			return fmt.Errorf("uppercase character %q", r)
+		case slices.Contains(seen, r):
+			return fmt.Errorf("duplicate character %q", r)
+		}
+		seen = append(seen, r)
+	}
+
+	if len(seen) < 2 {
+		return fmt.Errorf("at least 2 characters are required, got %d", len(seen))
+	}
+	return nil
+}
+
 func (a *Alphabet) Hints(matches int) []string {
 	if matches <= 0 {
 		return nil
@@ -59,6 +100,10 @@ func (a *Alphabet) Hints(matches int) []string {
 	if lettersCount == 0 {
 		return nil
 	}
+	// A single letter can't be combined into distinct hints
+	if lettersCount == 1 {
+		return []string{a.letters[0]}
+	}
 
 	// Start with single letters
 	expansion := make([]string, lettersCount)
@@ -66,7 +111,13 @@ func (a *Alphabet) Hints(matches int) []string {
 
 	var expanded []string
 
-	for len(expansion) > 0 && len(expansion)+len(expanded) < matches {
+	for len(expansion)+len(expanded) < matches {
+		// All hints of the current length are prefixes now, continue with
+		// the next length so that every match gets a hint
+		if len(expansion) == 0 {
+			expansion, expanded = expanded, nil
+		}
+
 		// Take the last element from expansion
 		prefix := expansion[len(expansion)-1]
 		expansion = expansion[:len(expansion)-1]
@@ -89,3 +140,13 @@ func (a *Alphabet) Hints(matches int) []string {
 	result := append(expansion, expanded...)
 	return result
 }
+
+// Capacity returns the number of hints of at most length letters the
+// alphabet provides
+func (a *Alphabet) Capacity(length int) int {
+	capacity := 1
+	for range length {
+		capacity *= len(a.letters)
+	}
+	return capacity
+}
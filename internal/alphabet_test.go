@@ -2,6 +2,7 @@ package internal
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -35,8 +36,111 @@ func TestComposedMatchesMultiple(t *testing.T) {
 func TestComposedMatchesMax(t *testing.T) {
 	alphabet := NewAlphabet("ab")
 	got := alphabet.Hints(8)
-	want := []string{"aa", "ab", "ba", "bb"}
+	want := []string{"aaa", "aab", "aba", "abb", "baa", "bab", "bba", "bbb"}
 	if !reflect.DeepEqual(got, want) {
 		t.Errorf("ComposedMatchesMax = %v; want %v", got, want)
 	}
 }
+
+func TestComposedMatchesThreeLevels(t *testing.T) {
+	alphabet := NewAlphabet("abc")
+	got := alphabet.Hints(12)
+	if len(got) != 12 {
+		t.Fatalf("Expected 12 hints, got %d: %v", len(got), got)
+	}
+
+	// No hint may be the prefix of another one
+	for i, a := range got {
+		for j, b := range got {
+			if i != j && strings.HasPrefix(b, a) {
+				t.Errorf("Hint %q is a prefix of %q", a, b)
+			}
+		}
+	}
+
+	if again := alphabet.Hints(12); !reflect.DeepEqual(got, again) {
+		t.Errorf("Expected deterministic hints, got %v and %v", got, again)
+	}
+}
+
+func TestAlphabetCapacity(t *testing.T) {
+	alphabet := NewAlphabet("abcd")
+	for length, expected := range []int{1, 4, 16, 64} {
+		if got := alphabet.Capacity(length); got != expected {
+			t.Errorf("Expected %d hints of at most %d letters, got %d", expected, length, got)
+		}
+	}
+
+	// Every hint of a full page is at most two letters long
+	for _, hint := range alphabet.Hints(alphabet.Capacity(2)) {
+		if len(hint) > 2 {
+			t.Errorf("Expected at most 2 letters, got %q", hint)
+		}
+	}
+}
+
+func TestMultiByteAlphabet(t *testing.T) {
+	alphabet := NewAlphabet("äöü")
+	got := alphabet.Hints(4)
+	want := []string{"ä", "ö", "üä", "üö"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MultiByteAlphabet = %v; want %v", got, want)
+	}
+}
+
+func TestValidateAlphabet(t *testing.T) {
+	tests := []struct {
+		letters string
+		wantErr bool
+	}{
+		{letters: "asdf"},
+		{letters: "äöüß"},
+		{letters: "1234567890"},
+		{letters: "a", wantErr: true},
+		{letters: "asda", wantErr: true},
+		{letters: "asDf", wantErr: true},
+		{letters: "as df", wantErr: true},
+		{letters: "as\tdf", wantErr: true},
+		{letters: "a\u0301s", wantErr: true},
+		{letters: "as\xffdf", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.letters, func(t *testing.T) {
+			err := ValidateAlphabet(tt.letters)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAlphabet(%q) error = %v, wantErr %v", tt.letters, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolveAlphabet(t *testing.T) {
+	custom := map[string]string{"mine": "jkl;", "qwerty": "ab", "broken": "aa"}
+
+	alphabet, err := ResolveAlphabet("mine", custom)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := alphabet.Hints(2); !reflect.DeepEqual(got, []string{"j", "k"}) {
+		t.Errorf("Expected custom alphabet hints, got %v", got)
+	}
+
+	alphabet, err = ResolveAlphabet("qwerty", custom)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := alphabet.Hints(2); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("Expected custom alphabet to override the preset, got %v", got)
+	}
+
+	if _, err := ResolveAlphabet("dvorak", nil); err != nil {
+		t.Errorf("Expected builtin alphabet, got error %v", err)
+	}
+	if _, err := ResolveAlphabet("broken", custom); err == nil {
+		t.Error("Expected error for invalid custom alphabet")
+	}
+	if _, err := ResolveAlphabet("nope", custom); err == nil {
+		t.Error("Expected error for unknown alphabet")
+	}
+}
@@ -0,0 +1,75 @@
+package internal
+
+import (
+	"github.com/Hanaasagi/magonote/pkg/textdetection/colordetection"
+	"github.com/gdamore/tcell/v2"
+)
+
+// passthroughAttrs are the text attributes of the original cell that are
+// kept when a hint or match is drawn on top of it
+const passthroughAttrs = tcell.AttrBold | tcell.AttrItalic | tcell.AttrUnderline |
+	tcell.AttrStrikeThrough | tcell.AttrDim
+
+// composeStyle merges the style of a hint or match with the style of the
+// text it is drawn on.
+//
+// Colors set by the overlay win, unset (default) colors are inherited from
+// the colors the original cell is displayed with, and text attributes such
+// as bold or underline pass through. Inside a reverse-video region the
+// terminal swaps foreground and background, so inherited colors are swapped
+// too and the reverse attribute is only kept when a terminal default color
+// can't be expressed otherwise. This keeps hints legible in reversed text.
+func composeStyle(original, overlay tcell.Style) tcell.Style {
+	origFg, origBg, origAttrs := original.Decompose()
+	fg, bg, attrs := overlay.Decompose()
+
+	attrs |= origAttrs & passthroughAttrs
+
+	reversed := origAttrs&tcell.AttrReverse != 0
+	if reversed {
+		// Colors the original cell is actually displayed with
+		origFg, origBg = origBg, origFg
+	}
+
+	inheritedDefault := false
+	if fg == tcell.ColorDefault {
+		fg = origFg
+		inheritedDefault = inheritedDefault || origFg == tcell.ColorDefault
+	}
+	if bg == tcell.ColorDefault {
+		bg = origBg
+		inheritedDefault = inheritedDefault || origBg == tcell.ColorDefault
+	}
+
+	// The terminal default colors of a reversed cell are only available
+	// through the reverse attribute
+	if reversed && inheritedDefault {
+		attrs |= tcell.AttrReverse
+		fg, bg = bg, fg
+	}
+
+	// Never draw text in the color of its own background
+	if fg == bg && fg != tcell.ColorDefault {
+		fg = tcell.ColorDefault
+		attrs |= tcell.AttrReverse
+	}
+
+	// Underline carries its own style and must be set through Underline
+	style := tcell.StyleDefault.Foreground(fg).Background(bg).Attributes(attrs &^ tcell.AttrUnderline)
+	if attrs&tcell.AttrUnderline != 0 {
+		style = style.Underline(true)
+	}
+	return style
+}
+
+// spanStyle returns the tcell style of a styled span of the original text
+func spanStyle(style colordetection.Style) tcell.Style {
+	result := tcell.StyleDefault
+	if fg := style.ForegroundColor; fg != nil {
+		result = result.Foreground(tcell.NewRGBColor(int32(fg.R), int32(fg.G), int32(fg.B)))
+	}
+	if bg := style.BackgroundColor; bg != nil {
+		result = result.Background(tcell.NewRGBColor(int32(bg.R), int32(bg.G), int32(bg.B)))
+	}
+	return result.Bold(style.Bold).Underline(style.Underline).Italic(style.Italic)
+}
@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestComposeStyle(t *testing.T) {
+	hint := tcell.StyleDefault.Foreground(tcell.ColorYellow).Background(tcell.ColorBlack)
+
+	tests := []struct {
+		name     string
+		original tcell.Style
+		overlay  tcell.Style
+		want     tcell.Style
+	}{
+		{
+			name:     "plain text keeps overlay",
+			original: tcell.StyleDefault,
+			overlay:  hint,
+			want:     hint,
+		},
+		{
+			name:     "attributes pass through",
+			original: tcell.StyleDefault.Bold(true).Underline(true).Blink(true),
+			overlay:  hint,
+			want:     hint.Bold(true).Underline(true),
+		},
+		{
+			name:     "unset colors are inherited",
+			original: tcell.StyleDefault.Foreground(tcell.ColorRed).Background(tcell.ColorBlue),
+			overlay:  tcell.StyleDefault.Foreground(tcell.ColorYellow),
+			want:     tcell.StyleDefault.Foreground(tcell.ColorYellow).Background(tcell.ColorBlue),
+		},
+		{
+			name:     "explicit hint colors in reversed region",
+			original: tcell.StyleDefault.Reverse(true),
+			overlay:  hint,
+			want:     hint,
+		},
+		{
+			name:     "inherited colors in reversed region are swapped",
+			original: tcell.StyleDefault.Foreground(tcell.ColorRed).Background(tcell.ColorBlue).Reverse(true),
+			overlay:  tcell.StyleDefault.Foreground(tcell.ColorYellow),
+			want:     tcell.StyleDefault.Foreground(tcell.ColorYellow).Background(tcell.ColorRed),
+		},
+		{
+			name:     "inherited default colors in reversed region keep reverse",
+			original: tcell.StyleDefault.Reverse(true),
+			overlay:  tcell.StyleDefault.Foreground(tcell.ColorYellow),
+			want:     tcell.StyleDefault.Background(tcell.ColorYellow).Reverse(true),
+		},
+		{
+			name:     "hint color equal to background is inverted",
+			original: tcell.StyleDefault.Background(tcell.ColorYellow),
+			overlay:  tcell.StyleDefault.Foreground(tcell.ColorYellow),
+			want:     tcell.StyleDefault.Background(tcell.ColorYellow).Reverse(true),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := composeStyle(tt.original, tt.overlay); got != tt.want {
+				t.Errorf("composeStyle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
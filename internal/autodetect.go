@@ -0,0 +1,112 @@
+package internal
+
+import (
+	"slices"
+	"strings"
+)
+
+// Color detection turns on with minStyledSpans styled spans or more, and at
+// least one for every styledLineRatio non-empty lines
+const (
+	minStyledSpans  = 3
+	styledLineRatio = 20
+)
+
+// WithAutoDetection turns table detection on when the input looks like it
+// holds tables, and color detection when it is colored, unless another
+// option configured them. Only the detections given are considered, those
+// turned off explicitly are left out
+func WithAutoDetection(tables, colors bool) Option {
+	return optionFunc(func(s *State) {
+		s.autoTables, s.autoColors = tables, colors
+	})
+}
+
+// applyAutoDetection turns the detections of WithAutoDetection on when the
+// input needs them, once every option is applied
+func (s *State) applyAutoDetection() {
+	if s.autoTables && s.TableDetectionConfig == nil && looksTabular(s.Lines) {
+		s.TableDetectionConfig = &TableDetectionConfig{
+			MinLines:            minLines,
+			MinColumns:          minColumns,
+			ConfidenceThreshold: confidenceThreshold,
+		}
+	}
+	if s.autoColors && s.ColorDetectionConfig == nil && s.looksColored() {
+		s.ColorDetectionConfig = &ColorDetectionConfig{}
+	}
+}
+
+// looksTabular reports whether minLines lines in a row are table rows:
+// lines whose columns are separated by two spaces or more, in line with
+// those of the previous row, or lines with borders
+func looksTabular(lines []string) bool {
+	run := 0
+	var prev []int
+	for _, line := range lines {
+		starts := columnStarts(line)
+		switch {
+		case isBorderedRow(line):
+			run, prev = run+1, nil
+		case len(starts) < minColumns-1:
+			run, prev = 0, nil
+		case prev == nil || slices.ContainsFunc(starts, func(x int) bool { return slices.Contains(prev, x) }):
+			run, prev = run+1, starts
+		default:
+			run, prev = 1, starts
+		}
+		if run >= minLines {
+			return true
+		}
+	}
+	return false
+}
+
+// columnStarts returns the offsets of line where text resumes after two
+// spaces or more, leaving out the indentation
+func columnStarts(line string) []int {
+	var starts []int
+	spaces := 0
+	text := false
+	for i := 0; i < len(line); i++ {
+		if line[i] == ' ' {
+			spaces++
+			continue
+		}
+		if text && spaces >= 2 {
+			starts = append(starts, i)
+		}
+		text, spaces = true, 0
+	}
+	return starts
+}
+
+// isBorderedRow reports whether line is a row or a rule of a table drawn
+// with pipes, box-drawing characters or dashes and pluses
+func isBorderedRow(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if strings.Count(trimmed, "|") >= 2 || strings.ContainsAny(trimmed, "│┃") {
+		return true
+	}
+	return len(trimmed) > 3 && strings.Trim(trimmed, "+-=") == "" && strings.Contains(trimmed, "+")
+}
+
+// looksColored reports whether the input holds enough styled spans, not
+// counting those of noise such as punctuation, for color detection to find
+// something to match
+func (s *State) looksColored() bool {
+	spans := 0
+	for _, mat := range s.styleMatches {
+		if !isTextNoise(mat.Text) {
+			spans++
+		}
+	}
+
+	lines := 0
+	for _, line := range s.Lines {
+		if strings.TrimSpace(line) != "" {
+			lines++
+		}
+	}
+	return spans >= minStyledSpans && spans*styledLineRatio >= lines
+}
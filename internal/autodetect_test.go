@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAutoDetection(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		tables bool
+		colors bool
+	}{
+		{
+			name:   "aligned columns",
+			text:   "NAME   STATUS   AGE\nweb    Running  3d\ndb     Pending  5m",
+			tables: true,
+		},
+		{
+			name:   "bordered",
+			text:   "+----+-------+\n| id | name  |\n+----+-------+\n|  1 | alice |",
+			tables: true,
+		},
+		{
+			name: "prose",
+			text: "lorem ipsum dolor\n  sit  amet\nconsectetur adipiscing",
+		},
+		{
+			name: "columns out of line",
+			text: "a  b  c\nlong text  here  x\nother  words  there  y",
+		},
+		{
+			name:   "colored",
+			text:   "\x1b[32mmain.go\x1b[0m \x1b[34mREADME.md\x1b[0m\n\x1b[1mbuild\x1b[0m done",
+			colors: true,
+		},
+		{
+			name: "single colored word",
+			text: "\x1b[31merror\x1b[0m" + strings.Repeat("\nplain", 5),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := NewState(tt.text, "abcd", []string{}, WithAutoDetection(true, true))
+			if got := state.TableDetectionConfig != nil; got != tt.tables {
+				t.Errorf("Expected table detection %v, got %v", tt.tables, got)
+			}
+			if got := state.ColorDetectionConfig != nil; got != tt.colors {
+				t.Errorf("Expected color detection %v, got %v", tt.colors, got)
+			}
+		})
+	}
+
+	state := NewState("NAME   STATUS   AGE\nweb    Running  3d\ndb     Pending  5m", "abcd", []string{}, WithAutoDetection(false, true))
+	if state.TableDetectionConfig != nil {
+		t.Error("Expected table detection to stay off when left out")
+	}
+}
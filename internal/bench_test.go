@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// Fixtures of the benchmarks, generated so that they stay representative
+// of real terminals without checking in megabytes of text
+
+// dockerPS returns the output of `docker ps` with n containers
+func dockerPS(n int) string {
+	var sb strings.Builder
+	sb.WriteString("CONTAINER ID   IMAGE                      COMMAND                  CREATED        STATUS                   PORTS                    NAMES\n")
+	for i := range n {
+		fmt.Fprintf(&sb, "%012x   registry.example.com/app:%d.%d   \"/entrypoint.sh sh\"     %2d hours ago   Up %2d hours (healthy)   0.0.0.0:%d->80/tcp    app_%d\n",
+			0x5386a67b0f15+i*7919, i%4, i%10, i%24, i%24, 8000+i, i)
+	}
+	return sb.String()
+}
+
+// gitLog returns the output of `git log --stat` with n commits
+func gitLog(n int) string {
+	var sb strings.Builder
+	for i := range n {
+		fmt.Fprintf(&sb, "commit %040x\n", 0xa91c94b0+i*104729)
+		fmt.Fprintf(&sb, "Author: Dev %d <dev%d@example.com>\n", i%17, i%17)
+		fmt.Fprintf(&sb, "Date:   Mon Oct %d 10:%02d:00 2025 +0900\n\n", 1+i%28, i%60)
+		fmt.Fprintf(&sb, "    Fix #%d in internal/view.go, see https://github.com/o/r/pull/%d\n\n", i, i+1)
+		fmt.Fprintf(&sb, " internal/view.go      | %d ++++--\n", 1+i%40)
+		fmt.Fprintf(&sb, " internal/view_test.go | %d +++\n", 1+i%9)
+		sb.WriteString(" 2 files changed, 12 insertions(+), 3 deletions(-)\n\n")
+	}
+	return sb.String()
+}
+
+// buildOutput returns n lines of colored compiler output
+func buildOutput(n int) string {
+	var sb strings.Builder
+	for i := range n {
+		switch i % 4 {
+		case 0:
+			fmt.Fprintf(&sb, "\x1b[1m\x1b[32m   Compiling\x1b[0m crate_%d v0.%d.0 (/home/dev/src/crate_%d)\n", i, i%10, i)
+		case 1:
+			fmt.Fprintf(&sb, "\x1b[1;31merror[E0308]\x1b[0m\x1b[1m: mismatched types\x1b[0m\n")
+		case 2:
+			fmt.Fprintf(&sb, "  \x1b[1;34m-->\x1b[0m src/lib.rs:%d:%d\n", 10+i, 1+i%80)
+		default:
+			fmt.Fprintf(&sb, "\x1b[33mwarning\x1b[0m: unused variable `x_%d` at 0x%08x\n", i, i*4099)
+		}
+	}
+	return sb.String()
+}
+
+var benchFixtures = []struct {
+	name string
+	text string
+}{
+	{"docker_ps", dockerPS(500)},
+	{"git_log", gitLog(1000)},
+	{"build_output", buildOutput(5000)},
+}
+
+func BenchmarkMatches(b *testing.B) {
+	for _, fixture := range benchFixtures {
+		b.Run(fixture.name, func(b *testing.B) {
+			state := NewState(fixture.text, "qwerty", nil)
+
+			b.ReportAllocs()
+			for b.Loop() {
+				state.Matches(false, 0)
+			}
+		})
+	}
+}
+
+func BenchmarkMatchesTableDetection(b *testing.B) {
+	state := NewState(dockerPS(100), "qwerty", nil, WithTableDetection(3, 3, 0.5))
+
+	b.ReportAllocs()
+	for b.Loop() {
+		state.Matches(false, 0)
+	}
+}
+
+func BenchmarkStyledTextProcessor(b *testing.B) {
+	text := buildOutput(5000)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(text)))
+	for b.Loop() {
+		if _, _, err := NewStyledTextProcessor().Process(text); err != nil {
+			b.Fatalf("Failed to process text: %v", err)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package internal
+
+import "golang.org/x/text/unicode/bidi"
+
+// Modes of WithBidi
+const (
+	BidiGutter = "gutter" // Hints of matches on right-to-left lines at the start of the line
+	BidiInline = "inline" // Hints next to the matches whatever the direction of the line
+)
+
+// WithBidi sets where the hints of matches on lines with right-to-left text
+// go. Terminals showing such lines in visual order put the text elsewhere
+// than the cells it is stored at, so BidiGutter, the default, draws the
+// hints one after another from the start of the line where they can't be
+// misplaced. Only supported by View
+func WithBidi(mode string) ViewOption {
+	return viewOptionFunc(func(o *viewOptions) {
+		o.bidi = mode
+	})
+}
+
+// hasRTL reports whether line has right-to-left text, such as Arabic or
+// Hebrew
+func hasRTL(line string) bool {
+	for _, r := range line {
+		props, _ := bidi.LookupRune(r)
+		if class := props.Class(); class == bidi.R || class == bidi.AL {
+			return true
+		}
+	}
+	return false
+}
+
+// gutterHints returns the columns of the hints drawn in the gutter by match
+// position, the hints of a line following each other in the order of its
+// matches
+func (v *View) gutterHints() map[[2]int]int {
+	if v.bidi != BidiGutter {
+		return nil
+	}
+
+	gutter := make(map[[2]int]int)
+	rtl := make(map[int]bool)
+	next := make(map[int]int) // Column of the next hint by line
+	for _, mat := range v.matches {
+		if mat.Hint == nil {
+			continue
+		}
+		isRTL, ok := rtl[mat.Y]
+		if !ok {
+			isRTL = hasRTL(v.state.Lines[mat.Y])
+			rtl[mat.Y] = isRTL
+		}
+		if !isRTL {
+			continue
+		}
+		gutter[[2]int{mat.X, mat.Y}] = next[mat.Y]
+		next[mat.Y] += displayWidth(v.makeHintText(*mat.Hint)) + 1
+	}
+	return gutter
+}
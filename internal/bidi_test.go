@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestHasRTL(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected bool
+	}{
+		{"see https://example.com", false},
+		{"日本語 👍🏽", false},
+		{"שלום https://example.com", true},
+		{"مرحبا", true},
+		{"123 ,.-", false},
+	}
+
+	for _, tt := range tests {
+		if got := hasRTL(tt.line); got != tt.expected {
+			t.Errorf("Expected %v for %q, got %v", tt.expected, tt.line, got)
+		}
+	}
+}
+
+func TestViewBidiGutter(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []ViewOption
+		columns []int // Columns of the hints of the lines
+	}{
+		{"gutter", nil, []int{4, 0, 2}},
+		{"inline", []ViewOption{WithBidi(BidiInline)}, []int{4, 5, 20}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := NewState("see https://a.io\nשלום https://b.io ו https://c.io", "qwerty", nil)
+			view := NewView(
+				state, false, false, 0, false, "",
+				GetColor("default"), GetColor("default"), GetColor("default"), GetColor("default"),
+				GetColor("default"), GetColor("default"), GetColor("default"), GetColor("default"),
+				tt.opts...,
+			)
+
+			screen := tcell.NewSimulationScreen("UTF-8")
+			if err := screen.Init(); err != nil {
+				t.Fatalf("Failed to initialize simulation screen: %v", err)
+			}
+			defer screen.Fini()
+			screen.SetSize(40, 5)
+			view.screen = screen
+
+			view.render("")
+
+			if len(view.matches) != len(tt.columns) {
+				t.Fatalf("Expected %d matches, got %d", len(tt.columns), len(view.matches))
+			}
+			for i, mat := range view.matches {
+				r, _, _, _ := screen.GetContent(tt.columns[i], mat.Y)
+				if hint := []rune(*mat.Hint); r != hint[0] {
+					t.Errorf("Expected hint %q of %q at column %d, got %q", *mat.Hint, mat.Text, tt.columns[i], r)
+				}
+			}
+		})
+	}
+}
@@ -13,6 +13,7 @@ import (
 // Color interface defines how to colorize text
 type Color interface {
 	FgString(text string) string
+	BgString(text string) string
 	GetFgColor() color.Attribute
 }
 
@@ -22,6 +23,8 @@ type ColorWrapper struct {
 	colorAttr color.Attribute
 	isRGB     bool
 	r, g, b   uint8
+	is256     bool
+	index     uint8 // Color of the 256-color palette
 }
 
 // FgString returns a string with the color applied
@@ -31,9 +34,28 @@ func (c ColorWrapper) FgString(text string) string {
 		// we'll fall back to the ANSI escape sequence for RGB
 		return fmt.Sprintf("\x1b[38;2;%d;%d;%dm%s\x1b[0m", c.r, c.g, c.b, text)
 	}
+	if c.is256 {
+		return fmt.Sprintf("\x1b[38;5;%dm%s\x1b[0m", c.index, text)
+	}
 	return c.colorFunc(text)
 }
 
+// BgString returns a string with the color applied as background, the
+// default color leaving it as is
+func (c ColorWrapper) BgString(text string) string {
+	if c.isRGB {
+		return fmt.Sprintf("\x1b[48;2;%d;%d;%dm%s\x1b[0m", c.r, c.g, c.b, text)
+	}
+	if c.is256 {
+		return fmt.Sprintf("\x1b[48;5;%dm%s\x1b[0m", c.index, text)
+	}
+	if c.colorAttr == color.Reset {
+		return text
+	}
+	// Backgrounds follow their foregrounds by 10, BgRed being FgRed + 10
+	return color.New(c.colorAttr + 10).Sprint(text)
+}
+
 // GetFgColor returns the color.Attribute for this color
 func (c ColorWrapper) GetFgColor() color.Attribute {
 	return c.colorAttr
@@ -41,6 +63,12 @@ func (c ColorWrapper) GetFgColor() color.Attribute {
 
 var rgbRegex = regexp.MustCompile(`^#([a-fA-F0-9]{2})([a-fA-F0-9]{2})([a-fA-F0-9]{2})$`)
 
+// shortRGBRegex matches the #rgb form of hex colors, #fa0 being #ffaa00
+var shortRGBRegex = regexp.MustCompile(`^#([a-fA-F0-9])([a-fA-F0-9])([a-fA-F0-9])$`)
+
+// paletteRegex matches colors of the 256-color palette, as tmux names them
+var paletteRegex = regexp.MustCompile(`^colou?r(\d{1,3})$`)
+
 var (
 	colorCache = make(map[string]Color, 32)
 	colorMutex sync.RWMutex
@@ -85,7 +113,9 @@ var predefinedColors = map[string]ColorWrapper{
 	},
 }
 
-// GetColor parses a color string and returns a Color interface
+// GetColor parses a color string and returns a Color interface. Colors are
+// names such as "green", "#rrggbb" or "#rgb" hex truecolors, or "color0" to
+// "color255" of the 256-color palette
 func GetColor(name string) Color {
 	// Check cache first
 	colorMutex.RLock()
@@ -97,8 +127,21 @@ func GetColor(name string) Color {
 
 	var result Color
 
-	// Check for RGB color
-	if m := rgbRegex.FindStringSubmatch(name); m != nil {
+	if m := shortRGBRegex.FindStringSubmatch(name); m != nil {
+		result = GetColor("#" + strings.Repeat(m[1], 2) + strings.Repeat(m[2], 2) + strings.Repeat(m[3], 2))
+	} else if m := paletteRegex.FindStringSubmatch(strings.ToLower(name)); m != nil {
+		index, err := strconv.ParseUint(m[1], 10, 8)
+		if err != nil {
+			panic(fmt.Sprintf("Unknown color: %s", name))
+		}
+		result = ColorWrapper{
+			colorFunc: color.New(color.FgWhite).SprintFunc(),
+			colorAttr: color.FgWhite,
+			is256:     true,
+			index:     uint8(index),
+		}
+	} else if m := rgbRegex.FindStringSubmatch(name); m != nil {
+		// Check for RGB color
 		r, _ := strconv.ParseUint(m[1], 16, 8)
 		g, _ := strconv.ParseUint(m[2], 16, 8)
 		b, _ := strconv.ParseUint(m[3], 16, 8)
@@ -44,3 +44,27 @@ func TestUnknownColor(t *testing.T) {
 	}()
 	_ = GetColor("wat")
 }
+
+func TestParsePaletteColor(t *testing.T) {
+	for _, name := range []string{"color208", "colour208", "Color208"} {
+		if got := GetColor(name).FgString("foo"); got != "\x1b[38;5;208mfoo\x1b[0m" {
+			t.Errorf("Expected palette color 208 for %s, got %q", name, got)
+		}
+	}
+}
+
+func TestParseShortRGB(t *testing.T) {
+	got := GetColor("#fa0").FgString("foo")
+	if !strings.Contains(got, "255;170;0") {
+		t.Errorf("Expected RGB color with 255;170;0, got %q", got)
+	}
+}
+
+func TestInvalidPaletteColor(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected panic for a palette color out of range")
+		}
+	}()
+	_ = GetColor("color256")
+}
@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// columnPack names the columns of a well known table, recognized by its header
+type columnPack struct {
+	// Headers maps the header of a column to the pattern name of its cells.
+	// The table is only recognized when all of them are present
+	Headers map[string]string
+}
+
+// columnPacks are the detected tables whose columns are reported under their
+// own pattern name instead of grid
+var columnPacks = []columnPack{
+	// docker ps
+	{Headers: map[string]string{
+		"CONTAINER ID": "docker_id",
+		"IMAGE":        "docker_image",
+		"NAMES":        "docker_name",
+	}},
+	// docker images
+	{Headers: map[string]string{
+		"REPOSITORY": "docker_image",
+		"IMAGE ID":   "docker_id",
+	}},
+}
+
+// matches reports whether the headers of a table are those of the pack
+func (p columnPack) matches(headers map[string]bool) bool {
+	for name := range p.Headers {
+		if !headers[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// getColumnMatches reports the cells of the named columns of the detected
+// tables whose headers match a column pack
+func (s *State) getColumnMatches(columns []TableColumn) []Match {
+	// Columns of the same table share the line of their header
+	tables := make(map[int][]TableColumn)
+	for _, column := range columns {
+		if column.Header != nil {
+			tables[column.Header.Y] = append(tables[column.Header.Y], column)
+		}
+	}
+
+	var matches []Match
+	for _, table := range tables {
+		headers := make(map[string]bool, len(table))
+		for _, column := range table {
+			headers[strings.TrimSpace(column.Header.Text)] = true
+		}
+		idx := slices.IndexFunc(columnPacks, func(p columnPack) bool { return p.matches(headers) })
+		if idx < 0 {
+			continue
+		}
+
+		for _, column := range table {
+			pattern := columnPacks[idx].Headers[strings.TrimSpace(column.Header.Text)]
+			if pattern == "" {
+				continue
+			}
+			for _, cell := range column.Cells {
+				// A cell is a single token, the rest belongs to an unnamed column
+				if fields := strings.Fields(cell.Text); len(fields) > 0 {
+					matches = append(matches, Match{X: cell.X, Y: cell.Y, Pattern: pattern, Text: fields[0]})
+				}
+			}
+		}
+	}
+
+	slices.SortFunc(matches, func(a, b Match) int {
+		return cmp.Or(cmp.Compare(a.Y, b.Y), cmp.Compare(a.X, b.X))
+	})
+	return matches
+}
+
+// mergeColumnMatches replaces matches that overlap a column match and keeps
+// the result in reading order
+func (s *State) mergeColumnMatches(matches, columnMatches []Match) []Match {
+	merged := append(s.filterOverlappingMatches(matches, columnMatches), columnMatches...)
+	slices.SortStableFunc(merged, func(a, b Match) int {
+		return cmp.Or(cmp.Compare(a.Y, b.Y), cmp.Compare(a.X, b.X))
+	})
+	return merged
+}
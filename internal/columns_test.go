@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"testing"
+)
+
+func TestColumnMatches(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  []Match
+	}{
+		{
+			name: "docker ps",
+			lines: []string{
+				"CONTAINER ID   IMAGE                      COMMAND                  NAMES",
+				"5386a67b0f15   linuxserver/ffmpeg:5.1.2   \"/ffmpegwrapper.sh b…\"   sad_austin",
+				"f3b0b352c2d5   mysql                      \"docker-entrypoint.s…\"   some-mysql",
+				"",
+				"5386a67b0f15 is running",
+			},
+			want: []Match{
+				{X: 0, Y: 1, Pattern: "docker_id", Text: "5386a67b0f15"},
+				{X: 15, Y: 1, Pattern: "docker_image", Text: "linuxserver/ffmpeg:5.1.2"},
+				{X: 69, Y: 1, Pattern: "docker_name", Text: "sad_austin"},
+				{X: 0, Y: 2, Pattern: "docker_id", Text: "f3b0b352c2d5"},
+				{X: 15, Y: 2, Pattern: "docker_image", Text: "mysql"},
+				{X: 69, Y: 2, Pattern: "docker_name", Text: "some-mysql"},
+			},
+		},
+		{
+			name: "docker images",
+			lines: []string{
+				"REPOSITORY   TAG       IMAGE ID       CREATED       SIZE",
+				"redis        latest    7614ae9453d1   2 weeks ago   113MB",
+			},
+			want: []Match{
+				{X: 0, Y: 1, Pattern: "docker_image", Text: "redis"},
+				{X: 23, Y: 1, Pattern: "docker_id", Text: "7614ae9453d1"},
+			},
+		},
+		{
+			name:  "unknown header",
+			lines: []string{"NAME   READY   STATUS", "web    1/1     Running"},
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := NewStateFromLines(tt.lines, "abcd", []string{}, WithTableDetection(2, 2, 0.5))
+			got := state.getColumnMatches(state.TableColumns())
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %d column matches, got %d: %v", len(tt.want), len(got), got)
+			}
+			for i, want := range tt.want {
+				if got[i].X != want.X || got[i].Y != want.Y || got[i].Pattern != want.Pattern || got[i].Text != want.Text {
+					t.Errorf("Expected %+v, got %+v", want, got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestColumnMatchesReplaceRegexMatches(t *testing.T) {
+	lines := []string{
+		"CONTAINER ID   IMAGE                NAMES",
+		"5386a67b0f15   linuxserver/ffmpeg   sad_austin",
+	}
+
+	results := NewStateFromLines(lines, "abcd", []string{}, WithTableDetection(2, 2, 0.5)).Matches(false, 0)
+	patterns := make(map[string]string)
+	for _, result := range results {
+		patterns[result.Text] = result.Pattern
+	}
+
+	for text, want := range map[string]string{
+		"5386a67b0f15":       "docker_id",
+		"linuxserver/ffmpeg": "docker_image",
+		"sad_austin":         "docker_name",
+	} {
+		if patterns[text] != want {
+			t.Errorf("Expected %q to match as %s, got %q", text, want, patterns[text])
+		}
+	}
+
+	// Without table detection the regex patterns apply
+	results = NewStateFromLines(lines, "abcd", []string{}).Matches(false, 0)
+	for _, result := range results {
+		if result.Text == "5386a67b0f15" && result.Pattern != "sha" {
+			t.Errorf("Expected sha without table detection, got %s", result.Pattern)
+		}
+	}
+}
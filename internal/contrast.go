@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"math"
+	"strings"
+)
+
+// MinContrastRatio is the contrast ratio below which text is hard to read,
+// the WCAG minimum for large text
+const MinContrastRatio = 3.0
+
+// RGB is a truecolor
+type RGB struct {
+	R, G, B uint8
+}
+
+// namedRGB are the xterm values of the named colors
+var namedRGB = map[string]RGB{
+	"black":   {0, 0, 0},
+	"red":     {205, 0, 0},
+	"green":   {0, 205, 0},
+	"yellow":  {205, 205, 0},
+	"blue":    {0, 0, 238},
+	"magenta": {205, 0, 205},
+	"cyan":    {0, 205, 205},
+	"white":   {229, 229, 229},
+}
+
+// paletteRGB returns the xterm value of a color of the 256-color palette
+func paletteRGB(index uint8) RGB {
+	switch {
+	case index < 16:
+		base := []RGB{
+			{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+			{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+			{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+			{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+		}
+		return base[index]
+	case index < 232:
+		level := func(v uint8) uint8 {
+			if v == 0 {
+				return 0
+			}
+			return 55 + v*40
+		}
+		i := index - 16
+		return RGB{level(i / 36), level(i / 6 % 6), level(i % 6)}
+	default:
+		gray := 8 + (index-232)*10
+		return RGB{gray, gray, gray}
+	}
+}
+
+// ColorRGB returns the truecolor of a color, ok is false for "default"
+// whose value is up to the terminal
+func ColorRGB(c Color) (rgb RGB, ok bool) {
+	cw, isWrapper := c.(ColorWrapper)
+	if !isWrapper {
+		return RGB{}, false
+	}
+	switch {
+	case cw.isRGB:
+		return RGB{cw.r, cw.g, cw.b}, true
+	case cw.is256:
+		return paletteRGB(cw.index), true
+	}
+
+	for name, predefined := range predefinedColors {
+		if predefined.colorAttr == cw.colorAttr {
+			rgb, ok = namedRGB[strings.ToLower(name)]
+			return rgb, ok
+		}
+	}
+	return RGB{}, false
+}
+
+// luminance returns the relative luminance of c as defined by WCAG
+func (c RGB) luminance() float64 {
+	channel := func(v uint8) float64 {
+		s := float64(v) / 255
+		if s <= 0.03928 {
+			return s / 12.92
+		}
+		return math.Pow((s+0.055)/1.055, 2.4)
+	}
+	return 0.2126*channel(c.R) + 0.7152*channel(c.G) + 0.0722*channel(c.B)
+}
+
+// ContrastRatio returns the WCAG contrast ratio of a and b, from 1 for the
+// same colors to 21 for black and white
+func ContrastRatio(a, b RGB) float64 {
+	la, lb := a.luminance(), b.luminance()
+	return (max(la, lb) + 0.05) / (min(la, lb) + 0.05)
+}
+
+// ContrastingColor returns "black" or "white", whichever is more readable
+// on bg
+func ContrastingColor(bg RGB) string {
+	if ContrastRatio(RGB{}, bg) >= ContrastRatio(RGB{255, 255, 255}, bg) {
+		return "black"
+	}
+	return "white"
+}
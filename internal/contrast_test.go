@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"math"
+	"testing"
+)
+
+func TestColorRGB(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected RGB
+		ok       bool
+	}{
+		{"black", RGB{0, 0, 0}, true},
+		{"Yellow", RGB{205, 205, 0}, true},
+		{"#1b1cbf", RGB{27, 28, 191}, true},
+		{"color196", RGB{255, 0, 0}, true},
+		{"color244", RGB{128, 128, 128}, true},
+		{"color9", RGB{255, 0, 0}, true},
+		{"default", RGB{}, false},
+	}
+
+	for _, tt := range tests {
+		rgb, ok := ColorRGB(GetColor(tt.name))
+		if ok != tt.ok || rgb != tt.expected {
+			t.Errorf("ColorRGB(%s): Expected %v %v, got %v %v", tt.name, tt.expected, tt.ok, rgb, ok)
+		}
+	}
+}
+
+func TestContrastRatio(t *testing.T) {
+	if got := ContrastRatio(RGB{0, 0, 0}, RGB{255, 255, 255}); math.Abs(got-21) > 0.01 {
+		t.Errorf("Expected 21 for black on white, got %f", got)
+	}
+	if got := ContrastRatio(RGB{40, 40, 40}, RGB{40, 40, 40}); got != 1 {
+		t.Errorf("Expected 1 for the same colors, got %f", got)
+	}
+}
+
+func TestContrastingColor(t *testing.T) {
+	tests := []struct {
+		bg       RGB
+		expected string
+	}{
+		{RGB{0, 0, 0}, "white"},
+		{RGB{0x28, 0x28, 0x28}, "white"},
+		{RGB{0xfd, 0xf6, 0xe3}, "black"},
+		{RGB{255, 255, 0}, "black"},
+	}
+
+	for _, tt := range tests {
+		if got := ContrastingColor(tt.bg); got != tt.expected {
+			t.Errorf("ContrastingColor(%v): Expected %s, got %s", tt.bg, tt.expected, got)
+		}
+	}
+}
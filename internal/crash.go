@@ -0,0 +1,33 @@
+package internal
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+)
+
+// PanicError is a panic of a view, recovered once the terminal was restored
+type PanicError struct {
+	View  string // "view" or "listview"
+	Value any    // Value passed to panic
+	Stack []byte // Stack of the panicking goroutine
+}
+
+// Error returns the panic value, the stack is left to crash reports
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%s panicked: %v", e.View, e.Value)
+}
+
+// recoverView turns a panic of a view into a PanicError stored in err. It
+// has to be deferred before the terminal restoration, so that it runs once
+// the terminal is usable again
+func recoverView(view string, err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	stack := debug.Stack()
+	slog.Error("View panicked", "view", view, "panic", r, "stack", string(stack))
+	*err = &PanicError{View: view, Value: r, Stack: stack}
+}
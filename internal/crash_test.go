@@ -0,0 +1,36 @@
+package internal
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRecoverView(t *testing.T) {
+	present := func() (err error) {
+		defer recoverView("view", &err)
+		var matches []Match
+		_ = matches[1]
+		return nil
+	}
+
+	err := present()
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("Expected a PanicError, got %v", err)
+	}
+	if !strings.HasPrefix(err.Error(), "view panicked: runtime error: index out of range") {
+		t.Errorf("Expected the panic value in the error, got %q", err.Error())
+	}
+	if !strings.Contains(string(panicErr.Stack), "TestRecoverView") {
+		t.Errorf("Expected the stack to contain the test, got %s", panicErr.Stack)
+	}
+
+	noPanic := func() (err error) {
+		defer recoverView("view", &err)
+		return nil
+	}
+	if err := noPanic(); err != nil {
+		t.Errorf("Expected no error without panic, got %v", err)
+	}
+}
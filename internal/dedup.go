@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"cmp"
+	"log/slog"
+	"slices"
+)
+
+// matchPriority ranks the patterns of overlapping matches, the first listed
+// wins. Patterns that aren't listed rank after the listed ones
+var matchPriority = []string{"hyperlink", "url", "markdown_url", "file_location", "path", "filename"}
+
+// patternRank returns the rank of pattern in matchPriority
+func patternRank(pattern string) int {
+	if i := slices.Index(matchPriority, pattern); i >= 0 {
+		return i
+	}
+	return len(matchPriority)
+}
+
+// preferMatch reports whether a is the canonical match when it overlaps b:
+// the higher ranked pattern wins, then the longer text
+func preferMatch(a, b Match) bool {
+	if c := cmp.Compare(patternRank(a.Pattern), patternRank(b.Pattern)); c != 0 {
+		return c < 0
+	}
+	return len(a.Text) > len(b.Text)
+}
+
+// dedupOverlappingMatches keeps a single match out of those overlapping on
+// a line, such as the nested groups of a custom pattern, so that they don't
+// get hints at the same position. Matches keep their order
+func (s *State) dedupOverlappingMatches(matches []Match) []Match {
+	order := make([]int, len(matches))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Or(
+			cmp.Compare(matches[a].Y, matches[b].Y),
+			cmp.Compare(matches[a].X, matches[b].X),
+		)
+	})
+
+	dropped := make([]bool, len(matches))
+	last := -1
+	for _, i := range order {
+		if last >= 0 && matches[i].Y == matches[last].Y && matches[i].X < matches[last].X+len(matches[last].Text) {
+			if !preferMatch(matches[i], matches[last]) {
+				dropped[i] = true
+				continue
+			}
+			dropped[last] = true
+		}
+		last = i
+	}
+
+	deduped := make([]Match, 0, len(matches))
+	for i, match := range matches {
+		if dropped[i] {
+			slog.Debug("Dropping overlapping match", "text", match.Text, "pattern", match.Pattern, "x", match.X, "y", match.Y)
+			continue
+		}
+		deduped = append(deduped, match)
+	}
+	return deduped
+}
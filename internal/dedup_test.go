@@ -0,0 +1,80 @@
+package internal
+
+import "testing"
+
+func TestDedupOverlappingMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		matches  []Match
+		expected []string
+	}{
+		{
+			name: "path wins over filename",
+			matches: []Match{
+				{X: 4, Y: 0, Pattern: "filename", Text: "main.go"},
+				{X: 0, Y: 0, Pattern: "path", Text: "src/main.go"},
+			},
+			expected: []string{"src/main.go"},
+		},
+		{
+			name: "url wins over path",
+			matches: []Match{
+				{X: 0, Y: 0, Pattern: "path", Text: "//example.com/a.txt"},
+				{X: 0, Y: 0, Pattern: "url", Text: "https://example.com/a.txt"},
+			},
+			expected: []string{"https://example.com/a.txt"},
+		},
+		{
+			name: "url wins over a longer filename",
+			matches: []Match{
+				{X: 0, Y: 0, Pattern: "url", Text: "https://a.io"},
+				{X: 8, Y: 0, Pattern: "filename", Text: "a.io.json.txt"},
+			},
+			expected: []string{"https://a.io"},
+		},
+		{
+			name: "longer match wins within a pattern",
+			matches: []Match{
+				{X: 6, Y: 0, Pattern: "custom", Text: "main.go"},
+				{X: 11, Y: 0, Pattern: "custom", Text: "go"},
+			},
+			expected: []string{"main.go"},
+		},
+		{
+			name: "same text on other lines is kept",
+			matches: []Match{
+				{X: 0, Y: 0, Pattern: "path", Text: "/tmp/a.log"},
+				{X: 0, Y: 1, Pattern: "filename", Text: "a.log"},
+				{X: 11, Y: 0, Pattern: "filename", Text: "a.log"},
+			},
+			expected: []string{"/tmp/a.log", "a.log", "a.log"},
+		},
+	}
+
+	state := NewStateFromLines(nil, "abcd", []string{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deduped := state.dedupOverlappingMatches(tt.matches)
+			if len(deduped) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %+v", tt.expected, deduped)
+			}
+			for i, match := range deduped {
+				if match.Text != tt.expected[i] {
+					t.Errorf("Expected %q at %d, got %q", tt.expected[i], i, match.Text)
+				}
+			}
+		})
+	}
+}
+
+func TestDedupNestedGroups(t *testing.T) {
+	state := NewStateFromLines([]string{"build main.go now"}, "abcd", []string{`(\w+\.(go|rs))`})
+
+	results := state.Matches(false, 0)
+	if len(results) != 1 || results[0].Text != "main.go" {
+		t.Fatalf("Expected main.go alone, got %+v", results)
+	}
+	if *results[0].Hint != "a" {
+		t.Errorf("Expected hint 'a', got %q", *results[0].Hint)
+	}
+}
@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxEnclosedLength is the longest text inside quotes or brackets matched,
+// in bytes, longer ones being sentences rather than tokens
+const maxEnclosedLength = 80
+
+// EnclosedPatterns match the text inside quotes and brackets, such as the
+// name an error message quotes. Their first group is the match. They only
+// match the text the other patterns miss
+var EnclosedPatterns = []MatchPattern{
+	{"quoted", `"([^"]+)"`},
+	{"quoted", `'([^']+)'`},
+	{"quoted", "`([^`]+)`"},
+	{"bracketed", `\(([^()]+)\)`},
+	{"bracketed", `\[([^\[\]]+)\]`},
+	{"bracketed", `\{([^{}]+)\}`},
+}
+
+// enclosedRegexes are the compiled EnclosedPatterns
+var enclosedRegexes = func() []*regexp.Regexp {
+	regexes := make([]*regexp.Regexp, len(EnclosedPatterns))
+	for i, p := range EnclosedPatterns {
+		regexes[i] = regexp.MustCompile(p.Pattern)
+	}
+	return regexes
+}()
+
+// enclosedMatches returns the matches of EnclosedPatterns in the lines in
+// scope that overlap none of matches nor each other
+func (s *State) enclosedMatches(ctx context.Context, matches []Match) ([]Match, error) {
+	var found []Match
+	for i, p := range EnclosedPatterns {
+		var patternMatches []Match
+		start, end := s.scopeLines()
+		for y := start; y < end; y++ {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			patternMatches = append(patternMatches, s.findEnclosed(y, p.Name, enclosedRegexes[i])...)
+		}
+		found = append(found, s.filterOverlappingMatches(patternMatches, slices.Concat(matches, found))...)
+	}
+	return found, nil
+}
+
+// findEnclosed returns the matches of the enclosed pattern re on line y.
+// Quotes next to a letter or digit are apostrophes or part of a word, as in
+// "don't", and don't enclose anything
+func (s *State) findEnclosed(y int, name string, re *regexp.Regexp) []Match {
+	line := s.Lines[y]
+	quoted := name == "quoted"
+
+	var matches []Match
+	for offset := 0; offset < len(line); {
+		loc := re.FindStringSubmatchIndex(line[offset:])
+		if loc == nil {
+			break
+		}
+		open, close := offset+loc[0], offset+loc[1]
+		x, text := offset+loc[2], line[offset+loc[2]:offset+loc[3]]
+
+		if quoted && (isWordRuneBefore(line, open) || isWordRuneAfter(line, close)) {
+			// The closing quote may open the next quoted text
+			_, size := utf8.DecodeRuneInString(line[open:])
+			offset = open + size
+			continue
+		}
+		offset = close
+
+		// The text of a markdown link, whose target is matched instead
+		if line[open] == '[' && strings.HasPrefix(line[close:], "(") {
+			continue
+		}
+		if strings.TrimSpace(text) == "" || len(text) > maxEnclosedLength || s.tooShort(name, text) {
+			continue
+		}
+		matches = append(matches, Match{X: x, Y: y, Pattern: name, Text: text})
+	}
+	return matches
+}
+
+// isWordRuneBefore reports whether the rune of line ending at i is a letter
+// or a digit
+func isWordRuneBefore(line string, i int) bool {
+	r, _ := utf8.DecodeLastRuneInString(line[:i])
+	return i > 0 && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// isWordRuneAfter reports whether the rune of line starting at i is a letter
+// or a digit
+func isWordRuneAfter(line string, i int) bool {
+	r, _ := utf8.DecodeRuneInString(line[i:])
+	return i < len(line) && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
@@ -0,0 +1,35 @@
+package internal
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEnclosedMatches(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected []string
+	}{
+		{`open "/tmp/a.txt": no such file`, []string{"path:/tmp/a.txt"}},
+		{`undefined: 'fooBar', don't`, []string{"quoted:fooBar"}},
+		{`it's 'x y' isn't it`, []string{"quoted:x y"}},
+		{"cannot find value `foo` in this scope", []string{"quoted:foo"}},
+		{`failed (exit status 2) [retry] {k: v}`, []string{"bracketed:exit status 2", "bracketed:retry", "bracketed:k: v"}},
+		// Values of JSON are hinted as such, the keys are left to the quotes
+		{`{"key": "value"}`, []string{"json:value"}},
+		{`body {"key": "value"}`, []string{"json:value", "quoted:key"}},
+		{`"" ( ) "` + strings.Repeat("x", maxEnclosedLength+1) + `"`, nil},
+	}
+
+	for _, tt := range tests {
+		state := NewStateFromLines([]string{tt.line}, "abcd", []string{})
+		var got []string
+		for _, mat := range state.Matches(false, 0) {
+			got = append(got, mat.Pattern+":"+mat.Text)
+		}
+		if !slices.Equal(got, tt.expected) {
+			t.Errorf("Expected %q for %q, got %q", tt.expected, tt.line, got)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// fuzzSeeds are inputs the fuzz targets start from: styled and plain
+// terminal output, malformed escape sequences and invalid UTF-8
+var fuzzSeeds = []string{
+	"",
+	"see https://example.com/a?b=c and /tmp/file.go:12:3",
+	"\x1b[31mred\x1b[0m https://example.com \x1b[1;32m0x7ffe\x1b[m",
+	"\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\ after",
+	"\x1b]133;A\x07$ ls\n\x1b]133;C\x07file\tother\n",
+	"\x1b[", "\x1b[38;5;", "\x1b[38;2;1;2m", "\x1b]8;;", "\x1b]8;;x\x07", "\x1b\x1b[0",
+	"\xff\xfe\xfd abc \xc3", "caf\xc3\xa9 \xe6\x97\xa5\xe6\x9c\xac 👨‍👩‍👧 שלום",
+	"NAME   READY   STATUS\npod-1  1/1     Running\npod-2  0/1     Pending\n",
+	`{"url": "https://example.com", "n": 1.5e3, "nested": [{"x": "é"}]}`,
+	"level=info msg=\"started\" addr=127.0.0.1:8080\n2025-10-15T10:00:00Z ERROR failed",
+}
+
+// discardLogs silences the logs of the extraction for the executions of f,
+// which would otherwise flood the output of the fuzzer
+func discardLogs(f *testing.F) {
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.DiscardHandler))
+	f.Cleanup(func() { slog.SetDefault(previous) })
+}
+
+func FuzzState(f *testing.F) {
+	discardLogs(f)
+	for i, seed := range fuzzSeeds {
+		f.Add(seed, uint8(i))
+	}
+
+	f.Fuzz(func(t *testing.T, text string, flags uint8) {
+		// The flags turn on the optional steps of the extraction
+		var opts []Option
+		if flags&1 != 0 {
+			opts = append(opts, WithTableDetection(2, 2, 0.5))
+		}
+		if flags&2 != 0 {
+			opts = append(opts, WithColorDetection())
+		}
+		if flags&4 != 0 {
+			opts = append(opts, WithLogDetection())
+		}
+		if flags&8 != 0 {
+			opts = append(opts, WithMode(ModeWords, 0))
+		}
+		if flags&16 != 0 {
+			opts = append(opts, WithJSONInput())
+		}
+		if flags&32 != 0 {
+			opts = append(opts, WithTabWidth(int(flags>>6)))
+		}
+
+		state := NewState(text, "qwerty", nil, opts...)
+		for _, mat := range state.Matches(flags&64 != 0, int(flags>>7)) {
+			if mat.Y < 0 || mat.Y >= len(state.Lines) {
+				t.Fatalf("Expected the line of %q in [0, %d), got %d", mat.Text, len(state.Lines), mat.Y)
+			}
+			if line := state.Lines[mat.Y]; mat.X < 0 || mat.X > len(line) {
+				t.Fatalf("Expected the column of %q in [0, %d], got %d", mat.Text, len(line), mat.X)
+			}
+		}
+	})
+}
+
+func FuzzStyledTextProcessor(f *testing.F) {
+	discardLogs(f)
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, text string) {
+		processor := NewStyledTextProcessor()
+		lines, styleMatches, err := processor.Process(text)
+		if err != nil {
+			return
+		}
+		if want := strings.Count(text, "\n") + 1; len(lines) > want {
+			t.Fatalf("Expected at most %d lines, got %d", want, len(lines))
+		}
+		for _, mat := range styleMatches {
+			if mat.Y < 0 || mat.Y >= len(lines) {
+				t.Fatalf("Expected the line of %q in [0, %d), got %d", mat.Text, len(lines), mat.Y)
+			}
+		}
+		for y := range processor.LineStyles() {
+			if y < 0 || y >= len(lines) {
+				t.Fatalf("Expected styled line in [0, %d), got %d", len(lines), y)
+			}
+		}
+	})
+}
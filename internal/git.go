@@ -0,0 +1,64 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// gitRef matches the characters allowed in branch and remote names
+const gitRef = `[\w][\w./\-]*`
+
+// GitPatterns match refs and paths in the output of git commands. They take
+// precedence over the builtin patterns so that e.g. the paths listed by
+// `git status` are reported as git_status_file instead of path
+var GitPatterns = []MatchPattern{
+	// `git status`: modified:   internal/state.go, renamed:    a.go -> b.go
+	{"git_status_file", `^\s+(?:modified|new file|deleted|renamed|copied|typechange|both modified|both added|both deleted|added by us|added by them|deleted by us|deleted by them):\s+(?:\S+ -> )?(?P<match>\S+)`},
+	// `git status`, `git checkout`: On branch main, Switched to branch 'dev'
+	{"git_branch", `(?:On branch|Switched to (?:a new )?branch|Your branch is (?:up to date with|ahead of|behind)|Your branch and) '?(?P<match>` + gitRef + `)`},
+	// `git branch`: * main, "  remotes/origin/dev"
+	{"git_branch", `^[* ] (?P<match>` + gitRef + `)$`},
+	// `git remote -v`: origin	git@github.com:user/repo.git (fetch)
+	{"git_remote", `^(?P<match>[\w.\-]+)\t\S+ \((?:fetch|push)\)$`},
+	// `git stash list`: stash@{0}: WIP on main
+	{"git_stash", `stash@\{\d+\}`},
+}
+
+// DefaultGitActions are the commands run for git matches when they are
+// selected with the run-action key, {} is replaced by the match
+var DefaultGitActions = map[string]string{
+	"git_branch":      "git checkout {}",
+	"git_remote":      "git remote show {}",
+	"git_stash":       "git stash show -p {}",
+	"git_status_file": "git diff -- {}",
+	"sha":             "git show {}",
+}
+
+// WithGitPatterns enables the git patterns
+func WithGitPatterns() Option {
+	return optionFunc(func(s *State) {
+		s.GitPatterns = GitPatterns
+		s.cacheValid = false
+	})
+}
+
+// IsGitRepo reports whether dir is inside a git working tree
+func IsGitRepo(dir string) bool {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+
+	for {
+		// .git is a directory in regular clones and a file in worktrees and submodules
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return false
+		}
+		dir = parent
+	}
+}
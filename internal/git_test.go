@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGitPatterns(t *testing.T) {
+	lines := []string{
+		"On branch feature/git-patterns",
+		"Your branch is up to date with 'origin/main'.",
+		"Changes not staged for commit:",
+		"\tmodified:   internal/state.go",
+		"\tnew file:   internal/git.go",
+		"\trenamed:    old.go -> cmd/new.go",
+		"* main",
+		"  remotes/origin/dev",
+		"origin\tgit@github.com:Hanaasagi/magonote.git (fetch)",
+		"stash@{1}: WIP on main: 3098015 Add tokens",
+	}
+
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{pattern: "git_branch", want: []string{"feature/git-patterns", "origin/main", "main", "remotes/origin/dev"}},
+		{pattern: "git_status_file", want: []string{"internal/state.go", "internal/git.go", "cmd/new.go"}},
+		{pattern: "git_remote", want: []string{"origin"}},
+		{pattern: "git_stash", want: []string{"stash@{1}"}},
+	}
+
+	results := NewStateFromLines(lines, "abcd", []string{}, WithGitPatterns()).Matches(false, 0)
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			var got []string
+			for _, result := range results {
+				if result.Pattern == tt.pattern {
+					got = append(got, result.Text)
+				}
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, got)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("Expected %q, got %q", tt.want[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGitPatternsDisabled(t *testing.T) {
+	lines := []string{"\tmodified:   internal/state.go", "stash@{0}: WIP on main"}
+	results := NewStateFromLines(lines, "abcd", []string{}).Matches(false, 0)
+
+	for _, result := range results {
+		if result.Pattern == "git_status_file" || result.Pattern == "git_stash" {
+			t.Errorf("Expected no git matches without WithGitPatterns, got %s: %q", result.Pattern, result.Text)
+		}
+	}
+}
+
+func TestIsGitRepo(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "repo", "a", "b")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if IsGitRepo(nested) {
+		t.Error("Expected directory without .git not to be a repository")
+	}
+
+	if err := os.Mkdir(filepath.Join(root, "repo", ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if !IsGitRepo(nested) {
+		t.Error("Expected nested directory of a repository to be detected")
+	}
+}
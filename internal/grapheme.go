@@ -0,0 +1,42 @@
+package internal
+
+import (
+	"iter"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/uniseg"
+)
+
+// graphemes yields the grapheme clusters of text with the number of cells
+// each takes in the buffer. A cluster is drawn in a single cell however many
+// runes it has, like an emoji ZWJ sequence or a letter followed by combining
+// marks, and zero-width characters take a cell so that they stay visible
+func graphemes(text string) iter.Seq2[string, int] {
+	return func(yield func(string, int) bool) {
+		state := -1
+		for text != "" {
+			var cluster string
+			var width int
+			cluster, text, width, state = uniseg.FirstGraphemeClusterInString(text, state)
+			if utf8.RuneCountInString(cluster) == 1 {
+				// Single runes follow runewidth like the rest of the view,
+				// which honours the East Asian ambiguous width of the locale
+				r, _ := utf8.DecodeRuneInString(cluster)
+				width = runewidth.RuneWidth(r)
+			}
+			if !yield(cluster, max(width, 1)) {
+				return
+			}
+		}
+	}
+}
+
+// displayWidth returns the number of cells the text takes in the buffer
+func displayWidth(text string) int {
+	width := 0
+	for _, w := range graphemes(text) {
+		width += w
+	}
+	return width
+}
@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestGraphemes(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		clusters []string
+		width    int
+	}{
+		{"ascii", "abc", []string{"a", "b", "c"}, 3},
+		{"cjk", "日本語", []string{"日", "本", "語"}, 6},
+		{"combining", "cafe\u0301", []string{"c", "a", "f", "e\u0301"}, 4},
+		{"skin tone", "👍🏽!", []string{"👍🏽", "!"}, 3},
+		{"zwj sequence", "👨\u200d👩\u200d👧x", []string{"👨\u200d👩\u200d👧", "x"}, 3},
+		{"flag", "🇯🇵", []string{"🇯🇵"}, 2},
+		{"zero width", "a\u200bb", []string{"a", "\u200b", "b"}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var clusters []string
+			for cluster := range graphemes(tt.text) {
+				clusters = append(clusters, cluster)
+			}
+			if !slices.Equal(clusters, tt.clusters) {
+				t.Errorf("Expected clusters %q, got %q", tt.clusters, clusters)
+			}
+			if got := displayWidth(tt.text); got != tt.width {
+				t.Errorf("Expected width %d, got %d", tt.width, got)
+			}
+		})
+	}
+}
+
+func TestViewGraphemeHints(t *testing.T) {
+	lines := []string{
+		"日本 https://a.io",
+		"👨\u200d👩\u200d👧 https://b.io",
+		"cafe\u0301 https://c.io",
+		"👍🏽👍🏽 https://d.io",
+	}
+	state := NewState(lines[0]+"\n"+lines[1]+"\n"+lines[2]+"\n"+lines[3], "qwerty", nil)
+	view := NewView(
+		state, false, false, 0, false, "",
+		GetColor("default"), GetColor("default"), GetColor("default"), GetColor("default"),
+		GetColor("default"), GetColor("default"), GetColor("default"), GetColor("default"),
+	)
+
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("Failed to initialize simulation screen: %v", err)
+	}
+	defer screen.Fini()
+	screen.SetSize(40, 5)
+	view.screen = screen
+
+	view.render("")
+
+	// The hints sit on the first cell of the URLs however the text before
+	// them is made of runes
+	columns := []int{5, 3, 5, 5}
+	for _, mat := range view.matches {
+		if mat.Hint == nil {
+			t.Fatalf("Expected a hint for %q", mat.Text)
+		}
+		r, _, _, _ := screen.GetContent(columns[mat.Y], mat.Y)
+		if hint := []rune(*mat.Hint); r != hint[0] {
+			t.Errorf("Expected hint %q at column %d of line %d, got %q", *mat.Hint, columns[mat.Y], mat.Y, r)
+		}
+	}
+
+	// Combining marks are drawn in the cell of the rune they follow
+	r, combining, _, _ := screen.GetContent(3, 2)
+	if r != 'e' || !slices.Equal(combining, []rune{'\u0301'}) {
+		t.Errorf("Expected e with a combining acute accent, got %q %q", r, combining)
+	}
+	if r, _, _, _ := screen.GetContent(4, 2); r != ' ' {
+		t.Errorf("Expected a space after the accented letter, got %q", r)
+	}
+}
+
+func TestPreviewTextGraphemes(t *testing.T) {
+	line := "👨\u200d👩\u200d👧 see https://example.com 👍🏽"
+	x := len("👨\u200d👩\u200d👧 see ")
+	got := previewText(line, x, len("https://example.com"), 80)
+	expected := "👨\u200d👩\u200d👧 see \x1b[4mhttps://example.com\x1b[24m 👍🏽"
+	if got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	// Cropping never splits a cluster
+	got = previewText(line, x, len("https://example.com"), 26)
+	expected = "…e \x1b[4mhttps://example.com\x1b[24m 👍🏽"
+	if got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
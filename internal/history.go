@@ -0,0 +1,197 @@
+package internal
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultHistorySize is the number of distinct values kept in the history
+const DefaultHistorySize = 500
+
+// HistoryEntry records how often and when a value was selected, and the
+// pattern it was last matched by
+type HistoryEntry struct {
+	Count    int       `json:"count"`
+	LastUsed time.Time `json:"last_used"`
+	Pattern  string    `json:"pattern,omitempty"`
+}
+
+// HistoryItem is a value of the history with its entry
+type HistoryItem struct {
+	Text string
+	HistoryEntry
+}
+
+// History is a small persistent store of previously selected values
+type History struct {
+	path       string
+	maxEntries int
+	entries    map[string]HistoryEntry
+}
+
+// LoadHistory reads the history stored at path, a missing file yields an
+// empty history. At most maxEntries values are kept when saving
+func LoadHistory(path string, maxEntries int) (*History, error) {
+	if maxEntries <= 0 {
+		maxEntries = DefaultHistorySize
+	}
+	h := &History{
+		path:       path,
+		maxEntries: maxEntries,
+		entries:    make(map[string]HistoryEntry),
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading history: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &h.entries); err != nil {
+		return nil, fmt.Errorf("decoding history: %w", err)
+	}
+	return h, nil
+}
+
+// Record counts a selection of every text, matched by pattern
+func (h *History) Record(pattern string, texts ...string) {
+	now := time.Now()
+	for _, text := range texts {
+		entry := h.entries[text]
+		entry.Count++
+		entry.LastUsed = now
+		entry.Pattern = pattern
+		h.entries[text] = entry
+	}
+}
+
+// Items returns the values of the history, the most recently used first
+func (h *History) Items() []HistoryItem {
+	items := make([]HistoryItem, 0, len(h.entries))
+	for text, entry := range h.entries {
+		items = append(items, HistoryItem{Text: text, HistoryEntry: entry})
+	}
+	slices.SortFunc(items, func(a, b HistoryItem) int {
+		return cmp.Or(b.LastUsed.Compare(a.LastUsed), cmp.Compare(a.Text, b.Text))
+	})
+	return items
+}
+
+// Scores returns the selection count of every known value
+func (h *History) Scores() map[string]int {
+	scores := make(map[string]int, len(h.entries))
+	for text, entry := range h.entries {
+		scores[text] = entry.Count
+	}
+	return scores
+}
+
+// Save writes the history back to its file, dropping the least used values
+// beyond the size cap. The file is replaced atomically
+func (h *History) Save() error {
+	h.prune()
+
+	data, err := json.Marshal(h.entries)
+	if err != nil {
+		return fmt.Errorf("encoding history: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return fmt.Errorf("creating history directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(h.path), filepath.Base(h.path)+".*")
+	if err != nil {
+		return fmt.Errorf("creating history file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // nolint: errcheck
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close() // nolint: errcheck
+		return fmt.Errorf("writing history: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing history: %w", err)
+	}
+	// The history may contain sensitive values, keep it private
+	if err := os.Chmod(tmp.Name(), 0600); err != nil {
+		return fmt.Errorf("writing history: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), h.path); err != nil {
+		return fmt.Errorf("replacing history: %w", err)
+	}
+	return nil
+}
+
+// prune keeps the maxEntries most used values, recent ones win ties
+func (h *History) prune() {
+	if len(h.entries) <= h.maxEntries {
+		return
+	}
+
+	texts := make([]string, 0, len(h.entries))
+	for text := range h.entries {
+		texts = append(texts, text)
+	}
+	slices.SortFunc(texts, func(a, b string) int {
+		ea, eb := h.entries[a], h.entries[b]
+		return cmp.Or(
+			cmp.Compare(eb.Count, ea.Count),
+			eb.LastUsed.Compare(ea.LastUsed),
+			cmp.Compare(a, b),
+		)
+	})
+
+	for _, text := range texts[h.maxEntries:] {
+		delete(h.entries, text)
+	}
+}
+
+// NewHistoryState creates a state to pick from the values of the history.
+// Every item gets a line telling when it was last selected and its pattern,
+// of which its value is the match
+func NewHistoryState(items []HistoryItem, alphabet string, opts ...Option) *State {
+	width := 0
+	for _, item := range items {
+		width = max(width, len(historyItemPattern(item)))
+	}
+
+	lines := make([]string, len(items))
+	matches := make([]Match, len(items))
+	for i, item := range items {
+		pattern := historyItemPattern(item)
+		prefix := fmt.Sprintf("%s  %-*s  ", item.LastUsed.Local().Format(time.DateTime), width, pattern)
+		lines[i] = prefix + item.Text
+		matches[i] = Match{X: len(prefix), Y: i, Pattern: pattern, Text: item.Text}
+	}
+
+	s := NewState(strings.Join(lines, "\n"), alphabet, nil, opts...)
+	s.historyMatches = matches
+	return s
+}
+
+// historyItemPattern returns the pattern of item, recorded by Record, or
+// "history" for values recorded without one
+func historyItemPattern(item HistoryItem) string {
+	if item.Pattern == "" {
+		return "history"
+	}
+	return item.Pattern
+}
+
+// WithHistoryScores assigns the shortest hints to the matches whose text was
+// selected most often before, as returned by History.Scores
+func WithHistoryScores(scores map[string]int) Option {
+	return optionFunc(func(s *State) {
+		s.HistoryScores = scores
+	})
+}
@@ -0,0 +1,153 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "history.json")
+
+	h, err := LoadHistory(path, 2)
+	if err != nil {
+		t.Fatalf("LoadHistory() error = %v", err)
+	}
+	if len(h.Scores()) != 0 {
+		t.Errorf("Expected empty history for a missing file, got %v", h.Scores())
+	}
+
+	h.Record("ipv4", "10.0.0.1", "/tmp")
+	h.Record("ipv4", "10.0.0.1")
+	h.Record("", "once")
+	if err := h.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Expected history file, got %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("Expected history file mode 0600, got %o", perm)
+	}
+
+	h, err = LoadHistory(path, 2)
+	if err != nil {
+		t.Fatalf("LoadHistory() error = %v", err)
+	}
+	scores := h.Scores()
+	if len(scores) != 2 {
+		t.Fatalf("Expected history capped at 2 entries, got %v", scores)
+	}
+	if scores["10.0.0.1"] != 2 {
+		t.Errorf("Expected count 2 for 10.0.0.1, got %d", scores["10.0.0.1"])
+	}
+	// "once" is the most recent of the values selected once
+	if scores["once"] != 1 {
+		t.Errorf("Expected most recent value to survive pruning, got %v", scores)
+	}
+}
+
+func TestHistoryState(t *testing.T) {
+	h, err := LoadHistory(filepath.Join(t.TempDir(), "history.json"), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Record("path", "/tmp/old")
+	h.Record("ipv4", "10.0.0.1")
+	h.Record("", "plain")
+	// Same timestamps sort by text
+	for text, entry := range h.entries {
+		entry.LastUsed = time.Date(2025, 1, 2, 3, 4, 5, 0, time.Local)
+		if text == "/tmp/old" {
+			entry.LastUsed = entry.LastUsed.Add(-time.Hour)
+		}
+		h.entries[text] = entry
+	}
+
+	items := h.Items()
+	var texts []string
+	for _, item := range items {
+		texts = append(texts, item.Text)
+	}
+	if expected := []string{"10.0.0.1", "plain", "/tmp/old"}; !slices.Equal(texts, expected) {
+		t.Fatalf("Expected items %q, got %q", expected, texts)
+	}
+
+	state := NewHistoryState(items, "qwerty")
+	expectedLines := []string{
+		"2025-01-02 03:04:05  ipv4     10.0.0.1",
+		"2025-01-02 03:04:05  history  plain",
+		"2025-01-02 02:04:05  path     /tmp/old",
+	}
+	if !slices.Equal(state.Lines, expectedLines) {
+		t.Errorf("Expected lines %q, got %q", expectedLines, state.Lines)
+	}
+
+	matches := state.Matches(false, 0)
+	if len(matches) != len(items) {
+		t.Fatalf("Expected %d matches, got %v", len(items), matches)
+	}
+	for i, mat := range matches {
+		if mat.Text != items[i].Text || mat.Y != i || state.Lines[i][mat.X:] != mat.Text {
+			t.Errorf("Expected match of %q on line %d, got %+v", items[i].Text, i, mat)
+		}
+	}
+	if matches[0].Pattern != "ipv4" || matches[1].Pattern != "history" {
+		t.Errorf("Expected patterns ipv4 and history, got %q and %q", matches[0].Pattern, matches[1].Pattern)
+	}
+}
+
+func TestLoadHistoryCorrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadHistory(path, 10); err == nil {
+		t.Error("Expected error for corrupted history")
+	}
+}
+
+func TestHistoryHints(t *testing.T) {
+	lines := []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}
+	scores := map[string]int{"10.0.0.3": 5, "10.0.0.2": 1}
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		reverse bool
+		want    map[string]string
+	}{
+		{
+			name: "frequent first",
+			opts: []Option{WithHistoryScores(scores)},
+			want: map[string]string{"10.0.0.3": "a", "10.0.0.2": "b", "10.0.0.1": "c"},
+		},
+		{
+			name:    "reverse for unknown values",
+			opts:    []Option{WithHistoryScores(map[string]int{"10.0.0.2": 1})},
+			reverse: true,
+			want:    map[string]string{"10.0.0.2": "a", "10.0.0.3": "b", "10.0.0.1": "c"},
+		},
+		{
+			name: "history before proximity",
+			opts: []Option{WithHistoryScores(map[string]int{"10.0.0.1": 1}), WithProximity(-1)},
+			want: map[string]string{"10.0.0.1": "a", "10.0.0.3": "b", "10.0.0.2": "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := NewStateFromLines(lines, "abcd", []string{}, tt.opts...).Matches(tt.reverse, 0)
+			for _, result := range results {
+				if result.Hint == nil || *result.Hint != tt.want[result.Text] {
+					t.Errorf("Expected hint %q for %s, got %v", tt.want[result.Text], result.Text, result.Hint)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package internal
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Hanaasagi/magonote/pkg/textdetection/colordetection"
+)
+
+// hyperlinkPattern is the name of the matches of OSC 8 hyperlinks
+const hyperlinkPattern = "hyperlink"
+
+// hyperlinkSequence matches an OSC 8 sequence such as
+// "\x1b]8;id=1;https://example.com\x1b\\", which starts a link to its URI or
+// ends the current link when the URI is empty
+var hyperlinkSequence = regexp.MustCompile(`\x1b\]8;[^;\x07\x1b]*;([^\x07\x1b]*)(?:\x07|\x1b\\)`)
+
+// extractHyperlinks removes the OSC 8 sequences of text, returning the text
+// without them and a match for the visible text of every link, targeting
+// its URI. Links end at the end of their line
+func extractHyperlinks(text string) (string, []Match) {
+	if !strings.Contains(text, "\x1b]8;") {
+		return text, nil
+	}
+
+	lines := strings.Split(text, "\n")
+	var links []Match
+	for y, line := range lines {
+		var stripped strings.Builder
+		uri, start := "", 0
+
+		// closeLink adds the link opened at start, ending where the stripped
+		// line is now
+		closeLink := func() {
+			if uri != "" {
+				if link, ok := hyperlinkMatch(stripped.String(), start, y, uri); ok {
+					links = append(links, link)
+				}
+			}
+		}
+
+		last := 0
+		for _, loc := range hyperlinkSequence.FindAllStringSubmatchIndex(line, -1) {
+			stripped.WriteString(line[last:loc[0]])
+			last = loc[1]
+
+			closeLink()
+			uri, start = line[loc[2]:loc[3]], stripped.Len()
+		}
+		stripped.WriteString(line[last:])
+		closeLink()
+
+		lines[y] = stripped.String()
+	}
+	return strings.Join(lines, "\n"), links
+}
+
+// hyperlinkMatch returns the match of a link to uri whose visible text is
+// line[start:], where line may still hold other escape sequences
+func hyperlinkMatch(line string, start, y int, uri string) (Match, bool) {
+	prefix, _ := colordetection.StripLine(line[:start])
+	text, _ := colordetection.StripLine(line[start:])
+	if strings.TrimSpace(text) == "" {
+		return Match{}, false
+	}
+	return Match{
+		X:       len(prefix),
+		Y:       y,
+		Pattern: hyperlinkPattern,
+		Text:    text,
+		Target:  uri,
+	}, true
+}
@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExtractHyperlinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		plain    string
+		expected []Match
+	}{
+		{
+			name:  "link terminated by ST",
+			text:  "see \x1b]8;;https://example.com/docs\x1b\\the docs\x1b]8;;\x1b\\ here",
+			plain: "see the docs here",
+			expected: []Match{
+				{X: 4, Y: 0, Pattern: "hyperlink", Text: "the docs", Target: "https://example.com/docs"},
+			},
+		},
+		{
+			name:  "link terminated by BEL with params",
+			text:  "a\n\x1b]8;id=1;file:///tmp/a.log\x07a.log\x1b]8;;\x07",
+			plain: "a\na.log",
+			expected: []Match{
+				{X: 0, Y: 1, Pattern: "hyperlink", Text: "a.log", Target: "file:///tmp/a.log"},
+			},
+		},
+		{
+			name:  "styled link text",
+			text:  "\x1b[1mx\x1b[0m \x1b]8;;https://a.io\x1b\\\x1b[4mlink\x1b[0m\x1b]8;;\x1b\\",
+			plain: "\x1b[1mx\x1b[0m \x1b[4mlink\x1b[0m",
+			expected: []Match{
+				{X: 2, Y: 0, Pattern: "hyperlink", Text: "link", Target: "https://a.io"},
+			},
+		},
+		{
+			name:  "consecutive links and a link left open",
+			text:  "\x1b]8;;https://a.io\x1b\\a\x1b]8;;https://b.io\x1b\\b \x1b]8;;https://c.io\x1b\\c",
+			plain: "ab c",
+			expected: []Match{
+				{X: 0, Y: 0, Pattern: "hyperlink", Text: "a", Target: "https://a.io"},
+				{X: 1, Y: 0, Pattern: "hyperlink", Text: "b ", Target: "https://b.io"},
+				{X: 3, Y: 0, Pattern: "hyperlink", Text: "c", Target: "https://c.io"},
+			},
+		},
+		{
+			name:     "blank link text",
+			text:     "\x1b]8;;https://a.io\x1b\\ \x1b]8;;\x1b\\",
+			plain:    " ",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plain, links := extractHyperlinks(tt.text)
+			if plain != tt.plain {
+				t.Errorf("Expected text %q, got %q", tt.plain, plain)
+			}
+			if len(links) != len(tt.expected) {
+				t.Fatalf("Expected %+v, got %+v", tt.expected, links)
+			}
+			for i, link := range links {
+				if link != tt.expected[i] {
+					t.Errorf("Expected %+v, got %+v", tt.expected[i], link)
+				}
+			}
+		})
+	}
+}
+
+func TestHyperlinkMatchOutputsTarget(t *testing.T) {
+	text := "docs at \x1b]8;;https://example.com/docs\x1b\\example.com\x1b]8;;\x1b\\ and /tmp/a.log"
+	state := NewState(text, "abcd", []string{})
+
+	matches := state.Matches(false, 0)
+	if len(matches) != 2 {
+		t.Fatalf("Expected 2 matches, got %+v", matches)
+	}
+	i := slices.IndexFunc(matches, func(m Match) bool { return m.Pattern == "hyperlink" })
+	if i < 0 || matches[i].Text != "example.com" {
+		t.Fatalf("Expected a hyperlink on example.com, got %+v", matches)
+	}
+
+	chosen := newChosenMatch(state, matches[i], i)
+	if chosen.Text != "https://example.com/docs" {
+		t.Errorf("Expected the link target as output, got %q", chosen.Text)
+	}
+	if state.Lines[0] != "docs at example.com and /tmp/a.log" {
+		t.Errorf("Expected the sequences to be stripped, got %q", state.Lines[0])
+	}
+}
@@ -0,0 +1,204 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPattern is the name of the matches of the values of a JSON input
+const jsonPattern = "json"
+
+// jsonIdentifier matches the keys a JSON path writes as .key rather than
+// .["key"]
+var jsonIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// WithJSONInput parses the input as JSON even when it doesn't start like
+// an object or an array, see jsonMatches
+func WithJSONInput() Option {
+	return optionFunc(func(s *State) {
+		s.JSONInput = true
+	})
+}
+
+// jsonFrame is an object or an array being walked by jsonMatches
+type jsonFrame struct {
+	path      string
+	array     bool
+	index     int
+	key       string
+	expectKey bool
+}
+
+// child returns the path of the current element of the frame
+func (f *jsonFrame) child() string {
+	if !f.array && jsonIdentifier.MatchString(f.key) {
+		return f.path + "." + f.key
+	}
+	path := f.path
+	if path == "" {
+		path = "."
+	}
+	if f.array {
+		return path + "[" + strconv.Itoa(f.index) + "]"
+	}
+	key, _ := json.Marshal(f.key)
+	return path + "[" + string(key) + "]"
+}
+
+// next moves the frame past its current element
+func (f *jsonFrame) next() {
+	if f.array {
+		f.index++
+	} else {
+		f.expectKey = true
+	}
+}
+
+// jsonMatches returns a match for every string and number value of the
+// input when it is JSON, or a stream of JSON values, with its jq style path,
+// such as ".items[0].name". The input is only tried as JSON when it starts
+// like an object or an array, or with WithJSONInput
+func (s *State) jsonMatches() ([]Match, bool) {
+	text, starts := s.jsonText()
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" || (!s.JSONInput && trimmed[0] != '{' && trimmed[0] != '[') {
+		return nil, false
+	}
+
+	matches, err := jsonValues(text, 0, len(text), starts)
+	if err != nil {
+		if s.JSONInput {
+			slog.Warn("Input isn't JSON", "error", err)
+		}
+		return nil, false
+	}
+	return matches, true
+}
+
+// jsonRegionMatches returns the matches of jsonMatches for the JSON objects
+// and arrays inside an input that isn't JSON as a whole, such as the
+// payloads of log lines or a response printed by curl -v. A region starts
+// at the first bracket of a line, at its start or after a space, = or :,
+// and ends its last line
+func (s *State) jsonRegionMatches() []Match {
+	text, starts := s.jsonText()
+
+	var matches []Match
+	for y := 0; y < len(s.Lines); y++ {
+		idx := strings.IndexAny(s.Lines[y], "{[")
+		if idx < 0 || (idx > 0 && !strings.ContainsRune(" \t=:", rune(s.Lines[y][idx-1]))) {
+			continue
+		}
+
+		start := starts[y] + idx
+		dec := json.NewDecoder(strings.NewReader(text[start:]))
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			continue
+		}
+		end := start + int(dec.InputOffset())
+		last := sort.SearchInts(starts, end+1) - 1
+		if strings.TrimSpace(text[end:starts[last]+len(s.Lines[last])]) != "" {
+			continue
+		}
+
+		values, err := jsonValues(text, start, end, starts)
+		if err != nil {
+			continue
+		}
+		matches = append(matches, values...)
+		y = last
+	}
+	return matches
+}
+
+// jsonText returns the lines of the input joined as text, and the offsets of
+// the lines in it
+func (s *State) jsonText() (string, []int) {
+	starts := make([]int, len(s.Lines))
+	for y, offset := 1, 0; y < len(s.Lines); y++ {
+		offset += len(s.Lines[y-1]) + 1
+		starts[y] = offset
+	}
+	return strings.Join(s.Lines, "\n"), starts
+}
+
+// jsonValues walks the JSON values of text[from:to] and returns a match for
+// every string and number with its path, placed by the line offsets starts
+func jsonValues(text string, from, to int, starts []int) ([]Match, error) {
+	dec := json.NewDecoder(strings.NewReader(text[from:to]))
+	dec.UseNumber()
+
+	var matches []Match
+	var stack []*jsonFrame
+	for {
+		before := from + int(dec.InputOffset())
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		end := from + int(dec.InputOffset())
+
+		var top *jsonFrame
+		path := "."
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+			if top.expectKey {
+				if key, ok := tok.(string); ok {
+					top.key, top.expectKey = key, false
+					continue
+				}
+			} else {
+				path = top.child()
+			}
+		}
+
+		switch tok := tok.(type) {
+		case json.Delim:
+			switch tok {
+			case '{', '[':
+				if path == "." {
+					path = ""
+				}
+				stack = append(stack, &jsonFrame{path: path, array: tok == '[', expectKey: tok == '{'})
+				continue
+			case '}', ']':
+				stack = stack[:len(stack)-1]
+				if len(stack) > 0 {
+					stack[len(stack)-1].next()
+				}
+				continue
+			}
+		case string, json.Number:
+			// The value starts after the separators and spaces before it
+			raw := text[before:end]
+			start := end - len(strings.TrimLeft(raw, " \t\r\n,:"))
+			value := text[start:end]
+			if _, ok := tok.(string); ok {
+				value = value[1 : len(value)-1]
+				start++
+			}
+			if value != "" {
+				y := sort.SearchInts(starts, start+1) - 1
+				mat := Match{X: start - starts[y], Y: y, Pattern: jsonPattern, Text: value, Path: path}
+				if decoded, ok := tok.(string); ok && decoded != value {
+					mat.Target = decoded
+				}
+				matches = append(matches, mat)
+			}
+		}
+		if top != nil {
+			top.next()
+		}
+	}
+	return matches, nil
+}
@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestJSONMatches(t *testing.T) {
+	tests := []struct {
+		input     string
+		jsonInput bool
+		expected  []string
+	}{
+		{
+			`{"items": [{"name": "a\"b", "id": 42, "ok": true}], "a b": -1.5e3}`, false,
+			[]string{`.items[0].name=a\"b>a"b@21`, ".items[0].id=42@34", `.["a b"]=-1.5e3@59`},
+		},
+		{"[\n  \"x\",\n  {\"k\": null, \"v\": \"\"},\n  7\n]", false, []string{".[0]=x@1:3", ".[2]=7@3:2"}},
+		{"{\"a\": 1}\n{\"a\": 2}", false, []string{".a=1@6", ".a=2@1:6"}},
+		{`"42"`, true, []string{".=42@1"}},
+		{`"42"`, false, nil},
+		{`{"a": 1} trailing`, false, nil},
+	}
+
+	for _, tt := range tests {
+		state := NewStateFromLines(strings.Split(tt.input, "\n"), "abcd", []string{})
+		state.JSONInput = tt.jsonInput
+		got, ok := state.jsonMatches()
+		if ok != (tt.expected != nil) {
+			t.Errorf("Expected JSON %v for %q, got %v", tt.expected != nil, tt.input, ok)
+			continue
+		}
+		var values []string
+		for _, mat := range got {
+			value := mat.Path + "=" + mat.Text
+			if mat.Target != "" {
+				value += ">" + mat.Target
+			}
+			if mat.Y > 0 {
+				value += fmt.Sprintf("@%d:%d", mat.Y, mat.X)
+			} else {
+				value += fmt.Sprintf("@%d", mat.X)
+			}
+			values = append(values, value)
+		}
+		if !slices.Equal(values, tt.expected) {
+			t.Errorf("Expected %q for %q, got %q", tt.expected, tt.input, values)
+		}
+	}
+}
+
+func TestJSONRegionMatches(t *testing.T) {
+	tests := []struct {
+		lines    []string
+		expected []string
+	}{
+		{
+			[]string{`time=10:00 level=info msg={"user": "bob", "id": 7}`, "done"},
+			[]string{".user=bob@0:36", ".id=7@0:48"},
+		},
+		{
+			[]string{"< HTTP/1.1 200 OK", "{", `  "items": ["a"]`, "}", "* closed"},
+			[]string{".items[0]=a@2:13"},
+		},
+		{[]string{`payload {"a": 1} trailing`, "arr[1]", "[retry] {k: v}"}, nil},
+	}
+
+	for _, tt := range tests {
+		var values []string
+		for _, mat := range NewStateFromLines(tt.lines, "abcd", []string{}).jsonRegionMatches() {
+			values = append(values, fmt.Sprintf("%s=%s@%d:%d", mat.Path, mat.Text, mat.Y, mat.X))
+		}
+		if !slices.Equal(values, tt.expected) {
+			t.Errorf("Expected %q for %q, got %q", tt.expected, tt.lines, values)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Action is a user interface operation that can be bound to keys
+type Action string
+
+const (
+	ActionQuit            Action = "quit"
+	ActionConfirm         Action = "confirm"
+	ActionToggleMulti     Action = "toggle-multi"
+	ActionUp              Action = "up"
+	ActionDown            Action = "down"
+	ActionScrollUp        Action = "scroll-up"
+	ActionScrollDown      Action = "scroll-down"
+	ActionPageUp          Action = "page-up"
+	ActionPageDown        Action = "page-down"
+	ActionOpenEditor      Action = "open-editor"
+	ActionRunAction       Action = "run-action"
+	ActionUppercaseSelect Action = "uppercase-select"
+	ActionClearQuery      Action = "clear-query"
+	ActionTogglePreview   Action = "toggle-preview"
+	ActionToggleColumns   Action = "toggle-columns"
+	ActionFilterPatterns  Action = "filter-patterns"
+	ActionNextHintPage    Action = "next-hint-page"
+	ActionUndoSelection   Action = "undo-selection"
+	ActionSelectAll       Action = "select-all"
+	ActionSelectPattern   Action = "select-pattern"
+	ActionSearch          Action = "search"
+)
+
+var knownActions = []Action{
+	ActionQuit,
+	ActionConfirm,
+	ActionToggleMulti,
+	ActionUp,
+	ActionDown,
+	ActionScrollUp,
+	ActionScrollDown,
+	ActionPageUp,
+	ActionPageDown,
+	ActionOpenEditor,
+	ActionRunAction,
+	ActionUppercaseSelect,
+	ActionClearQuery,
+	ActionTogglePreview,
+	ActionToggleColumns,
+	ActionFilterPatterns,
+	ActionNextHintPage,
+	ActionUndoSelection,
+	ActionSelectAll,
+	ActionSelectPattern,
+	ActionSearch,
+}
+
+// Key identifies a single key press, Rune is only set when Code is tcell.KeyRune
+type Key struct {
+	Code tcell.Key
+	Rune rune
+}
+
+var namedKeys = map[string]Key{
+	"esc":       {Code: tcell.KeyEscape},
+	"escape":    {Code: tcell.KeyEscape},
+	"enter":     {Code: tcell.KeyEnter},
+	"return":    {Code: tcell.KeyEnter},
+	"tab":       {Code: tcell.KeyTab},
+	"backspace": {Code: tcell.KeyBackspace2},
+	"space":     {Code: tcell.KeyRune, Rune: ' '},
+	"up":        {Code: tcell.KeyUp},
+	"down":      {Code: tcell.KeyDown},
+	"left":      {Code: tcell.KeyLeft},
+	"right":     {Code: tcell.KeyRight},
+	"pgup":      {Code: tcell.KeyPgUp},
+	"pageup":    {Code: tcell.KeyPgUp},
+	"pgdn":      {Code: tcell.KeyPgDn},
+	"pagedown":  {Code: tcell.KeyPgDn},
+}
+
+// ParseKey parses a key name such as "q", "space", "esc", "pgdn" or "ctrl-d"
+func ParseKey(name string) (Key, error) {
+	if utf8.RuneCountInString(name) == 1 {
+		r, _ := utf8.DecodeRuneInString(name)
+		return Key{Code: tcell.KeyRune, Rune: r}, nil
+	}
+
+	lower := strings.ToLower(name)
+	if key, ok := namedKeys[lower]; ok {
+		return key, nil
+	}
+
+	if letter, ok := strings.CutPrefix(lower, "ctrl-"); ok && len(letter) == 1 && letter[0] >= 'a' && letter[0] <= 'z' {
+		return Key{Code: tcell.KeyCtrlA + tcell.Key(letter[0]-'a')}, nil
+	}
+
+	return Key{}, fmt.Errorf("unknown key %q", name)
+}
+
+// KeyFromEvent converts a tcell key event into a Key
+func KeyFromEvent(ev *tcell.EventKey) Key {
+	if ev.Key() == tcell.KeyRune {
+		return Key{Code: tcell.KeyRune, Rune: ev.Rune()}
+	}
+	// Terminals report backspace as either BS or DEL
+	if ev.Key() == tcell.KeyBackspace {
+		return Key{Code: tcell.KeyBackspace2}
+	}
+	return Key{Code: ev.Key()}
+}
+
+// sequence returns the bytes a terminal in raw mode sends for the key
+func (k Key) sequence() []byte {
+	switch k.Code {
+	case tcell.KeyRune:
+		return []byte(string(k.Rune))
+	case tcell.KeyUp:
+		return []byte("\x1b[A")
+	case tcell.KeyDown:
+		return []byte("\x1b[B")
+	case tcell.KeyRight:
+		return []byte("\x1b[C")
+	case tcell.KeyLeft:
+		return []byte("\x1b[D")
+	case tcell.KeyPgUp:
+		return []byte("\x1b[5~")
+	case tcell.KeyPgDn:
+		return []byte("\x1b[6~")
+	case tcell.KeyBackspace2:
+		return []byte{del}
+	default:
+		// Control keys, tab, enter and escape map to their ASCII codes
+		return []byte{byte(k.Code)}
+	}
+}
+
+// KeyBindings maps actions to the keys that trigger them
+type KeyBindings map[Action][]Key
+
+// ParseKeyBindings parses a mapping of action names to key names, as found
+// in the `[keys]` section of the config file
+func ParseKeyBindings(raw map[string][]string) (KeyBindings, error) {
+	bindings := make(KeyBindings, len(raw))
+	for name, keyNames := range raw {
+		action := Action(name)
+		if !isKnownAction(action) {
+			return nil, fmt.Errorf("unknown action %q", name)
+		}
+
+		keys := make([]Key, 0, len(keyNames))
+		for _, keyName := range keyNames {
+			key, err := ParseKey(keyName)
+			if err != nil {
+				return nil, fmt.Errorf("parsing keys for %q: %w", name, err)
+			}
+			keys = append(keys, key)
+		}
+		bindings[action] = keys
+	}
+	return bindings, nil
+}
+
+func isKnownAction(action Action) bool {
+	return slices.Contains(knownActions, action)
+}
+
+// mustParseKeys is used to declare the default bindings
+func mustParseKeys(names ...string) []Key {
+	keys := make([]Key, len(names))
+	for i, name := range names {
+		key, err := ParseKey(name)
+		if err != nil {
+			panic(err)
+		}
+		keys[i] = key
+	}
+	return keys
+}
+
+// DefaultViewKeyBindings returns the default bindings of the full screen view
+func DefaultViewKeyBindings() KeyBindings {
+	return KeyBindings{
+		ActionQuit:           mustParseKeys("esc", "ctrl-c"),
+		ActionConfirm:        mustParseKeys("enter"),
+		ActionToggleMulti:    mustParseKeys("space"),
+		ActionUp:             mustParseKeys("up", "left"),
+		ActionDown:           mustParseKeys("down", "right"),
+		ActionScrollUp:       mustParseKeys("ctrl-u"),
+		ActionScrollDown:     mustParseKeys("ctrl-d"),
+		ActionPageUp:         mustParseKeys("pgup"),
+		ActionPageDown:       mustParseKeys("pgdn"),
+		ActionRunAction:      mustParseKeys("ctrl-g"),
+		ActionToggleColumns:  mustParseKeys("ctrl-t"),
+		ActionFilterPatterns: mustParseKeys("tab"),
+		ActionNextHintPage:   mustParseKeys("ctrl-n"),
+		ActionSelectAll:      mustParseKeys("ctrl-a"),
+		ActionSelectPattern:  mustParseKeys("ctrl-p"),
+		ActionSearch:         mustParseKeys("/"),
+	}
+}
+
+// DefaultListKeyBindings returns the default bindings of the list view
+func DefaultListKeyBindings() KeyBindings {
+	return KeyBindings{
+		ActionQuit:          mustParseKeys("esc", "ctrl-c"),
+		ActionConfirm:       mustParseKeys("enter"),
+		ActionToggleMulti:   mustParseKeys("tab"),
+		ActionUp:            mustParseKeys("up", "ctrl-p", "ctrl-k"),
+		ActionDown:          mustParseKeys("down", "ctrl-n", "ctrl-j"),
+		ActionClearQuery:    mustParseKeys("ctrl-u"),
+		ActionTogglePreview: mustParseKeys("ctrl-v"),
+	}
+}
+
+// Override replaces the keys of every action present in other, keys bound
+// by other are released from the actions they were previously bound to
+func (b KeyBindings) Override(other KeyBindings) KeyBindings {
+	for _, keys := range other {
+		for _, key := range keys {
+			for action, bound := range b {
+				b[action] = slices.DeleteFunc(bound, func(k Key) bool { return k == key })
+			}
+		}
+	}
+	for action, keys := range other {
+		b[action] = keys
+	}
+	return b
+}
+
+// Lookup returns the action bound to key
+func (b KeyBindings) Lookup(key Key) (Action, bool) {
+	for action, keys := range b {
+		for _, k := range keys {
+			if k == key {
+				return action, true
+			}
+		}
+	}
+	return "", false
+}
+
+// LookupSequence returns the action bound to the raw terminal input seq
+func (b KeyBindings) LookupSequence(seq []byte) (Action, bool) {
+	for action, keys := range b {
+		for _, k := range keys {
+			if string(k.sequence()) == string(seq) {
+				return action, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,89 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Key
+		wantErr bool
+	}{
+		{name: "q", want: Key{Code: tcell.KeyRune, Rune: 'q'}},
+		{name: "ö", want: Key{Code: tcell.KeyRune, Rune: 'ö'}},
+		{name: "space", want: Key{Code: tcell.KeyRune, Rune: ' '}},
+		{name: "Esc", want: Key{Code: tcell.KeyEscape}},
+		{name: "pgdn", want: Key{Code: tcell.KeyPgDn}},
+		{name: "ctrl-d", want: Key{Code: tcell.KeyCtrlD}},
+		{name: "ctrl-", wantErr: true},
+		{name: "hyper-x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseKey(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseKeyBindings(t *testing.T) {
+	if _, err := ParseKeyBindings(map[string][]string{"explode": {"x"}}); err == nil {
+		t.Error("Expected error for unknown action")
+	}
+
+	if _, err := ParseKeyBindings(map[string][]string{"quit": {"nope"}}); err == nil {
+		t.Error("Expected error for unknown key")
+	}
+
+	bindings, err := ParseKeyBindings(map[string][]string{"scroll-up": {"ctrl-d"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	keys := DefaultViewKeyBindings().Override(bindings)
+	action, ok := keys.Lookup(Key{Code: tcell.KeyCtrlD})
+	if !ok || action != ActionScrollUp {
+		t.Errorf("Expected ctrl-d to be rebound to scroll-up, got %q", action)
+	}
+	if _, ok := keys.Lookup(Key{Code: tcell.KeyCtrlU}); ok {
+		t.Error("Expected ctrl-u to be unbound")
+	}
+	if action, _ := keys.Lookup(Key{Code: tcell.KeyRune, Rune: ' '}); action != ActionToggleMulti {
+		t.Errorf("Expected space to keep its default binding, got %q", action)
+	}
+}
+
+func TestKeyBindingsLookupSequence(t *testing.T) {
+	keys := DefaultListKeyBindings()
+
+	tests := []struct {
+		seq  string
+		want Action
+	}{
+		{seq: "\x1b", want: ActionQuit},
+		{seq: "\r", want: ActionConfirm},
+		{seq: "\t", want: ActionToggleMulti},
+		{seq: "\x1b[A", want: ActionUp},
+		{seq: "\x0e", want: ActionDown},
+		{seq: "\x15", want: ActionClearQuery},
+	}
+
+	for _, tt := range tests {
+		if got, _ := keys.LookupSequence([]byte(tt.seq)); got != tt.want {
+			t.Errorf("LookupSequence(%q) = %q, want %q", tt.seq, got, tt.want)
+		}
+	}
+
+	if _, ok := keys.LookupSequence([]byte("a")); ok {
+		t.Error("Expected printable characters to be unbound")
+	}
+}
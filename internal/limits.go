@@ -0,0 +1,37 @@
+package internal
+
+import "log/slog"
+
+// WithMaxMatches keeps at most max matches, the regex matches of the first
+// lines first. Zero or less keeps every match
+func WithMaxMatches(max int) Option {
+	return optionFunc(func(s *State) {
+		s.MaxMatches = max
+	})
+}
+
+// WithTruncatedInput marks the text as cut short by the caller, such as the
+// input read up to a line limit, so that the views warn about it
+func WithTruncatedInput() Option {
+	return optionFunc(func(s *State) {
+		s.Truncated = true
+	})
+}
+
+// matchLimitReached reports whether matches already exceed the match limit,
+// so that looking for more is pointless
+func (s *State) matchLimitReached(matches []Match) bool {
+	return s.MaxMatches > 0 && len(matches) > s.MaxMatches
+}
+
+// limitMatches drops the matches beyond the match limit and marks the state
+// truncated when some were dropped
+func (s *State) limitMatches(matches []Match) []Match {
+	if !s.matchLimitReached(matches) {
+		return matches
+	}
+
+	slog.Warn("Too many matches, dropping the last ones", "matches_count", len(matches), "max_matches", s.MaxMatches)
+	s.Truncated = true
+	return matches[:s.MaxMatches]
+}
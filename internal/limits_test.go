@@ -0,0 +1,47 @@
+package internal
+
+import "testing"
+
+func TestMaxMatches(t *testing.T) {
+	lines := []string{"10.0.0.1 10.0.0.2", "10.0.0.3", "10.0.0.4"}
+
+	tests := []struct {
+		name          string
+		max           int
+		want          int
+		wantTruncated bool
+	}{
+		{name: "no limit", max: 0, want: 4},
+		{name: "under the limit", max: 4, want: 4},
+		{name: "over the limit", max: 2, want: 2, wantTruncated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := NewStateFromLines(lines, "abcd", []string{}, WithMaxMatches(tt.max))
+			matches := state.Matches(false, 0)
+			if len(matches) != tt.want {
+				t.Fatalf("Expected %d matches, got %d", tt.want, len(matches))
+			}
+			if state.Truncated != tt.wantTruncated {
+				t.Errorf("Expected truncated %v, got %v", tt.wantTruncated, state.Truncated)
+			}
+			if matches[0].Text != "10.0.0.1" {
+				t.Errorf("Expected first match 10.0.0.1, got %q", matches[0].Text)
+			}
+			for _, match := range matches {
+				if match.Hint == nil {
+					t.Errorf("Expected match %q to have a hint", match.Text)
+				}
+			}
+		})
+	}
+}
+
+func TestTruncatedInput(t *testing.T) {
+	state := NewStateFromLines([]string{"10.0.0.1"}, "abcd", []string{}, WithTruncatedInput())
+	state.Matches(false, 0)
+	if !state.Truncated {
+		t.Error("Expected truncated input to stay truncated")
+	}
+}
@@ -2,14 +2,19 @@ package internal
 
 import (
 	"fmt"
-	"log/slog"
 	"os"
+	"os/signal"
 	"regexp"
 	"strconv"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
+	"github.com/Hanaasagi/magonote/internal/logger"
 	fz "github.com/Hanaasagi/magonote/pkg/fuzzymatch"
 	"github.com/fatih/color"
+	"github.com/mattn/go-runewidth"
 	"golang.org/x/term"
 )
 
@@ -18,25 +23,19 @@ const (
 	defaultMaxVisibleItems = 10
 	defaultWidth           = 80
 	defaultHeight          = 24
+	previewHeight          = 1 // Rows reserved for the preview pane
 
-	// Control characters
-	ctrlC = 3   // Ctrl+C
-	esc   = 27  // ESC
-	del   = 127 // Backspace/Delete
-	bs    = 8   // Backspace
-	enter = 13  // Enter
-	ctrlU = 21  // Ctrl+U (clear input)
-	ctrlP = 16  // Ctrl+P (up)
-	ctrlN = 14  // Ctrl+N (down)
-	ctrlJ = 10  // Ctrl+J (down)
-	ctrlK = 11  // Ctrl+K (up)
-	tab   = 9   // Tab
+	// Control characters, everything else is configured by key bindings
+	esc = 27  // ESC
+	del = 127 // Backspace/Delete
+	bs  = 8   // Backspace
 )
 
 // ListView represents a direct terminal-based dropdown selector
 type ListView struct {
 	// Core state
 	state           *State
+	matches         []Match
 	candidates      []string
 	filteredMatches []fz.FuzzyMatch
 	selectedIndex   int
@@ -45,10 +44,20 @@ type ListView struct {
 	fuzzyMatcher    *fz.FuzzyMatcher
 	multi           bool
 	chosen          []ChosenMatch
+	keys            KeyBindings
+	preview         bool // Show the line of the highlighted match
+
+	// Vim keys move with j/k/gg/G and choose a row by its number, typing
+	// only filters the list while searching after /
+	vimKeys   bool
+	searching bool
+	count     int  // Row number typed before enter, tab, a motion or G
+	pendingG  bool // First g of gg typed
 
 	// Display configuration
 	maxVisibleItems    int
 	originalTotalWidth int // Width based on original total count for consistent layout
+	promptWidth        int // Columns of the prompt line, where the cursor is left
 
 	// Terminal state
 	originalState *term.State
@@ -60,6 +69,7 @@ type ListView struct {
 	// Terminal I/O
 	ttyin  *os.File
 	ttyout *os.File
+	mu     sync.Mutex // Held while handling a key or a resize and rendering
 
 	// Colors
 	colors ViewColors
@@ -68,6 +78,8 @@ type ListView struct {
 	selectColor *color.Color
 	chosenColor *color.Color
 	normalColor *color.Color
+
+	err error // Panic recovered by Present
 }
 
 // NewListView creates a new direct terminal ListView instance
@@ -82,7 +94,13 @@ func NewListView(
 	backgroundColor Color,
 	hintForegroundColor Color,
 	hintBackgroundColor Color,
+	opts ...ViewOption,
 ) *ListView {
+	options := &viewOptions{}
+	for _, opt := range opts {
+		opt.apply(options)
+	}
+
 	// Extract candidate texts from matches
 	matches := state.Matches(false, 2) // list view should only show unique matches
 	candidates := make([]string, len(matches))
@@ -92,6 +110,7 @@ func NewListView(
 
 	lv := &ListView{
 		state:              state,
+		matches:            matches,
 		candidates:         candidates,
 		filteredMatches:    []fz.FuzzyMatch{},
 		selectedIndex:      0,
@@ -101,6 +120,8 @@ func NewListView(
 		maxVisibleItems:    defaultMaxVisibleItems,
 		multi:              multi,
 		chosen:             make([]ChosenMatch, 0),
+		keys:               DefaultListKeyBindings().Override(options.keys),
+		vimKeys:            options.vimKeys,
 		originalTotalWidth: len(fmt.Sprintf("%d", len(candidates))),
 		colors: ViewColors{
 			selectForeground: selectForegroundColor,
@@ -111,6 +132,7 @@ func NewListView(
 			background:       backgroundColor,
 			hintForeground:   hintForegroundColor,
 			hintBackground:   hintBackgroundColor,
+			patterns:         options.patternColors,
 		},
 		selectColor: color.New(color.BgCyan, color.FgBlack),
 		chosenColor: color.New(color.FgGreen, color.Bold),
@@ -166,6 +188,40 @@ func (lv *ListView) getTerminalSize() error {
 	return err
 }
 
+// resize fits the popup in the new size of the terminal, render moves it up
+// when it no longer fits below its row
+func (lv *ListView) resize(width, height int) {
+	lv.width, lv.height = width, height
+	lv.startRow = min(lv.startRow, max(height-1, 0))
+}
+
+// watchResize redraws the list whenever the terminal is resized, until stop
+// is closed
+func (lv *ListView) watchResize(stop <-chan struct{}) {
+	defer recoverView("listview", &lv.err)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGWINCH)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-signals:
+		case <-stop:
+			return
+		}
+
+		width, height, err := term.GetSize(int(lv.ttyin.Fd()))
+		if err != nil {
+			continue
+		}
+		lv.mu.Lock()
+		lv.resize(width, height)
+		lv.render()
+		lv.mu.Unlock()
+	}
+}
+
 // setDefaultSize sets fallback terminal dimensions
 func (lv *ListView) setDefaultSize() {
 	lv.width = defaultWidth
@@ -233,6 +289,23 @@ func (lv *ListView) writeColored(text string, selected bool, chosen bool) {
 	}
 }
 
+// rowLabel returns the row number of a match, which stands for its hint,
+// in the colors configured for its pattern
+func (lv *ListView) rowLabel(mat *Match, row int) string {
+	label := fmt.Sprintf("%*d", lv.originalTotalWidth, row)
+	pc, ok := lv.colors.patterns[mat.Pattern]
+	if !ok {
+		return label
+	}
+	if pc.Foreground != nil {
+		label = pc.Foreground.FgString(label)
+	}
+	if pc.Background != nil {
+		label = pc.Background.BgString(label)
+	}
+	return label
+}
+
 // moveCursor moves cursor to specific position
 func (lv *ListView) moveCursor(row, col int) {
 	lv.write(fmt.Sprintf("\x1b[%d;%dH", row+1, col+1)) // Convert to 1-based
@@ -341,6 +414,18 @@ func (lv *ListView) moveDown() {
 	}
 }
 
+// moveTo selects the row-th item of the list, 1-based, or the last one
+func (lv *ListView) moveTo(row int) {
+	lv.selectedIndex = min(row, len(lv.filteredMatches)) - 1
+	lv.constrainSelection()
+}
+
+// moveBy moves selection by delta items, stopping at both ends
+func (lv *ListView) moveBy(delta int) {
+	lv.selectedIndex += delta
+	lv.constrainSelection()
+}
+
 // clearQuery clears the search query
 func (lv *ListView) clearQuery() {
 	lv.query = ""
@@ -365,6 +450,9 @@ func (lv *ListView) backspaceQuery() {
 func (lv *ListView) calculateDisplayMetrics() (visibleCount, totalLines int) {
 	visibleCount = min(lv.maxVisibleItems, len(lv.filteredMatches))
 	totalLines = visibleCount + 1 // +1 for prompt
+	if lv.preview {
+		totalLines += previewHeight
+	}
 	return
 }
 
@@ -394,8 +482,20 @@ func (lv *ListView) renderPrompt() {
 			lv.originalTotalWidth, 0)
 	}
 
-	promptText := fmt.Sprintf("%s > %s", counterText, lv.query)
+	if lv.state.Truncated {
+		counterText += " " + truncationIndicator
+	}
+
+	marker := ">"
+	if lv.searching {
+		marker = "/"
+	}
+	promptText := fmt.Sprintf("%s %s %s", counterText, marker, lv.query)
+	if lv.count > 0 {
+		promptText += fmt.Sprintf(" :%d", lv.count)
+	}
 	lv.write(promptText)
+	lv.promptWidth = runewidth.StringWidth(promptText)
 }
 
 // createChosenMap creates a map for quick lookup of chosen items
@@ -422,14 +522,14 @@ func (lv *ListView) renderMatches(visibleCount int) {
 
 		// Determine item state
 		isSelected := matchIndex == lv.selectedIndex
-		isChosen := chosenMap[match.Text]
+		isChosen := chosenMap[lv.matches[match.Original].Value()]
 
-		lv.renderSingleMatch(match, isSelected, isChosen)
+		lv.renderSingleMatch(match, matchIndex+1, isSelected, isChosen)
 	}
 }
 
-// renderSingleMatch renders a single match item
-func (lv *ListView) renderSingleMatch(match fz.FuzzyMatch, selected, chosen bool) {
+// renderSingleMatch renders a single match item, prefixed with its row number
+func (lv *ListView) renderSingleMatch(match fz.FuzzyMatch, row int, selected, chosen bool) {
 	// Render indicator
 	var indicator string
 	if selected {
@@ -438,25 +538,102 @@ func (lv *ListView) renderSingleMatch(match fz.FuzzyMatch, selected, chosen bool
 		indicator = "   "
 	}
 
-	// Truncate text if too long
-	text := match.Text
-	maxTextWidth := lv.width - len(indicator)
+	// Truncate text if too long, the line breaks of multi-line matches
+	// being shown as ⏎
+	original := lv.matches[match.Original]
+	text := strings.ReplaceAll(lv.state.maskSecrets(original.Y, original.X, match.Text), "\n", "⏎")
+	maxTextWidth := lv.width - len(indicator) - lv.originalTotalWidth - 1
 	if len(text) > maxTextWidth {
 		text = text[:maxTextWidth-3] + "..."
 	}
 
-	// Write indicator without color
-	lv.write(indicator)
+	// Write indicator without color, the row number in the hint colors of
+	// the pattern
+	lv.write(indicator + lv.rowLabel(&original, row) + " ")
 
 	// Write text with appropriate coloring
 	lv.writeColored(text, selected, chosen)
 }
 
-// positionCursor positions cursor in the search box
+// renderPreview renders the line containing the highlighted match below the
+// list, with the match underlined
+func (lv *ListView) renderPreview(visibleCount int) {
+	if !lv.preview || lv.selectedIndex >= len(lv.filteredMatches) {
+		return
+	}
+
+	mat := lv.matches[lv.filteredMatches[lv.selectedIndex].Original]
+	prefix := fmt.Sprintf("   %d: ", mat.Y+1)
+	lv.moveCursor(lv.startRow+1+visibleCount, 0)
+	lv.write(prefix)
+	lv.write(previewText(lv.state.maskSecrets(mat.Y, 0, lv.state.Lines[mat.Y]), mat.X, len(mat.Text), lv.width-len(prefix)))
+}
+
+// previewText returns line with the match at byte offset x underlined,
+// cropped around the match to fit in width columns
+func previewText(line string, x, length, width int) string {
+	// The line is cropped between grapheme clusters, so that emoji
+	// sequences and combining marks are never split
+	var clusters []string
+	var widths []int
+	start, end, offset := 0, 0, 0
+	for cluster, w := range graphemes(line) {
+		if offset < x {
+			start++
+		}
+		if offset < x+length {
+			end++
+		}
+		clusters = append(clusters, cluster)
+		widths = append(widths, w)
+		offset += len(cluster)
+	}
+	join := func(from, to int) string {
+		return strings.Join(clusters[from:to], "")
+	}
+
+	lo, hi := 0, len(clusters)
+	if runewidth.StringWidth(line) > width {
+		// Leave room for the ellipses on both sides
+		avail := max(width-2, 0)
+		context := max(avail-runewidth.StringWidth(join(start, end)), 0) / 2
+
+		lo = start
+		for used := 0; lo > 0; lo-- {
+			used += widths[lo-1]
+			if used > context {
+				break
+			}
+		}
+		hi = lo
+		for used := 0; hi < len(clusters); hi++ {
+			used += widths[hi]
+			if used > avail {
+				break
+			}
+		}
+	}
+
+	var text string
+	if lo > 0 {
+		text += "…"
+	}
+	text += join(lo, max(lo, min(start, hi)))
+	if start < hi {
+		text += "\x1b[4m" + join(max(start, lo), min(end, hi)) + "\x1b[24m"
+	}
+	if end < hi {
+		text += join(end, hi)
+	}
+	if hi < len(clusters) {
+		text += "…"
+	}
+	return text
+}
+
+// positionCursor positions cursor at the end of the prompt
 func (lv *ListView) positionCursor() {
-	counterLen := len(fmt.Sprintf("[ %*d/%-*d ]",
-		lv.originalTotalWidth, 0, lv.originalTotalWidth, 0))
-	lv.moveCursor(lv.startRow, counterLen+3+len(lv.query)) // +3 for " > "
+	lv.moveCursor(lv.startRow, lv.promptWidth)
 }
 
 // render renders the complete popup interface
@@ -466,19 +643,52 @@ func (lv *ListView) render() {
 	lv.clearPopupArea(lv.height)
 	lv.renderPrompt()
 	lv.renderMatches(visibleCount)
+	lv.renderPreview(visibleCount)
 	lv.positionCursor()
 }
 
-// handleArrowKeys handles arrow key sequences
-func (lv *ListView) handleArrowKeys(buf []byte) bool {
-	if len(buf) >= 3 && buf[0] == esc && buf[1] == 91 { // ESC [
-		switch buf[2] {
-		case 65: // Up arrow
-			lv.moveUp()
-		case 66: // Down arrow
-			lv.moveDown()
-		case 67, 68: // Right/Left arrow (ignore)
-			// Do nothing
+// handleAction performs a bound action, it returns true when the list should exit
+func (lv *ListView) handleAction(action Action) bool {
+	if lv.searching {
+		// Leave the search, keeping the filter on enter and clearing it on quit
+		switch action {
+		case ActionQuit:
+			lv.searching = false
+			lv.clearQuery()
+			return false
+		case ActionConfirm:
+			lv.searching = false
+			return false
+		}
+	}
+
+	switch action {
+	case ActionQuit:
+		return true
+	case ActionConfirm:
+		return lv.selectCurrentItem()
+	case ActionClearQuery:
+		lv.clearQuery()
+	case ActionTogglePreview:
+		lv.preview = !lv.preview
+	case ActionUp:
+		lv.moveUp()
+	case ActionDown:
+		lv.moveDown()
+	case ActionToggleMulti:
+		if lv.multi {
+			lv.selectCurrentItem()
+		}
+	}
+	return false
+}
+
+// handleEscapeSequence handles unbound escape sequences
+func (lv *ListView) handleEscapeSequence(seq []byte) bool {
+	if len(seq) >= 3 && seq[0] == esc && seq[1] == 91 { // ESC [
+		switch seq[2] {
+		case 65, 66, 67, 68: // Unbound arrow keys (ignore)
+			return false
 		default:
 			// Unknown escape sequence, treat as ESC
 			return true
@@ -487,26 +697,48 @@ func (lv *ListView) handleArrowKeys(buf []byte) bool {
 	return false
 }
 
-// handleControlChars handles control character sequences
+// handleVimKey handles the keys of vim navigation outside of a search, it
+// returns false for other keys
+func (lv *ListView) handleVimKey(ch byte) bool {
+	switch {
+	case ch >= '1' && ch <= '9', ch == '0' && lv.count > 0:
+		// Bounded so that a long number can't overflow
+		if lv.count < len(lv.filteredMatches) {
+			lv.count = lv.count*10 + int(ch-'0')
+		}
+		return true
+	case ch == 'g' && !lv.pendingG:
+		lv.pendingG = true
+		return true
+	case ch == 'g':
+		lv.moveTo(max(lv.count, 1))
+	case ch == 'G':
+		if lv.count > 0 {
+			lv.moveTo(lv.count)
+		} else {
+			lv.moveTo(len(lv.filteredMatches))
+		}
+	case ch == 'j':
+		lv.moveBy(max(lv.count, 1))
+	case ch == 'k':
+		lv.moveBy(-max(lv.count, 1))
+	case ch == '/':
+		lv.searching = true
+	default:
+		return false
+	}
+	lv.count, lv.pendingG = 0, false
+	return true
+}
+
+// handleControlChars handles unbound single byte input
 func (lv *ListView) handleControlChars(ch byte) bool {
+	if lv.vimKeys && !lv.searching {
+		return false
+	}
 	switch ch {
-	case ctrlC, esc:
-		return true // Exit
 	case del, bs:
 		lv.backspaceQuery()
-	case enter:
-		return lv.selectCurrentItem()
-	case ctrlU:
-		lv.clearQuery()
-	case ctrlP, ctrlK:
-		lv.moveUp()
-	case ctrlN, ctrlJ:
-		lv.moveDown()
-	case tab:
-		if lv.multi {
-			lv.selectCurrentItem()
-			return false
-		}
 	default:
 		if ch >= 32 && ch < 127 { // Printable ASCII
 			lv.appendToQuery(ch)
@@ -523,16 +755,48 @@ func (lv *ListView) handleInput() bool {
 		return false
 	}
 
-	// Handle escape sequences (like arrow keys)
-	if n >= 3 {
-		if lv.handleArrowKeys(buf) {
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+	if buf[0] == esc {
+		return lv.handleSequence(buf[:n])
+	}
+
+	// Keys typed faster than they are read come together
+	for i := range n {
+		if lv.handleSequence(buf[i : i+1]) {
 			return true
 		}
 	}
+	return false
+}
+
+// handleSequence handles the bytes of a key press, it returns true when the
+// list should exit
+func (lv *ListView) handleSequence(seq []byte) bool {
+	n := len(seq)
+	if lv.vimKeys && !lv.searching && n == 1 && lv.handleVimKey(seq[0]) {
+		return false
+	}
+
+	// A row number typed before choosing picks that row
+	count := lv.count
+	lv.count, lv.pendingG = 0, false
+
+	if action, ok := lv.keys.LookupSequence(seq); ok {
+		if count > 0 && !lv.searching && (action == ActionConfirm || action == ActionToggleMulti) {
+			lv.moveTo(count)
+		}
+		return lv.handleAction(action)
+	}
+
+	// Handle escape sequences (like arrow keys)
+	if n >= 3 {
+		return lv.handleEscapeSequence(seq)
+	}
 
 	// Handle single characters
 	if n == 1 {
-		return lv.handleControlChars(buf[0])
+		return lv.handleControlChars(seq[0])
 	}
 
 	return false
@@ -542,11 +806,7 @@ func (lv *ListView) handleInput() bool {
 func (lv *ListView) selectCurrentItem() bool {
 	if lv.selectedIndex < len(lv.filteredMatches) {
 		match := lv.filteredMatches[lv.selectedIndex]
-		lv.chosen = append(lv.chosen, ChosenMatch{
-			Text:           match.Text,
-			Uppercase:      false,
-			ShouldOpenFile: false,
-		})
+		lv.chosen = append(lv.chosen, newChosenMatch(lv.state, lv.matches[match.Original], match.Original))
 
 		if !lv.multi {
 			return true // Exit after single selection
@@ -559,19 +819,16 @@ func (lv *ListView) selectCurrentItem() bool {
 func (lv *ListView) getDefaultSelection() []ChosenMatch {
 	if len(lv.chosen) == 0 && len(lv.filteredMatches) > 0 {
 		match := lv.filteredMatches[lv.selectedIndex]
-		return []ChosenMatch{
-			{
-				Text:           match.Text,
-				Uppercase:      false,
-				ShouldOpenFile: false,
-			},
-		}
+		return []ChosenMatch{newChosenMatch(lv.state, lv.matches[match.Original], match.Original)}
 	}
 	return lv.chosen
 }
 
-// Present displays the list interface and returns chosen matches
-func (lv *ListView) Present() []ChosenMatch {
+// Present displays the list interface and returns chosen matches. A panic
+// of the list is recovered once the terminal is restored and reported by Err
+func (lv *ListView) Present() (chosen []ChosenMatch) {
+	defer recoverView("listview", &lv.err)
+
 	if len(lv.candidates) == 0 {
 		return []ChosenMatch{}
 	}
@@ -591,20 +848,30 @@ func (lv *ListView) Present() []ChosenMatch {
 	// Ensure initial state is properly constrained
 	lv.constrainSelection()
 
-	// Make space for our popup
+	// Make space for our popup, including the preview pane it may show
 	_, totalLines := lv.calculateDisplayMetrics()
+	totalLines += previewHeight
 	lv.makeSpace(totalLines)
 
-	firstRenderStart := time.Now()
+	span := logger.StartSpan("listview first render")
 	lv.render()
-	firstRenderDuration := time.Since(firstRenderStart)
-	slog.Info("listview first render completed", "duration_ms", firstRenderDuration.Milliseconds())
+	span.End()
+
+	stop, resized := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(resized)
+		lv.watchResize(stop)
+	}()
 
 	// Main event loop
 	for !lv.handleInput() {
+		lv.mu.Lock()
 		lv.render()
+		lv.mu.Unlock()
 		time.Sleep(time.Millisecond * 10)
 	}
+	close(stop)
+	<-resized
 
 	// Clear our popup area
 	lv.clearPopupArea(totalLines)
@@ -613,3 +880,8 @@ func (lv *ListView) Present() []ChosenMatch {
 
 	return lv.getDefaultSelection()
 }
+
+// Err returns the panic recovered by Present, if any
+func (lv *ListView) Err() error {
+	return lv.err
+}
@@ -0,0 +1,150 @@
+package internal
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPreviewText(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		x      int
+		length int
+		width  int
+		want   string
+	}{
+		{
+			name:   "whole line",
+			line:   "see /tmp/a.txt here",
+			x:      4,
+			length: 10,
+			width:  40,
+			want:   "see \x1b[4m/tmp/a.txt\x1b[24m here",
+		},
+		{
+			name:   "cropped around the match",
+			line:   "0123456789 /tmp/a.txt 0123456789",
+			x:      11,
+			length: 10,
+			width:  16,
+			want:   "…9 \x1b[4m/tmp/a.txt\x1b[24m 0…",
+		},
+		{
+			name:   "match at the start",
+			line:   "/tmp/a.txt 0123456789",
+			x:      0,
+			length: 10,
+			width:  14,
+			want:   "\x1b[4m/tmp/a.txt\x1b[24m 0…",
+		},
+		{
+			name:   "wide characters",
+			line:   "日本 /tmp/a.txt",
+			x:      7,
+			length: 10,
+			width:  40,
+			want:   "日本 \x1b[4m/tmp/a.txt\x1b[24m",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := previewText(tt.line, tt.x, tt.length, tt.width)
+			if got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestListViewVimKeys(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 12; i++ {
+		lines = append(lines, fmt.Sprintf("host 10.0.0.%d", i))
+	}
+
+	tests := []struct {
+		name     string
+		keys     string
+		expected int // Selected index, 0-based
+		query    string
+	}{
+		{"down", "jj", 2, ""},
+		{"down by a count", "3jk", 2, ""},
+		{"last row", "G", 11, ""},
+		{"row by number", "5G", 4, ""},
+		{"first row", "Ggg", 0, ""},
+		{"stops at the end", "20j", 11, ""},
+		{"search", "/10.0.0.12\r", 0, "10.0.0.12"},
+		{"search dropped", "/10.0.0.1\x1bj", 1, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := NewStateFromLines(lines, "abcd", []string{})
+			lv := NewListView(state, false, nil, nil, nil, nil, nil, nil, nil, nil, WithVimKeys())
+			lv.updateFilter()
+
+			for i := 0; i < len(tt.keys); i++ {
+				if lv.handleSequence([]byte{tt.keys[i]}) {
+					t.Fatalf("Expected the list to stay open after %q", tt.keys[:i+1])
+				}
+			}
+			if lv.selectedIndex != tt.expected {
+				t.Errorf("Expected index %d, got %d", tt.expected, lv.selectedIndex)
+			}
+			if lv.query != tt.query {
+				t.Errorf("Expected query %q, got %q", tt.query, lv.query)
+			}
+		})
+	}
+
+	t.Run("row number and enter", func(t *testing.T) {
+		state := NewStateFromLines(lines, "abcd", []string{})
+		lv := NewListView(state, false, nil, nil, nil, nil, nil, nil, nil, nil, WithVimKeys())
+		lv.updateFilter()
+
+		lv.handleSequence([]byte("1"))
+		lv.handleSequence([]byte("0"))
+		if !lv.handleSequence([]byte("\r")) {
+			t.Fatal("Expected enter to choose a row")
+		}
+		if len(lv.chosen) != 1 || lv.chosen[0].Text != "10.0.0.10" {
+			t.Errorf("Expected 10.0.0.10 to be chosen, got %v", lv.chosen)
+		}
+	})
+}
+
+func TestListViewRowLabelColors(t *testing.T) {
+	state := NewStateFromLines([]string{"see https://example.com in /tmp/x"}, "abcd", []string{})
+	lv := NewListView(state, false, nil, nil, nil, nil, nil, nil, nil, nil,
+		WithPatternColors(map[string]PatternColor{"url": {Foreground: GetColor("#0000ff"), Background: GetColor("#ffff00")}}))
+
+	url := Match{Pattern: "url"}
+	if got, expected := lv.rowLabel(&url, 1), "\x1b[48;2;255;255;0m\x1b[38;2;0;0;255m1\x1b[0m\x1b[0m"; got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+	path := Match{Pattern: "path"}
+	if got := lv.rowLabel(&path, 2); got != "2" {
+		t.Errorf("Expected the row number of other patterns uncolored, got %q", got)
+	}
+}
+
+func TestListViewResize(t *testing.T) {
+	lv := &ListView{width: 120, height: 40, startRow: 35}
+
+	lv.resize(80, 20)
+	if lv.width != 80 || lv.height != 20 {
+		t.Errorf("Expected the size 80x20, got %dx%d", lv.width, lv.height)
+	}
+	if lv.startRow != 19 {
+		t.Errorf("Expected the popup to start on the last row, got %d", lv.startRow)
+	}
+
+	// Rendering moves the popup up to fit
+	lv.ensureSpace(11)
+	if lv.startRow != 8 {
+		t.Errorf("Expected the popup to move up to row 8, got %d", lv.startRow)
+	}
+}
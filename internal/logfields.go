@@ -0,0 +1,44 @@
+package internal
+
+import (
+	"context"
+
+	"github.com/Hanaasagi/magonote/pkg/textdetection/logdetection"
+)
+
+// logPatterns are the pattern names of the fields of log lines per kind
+var logPatterns = map[logdetection.Kind]string{
+	logdetection.KindLevel:     "log_level",
+	logdetection.KindTimestamp: "log_timestamp",
+	logdetection.KindMessage:   "log_message",
+	logdetection.KindField:     "log_field",
+}
+
+// logMatches returns the fields of the log lines in scope, logfmt, JSON or
+// syslog, apart from their messages, and the messages
+func (s *State) logMatches(ctx context.Context) ([]Match, []Match, error) {
+	var fields, messages []Match
+	start, end := s.scopeLines()
+	for y := start; y < end; y++ {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		record, ok := logdetection.Detect(s.Lines[y])
+		if !ok {
+			continue
+		}
+		for _, field := range record.Fields {
+			name := logPatterns[field.Kind]
+			if field.Value == "" || s.tooShort(name, field.Value) {
+				continue
+			}
+			mat := Match{X: field.Start, Y: y, Pattern: name, Text: field.Value}
+			if field.Kind == logdetection.KindMessage {
+				messages = append(messages, mat)
+			} else {
+				fields = append(fields, mat)
+			}
+		}
+	}
+	return fields, messages, nil
+}
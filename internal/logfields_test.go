@@ -0,0 +1,33 @@
+package internal
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLogMatches(t *testing.T) {
+	lines := []string{
+		`time=2024-05-01T10:00:00Z level=info msg="served /index.html" status=200`,
+		`level=error msg="request failed"`,
+		"not a log line 10.0.0.1",
+	}
+
+	state := NewStateFromLines(lines, "abcd", []string{}, WithLogDetection())
+	var got []string
+	for _, mat := range state.Matches(false, 0) {
+		got = append(got, mat.Pattern+":"+mat.Text)
+	}
+
+	expected := []string{
+		"log_timestamp:2024-05-01T10:00:00Z",
+		"log_level:info",
+		"path:/index.html",
+		"log_field:200",
+		"log_level:error",
+		"log_message:request failed",
+		"ipv4:10.0.0.1",
+	}
+	if !slices.Equal(got, expected) {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
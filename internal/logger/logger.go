@@ -41,6 +41,7 @@ func InitLogger(path, level string) {
 	// slog defaults to logging in the order of time, level, msg, and other attributes.
 	handler := slog.NewTextHandler(logFile, &slog.HandlerOptions{Level: loglevel})
 
-	logger := slog.New(handler)
+	logger := slog.New(handler).With("run_id", RunID())
 	slog.SetDefault(logger)
+	startRecording()
 }
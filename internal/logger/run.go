@@ -0,0 +1,141 @@
+package logger
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// RunIDEnv passes the run ID to child processes, so that the logs of a
+// magonote-tmux invocation and of the magonote it starts share it
+const RunIDEnv = "MAGONOTE_RUN_ID"
+
+// Run is a single invocation with the timings of its spans
+type Run struct {
+	ID      string    `json:"id"`
+	Started time.Time `json:"started"`
+	Spans   []Span    `json:"spans"`
+}
+
+// Span is a timed step of a run
+type Span struct {
+	Name     string        `json:"name"`
+	Start    time.Duration `json:"start"` // Since the start of the run
+	Duration time.Duration `json:"duration"`
+}
+
+var current = struct {
+	sync.Mutex
+	run       Run
+	recording bool
+}{run: Run{ID: newRunID(), Started: time.Now()}}
+
+// newRunID returns the run ID given by the parent process, or a random one
+func newRunID() string {
+	if id := os.Getenv(RunIDEnv); id != "" {
+		return id
+	}
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// RunID returns the ID of the current run
+func RunID() string {
+	current.Lock()
+	defer current.Unlock()
+	return current.run.ID
+}
+
+// StartRun starts a new run with the given ID, or a random one, for
+// processes handling several invocations such as magonote serve
+func StartRun(id string) {
+	if id == "" {
+		id = newRunID()
+	}
+	current.Lock()
+	defer current.Unlock()
+	current.run = Run{ID: id, Started: time.Now()}
+}
+
+// startRecording records the spans of the run from now on, spans aren't
+// kept unless the logger is initialized so that library users don't pile
+// them up
+func startRecording() {
+	current.Lock()
+	defer current.Unlock()
+	current.recording = true
+}
+
+// ActiveSpan is a span being timed, see StartSpan
+type ActiveSpan struct {
+	name  string
+	start time.Time
+}
+
+// StartSpan starts timing a step of the run
+func StartSpan(name string) *ActiveSpan {
+	return &ActiveSpan{name: name, start: time.Now()}
+}
+
+// End logs the duration of the span along with attrs, as "<name> completed",
+// and records it in the run
+func (s *ActiveSpan) End(attrs ...any) {
+	duration := time.Since(s.start)
+	slog.Info(s.name+" completed", append([]any{"duration_ms", duration.Milliseconds()}, attrs...)...)
+
+	current.Lock()
+	defer current.Unlock()
+	if !current.recording {
+		return
+	}
+	current.run.Spans = append(current.run.Spans, Span{
+		Name:     s.name,
+		Start:    s.start.Sub(current.run.Started),
+		Duration: duration,
+	})
+}
+
+// CurrentRun returns a copy of the current run and its spans so far
+func CurrentRun() Run {
+	current.Lock()
+	defer current.Unlock()
+	run := current.run
+	run.Spans = slices.Clone(run.Spans)
+	return run
+}
+
+// SaveRun writes the current run to path, for `magonote debug last-run`
+func SaveRun(path string) error {
+	current.Lock()
+	data, err := json.MarshalIndent(current.run, "", "  ")
+	current.Unlock()
+	if err != nil {
+		return fmt.Errorf("encoding run: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("writing run: %w", err)
+	}
+	return nil
+}
+
+// LoadRun reads a run written by SaveRun
+func LoadRun(path string) (*Run, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading run: %w", err)
+	}
+
+	var run Run
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("decoding run: %w", err)
+	}
+	return &run, nil
+}
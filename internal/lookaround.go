@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"unicode/utf8"
+)
+
+// Lookaround rejects matches by the characters next to them, which Go's
+// regexp can't look at without making them part of the match. Each field is
+// a character class such as `[\w-]`, empty allows any character
+type Lookaround struct {
+	NotPrecededBy string // Class the character before a match can't be in
+	NotFollowedBy string // Class the character after a match can't be in
+}
+
+// builtinLookarounds are the lookarounds of the builtin patterns, keyed by
+// pattern name
+var builtinLookarounds = map[string]Lookaround{
+	// Hashes inside words, such as the ID of "webapp-editor-7fdbfbf4b-k68b7"
+	"sha": {NotPrecededBy: `[a-zA-Z0-9_-]`, NotFollowedBy: `[a-zA-Z0-9_-]`},
+	// Dashes inside words, such as those of "x--y"
+	"flag": {NotPrecededBy: `[a-zA-Z0-9_-]`},
+	// Groups of longer addresses, such as the end of "2001:db8:aa:bb:cc:dd:ee:ff"
+	"mac": {NotPrecededBy: `[\w:.-]`, NotFollowedBy: `[\w:-]`},
+	// Parts of longer tokens, such as the end of "v1.2.3.4" or "1:2:3:4:5:6:7:8:9"
+	"ipv4":      {NotPrecededBy: `[\w.]`, NotFollowedBy: `\w`},
+	"ipv4_port": {NotPrecededBy: `[\w.]`},
+	"ipv4_cidr": {NotPrecededBy: `[\w.]`, NotFollowedBy: `\w`},
+	"ipv6":      {NotPrecededBy: `[\w:.]`, NotFollowedBy: `\w`},
+	"ipv6_cidr": {NotPrecededBy: `[\w:.]`, NotFollowedBy: `\w`},
+}
+
+// lookaround returns the lookaround of the pattern, the configured classes
+// taking precedence over the builtin ones
+func (s *State) lookaround(pattern string) Lookaround {
+	lookaround := builtinLookarounds[pattern]
+	if config, ok := s.PatternConfigs[pattern]; ok {
+		if config.NotPrecededBy != "" {
+			lookaround.NotPrecededBy = config.NotPrecededBy
+		}
+		if config.NotFollowedBy != "" {
+			lookaround.NotFollowedBy = config.NotFollowedBy
+		}
+	}
+	return lookaround
+}
+
+// allowedAround reports whether the match line[start:end] of the pattern
+// passes its lookaround
+func (s *State) allowedAround(pattern, line string, start, end int) bool {
+	lookaround := s.lookaround(pattern)
+	if lookaround.NotPrecededBy != "" && start > 0 {
+		_, size := utf8.DecodeLastRuneInString(line[:start])
+		if inClass(lookaround.NotPrecededBy, line[start-size:start]) {
+			return false
+		}
+	}
+	if lookaround.NotFollowedBy != "" && end < len(line) {
+		_, size := utf8.DecodeRuneInString(line[end:])
+		if inClass(lookaround.NotFollowedBy, line[end:end+size]) {
+			return false
+		}
+	}
+	return true
+}
+
+// inClass reports whether char is in the character class
+func inClass(class, char string) bool {
+	return globalPatternCache.GetCompiledPattern("lookaround", `^(?:`+class+`)$`).Pattern.MatchString(char)
+}
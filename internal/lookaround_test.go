@@ -0,0 +1,54 @@
+package internal
+
+import "testing"
+
+func TestLookaround(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		configs  map[string]PatternConfig
+		expected []string
+	}{
+		{
+			name:     "sha inside a pod name",
+			line:     "webapp-editor-7fdbfbf4b-k68b7 Running",
+			expected: nil,
+		},
+		{
+			name:     "sha between delimiters",
+			line:     "commit 7fdbfbf4b (HEAD) and 1a2b3c4d",
+			expected: []string{"7fdbfbf4b", "1a2b3c4d"},
+		},
+		{
+			name:     "sha after a rejected one",
+			line:     "x1a2b3c4d 5e6f7a8b",
+			expected: []string{"5e6f7a8b"},
+		},
+		{
+			name:     "configured classes",
+			line:     "(1a2b3c4d) 5e6f7a8b",
+			configs:  map[string]PatternConfig{"sha": {NotPrecededBy: `[\w(-]`}},
+			expected: []string{"5e6f7a8b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := NewStateFromLines([]string{tt.line}, "abcd", []string{}, WithPatternConfigs(tt.configs))
+			var shas []string
+			for _, match := range state.Matches(false, 0) {
+				if match.Pattern == "sha" {
+					shas = append(shas, match.Text)
+				}
+			}
+			if len(shas) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, shas)
+			}
+			for i := range shas {
+				if shas[i] != tt.expected[i] {
+					t.Errorf("Expected %q, got %q", tt.expected[i], shas[i])
+				}
+			}
+		})
+	}
+}
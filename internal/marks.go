@@ -0,0 +1,122 @@
+package internal
+
+import (
+	"cmp"
+	"regexp"
+	"strings"
+
+	"github.com/Hanaasagi/magonote/pkg/textdetection/colordetection"
+)
+
+// Kinds of PromptMark, named after the final term sequences (FTCS) shells
+// with iTerm2 or VS Code style shell integration print
+const (
+	MarkPromptStart   = 'A' // FTCS_PROMPT, the prompt is printed
+	MarkCommandStart  = 'B' // FTCS_COMMAND_START, the prompt ends and the command is typed
+	MarkCommandOutput = 'C' // FTCS_COMMAND_EXECUTED, the output of the command follows
+	MarkCommandEnd    = 'D' // FTCS_COMMAND_FINISHED, the command exited
+)
+
+// PromptMark is a semantic mark of the input, at the byte X of line Y of
+// the plain text
+type PromptMark struct {
+	Kind byte
+	X, Y int
+}
+
+// markSequence matches the OSC 133 marks, such as "\x1b]133;D;0\x07", and
+// the other iTerm2 OSC 1337 sequences, such as "\x1b]1337;CurrentDir=/tmp\x07",
+// which aren't displayed by the terminal
+var markSequence = regexp.MustCompile(`\x1b\](?:133;([A-D])|1337;)[^\x07\x1b]*(?:\x07|\x1b\\)`)
+
+// extractMarks removes the OSC 133 and OSC 1337 sequences of text, returning
+// the text without them and the marks in order
+func extractMarks(text string) (string, []PromptMark) {
+	if !strings.Contains(text, "\x1b]133;") && !strings.Contains(text, "\x1b]1337;") {
+		return text, nil
+	}
+
+	lines := strings.Split(text, "\n")
+	var marks []PromptMark
+	for y, line := range lines {
+		var stripped strings.Builder
+		last := 0
+		for _, loc := range markSequence.FindAllStringSubmatchIndex(line, -1) {
+			stripped.WriteString(line[last:loc[0]])
+			last = loc[1]
+
+			if loc[2] >= 0 {
+				prefix, _ := colordetection.StripLine(stripped.String())
+				marks = append(marks, PromptMark{Kind: line[loc[2]], X: len(prefix), Y: y})
+			}
+		}
+		stripped.WriteString(line[last:])
+		lines[y] = stripped.String()
+	}
+	return strings.Join(lines, "\n"), marks
+}
+
+// comparePosition orders marks and matches by their position in the text
+func comparePosition(x1, y1, x2, y2 int) int {
+	return cmp.Or(cmp.Compare(y1, y2), cmp.Compare(x1, x2))
+}
+
+// lastMark returns the index of the last mark of kind at or before the
+// position, or -1
+func lastMark(marks []PromptMark, kind byte, x, y int) int {
+	for i := len(marks) - 1; i >= 0; i-- {
+		if marks[i].Kind == kind && comparePosition(marks[i].X, marks[i].Y, x, y) <= 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// markedScopeLines returns the lines of the output of the last command
+// following the marks, ok is false without marks
+func (s *State) markedScopeLines() (start, end int, ok bool) {
+	marks := s.processor.PromptMarks()
+	output := lastMark(marks, MarkCommandOutput, 0, len(s.Lines))
+	if output < 0 || marks[output].Y >= len(s.Lines) {
+		return 0, 0, false
+	}
+
+	// The output ends at the prompt waiting for the next command, or with
+	// the text when the command is still running
+	end = len(s.Lines)
+	for _, mark := range marks[output:] {
+		if mark.Kind == MarkPromptStart {
+			end = mark.Y
+			break
+		}
+	}
+
+	// The output starts on the line after the command, where the mark
+	// usually is
+	mark := marks[output]
+	start = mark.Y
+	if strings.TrimSpace(s.Lines[mark.Y][:min(mark.X, len(s.Lines[mark.Y]))]) != "" {
+		start++
+	}
+	return start, max(start, end), true
+}
+
+// dropPromptMatches drops the matches in the prompts delimited by marks,
+// such as the current directory or git branch of a shell prompt
+func (s *State) dropPromptMatches(matches []Match) []Match {
+	marks := s.processor.PromptMarks()
+	if len(marks) == 0 {
+		return matches
+	}
+
+	kept := matches[:0]
+	for _, match := range matches {
+		prompt := lastMark(marks, MarkPromptStart, match.X, match.Y)
+		command := lastMark(marks, MarkCommandStart, match.X, match.Y)
+		if prompt >= 0 && command < prompt {
+			continue
+		}
+		kept = append(kept, match)
+	}
+	return kept
+}
@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// marked builds the output of a shell with shell integration, running the
+// commands with their outputs after the prompt
+func marked(prompt string, commands ...[2]string) string {
+	prompt = "\x1b]133;A\x07" + prompt + "\x1b]133;B\x07"
+	var b strings.Builder
+	b.WriteString("\x1b]1337;RemoteHost=user@host\x07")
+	for _, command := range commands {
+		b.WriteString(prompt + command[0] + "\n\x1b]133;C\x07")
+		b.WriteString(command[1])
+		b.WriteString("\x1b]133;D;0\x07")
+	}
+	b.WriteString(prompt)
+	return b.String()
+}
+
+func TestExtractMarks(t *testing.T) {
+	text, marks := extractMarks(marked("/tmp $ ", [2]string{"ls", "a.log\n"}))
+
+	wantText := "/tmp $ ls\na.log\n/tmp $ "
+	if text != wantText {
+		t.Errorf("Expected %q, got %q", wantText, text)
+	}
+	wantMarks := []PromptMark{
+		{Kind: MarkPromptStart, X: 0, Y: 0},
+		{Kind: MarkCommandStart, X: 7, Y: 0},
+		{Kind: MarkCommandOutput, X: 0, Y: 1},
+		{Kind: MarkCommandEnd, X: 0, Y: 2},
+		{Kind: MarkPromptStart, X: 0, Y: 2},
+		{Kind: MarkCommandStart, X: 7, Y: 2},
+	}
+	if !reflect.DeepEqual(marks, wantMarks) {
+		t.Errorf("Expected %+v, got %+v", wantMarks, marks)
+	}
+}
+
+func TestMarkedScope(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		scope string
+		want  []string
+	}{
+		{
+			name: "last command",
+			text: marked("/srv $ ",
+				[2]string{"cat hosts", "10.0.0.1\n"},
+				[2]string{"ls /tmp", "/tmp/a.log\n/tmp/b.log\n"},
+			),
+			scope: ScopeLastCommand,
+			want:  []string{"/tmp/a.log", "/tmp/b.log"},
+		},
+		{
+			name: "prompt that doesn't look like one",
+			text: marked("srv ~ ",
+				[2]string{"cat hosts", "10.0.0.1\n"},
+				[2]string{"cat other-hosts", "10.0.0.2\n"},
+			),
+			scope: ScopeLastCommand,
+			want:  []string{"10.0.0.2"},
+		},
+		{
+			name:  "command without output",
+			text:  marked("/srv $ ", [2]string{"cat hosts", "10.0.0.1\n"}, [2]string{"true", ""}),
+			scope: ScopeLastCommand,
+			want:  nil,
+		},
+		{
+			name:  "matches in prompts are dropped",
+			text:  marked("/srv $ ", [2]string{"cat /etc/hosts", "10.0.0.1\n"}),
+			scope: ScopeAll,
+			want:  []string{"/etc/hosts", "10.0.0.1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := NewState(tt.text, "abcd", []string{}, WithScope(tt.scope))
+			var got []string
+			for _, match := range state.Matches(false, 0) {
+				got = append(got, match.Text)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Modes of WithMode
+const (
+	ModePatterns    = "patterns"    // Matches of the patterns only
+	ModeWords       = "words"       // And the whitespace delimited words left, like vim's WORD
+	ModeIdentifiers = "identifiers" // And the runs of letters, digits and underscores left, like vim's word
+	ModeLines       = "lines"       // The non-empty lines instead, trimmed
+)
+
+// Patterns of the matches added by the modes
+var (
+	bigWordPattern    = regexp.MustCompile(`\S+`)
+	identifierPattern = regexp.MustCompile(`[\p{L}\p{N}_]+`)
+)
+
+// WithMode also gives hints to the words the patterns miss, with
+// ModeWords or ModeIdentifiers, or to every line instead of the matches of
+// the patterns with ModeLines. Words shorter than minLength characters are
+// left out, as are those shorter than the minimum length of their pattern,
+// "word" or "identifier"
+func WithMode(mode string, minLength int) Option {
+	return optionFunc(func(s *State) {
+		s.Mode = mode
+		s.WordMinLength = minLength
+	})
+}
+
+// wordMatches returns the words of the lines in scope for the mode of the
+// state, nil for ModePatterns
+func (s *State) wordMatches(ctx context.Context) ([]Match, error) {
+	var name string
+	var pattern *regexp.Regexp
+	switch s.Mode {
+	case ModeWords:
+		name, pattern = "word", bigWordPattern
+	case ModeIdentifiers:
+		name, pattern = "identifier", identifierPattern
+	default:
+		return nil, nil
+	}
+
+	var matches []Match
+	start, end := s.scopeLines()
+	for y := start; y < end; y++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		line := s.Lines[y]
+		for _, loc := range pattern.FindAllStringIndex(line, -1) {
+			text := line[loc[0]:loc[1]]
+			if s.shouldTrimPunctuation(name) {
+				text = TrimTrailingPunctuation(text)
+			}
+			if text == "" || utf8.RuneCountInString(text) < s.WordMinLength || s.tooShort(name, text) {
+				continue
+			}
+			matches = append(matches, Match{X: loc[0], Y: y, Pattern: name, Text: text})
+		}
+	}
+	return matches, nil
+}
+
+// lineMatches returns a match for every non-empty line in scope, without
+// its surrounding whitespace
+func (s *State) lineMatches() []Match {
+	var matches []Match
+	start, end := s.scopeLines()
+	for y := start; y < end; y++ {
+		line := s.Lines[y]
+		text := strings.TrimSpace(line)
+		if text == "" {
+			continue
+		}
+		x := len(line) - len(strings.TrimLeftFunc(line, unicode.IsSpace))
+		matches = append(matches, Match{X: x, Y: y, Pattern: "line", Text: text})
+	}
+	return matches
+}
@@ -0,0 +1,59 @@
+package internal
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWordMatches(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      string
+		minLength int
+		expected  []string
+	}{
+		// The brackets enclose a match of their own, the words in them
+		// aren't matched again
+		{"patterns only", ModePatterns, 0, []string{"bracketed:retry 3"}},
+		{"words", ModeWords, 0, []string{"bracketed:retry 3", "word:error", "word:cannot", "word:open", "word:config"}},
+		{"words with a minimum length", ModeWords, 5, []string{"bracketed:retry 3", "word:error", "word:cannot", "word:config"}},
+		{"identifiers", ModeIdentifiers, 0, []string{"bracketed:retry 3", "identifier:error", "identifier:cannot", "identifier:open", "identifier:config"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := NewStateFromLines([]string{"error: cannot open /etc/app.toml config. (retry 3)"}, "abcd", []string{}, WithMode(tt.mode, tt.minLength))
+
+			var got []string
+			for _, mat := range state.Matches(false, 0) {
+				if mat.Pattern != "path" {
+					got = append(got, mat.Pattern+":"+mat.Text)
+				} else if mat.Text != "/etc/app.toml" {
+					t.Errorf("Expected only the path to be matched by a pattern, got %v", mat)
+				}
+			}
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestLineMatches(t *testing.T) {
+	lines := []string{"  main.go:12:5: undefined: foo  ", "", "\t", "FAIL\tgithub.com/x/y [build failed]"}
+	state := NewStateFromLines(lines, "abcd", []string{}, WithMode(ModeLines, 0))
+
+	expected := []Match{
+		{X: 2, Y: 0, Pattern: "line", Text: "main.go:12:5: undefined: foo"},
+		{X: 0, Y: 3, Pattern: "line", Text: "FAIL\tgithub.com/x/y [build failed]"},
+	}
+	matches := state.Matches(false, 0)
+	if len(matches) != len(expected) {
+		t.Fatalf("Expected %d matches, got %v", len(expected), matches)
+	}
+	for i, mat := range matches {
+		if !mat.Equals(expected[i]) || mat.Text != expected[i].Text || mat.Pattern != expected[i].Pattern || mat.Hint == nil {
+			t.Errorf("Expected %v with a hint, got %v", expected[i], mat)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package internal
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+)
+
+// BuiltinMultilinePatterns match across the lines of the input, they are
+// only tried when enabled, see WithMultilinePatterns
+var BuiltinMultilinePatterns = []MatchPattern{
+	// PEM blocks: certificates, keys and signatures
+	{"pem", `-----BEGIN [A-Z0-9 ]+-----\n(?:[^\n]*\n)*?[ \t]*-----END [A-Z0-9 ]+-----`},
+	// Shell commands continued on the next lines with a trailing backslash,
+	// without their prompt
+	{"shell_continuation", `(?m)^[ \t]*(?:[$#>%] +)?(?P<match>\S[^\n]*[ \t]\\\n(?:[^\n]*\\\n)*[^\n]*\S)`},
+	// JSON strings holding line breaks, as values or array items
+	{"json_string", `(?m)(?:^[ \t]*|[:\[,][ \t]*)"(?P<match>(?:[^"\\\n]|\\.)*(?:\n(?:[^"\\\n]|\\.)*)+)"`},
+}
+
+// WithMultilinePatterns adds patterns matched against the lines in scope
+// joined by newlines, so that their matches may span several lines. Their
+// matches take precedence over every other match in the text they span
+func WithMultilinePatterns(patterns []MatchPattern) Option {
+	return optionFunc(func(s *State) {
+		s.MultilinePatterns = patterns
+	})
+}
+
+// multilineMatches returns the matches of the multi-line patterns, moved to
+// the line and offset they start at. Their text keeps the line breaks
+func (s *State) multilineMatches(ctx context.Context) ([]Match, error) {
+	if len(s.MultilinePatterns) == 0 {
+		return nil, nil
+	}
+	patterns := make([]*CompiledPattern, 0, len(s.MultilinePatterns))
+	for _, p := range s.MultilinePatterns {
+		patterns = append(patterns, globalPatternCache.GetCompiledPattern(p.Name, p.Pattern))
+	}
+
+	start, end := s.scopeLines()
+	lines := s.Lines[start:end]
+	conflicts := len(s.conflicts)
+	matches := s.processLine(ctx, start, strings.Join(lines, "\n"), patterns)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range matches {
+		y, x := lineOffset(lines, matches[i].X)
+		matches[i].X, matches[i].Y = x, start+y
+	}
+	for i := conflicts; i < len(s.conflicts); i++ {
+		y, x := lineOffset(lines, s.conflicts[i].X)
+		s.conflicts[i].X, s.conflicts[i].Y = x, start+y
+	}
+	return matches, nil
+}
+
+// lineOffset returns the line and the offset in it of a byte offset into
+// lines joined by newlines. An offset at a line break stays at the end of
+// its line
+func lineOffset(lines []string, offset int) (y, x int) {
+	for y < len(lines)-1 && offset > len(lines[y]) {
+		offset -= len(lines[y]) + 1
+		y++
+	}
+	return y, offset
+}
+
+// matchEnd returns the line and the offset the text of mat ends at
+func matchEnd(mat Match) (y, x int) {
+	breaks := strings.Count(mat.Text, "\n")
+	if breaks == 0 {
+		return mat.Y, mat.X + len(mat.Text)
+	}
+	return mat.Y + breaks, len(mat.Text) - strings.LastIndex(mat.Text, "\n") - 1
+}
+
+// spansOverlap reports whether the texts of a and b overlap, whether they
+// span several lines or not
+func spansOverlap(a, b Match) bool {
+	before := func(y1, x1, y2, x2 int) bool {
+		return y1 < y2 || y1 == y2 && x1 < x2
+	}
+	aEndY, aEndX := matchEnd(a)
+	bEndY, bEndX := matchEnd(b)
+	return before(a.Y, a.X, bEndY, bEndX) && before(b.Y, b.X, aEndY, aEndX)
+}
+
+// mergeMultilineMatches adds the multi-line matches to matches, dropping
+// those overlapping them
+func mergeMultilineMatches(matches, multiline []Match) []Match {
+	merged := slices.DeleteFunc(matches, func(mat Match) bool {
+		return slices.ContainsFunc(multiline, func(m Match) bool {
+			return spansOverlap(mat, m)
+		})
+	})
+	merged = append(merged, multiline...)
+	slices.SortStableFunc(merged, func(a, b Match) int {
+		return cmp.Or(cmp.Compare(a.Y, b.Y), cmp.Compare(a.X, b.X))
+	})
+	return merged
+}
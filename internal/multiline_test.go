@@ -0,0 +1,139 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestMultilineMatches(t *testing.T) {
+	pem := "-----BEGIN CERTIFICATE-----\nMIIBszCCAVmgAwIBAgIUQ/0a2b3c4d\n-----END CERTIFICATE-----"
+	tests := []struct {
+		name     string
+		lines    []string
+		patterns []MatchPattern
+		expected []Match // Pattern, X, Y and Text of the multi-line matches
+	}{
+		{
+			name:     "pem block",
+			lines:    []string{"cat cert.pem", pem, "$ "},
+			patterns: BuiltinMultilinePatterns,
+			expected: []Match{{X: 0, Y: 1, Pattern: "pem", Text: pem}},
+		},
+		{
+			name: "shell continuation",
+			lines: []string{
+				"$ docker run \\",
+				"    -v /tmp/data:/data \\",
+				"    alpine:3.19",
+				"done",
+			},
+			patterns: BuiltinMultilinePatterns,
+			expected: []Match{{
+				X: 2, Y: 0, Pattern: "shell_continuation",
+				Text: "docker run \\\n    -v /tmp/data:/data \\\n    alpine:3.19",
+			}},
+		},
+		{
+			name:     "json string",
+			lines:    []string{`{`, `  "message": "first line`, `second line",`, `  "code": 3`, `}`},
+			patterns: BuiltinMultilinePatterns,
+			expected: []Match{{X: 14, Y: 1, Pattern: "json_string", Text: "first line\nsecond line"}},
+		},
+		{
+			name:     "quotes of prose",
+			lines:    []string{`He said "wait`, `and left."`},
+			patterns: BuiltinMultilinePatterns,
+		},
+		{
+			name:     "custom pattern",
+			lines:    []string{"Host web", "  User deploy", "  Port 2222", "other"},
+			patterns: []MatchPattern{{Name: "stanza", Pattern: `(?m)^Host \S+\n(?:[ \t]+\S[^\n]*\n?)+`}},
+			expected: []Match{{X: 0, Y: 0, Pattern: "stanza", Text: "Host web\n  User deploy\n  Port 2222\n"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := NewStateFromLines(tt.lines, "abcd", []string{}, WithMultilinePatterns(tt.patterns))
+			matches := state.Matches(false, 0)
+
+			var got []Match
+			for _, mat := range matches {
+				if strings.Contains(mat.Text, "\n") {
+					got = append(got, mat)
+				}
+			}
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Expected %d multi-line matches, got %v", len(tt.expected), matches)
+			}
+			for i, expected := range tt.expected {
+				if got[i].X != expected.X || got[i].Y != expected.Y || got[i].Pattern != expected.Pattern || got[i].Text != expected.Text {
+					t.Errorf("Expected %s at %d,%d: %q, got %s at %d,%d: %q", expected.Pattern, expected.X, expected.Y, expected.Text,
+						got[i].Pattern, got[i].X, got[i].Y, got[i].Text)
+				}
+			}
+
+			// Nothing else is matched inside the blocks
+			for _, mat := range matches {
+				for _, block := range got {
+					endY, endX := matchEnd(block)
+					if mat != block && (mat.Y > block.Y || mat.Y == block.Y && mat.X >= block.X) &&
+						(mat.Y < endY || mat.Y == endY && mat.X < endX) {
+						t.Errorf("Expected no match inside %q, got %s", block.Text, mat)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestMultilineDisabled(t *testing.T) {
+	state := NewStateFromLines([]string{"$ ls \\", "  /tmp/data"}, "abcd", []string{})
+	for _, mat := range state.Matches(false, 0) {
+		if strings.Contains(mat.Text, "\n") {
+			t.Errorf("Expected no multi-line match unless enabled, got %s", mat)
+		}
+	}
+}
+
+func TestViewMultilineMatch(t *testing.T) {
+	lines := []string{"$ tar czf out.tgz \\", "    /srv/www", "ok"}
+	state := NewStateFromLines(lines, "abcd", []string{}, WithMultilinePatterns(BuiltinMultilinePatterns))
+	view := NewView(
+		state, false, false, 0, false, "",
+		GetColor("green"), GetColor("default"), GetColor("default"), GetColor("default"),
+		GetColor("green"), GetColor("default"), GetColor("default"), GetColor("default"),
+	)
+
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("Failed to initialize simulation screen: %v", err)
+	}
+	defer screen.Fini()
+	screen.SetSize(40, 5)
+	view.screen = screen
+
+	view.render("")
+
+	if len(view.matches) != 1 {
+		t.Fatalf("Expected the command as a single match, got %v", view.matches)
+	}
+	// The hint sits on the first line and the text of the second line is
+	// drawn in the style of the match, unlike the next line
+	r, _, _, _ := screen.GetContent(2, 0)
+	if hint := []rune(*view.matches[0].Hint); r != hint[0] {
+		t.Errorf("Expected hint %q at the start of the command, got %q", *view.matches[0].Hint, r)
+	}
+	cells := view.textBuffer.content
+	matchStyle := cells[0][5].Style
+	for x := 4; x < len(lines[1]); x++ {
+		if cell := cells[1][x]; cell.Rune != rune(lines[1][x]) || cell.Style != matchStyle {
+			t.Errorf("Expected %q in the style of the match at column %d of line 1, got %q", lines[1][x], x, cell.Rune)
+		}
+	}
+	if cells[2][0].Style == matchStyle {
+		t.Errorf("Expected the line after the command in another style than the match")
+	}
+}
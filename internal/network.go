@@ -0,0 +1,78 @@
+package internal
+
+import (
+	"net/netip"
+	"unicode"
+	"unicode/utf8"
+)
+
+// networkPatterns match addresses, subnets and interfaces of networks. The
+// regexes only find candidates, networkValidators check them with
+// net/netip, and the characters around them are checked by
+// builtinLookarounds
+var networkPatterns = []MatchPattern{
+	// IPv4: 10.0.0.0/24, 192.168.1.1:8080, 127.0.0.1
+	{"ipv4_cidr", `\d{1,3}(?:\.\d{1,3}){3}/\d{1,2}`},
+	{"ipv4_port", `\b\d{1,3}(?:\.\d{1,3}){3}:\d{1,5}\b`},
+	{"ipv4", `\d{1,3}(?:\.\d{1,3}){3}`},
+
+	// IPv6: 2001:db8::/32, [2001:db8::1]:443, fe80::1%eth0
+	{"ipv6_cidr", `[0-9A-Fa-f]*:[0-9A-Fa-f:.]*:[0-9A-Fa-f:.]*/\d{1,3}`},
+	{"ipv6_port", `\[[0-9A-Fa-f:.]+(?:%[\w.]+)?\]:\d{1,5}`},
+
+	// MAC addresses: aa:bb:cc:dd:ee:ff, AA-BB-CC-DD-EE-FF and aabb.ccdd.eeff.
+	// Not inside IPv6 addresses, see builtinLookarounds
+	{"mac", `[0-9A-Fa-f]{2}(?::[0-9A-Fa-f]{2}){5}|[0-9A-Fa-f]{2}(?:-[0-9A-Fa-f]{2}){5}|[0-9A-Fa-f]{4}\.[0-9A-Fa-f]{4}\.[0-9A-Fa-f]{4}`},
+	{"ipv6", `[0-9A-Fa-f]*:[0-9A-Fa-f:.]*:[0-9A-Fa-f:.]*[0-9A-Fa-f](?:%[\w.]+)?`},
+
+	// Network interfaces: eth0, enp3s0, wlp2s0, br-1234abcd, veth1a2b3c4, eth0.100
+	{"interface", `\b(?:(?:en|wl|ww|ib)(?:o\d+|s\d+(?:f\d+)?|p\d+s\d+(?:f\d+)?|x[0-9a-f]{12})|(?:eth|wlan|wwan|usb|bond|team|tun|tap|wg|docker|virbr|vlan|vmnet|vboxnet|lxcbr|lxdbr|cni|utun)\d+|veth[0-9a-f]{6,}|br-[0-9a-f]{8,12})(?:\.\d+)?\b`},
+}
+
+// networkValidators reject the matches of network patterns their regexes
+// can't, such as octets over 255, ports over 65535, prefixes longer than
+// the address, times like 10:20:30 and parts of longer dotted numbers such
+// as version 1.2.3.4.5, keyed by pattern name
+var networkValidators = map[string]func(line string, start, end int) bool{
+	"ipv4_cidr": func(line string, start, end int) bool {
+		prefix, err := netip.ParsePrefix(line[start:end])
+		return err == nil && prefix.Addr().Is4() && !followedByNumber(line, end)
+	},
+	"ipv4_port": func(line string, start, end int) bool {
+		addrPort, err := netip.ParseAddrPort(line[start:end])
+		return err == nil && addrPort.Addr().Is4() && !followedByNumber(line, end)
+	},
+	"ipv4": func(line string, start, end int) bool {
+		addr, err := netip.ParseAddr(line[start:end])
+		return err == nil && addr.Is4() && !followedByNumber(line, end)
+	},
+	"ipv6_cidr": func(line string, start, end int) bool {
+		prefix, err := netip.ParsePrefix(line[start:end])
+		return err == nil && prefix.Addr().Is6()
+	},
+	"ipv6_port": func(line string, start, end int) bool {
+		addrPort, err := netip.ParseAddrPort(line[start:end])
+		return err == nil && addrPort.Addr().Is6()
+	},
+	"ipv6": func(line string, start, end int) bool {
+		addr, err := netip.ParseAddr(line[start:end])
+		return err == nil && addr.Is6()
+	},
+}
+
+// validNetworkMatch reports whether the match line[start:end] of the
+// pattern passes its validator, matches of other patterns always do
+func validNetworkMatch(pattern, line string, start, end int) bool {
+	validate, ok := networkValidators[pattern]
+	return !ok || validate(line, start, end)
+}
+
+// followedByNumber reports whether the match ending at end goes on with a
+// dot and a digit, as versions such as 1.2.3.4.5 do
+func followedByNumber(line string, end int) bool {
+	if end+1 >= len(line) || line[end] != '.' {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(line[end+1:])
+	return unicode.IsDigit(r)
+}
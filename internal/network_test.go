@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMatchNetworks(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected []string // Pattern:Text of the network matches
+	}{
+		{"route add 10.0.0.0/24 via 10.0.0.1", []string{"ipv4_cidr:10.0.0.0/24", "ipv4:10.0.0.1"}},
+		{"allow 2001:db8::/32 and ::/0", []string{"ipv6_cidr:2001:db8::/32", "ipv6_cidr:::/0"}},
+		{"inet6 fe80::1/64 scope link", []string{"ipv6_cidr:fe80::1/64"}},
+		{"listen [::1]:8080 and 192.168.1.1:443.", []string{"ipv6_port:[::1]:8080", "ipv4_port:192.168.1.1:443"}},
+		{"host 10.0.0.1.", []string{"ipv4:10.0.0.1"}},
+		{"octets 256.1.1.1 999.0.0.1 01.2.3.4", nil},
+		{"subnets 10.0.0.0/33 2001:db8::/129", nil},
+		// Addresses are still matched without their ports
+		{"ports 10.0.0.1:99999 [::1]:70000", []string{"ipv4:10.0.0.1", "ipv6:::1"}},
+		{"versions v1.2.3.4 go1.2.3.4 1.2.3.4.5", nil},
+		{"at 10:20:30 and 1:2:3:4:5:6:7:8:9", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			results := NewStateFromLines([]string{tt.line}, "abcd", []string{}).Matches(false, 0)
+
+			var got []string
+			for _, result := range results {
+				if slices.ContainsFunc(networkPatterns, func(p MatchPattern) bool { return p.Name == result.Pattern }) {
+					got = append(got, result.Pattern+":"+result.Text)
+				}
+			}
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestValidNetworkMatch(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		line     string
+		expected bool
+	}{
+		{"ipv4", "255.255.255.255", true},
+		{"ipv4", "255.255.255.256", false},
+		{"ipv4", "010.0.0.1", false},
+		{"ipv4_port", "10.0.0.1:65535", true},
+		{"ipv4_port", "10.0.0.1:65536", false},
+		{"ipv4_cidr", "10.0.0.0/32", true},
+		{"ipv4_cidr", "10.0.0.0/33", false},
+		{"ipv6", "fe80::1%eth0", true},
+		{"ipv6", "10:20:30", false},
+		{"ipv6_cidr", "2001:db8::/128", true},
+		{"ipv6_cidr", "2001:db8::/129", false},
+		{"ipv6_port", "[2001:db8::1]:443", true},
+		{"ipv6_port", "[10.0.0.1]:443", false},
+		{"path", "256.0.0.1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.line, func(t *testing.T) {
+			if got := validNetworkMatch(tt.pattern, tt.line, 0, len(tt.line)); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package internal
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+// PatternCount is the number of matches of a pattern
+type PatternCount struct {
+	Pattern string
+	Count   int
+}
+
+// countPatterns returns the patterns of matches with their number of matches,
+// the most frequent first
+func countPatterns(matches []Match) []PatternCount {
+	counts := map[string]int{}
+	for _, match := range matches {
+		counts[match.Pattern]++
+	}
+
+	result := make([]PatternCount, 0, len(counts))
+	for pattern, count := range counts {
+		result = append(result, PatternCount{Pattern: pattern, Count: count})
+	}
+	slices.SortFunc(result, func(a, b PatternCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Pattern, b.Pattern))
+	})
+	return result
+}
+
+// filterPatterns returns copies of the matches whose pattern isn't hidden,
+// without hints
+func filterPatterns(matches []Match, hidden map[string]bool) []Match {
+	filtered := make([]Match, 0, len(matches))
+	for _, match := range matches {
+		if hidden[match.Pattern] {
+			continue
+		}
+		match.Hint = nil
+		filtered = append(filtered, match)
+	}
+	return filtered
+}
+
+// PatternFilter is an overlay listing the patterns of the matches with their
+// counts, where patterns can be hidden or shown
+type PatternFilter struct {
+	screen   tcell.Screen
+	patterns []PatternCount
+	shown    []bool
+	cursor   int
+	colors   ViewColors
+}
+
+// NewPatternFilter creates a PatternFilter for the patterns of matches,
+// those in hidden start unchecked
+func NewPatternFilter(screen tcell.Screen, matches []Match, hidden map[string]bool, colors ViewColors) *PatternFilter {
+	patterns := countPatterns(matches)
+	shown := make([]bool, len(patterns))
+	for i, p := range patterns {
+		shown[i] = !hidden[p.Pattern]
+	}
+
+	return &PatternFilter{
+		screen:   screen,
+		patterns: patterns,
+		shown:    shown,
+		colors:   colors,
+	}
+}
+
+// Toggle flips whether the pattern at index i is shown
+func (f *PatternFilter) Toggle(i int) {
+	if i < 0 || i >= len(f.shown) {
+		return
+	}
+	f.shown[i] = !f.shown[i]
+}
+
+// Only shows the pattern at index i alone
+func (f *PatternFilter) Only(i int) {
+	if i < 0 || i >= len(f.shown) {
+		return
+	}
+	for j := range f.shown {
+		f.shown[j] = j == i
+	}
+}
+
+// ShowAll shows every pattern
+func (f *PatternFilter) ShowAll() {
+	for i := range f.shown {
+		f.shown[i] = true
+	}
+}
+
+// Hidden returns the patterns that are not shown
+func (f *PatternFilter) Hidden() map[string]bool {
+	hidden := map[string]bool{}
+	for i, p := range f.patterns {
+		if !f.shown[i] {
+			hidden[p.Pattern] = true
+		}
+	}
+	return hidden
+}
+
+// Present runs the filter loop and returns the hidden patterns, ok is false
+// when the user aborted
+func (f *PatternFilter) Present() (hidden map[string]bool, ok bool) {
+	f.render()
+
+	for {
+		switch ev := f.screen.PollEvent().(type) {
+		case *tcell.EventKey:
+			if done, applied := f.handleKeyEvent(ev); done {
+				if !applied {
+					return nil, false
+				}
+				return f.Hidden(), true
+			}
+		case *tcell.EventResize, *resizeEvent:
+			f.screen.Sync()
+		case *tcell.EventError:
+			return nil, false
+		}
+
+		f.render()
+	}
+}
+
+// handleKeyEvent processes a key event, it reports whether the filter is
+// finished and whether it was applied. Hiding every pattern can't be applied
+func (f *PatternFilter) handleKeyEvent(ev *tcell.EventKey) (done bool, applied bool) {
+	switch ev.Key() {
+	case tcell.KeyEscape, tcell.KeyCtrlC:
+		return true, false
+	case tcell.KeyEnter, tcell.KeyTab:
+		return len(f.Hidden()) < len(f.patterns), true
+	case tcell.KeyUp, tcell.KeyCtrlP:
+		if f.cursor > 0 {
+			f.cursor--
+		}
+	case tcell.KeyDown, tcell.KeyCtrlN:
+		if f.cursor < len(f.patterns)-1 {
+			f.cursor++
+		}
+	case tcell.KeyRune:
+		switch ev.Rune() {
+		case ' ', 'x':
+			f.Toggle(f.cursor)
+		case 'o':
+			f.Only(f.cursor)
+		case 'a':
+			f.ShowAll()
+		case 'k':
+			if f.cursor > 0 {
+				f.cursor--
+			}
+		case 'j':
+			if f.cursor < len(f.patterns)-1 {
+				f.cursor++
+			}
+		case 'q':
+			return true, false
+		}
+	}
+	return false, false
+}
+
+// render draws the filter overlay
+func (f *PatternFilter) render() {
+	f.screen.Clear()
+	width, height := f.screen.Size()
+
+	titleStyle := tcell.StyleDefault.Bold(true)
+	itemStyle := tcell.StyleDefault.
+		Foreground(colorToTcell(f.colors.foreground))
+	cursorStyle := tcell.StyleDefault.
+		Foreground(colorToTcell(f.colors.selectForeground)).
+		Background(colorToTcell(f.colors.selectBackground))
+
+	y := 0
+	f.drawString(0, y, width, "Patterns to show hints for", titleStyle)
+	y += 2
+
+	for i, p := range f.patterns {
+		if y >= height-2 {
+			break
+		}
+		mark := "[ ]"
+		if f.shown[i] {
+			mark = "[x]"
+		}
+		style := itemStyle
+		if i == f.cursor {
+			style = cursorStyle
+		}
+		f.drawString(0, y, width, fmt.Sprintf("%s %s: %d", mark, p.Pattern, p.Count), style)
+		y++
+	}
+
+	help := "space: toggle  o: only  a: show all  enter: apply  esc: cancel"
+	f.drawString(0, height-1, width, help, titleStyle)
+
+	f.screen.Show()
+}
+
+// drawString writes text at the given position, truncated to width
+func (f *PatternFilter) drawString(x, y, width int, text string, style tcell.Style) {
+	for _, ch := range text {
+		w := runewidth.RuneWidth(ch)
+		if x+w > width {
+			return
+		}
+		f.screen.SetContent(x, y, ch, nil, style)
+		x += w
+	}
+}
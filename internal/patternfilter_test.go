@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestCountPatterns(t *testing.T) {
+	matches := []Match{
+		{Pattern: "path"}, {Pattern: "url"}, {Pattern: "path"}, {Pattern: "sha"}, {Pattern: "url"}, {Pattern: "path"},
+	}
+
+	want := []PatternCount{{"path", 3}, {"url", 2}, {"sha", 1}}
+	if got := countPatterns(matches); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestPatternFilterKeyEvents(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("Failed to init simulation screen: %v", err)
+	}
+	t.Cleanup(screen.Fini)
+
+	matches := []Match{{Pattern: "path"}, {Pattern: "path"}, {Pattern: "url"}}
+	filter := NewPatternFilter(screen, matches, map[string]bool{"url": true}, ViewColors{})
+	if got := filter.Hidden(); !reflect.DeepEqual(got, map[string]bool{"url": true}) {
+		t.Errorf("Expected url hidden initially, got %v", got)
+	}
+
+	// Hide path too, every pattern hidden can't be applied
+	filter.handleKeyEvent(tcell.NewEventKey(tcell.KeyRune, ' ', tcell.ModNone))
+	if done, _ := filter.handleKeyEvent(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone)); done {
+		t.Error("Expected hiding every pattern not to be applied")
+	}
+
+	filter.handleKeyEvent(tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone))
+	filter.handleKeyEvent(tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone))
+	done, applied := filter.handleKeyEvent(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	if !done || !applied {
+		t.Fatalf("Expected the filter to be applied, got done %v applied %v", done, applied)
+	}
+	if got := filter.Hidden(); !reflect.DeepEqual(got, map[string]bool{"path": true}) {
+		t.Errorf("Expected only path hidden, got %v", got)
+	}
+}
+
+func TestViewPatternFilter(t *testing.T) {
+	lines := []string{"see https://example.com and /tmp/a.log", "/tmp/b.log"}
+	state := NewStateFromLines(lines, "abcd", []string{})
+	view := NewView(
+		state, false, false, 0, false, "",
+		GetColor("default"), GetColor("default"), GetColor("default"), GetColor("default"),
+		GetColor("default"), GetColor("default"), GetColor("default"), GetColor("default"),
+	)
+	if len(view.matches) != 3 {
+		t.Fatalf("Expected 3 matches, got %+v", view.matches)
+	}
+
+	// The path after the url gets the first hint once urls are hidden
+	view.applyPatternFilter(map[string]bool{"url": true})
+	if len(view.matches) != 2 || view.matches[0].Text != "/tmp/a.log" {
+		t.Fatalf("Expected the path matches alone, got %+v", view.matches)
+	}
+	if *view.matches[0].Hint != "a" {
+		t.Errorf("Expected /tmp/a.log to get hint 'a', got %q", *view.matches[0].Hint)
+	}
+
+	view.applyPatternFilter(map[string]bool{})
+	if len(view.matches) != 3 {
+		t.Errorf("Expected every match back, got %+v", view.matches)
+	}
+}
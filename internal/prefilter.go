@@ -0,0 +1,74 @@
+package internal
+
+// literalScanner finds which groups of literals a text contains in a single
+// pass, with an Aho-Corasick automaton. Group i is reported as bit i, so
+// there are at most 64 groups
+type literalScanner struct {
+	// delta is the transition of every state on every byte, failures
+	// included, state 0 being the root
+	delta [][256]int32
+	// output holds the groups of the literals ending at every state
+	output []uint64
+}
+
+// newLiteralScanner builds the automaton of the literals of groups
+func newLiteralScanner(groups [][]string) *literalScanner {
+	ls := &literalScanner{
+		delta:  make([][256]int32, 1),
+		output: make([]uint64, 1),
+	}
+
+	// Trie of the literals, 0 is no transition yet as the root is never a
+	// child
+	for group, literals := range groups {
+		for _, literal := range literals {
+			state := int32(0)
+			for i := 0; i < len(literal); i++ {
+				c := literal[i]
+				if ls.delta[state][c] == 0 {
+					ls.delta = append(ls.delta, [256]int32{})
+					ls.output = append(ls.output, 0)
+					ls.delta[state][c] = int32(len(ls.delta) - 1)
+				}
+				state = ls.delta[state][c]
+			}
+			ls.output[state] |= 1 << group
+		}
+	}
+
+	// Breadth first, the missing transitions of a state are those of its
+	// failure state, the longest proper suffix of it in the trie
+	fail := make([]int32, len(ls.delta))
+	var queue []int32
+	for c := range 256 {
+		if child := ls.delta[0][c]; child != 0 {
+			queue = append(queue, child)
+		}
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		ls.output[state] |= ls.output[fail[state]]
+		for c := range 256 {
+			child := ls.delta[state][c]
+			if child == 0 {
+				ls.delta[state][c] = ls.delta[fail[state]][c]
+				continue
+			}
+			fail[child] = ls.delta[fail[state]][c]
+			queue = append(queue, child)
+		}
+	}
+	return ls
+}
+
+// scan returns the groups of the literals text contains
+func (ls *literalScanner) scan(text string) uint64 {
+	var found uint64
+	state := int32(0)
+	for i := 0; i < len(text); i++ {
+		state = ls.delta[state][text[i]]
+		found |= ls.output[state]
+	}
+	return found
+}
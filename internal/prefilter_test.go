@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLiteralScanner(t *testing.T) {
+	scanner := newLiteralScanner([][]string{
+		{"he", "she"},
+		{"hers"},
+		{"his"},
+		{"ushers!"},
+	})
+
+	tests := []struct {
+		text     string
+		expected uint64
+	}{
+		{"", 0},
+		{"nothing", 0},
+		{"she", 0b0001},
+		{"ushers", 0b0011},
+		{"ushers!", 0b1011},
+		{"this", 0b0100},
+		{"h e r s", 0},
+		{"hhhers", 0b0011},
+	}
+
+	for _, tt := range tests {
+		if got := scanner.scan(tt.text); got != tt.expected {
+			t.Errorf("Expected %04b for %q, got %04b", tt.expected, tt.text, got)
+		}
+	}
+}
+
+func TestPatternsForLine(t *testing.T) {
+	lines := SplitLines("address 0x7fff5fbff5c0\nno hashes here")
+	state := NewStateFromLines(lines, "abcd", []string{})
+	patterns := state.getCompiledPatterns()
+
+	tried := func(y int) bool {
+		return slices.ContainsFunc(state.patternsForLine(y, patterns), func(p *CompiledPattern) bool { return p.Name == "address" })
+	}
+	if !tried(0) {
+		t.Error("Expected the address pattern to be tried on a line with 0x")
+	}
+	if tried(1) {
+		t.Error("Expected the address pattern to be skipped on a line without 0x")
+	}
+
+	matches := state.Matches(false, 0)
+	if !slices.ContainsFunc(matches, func(m Match) bool { return m.Pattern == "address" && m.Text == "0x7fff5fbff5c0" }) {
+		t.Errorf("Expected the address to match, got %v", matches)
+	}
+}
@@ -0,0 +1,127 @@
+package internal
+
+import "slices"
+
+// Reasons of Conflict, the first rule of the policy telling the matches apart
+const (
+	ReasonExclusion = "exclusion" // The winner is an exclusion pattern, which always wins
+	ReasonLength    = "length"    // The winner is the longest match
+	ReasonPriority  = "priority"  // The winner's pattern has the highest priority
+	ReasonPosition  = "position"  // The winner starts first
+	ReasonOrder     = "order"     // The winner's pattern comes first in the pattern list
+)
+
+// Conflict records how overlapping matches of several patterns were
+// resolved. The policy, applied to the matches overlapping the earliest one,
+// is: exclusion patterns win, then the longest match, then the pattern with
+// the highest priority, then the match starting first, then the pattern
+// listed first (custom, named, git, then builtin patterns)
+type Conflict struct {
+	Y      int
+	X      int    // Byte offset of the winning match in its line
+	Text   string // Text of the winning match, before captures are taken
+	Winner string
+	Losers []string // Patterns of the matches dropped, in pattern order
+	Reason string
+}
+
+// Conflicts returns the conflicts resolved by the last Matches call, in
+// line order
+func (s *State) Conflicts() []Conflict {
+	return s.conflicts
+}
+
+// ConflictAt returns the conflict whose winning match covers the byte x of
+// line y, false when the match there had no contender
+func (s *State) ConflictAt(y, x int) (Conflict, bool) {
+	for _, conflict := range s.conflicts {
+		if conflict.Y == y && x >= conflict.X && x < conflict.X+len(conflict.Text) {
+			return conflict, true
+		}
+	}
+	return Conflict{}, false
+}
+
+// priority returns the priority of the pattern, 0 unless configured
+func (s *State) priority(pattern string) int {
+	return s.PatternConfigs[pattern].Priority
+}
+
+// isExclusion reports whether pattern is one of ExcludePatterns
+func isExclusion(pattern string) bool {
+	return slices.ContainsFunc(ExcludePatterns, func(p MatchPattern) bool {
+		return p.Name == pattern
+	})
+}
+
+// resolveConflict returns the winner among the candidates overlapping the
+// earliest one, recording the conflict on line y when there are several.
+// Candidates are in pattern order, their indexes relative to offset
+func (s *State) resolveConflict(y, offset int, candidates []submatch) *submatch {
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	earliest := 0
+	for i, candidate := range candidates {
+		if candidate.Index < candidates[earliest].Index {
+			earliest = i
+		}
+	}
+	first := candidates[earliest]
+	contenders := candidates[:0:0]
+	for _, candidate := range candidates {
+		if candidate.Index < first.Index+first.Length && first.Index < candidate.Index+candidate.Length ||
+			candidate.Index == first.Index {
+			contenders = append(contenders, candidate)
+		}
+	}
+	if len(contenders) == 1 {
+		return &first
+	}
+
+	// Exclusions come first whatever the rest, SortStableFunc keeps the
+	// pattern order of the contenders equal on every rule
+	slices.SortStableFunc(contenders, func(a, b submatch) int {
+		_, cmp := s.compareCandidates(a, b)
+		return cmp
+	})
+	winner := contenders[0]
+	reason, _ := s.compareCandidates(winner, contenders[1])
+
+	conflict := Conflict{
+		Y:      y,
+		X:      offset + winner.Index,
+		Text:   winner.Text,
+		Winner: winner.Pattern.Name,
+		Reason: reason,
+	}
+	for _, candidate := range candidates {
+		if slices.ContainsFunc(contenders[1:], func(c submatch) bool { return c.Pattern == candidate.Pattern }) {
+			conflict.Losers = append(conflict.Losers, candidate.Pattern.Name)
+		}
+	}
+	s.conflicts = append(s.conflicts, conflict)
+	return &winner
+}
+
+// compareCandidates orders a before b when it wins by the policy of
+// Conflict, returning the rule that tells them apart
+func (s *State) compareCandidates(a, b submatch) (string, int) {
+	if ea, eb := isExclusion(a.Pattern.Name), isExclusion(b.Pattern.Name); ea != eb {
+		if ea {
+			return ReasonExclusion, -1
+		}
+		return ReasonExclusion, 1
+	}
+	if a.Length != b.Length {
+		return ReasonLength, b.Length - a.Length
+	}
+	if pa, pb := s.priority(a.Pattern.Name), s.priority(b.Pattern.Name); pa != pb {
+		return ReasonPriority, pb - pa
+	}
+	if a.Index != b.Index {
+		return ReasonPosition, a.Index - b.Index
+	}
+	return ReasonOrder, 0
+}
@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestConflictResolution(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		named    []MatchPattern
+		configs  map[string]PatternConfig
+		x        int // Byte of the conflict to inspect
+		expected Conflict
+	}{
+		{
+			name: "longest match",
+			line: "listen 10.0.0.1:8080",
+			x:    7,
+			expected: Conflict{
+				X: 7, Text: "10.0.0.1:8080", Winner: "ipv4_port", Losers: []string{"ipv4", "semver"}, Reason: ReasonLength,
+			},
+		},
+		{
+			name:  "pattern order",
+			line:  "host 10.0.0.1",
+			named: []MatchPattern{{Name: "host", Pattern: `\d+\.\d+\.\d+\.\d+`}},
+			x:     5,
+			expected: Conflict{
+				X: 5, Text: "10.0.0.1", Winner: "host", Losers: []string{"ipv4", "semver"}, Reason: ReasonOrder,
+			},
+		},
+		{
+			name:    "priority",
+			line:    "host 10.0.0.1",
+			named:   []MatchPattern{{Name: "host", Pattern: `\d+\.\d+\.\d+\.\d+`}},
+			configs: map[string]PatternConfig{"ipv4": {Priority: 5}},
+			x:       5,
+			expected: Conflict{
+				X: 5, Text: "10.0.0.1", Winner: "ipv4", Losers: []string{"host", "semver"}, Reason: ReasonPriority,
+			},
+		},
+		{
+			name:    "priority doesn't beat length",
+			line:    "listen 10.0.0.1:8080",
+			configs: map[string]PatternConfig{"ipv4": {Priority: 5}},
+			x:       7,
+			expected: Conflict{
+				X: 7, Text: "10.0.0.1:8080", Winner: "ipv4_port", Losers: []string{"ipv4", "semver"}, Reason: ReasonLength,
+			},
+		},
+		{
+			name:  "position",
+			line:  "host 10.0.0.1-",
+			named: []MatchPattern{{Name: "tail", Pattern: `0\.0\.0\.1-`}},
+			x:     5,
+			expected: Conflict{
+				X: 5, Text: "10.0.0.1", Winner: "ipv4", Losers: []string{"tail", "semver"}, Reason: ReasonPosition,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := NewStateFromLines([]string{tt.line}, "abcd", []string{},
+				WithNamedPatterns(tt.named), WithPatternConfigs(tt.configs))
+			matches := state.Matches(false, 0)
+			if !slices.ContainsFunc(matches, func(m Match) bool { return m.Pattern == tt.expected.Winner }) {
+				t.Errorf("Expected a %s match, got %v", tt.expected.Winner, matches)
+			}
+
+			conflict, ok := state.ConflictAt(0, tt.x)
+			if !ok {
+				t.Fatalf("Expected a conflict at %d, got %v", tt.x, state.Conflicts())
+			}
+			if conflict.X != tt.expected.X || conflict.Text != tt.expected.Text ||
+				conflict.Winner != tt.expected.Winner || conflict.Reason != tt.expected.Reason ||
+				!slices.Equal(conflict.Losers, tt.expected.Losers) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, conflict)
+			}
+		})
+	}
+}
+
+func TestConflictAtOutsideMatches(t *testing.T) {
+	state := NewStateFromLines([]string{"see /tmp/a.txt"}, "abcd", []string{})
+	state.Matches(false, 0)
+	if conflict, ok := state.ConflictAt(0, 4); !ok || conflict.Winner != "path" {
+		t.Errorf("Expected path to win at 4, got %+v", conflict)
+	}
+	if conflict, ok := state.ConflictAt(0, 1); ok {
+		t.Errorf("Expected no conflict, got %+v", conflict)
+	}
+	if stats := state.Stats(); stats.Conflicts != len(state.Conflicts()) {
+		t.Errorf("Expected %d conflicts in the stats, got %d", len(state.Conflicts()), stats.Conflicts)
+	}
+}
@@ -0,0 +1,34 @@
+package internal
+
+// socketState matches the states of sockets listed by ss and netstat
+const socketState = `(?:LISTEN|ESTAB(?:LISHED)?|UNCONN|TIME[-_]WAIT|CLOSE[-_]WAIT|SYN[-_]SENT|SYN[-_]RECV|FIN[-_]WAIT[-_]?[12]|LAST[-_]ACK|CLOSING|CLOSED?)`
+
+// ProcessPatterns match the process IDs and local ports in the output of
+// ss, netstat, lsof and ps, as pid and port matches that actions such as
+// `kill {}` or `lsof -p {}` can be bound to. Each matches the columns
+// around its value so that e.g. the port of `*:8080` wins over the address
+var ProcessPatterns = []MatchPattern{
+	// `ss -tlnp`: LISTEN 0 128 *:8080 *:* users:(("nginx",pid=1234,fd=6))
+	{"port", socketState + `\s+\d+\s+\d+\s+\S*:(?P<match>\d+)\b`},
+	{"pid", `\bpid=(?P<match>\d+)`},
+	// `netstat -tlnp`: tcp 0 0 0.0.0.0:22 0.0.0.0:* LISTEN 812/sshd, and
+	// tcp4 0 0 *.8080 *.* LISTEN on macOS
+	{"port", `^(?:tcp|udp)(?:4|6|46)?\s+\d+\s+\d+\s+\S*[:.](?P<match>\d+)\s`},
+	{"pid", `[:.](?:\*|\d+)\s+(?:` + socketState + `\s+)?(?P<match>\d+)/[^\s/]+\s*$`},
+	// `lsof -i`: nginx 1234 root 6u IPv4 12345 0t0 TCP *:8080 (LISTEN)
+	{"pid", `^\S+\s+(?P<match>\d+)\s+\S+\s+(?:\d+[rwu]?|cwd|rtd|txt|mem|DEL)\s+(?:IPv[46]|REG|DIR|CHR|FIFO|unix|sock|a_inode|netlink|pipe)\b`},
+	{"port", `\b(?:TCP|UDP)\s+(?:\[[^\]\s]*\]|[^\s:\[]*):(?P<match>\d+)\b`},
+	// `ps aux`: root 1234 0.0 0.1 ..., `ps -ef`: root 1234 1 0 10:00 ?
+	// and `ps`: 1234 pts/0 00:00:01 bash
+	{"pid", `^\S+\s+(?P<match>\d+)\s+\d+\.\d+\s+\d+\.\d+\s`},
+	{"pid", `^\S+\s+(?P<match>\d+)\s+\d+\s+\d+\s+(?:\d{1,2}:\d{2}|[A-Z][a-z]{2}\d{1,2}|\d{4})\s`},
+	{"pid", `^\s*(?P<match>\d+)\s+(?:pts/\d+|tty\w*|\?|\?\?|console)\s+\d+:\d{2}(?::\d{2})?(?:\.\d+)?\s`},
+}
+
+// WithProcessPatterns enables the process patterns
+func WithProcessPatterns() Option {
+	return optionFunc(func(s *State) {
+		s.ProcessPatterns = ProcessPatterns
+		s.cacheValid = false
+	})
+}
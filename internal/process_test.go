@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestProcessPatterns(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		pids  []string
+		ports []string
+	}{
+		{
+			name: "ss",
+			lines: []string{
+				"State  Recv-Q Send-Q Local Address:Port  Peer Address:Port Process",
+				`LISTEN 0      128          0.0.0.0:8080       0.0.0.0:*     users:(("nginx",pid=1234,fd=6),("nginx",pid=1235,fd=6))`,
+				`tcp   LISTEN 0      4096            [::]:22           [::]:*     users:(("sshd",pid=812,fd=4))`,
+				"ESTAB  0      0      10.0.0.5:43210    93.184.216.34:443",
+			},
+			pids:  []string{"1234", "1235", "812"},
+			ports: []string{"8080", "22", "43210"},
+		},
+		{
+			name: "netstat",
+			lines: []string{
+				"Proto Recv-Q Send-Q Local Address           Foreign Address         State       PID/Program name",
+				"tcp        0      0 0.0.0.0:22              0.0.0.0:*               LISTEN      812/sshd",
+				"tcp6       0      0 :::5432                 :::*                    LISTEN      950/postgres",
+				"udp        0      0 127.0.0.53:53           0.0.0.0:*                           640/systemd-resolve",
+				"tcp4       0      0  *.3000                 *.*                    LISTEN",
+			},
+			pids:  []string{"812", "950", "640"},
+			ports: []string{"22", "5432", "53", "3000"},
+		},
+		{
+			name: "lsof",
+			lines: []string{
+				"COMMAND   PID USER   FD   TYPE DEVICE SIZE/OFF NODE NAME",
+				"nginx    1234 root    6u  IPv4  12345      0t0  TCP *:8080 (LISTEN)",
+				"postgres  950 pg     7u  IPv6  23456      0t0  TCP [::1]:5432 (LISTEN)",
+				"node     4321 dev   21u  IPv4  34567      0t0  TCP 127.0.0.1:3000->127.0.0.1:51234 (ESTABLISHED)",
+			},
+			pids:  []string{"1234", "950", "4321"},
+			ports: []string{"8080", "5432", "3000"},
+		},
+		{
+			name: "ps",
+			lines: []string{
+				"root         1  0.0  0.1 168404 11744 ?        Ss   Oct14   0:05 /sbin/init",
+				"dev       4321  1.2  3.4 987654 65432 pts/1    Sl+  10:02   1:23 node server.js",
+				"UID        PID  PPID  C STIME TTY          TIME CMD",
+				"dev       5678     1  0 10:02 pts/1    00:00:00 bash",
+				"    PID TTY          TIME CMD",
+				"   9012 pts/1    00:00:00 ps",
+			},
+			pids: []string{"1", "4321", "5678", "9012"},
+		},
+		{
+			name:  "other output",
+			lines: []string{"Progress 3/5 done", "Listening on 0.0.0.0:8080", "version 1 2 3:45 ok"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := NewStateFromLines(tt.lines, "abcd", []string{}, WithProcessPatterns()).Matches(false, 0)
+
+			var pids, ports []string
+			for _, result := range results {
+				switch result.Pattern {
+				case "pid":
+					pids = append(pids, result.Text)
+				case "port":
+					ports = append(ports, result.Text)
+				}
+			}
+			if !slices.Equal(pids, tt.pids) {
+				t.Errorf("Expected pids %q, got %q", tt.pids, pids)
+			}
+			if !slices.Equal(ports, tt.ports) {
+				t.Errorf("Expected ports %q, got %q", tt.ports, ports)
+			}
+		})
+	}
+}
+
+func TestProcessPatternsDisabled(t *testing.T) {
+	lines := []string{`LISTEN 0 128 0.0.0.0:8080 0.0.0.0:* users:(("nginx",pid=1234,fd=6))`}
+	results := NewStateFromLines(lines, "abcd", []string{}).Matches(false, 0)
+
+	for _, result := range results {
+		if result.Pattern == "pid" || result.Pattern == "port" {
+			t.Errorf("Expected no process matches without WithProcessPatterns, got %s: %q", result.Pattern, result.Text)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// ProximityConfig assigns the shortest hints to the matches closest to a line
+type ProximityConfig struct {
+	// Line is the 0-based line of the cursor, negative for the last non-empty line
+	Line int
+}
+
+// WithProximity assigns the shortest hints to the matches closest to line,
+// the cursor line of the pane the text was captured from. A negative line
+// uses the last non-empty line, where the prompt usually is
+func WithProximity(line int) Option {
+	return optionFunc(func(s *State) {
+		s.ProximityConfig = &ProximityConfig{Line: line}
+	})
+}
+
+// proximityLine returns the line distances are measured from
+func (s *State) proximityLine() int {
+	line := s.ProximityConfig.Line
+	if line >= 0 && line < len(s.Lines) {
+		return line
+	}
+
+	for y := len(s.Lines) - 1; y >= 0; y-- {
+		if strings.TrimSpace(s.Lines[y]) != "" {
+			return y
+		}
+	}
+	return 0
+}
+
+// proximityOrder returns the indices of matches ordered by distance to the
+// proximity line. On equal distance lines above win, as that is the output
+// the user just read, then matches on the same line keep their order
+func (s *State) proximityOrder(matches []Match) []int {
+	line := s.proximityLine()
+
+	order := make([]int, len(matches))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		ya, yb := matches[a].Y, matches[b].Y
+		return cmp.Or(
+			cmp.Compare(abs(ya-line), abs(yb-line)),
+			// Above the line first
+			cmp.Compare(ya, yb),
+		)
+	})
+	return order
+}
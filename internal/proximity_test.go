@@ -0,0 +1,48 @@
+package internal
+
+import "testing"
+
+func TestProximityHints(t *testing.T) {
+	lines := []string{"10.0.0.1", "10.0.0.2", "", "10.0.0.3", "$ ls", ""}
+
+	tests := []struct {
+		name string
+		line int
+		want map[string]string
+	}{
+		{
+			name: "last non-empty line",
+			line: -1,
+			want: map[string]string{"10.0.0.3": "a", "10.0.0.2": "b", "10.0.0.1": "c"},
+		},
+		{
+			name: "cursor line",
+			line: 1,
+			want: map[string]string{"10.0.0.2": "a", "10.0.0.1": "b", "10.0.0.3": "c"},
+		},
+		{
+			name: "above wins on equal distance",
+			line: 2,
+			want: map[string]string{"10.0.0.2": "a", "10.0.0.3": "b", "10.0.0.1": "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := NewStateFromLines(lines, "abcd", []string{}, WithProximity(tt.line)).Matches(false, 0)
+			if len(results) != len(tt.want) {
+				t.Fatalf("Expected %d matches, got %d", len(tt.want), len(results))
+			}
+			for _, result := range results {
+				if result.Hint == nil || *result.Hint != tt.want[result.Text] {
+					t.Errorf("Expected hint %q for %s, got %v", tt.want[result.Text], result.Text, result.Hint)
+				}
+			}
+
+			// Matches stay in reading order
+			if results[0].Text != "10.0.0.1" || results[2].Text != "10.0.0.3" {
+				t.Errorf("Expected matches in reading order, got %s, %s", results[0].Text, results[2].Text)
+			}
+		})
+	}
+}
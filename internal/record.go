@@ -0,0 +1,117 @@
+package internal
+
+import (
+	"errors"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// errReplayEnded ends a replayed view whose events ran out before it exited
+var errReplayEnded = errors.New("the recorded events ran out")
+
+// RecordedEvent is a key press, a mouse wheel turn or a resize the view got,
+// as recorded by WithRecorder and replayed by WithReplay
+type RecordedEvent struct {
+	Key     tcell.Key        `json:"key,omitempty"`
+	Rune    string           `json:"rune,omitempty"` // Character of tcell.KeyRune presses
+	Mod     tcell.ModMask    `json:"mod,omitempty"`
+	Buttons tcell.ButtonMask `json:"buttons,omitempty"` // Wheel of mouse events, at X and Y
+	X       int              `json:"x,omitempty"`
+	Y       int              `json:"y,omitempty"`
+	Width   int              `json:"width,omitempty"` // Size of resizes
+	Height  int              `json:"height,omitempty"`
+}
+
+// WithRecorder calls record with every key press, wheel turn and resize of
+// the view, including those of its review and pattern filter
+func WithRecorder(record func(RecordedEvent)) ViewOption {
+	return viewOptionFunc(func(o *viewOptions) {
+		o.recorder = record
+	})
+}
+
+// WithReplay shows the view on a simulated screen rather than a terminal,
+// replaying events in place of the user. The view exits without a selection
+// once they run out
+func WithReplay(events []RecordedEvent) ViewOption {
+	return viewOptionFunc(func(o *viewOptions) {
+		o.replay = events
+	})
+}
+
+// recordingScreen records the events of the screen it wraps as they are
+// polled
+type recordingScreen struct {
+	tcell.Screen
+	record func(RecordedEvent)
+}
+
+func (s *recordingScreen) PollEvent() tcell.Event {
+	ev := s.Screen.PollEvent()
+	switch ev := ev.(type) {
+	case *tcell.EventKey:
+		recorded := RecordedEvent{Key: ev.Key(), Mod: ev.Modifiers()}
+		if ev.Key() == tcell.KeyRune {
+			recorded.Rune = string(ev.Rune())
+		}
+		s.record(recorded)
+	case *tcell.EventMouse:
+		if buttons := ev.Buttons() & (tcell.WheelUp | tcell.WheelDown); buttons != 0 {
+			x, y := ev.Position()
+			s.record(RecordedEvent{Buttons: buttons, X: x, Y: y, Mod: ev.Modifiers()})
+		}
+	case *tcell.EventResize:
+		width, height := ev.Size()
+		s.record(RecordedEvent{Width: width, Height: height})
+	}
+	return ev
+}
+
+// replayScreen is a simulated screen whose events are those of a recording,
+// in order, followed by an error once they run out
+type replayScreen struct {
+	tcell.SimulationScreen
+	events []RecordedEvent
+}
+
+func newReplayScreen(events []RecordedEvent) *replayScreen {
+	return &replayScreen{SimulationScreen: tcell.NewSimulationScreen("UTF-8"), events: events}
+}
+
+func (s *replayScreen) PollEvent() tcell.Event {
+	if len(s.events) == 0 {
+		return tcell.NewEventError(errReplayEnded)
+	}
+	ev := s.events[0]
+	s.events = s.events[1:]
+
+	switch {
+	case ev.Width > 0 && ev.Height > 0:
+		s.SetSize(ev.Width, ev.Height)
+		return tcell.NewEventResize(ev.Width, ev.Height)
+	case ev.Buttons != 0:
+		return tcell.NewEventMouse(ev.X, ev.Y, ev.Buttons, ev.Mod)
+	default:
+		var r rune
+		if runes := []rune(ev.Rune); len(runes) > 0 {
+			r = runes[0]
+		}
+		return tcell.NewEventKey(ev.Key, r, ev.Mod)
+	}
+}
+
+// openScreen opens the screen the view is shown on: a replayed one, or the
+// one of the terminal, recorded when a recorder is set
+func (v *View) openScreen() (tcell.Screen, error) {
+	if v.replay != nil {
+		return newReplayScreen(v.replay), nil
+	}
+	screen, err := openTerminalScreen(v.terminal)
+	if err != nil {
+		return nil, err
+	}
+	if v.recorder != nil {
+		return &recordingScreen{Screen: screen, record: v.recorder}, nil
+	}
+	return screen, nil
+}
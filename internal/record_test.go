@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func newReplayTestView(events []RecordedEvent, recorded *[]RecordedEvent) *View {
+	opts := []ViewOption{WithReplay(events)}
+	if recorded != nil {
+		opts = append(opts, WithRecorder(func(ev RecordedEvent) {
+			*recorded = append(*recorded, ev)
+		}))
+	}
+	state := NewState("see 10.0.0.1 and /tmp/foo.txt", "qwerty", nil)
+	return NewView(
+		state, true, false, 0, false, "",
+		GetColor("default"), GetColor("default"), GetColor("default"), GetColor("default"),
+		GetColor("default"), GetColor("default"), GetColor("default"), GetColor("default"),
+		opts...,
+	)
+}
+
+func TestViewReplay(t *testing.T) {
+	// Hints are given top to bottom: a for the IP, s for the path
+	events := []RecordedEvent{
+		{Width: 40, Height: 5},
+		{Key: tcell.KeyRune, Rune: "s"},
+		{Key: tcell.KeyRune, Rune: "a"},
+		{Key: tcell.KeyRune, Rune: " "},
+	}
+	view := newReplayTestView(events, nil)
+	chosen := view.Present()
+	if err := view.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	var texts []string
+	for _, mat := range chosen {
+		texts = append(texts, mat.Text)
+	}
+	if len(texts) != 2 || texts[0] != "/tmp/foo.txt" || texts[1] != "10.0.0.1" {
+		t.Errorf("Expected the path then the IP to be chosen, got %q", texts)
+	}
+
+	// Running out of events exits without a selection
+	view = newReplayTestView(events[:2], nil)
+	if chosen := view.Present(); len(chosen) != 0 {
+		t.Errorf("Expected no selection once the events run out, got %v", chosen)
+	}
+}
+
+func TestRecordingScreen(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("Failed to initialize simulation screen: %v", err)
+	}
+	defer screen.Fini()
+
+	var recorded []RecordedEvent
+	recording := &recordingScreen{Screen: screen, record: func(ev RecordedEvent) {
+		recorded = append(recorded, ev)
+	}}
+	if err := screen.PostEvent(tcell.NewEventResize(30, 10)); err != nil {
+		t.Fatal(err)
+	}
+	screen.InjectKey(tcell.KeyRune, 'x', tcell.ModNone)
+	screen.InjectKey(tcell.KeyCtrlN, 0, tcell.ModCtrl)
+	screen.InjectMouse(3, 4, tcell.WheelDown, tcell.ModNone)
+	screen.InjectMouse(3, 4, tcell.ButtonNone, tcell.ModNone)
+	for range 5 {
+		recording.PollEvent()
+	}
+
+	expected := []RecordedEvent{
+		{Width: 30, Height: 10},
+		{Key: tcell.KeyRune, Rune: "x"},
+		{Key: tcell.KeyCtrlN, Mod: tcell.ModCtrl},
+		{Buttons: tcell.WheelDown, X: 3, Y: 4},
+	}
+	if len(recorded) != len(expected) {
+		t.Fatalf("Expected %d recorded events, got %+v", len(expected), recorded)
+	}
+	for i := range expected {
+		if recorded[i] != expected[i] {
+			t.Errorf("Expected event %d to be %+v, got %+v", i, expected[i], recorded[i])
+		}
+	}
+}
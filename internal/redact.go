@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// privateKeyPattern matches the PEM blocks of private keys, such as RSA,
+// EC, OpenSSH, encrypted PKCS#8 or PGP keys. A block whose end is missing,
+// cut off by the capture, goes on to the end of the text
+var privateKeyPattern = regexp.MustCompile(
+	`-----BEGIN [A-Z0-9 ]*PRIVATE KEY[A-Z0-9 ]*-----(?s:.*?)(?:-----END [A-Z0-9 ]*PRIVATE KEY[A-Z0-9 ]*-----|\z)`)
+
+// RedactedPrivateKey takes the place of private keys in RedactPrivateKeys
+const RedactedPrivateKey = "[REDACTED PRIVATE KEY]"
+
+// WithRedactedPrivateKeys leaves the lines of the private key blocks of the
+// input out of the matches, nothing inside them can be picked
+func WithRedactedPrivateKeys() Option {
+	return optionFunc(func(s *State) {
+		s.RedactPrivateKeys = true
+	})
+}
+
+// RedactPrivateKeys returns text with its private key blocks replaced by
+// RedactedPrivateKey, to keep them out of the files written
+func RedactPrivateKeys(text string) string {
+	return privateKeyPattern.ReplaceAllLiteralString(text, RedactedPrivateKey)
+}
+
+// privateKeyRegions returns the regions of the private key blocks of the
+// lines
+func (s *State) privateKeyRegions() []ExclusionRegion {
+	var regions []ExclusionRegion
+	for _, indices := range privateKeyPattern.FindAllStringIndex(strings.Join(s.Lines, "\n"), -1) {
+		startLine, startCol := lineOffset(s.Lines, indices[0])
+		endLine, endCol := lineOffset(s.Lines, indices[1])
+		regions = append(regions, ExclusionRegion{
+			StartLine: startLine,
+			StartCol:  startCol,
+			EndLine:   endLine,
+			EndCol:    endCol,
+		})
+	}
+	return regions
+}
+
+// dropPrivateKeyMatches drops the matches on the lines of private key
+// blocks when they are redacted
+func (s *State) dropPrivateKeyMatches(matches []Match) []Match {
+	if !s.RedactPrivateKeys {
+		return matches
+	}
+	regions := s.privateKeyRegions()
+	if len(regions) == 0 {
+		return matches
+	}
+	return slices.DeleteFunc(matches, func(mat Match) bool {
+		endLine, endCol := matchEnd(mat)
+		for _, region := range regions {
+			if s.regionsOverlap(mat.Y, mat.X, endLine, endCol,
+				region.StartLine, region.StartCol, region.EndLine, region.EndCol) {
+				slog.Debug("Dropping match in a private key", "pattern", mat.Pattern, "x", mat.X, "y", mat.Y)
+				return true
+			}
+		}
+		return false
+	})
+}
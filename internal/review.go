@@ -0,0 +1,200 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/Hanaasagi/magonote/internal/shell"
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+// ReviewConfig holds the settings for reviewing multi-selections
+type ReviewConfig struct {
+	// Command is the template the selection will be expanded into, `{}` is
+	// replaced by the selected texts, see shell.Expand
+	Command string
+}
+
+// Review is a confirmation overlay listing the chosen items and the exact
+// command that will be run with them
+type Review struct {
+	screen   tcell.Screen
+	items    []ChosenMatch
+	selected []bool
+	cursor   int
+	command  string
+	colors   ViewColors
+}
+
+// NewReview creates a new Review for the given chosen items
+func NewReview(screen tcell.Screen, items []ChosenMatch, command string, colors ViewColors) *Review {
+	selected := make([]bool, len(items))
+	for i := range selected {
+		selected[i] = true
+	}
+
+	return &Review{
+		screen:   screen,
+		items:    items,
+		selected: selected,
+		command:  command,
+		colors:   colors,
+	}
+}
+
+// Toggle flips the selection state of the item at index i
+func (r *Review) Toggle(i int) {
+	if i < 0 || i >= len(r.selected) {
+		return
+	}
+	r.selected[i] = !r.selected[i]
+}
+
+// ToggleAll selects every item, or deselects all of them if all are selected
+func (r *Review) ToggleAll() {
+	all := true
+	for _, s := range r.selected {
+		all = all && s
+	}
+	for i := range r.selected {
+		r.selected[i] = !all
+	}
+}
+
+// Selected returns the items that are still selected
+func (r *Review) Selected() []ChosenMatch {
+	result := make([]ChosenMatch, 0, len(r.items))
+	for i, item := range r.items {
+		if r.selected[i] {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// Preview returns the command that will be executed for the current selection
+func (r *Review) Preview() string {
+	selected := r.Selected()
+	texts := make([]string, len(selected))
+	for i, item := range selected {
+		texts[i] = item.Text
+	}
+	return shell.Expand(r.command, texts...)
+}
+
+// Present runs the review loop and returns the confirmed items,
+// or an empty slice if the user aborted
+func (r *Review) Present() []ChosenMatch {
+	r.render()
+
+	for {
+		switch ev := r.screen.PollEvent().(type) {
+		case *tcell.EventKey:
+			if done, confirmed := r.handleKeyEvent(ev); done {
+				if !confirmed {
+					return []ChosenMatch{}
+				}
+				return r.Selected()
+			}
+		case *tcell.EventResize, *resizeEvent:
+			r.screen.Sync()
+		case *tcell.EventError:
+			return []ChosenMatch{}
+		}
+
+		r.render()
+	}
+}
+
+// handleKeyEvent processes a key event, it reports whether the review is
+// finished and whether the selection was confirmed
+func (r *Review) handleKeyEvent(ev *tcell.EventKey) (done bool, confirmed bool) {
+	switch ev.Key() {
+	case tcell.KeyEscape, tcell.KeyCtrlC:
+		return true, false
+	case tcell.KeyEnter:
+		return true, len(r.Selected()) > 0
+	case tcell.KeyUp, tcell.KeyCtrlP:
+		if r.cursor > 0 {
+			r.cursor--
+		}
+	case tcell.KeyDown, tcell.KeyCtrlN, tcell.KeyTab:
+		if r.cursor < len(r.items)-1 {
+			r.cursor++
+		}
+	case tcell.KeyRune:
+		switch ev.Rune() {
+		case ' ', 'x':
+			r.Toggle(r.cursor)
+		case 'a':
+			r.ToggleAll()
+		case 'k':
+			if r.cursor > 0 {
+				r.cursor--
+			}
+		case 'j':
+			if r.cursor < len(r.items)-1 {
+				r.cursor++
+			}
+		case 'q':
+			return true, false
+		}
+	}
+	return false, false
+}
+
+// render draws the review overlay
+func (r *Review) render() {
+	r.screen.Clear()
+	width, height := r.screen.Size()
+
+	titleStyle := tcell.StyleDefault.Bold(true)
+	itemStyle := tcell.StyleDefault.
+		Foreground(colorToTcell(r.colors.foreground))
+	cursorStyle := tcell.StyleDefault.
+		Foreground(colorToTcell(r.colors.selectForeground)).
+		Background(colorToTcell(r.colors.selectBackground))
+	hintStyle := tcell.StyleDefault.
+		Foreground(colorToTcell(r.colors.hintForeground)).
+		Background(colorToTcell(r.colors.hintBackground))
+
+	y := 0
+	r.drawString(0, y, width, "Review selection before running command", titleStyle)
+	y += 2
+
+	for i, item := range r.items {
+		if y >= height-4 {
+			break
+		}
+		mark := "[ ]"
+		if r.selected[i] {
+			mark = "[x]"
+		}
+		style := itemStyle
+		if i == r.cursor {
+			style = cursorStyle
+		}
+		r.drawString(0, y, width, fmt.Sprintf("%s %s", mark, item.Text), style)
+		y++
+	}
+
+	y++
+	r.drawString(0, y, width, "$ "+r.Preview(), hintStyle)
+
+	help := "space: toggle  a: toggle all  enter: run  esc: abort"
+	r.drawString(0, height-1, width, help, titleStyle)
+
+	r.screen.Show()
+}
+
+// drawString writes text at the given position, truncated to width
+func (r *Review) drawString(x, y, width int, text string, style tcell.Style) {
+	for _, ch := range text {
+		w := runewidth.RuneWidth(ch)
+		if x+w > width {
+			return
+		}
+		r.screen.SetContent(x, y, ch, nil, style)
+		x += w
+	}
+}
@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func newTestReview(t *testing.T, texts ...string) *Review {
+	t.Helper()
+
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("Failed to init simulation screen: %v", err)
+	}
+	t.Cleanup(screen.Fini)
+
+	items := make([]ChosenMatch, len(texts))
+	for i, text := range texts {
+		items[i] = ChosenMatch{Text: text}
+	}
+
+	colors := ViewColors{
+		selectForeground: GetColor("default"),
+		selectBackground: GetColor("default"),
+		foreground:       GetColor("default"),
+		hintForeground:   GetColor("default"),
+		hintBackground:   GetColor("default"),
+	}
+	return NewReview(screen, items, "rm {}", colors)
+}
+
+func TestReviewPreview(t *testing.T) {
+	review := newTestReview(t, "a.txt", "b.txt", "c.txt")
+
+	if got := review.Preview(); got != "rm a.txt b.txt c.txt" {
+		t.Errorf("Expected 'rm a.txt b.txt c.txt', got '%s'", got)
+	}
+
+	review.Toggle(1)
+	if got := review.Preview(); got != "rm a.txt c.txt" {
+		t.Errorf("Expected 'rm a.txt c.txt', got '%s'", got)
+	}
+
+	review.ToggleAll()
+	if got := len(review.Selected()); got != 3 {
+		t.Errorf("Expected 3 selected items, got %d", got)
+	}
+
+	review.ToggleAll()
+	if got := len(review.Selected()); got != 0 {
+		t.Errorf("Expected 0 selected items, got %d", got)
+	}
+}
+
+func TestReviewKeyEvents(t *testing.T) {
+	review := newTestReview(t, "a.txt", "b.txt")
+
+	review.handleKeyEvent(tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone))
+	review.handleKeyEvent(tcell.NewEventKey(tcell.KeyRune, ' ', tcell.ModNone))
+
+	selected := review.Selected()
+	if len(selected) != 1 || selected[0].Text != "a.txt" {
+		t.Errorf("Expected only 'a.txt' selected, got %v", selected)
+	}
+
+	done, confirmed := review.handleKeyEvent(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	if !done || !confirmed {
+		t.Errorf("Expected enter to confirm, got done=%v confirmed=%v", done, confirmed)
+	}
+
+	done, confirmed = review.handleKeyEvent(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone))
+	if !done || confirmed {
+		t.Errorf("Expected escape to abort, got done=%v confirmed=%v", done, confirmed)
+	}
+
+	// Confirming an empty selection is treated as abort
+	review.Toggle(0)
+	done, confirmed = review.handleKeyEvent(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	if !done || confirmed {
+		t.Errorf("Expected empty selection to abort, got done=%v confirmed=%v", done, confirmed)
+	}
+}
@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+)
+
+// Scopes of WithScope
+const (
+	ScopeAll         = "all"          // Every line
+	ScopeLastCommand = "last-command" // The output of the last command
+)
+
+// PromptMarkers are the characters shells usually end or start prompts with,
+// those the last-command scope recognizes prompts by
+const PromptMarkers = "$#%>❯➜λ»"
+
+// WithScope restricts matches to a part of the text, ScopeAll or
+// ScopeLastCommand
+func WithScope(scope string) Option {
+	return optionFunc(func(s *State) {
+		s.Scope = scope
+	})
+}
+
+// scopeLines returns the range [start, end) of lines matches are kept from
+func (s *State) scopeLines() (start, end int) {
+	if s.Scope != ScopeLastCommand {
+		return 0, len(s.Lines)
+	}
+
+	// Shell integration marks tell precisely where the output is
+	if start, end, ok := s.markedScopeLines(); ok {
+		return start, end
+	}
+
+	// The last non-empty line is the prompt waiting for the next command
+	current := len(s.Lines) - 1
+	for current >= 0 && strings.TrimSpace(s.Lines[current]) == "" {
+		current--
+	}
+	if current < 0 {
+		return 0, len(s.Lines)
+	}
+	key, ok := promptKey(s.Lines[current])
+	if !ok {
+		slog.Debug("No prompt on the last line, keeping every line", "line", s.Lines[current])
+		return 0, len(s.Lines)
+	}
+
+	// The output starts after the prompt the last command was typed at
+	for y := current - 1; y >= 0; y-- {
+		if k, ok := promptKey(s.Lines[y]); ok && k == key {
+			return y + 1, current
+		}
+	}
+	return 0, current
+}
+
+// LooksLikePrompt reports whether line starts with a prompt the last-command
+// scope recognizes
+func LooksLikePrompt(line string) bool {
+	_, ok := promptKey(line)
+	return ok
+}
+
+// promptKey returns the part of line identifying the prompt it starts with,
+// its first word up to a colon, such as "user@host" of "user@host:~/src$".
+// ok is false when the line doesn't look like a prompt: one of its first two
+// words has to end with a prompt marker or the first has to start with one,
+// as in "$ ls", "user@host:~$ ls", "[user@host src]$ ls" or "➜  src"
+func promptKey(line string) (key string, ok bool) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", false
+	}
+
+	first, _ := utf8.DecodeRuneInString(fields[0])
+	ok = strings.ContainsRune(PromptMarkers, first)
+	for _, field := range fields[:min(2, len(fields))] {
+		last, _ := utf8.DecodeLastRuneInString(field)
+		ok = ok || strings.ContainsRune(PromptMarkers, last)
+	}
+	if !ok {
+		return "", false
+	}
+
+	key, _, _ = strings.Cut(fields[0], ":")
+	return key, true
+}
+
+// scopeMatches drops the matches outside of the scope
+func (s *State) scopeMatches(matches []Match) []Match {
+	start, end := s.scopeLines()
+	if start == 0 && end == len(s.Lines) {
+		return matches
+	}
+
+	scoped := matches[:0]
+	for _, match := range matches {
+		if match.Y >= start && match.Y < end {
+			scoped = append(scoped, match)
+		}
+	}
+	return scoped
+}
@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLastCommandScope(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  []string
+	}{
+		{
+			name:  "dollar prompt",
+			lines: []string{"$ cat hosts", "10.0.0.1", "$ ls /tmp", "/tmp/a.log", "/tmp/b.log", "$ ", ""},
+			want:  []string{"/tmp/a.log", "/tmp/b.log"},
+		},
+		{
+			name: "prompt with changing directory",
+			lines: []string{
+				"user@host:~$ cd /var/log",
+				"user@host:/var/log$ ls",
+				"/var/log/syslog",
+				"user@host:/var/log$",
+			},
+			want: []string{"/var/log/syslog"},
+		},
+		{
+			name:  "no earlier prompt",
+			lines: []string{"10.0.0.1", "10.0.0.2", "❯"},
+			want:  []string{"10.0.0.1", "10.0.0.2"},
+		},
+		{
+			name:  "no prompt on the last line",
+			lines: []string{"$ cat hosts", "10.0.0.1", "10.0.0.2"},
+			want:  []string{"10.0.0.1", "10.0.0.2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := NewStateFromLines(tt.lines, "abcd", []string{}, WithScope(ScopeLastCommand))
+			var got []string
+			for _, match := range state.Matches(false, 0) {
+				got = append(got, match.Text)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+)
+
+// SearchPattern names the matches of a regex typed in the view
+const SearchPattern = "search"
+
+// searchMatches returns the non-empty matches of re in the lines in scope,
+// at most the match limit
+func (s *State) searchMatches(re *regexp.Regexp) []Match {
+	var matches []Match
+	start, end := s.scopeLines()
+	for y := start; y < end && !s.matchLimitReached(matches); y++ {
+		for _, loc := range re.FindAllStringIndex(s.Lines[y], -1) {
+			if loc[0] == loc[1] {
+				continue
+			}
+			matches = append(matches, Match{
+				X:       loc[0],
+				Y:       y,
+				Pattern: SearchPattern,
+				Text:    s.Lines[y][loc[0]:loc[1]],
+			})
+		}
+	}
+	return s.limitMatches(matches)
+}
+
+// mergeSearchMatches returns matches with found, without hints. Matches
+// overlapping one of found give way to it, as the search asked for its text
+func (s *State) mergeSearchMatches(matches, found []Match) []Match {
+	merged := s.filterOverlappingMatches(matches, found)
+	merged = append(merged, found...)
+	slices.SortStableFunc(merged, func(a, b Match) int {
+		return cmp.Or(cmp.Compare(a.Y, b.Y), cmp.Compare(a.X, b.X))
+	})
+	for i := range merged {
+		merged[i].Hint = nil
+	}
+	return merged
+}
@@ -0,0 +1,105 @@
+package shell
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Placeholder is replaced by the selection in command templates
+const Placeholder = "{}"
+
+// safeWord matches the words the shell takes literally without quotes
+var safeWord = regexp.MustCompile(`^[\w@%+=:,./-]+$`)
+
+// Quote returns s as a single shell word that the shell takes literally,
+// quoting it only when needed
+func Quote(s string) string {
+	if safeWord.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Expand replaces every Placeholder of the command template with values,
+// escaped for the quotes the placeholder is in so that the shell never
+// interprets them, whether the template writes {}, "{}" or '{}'. Outside of
+// quotes every value is a word of its own, inside quotes the values are
+// joined with spaces
+func Expand(template string, values ...string) string {
+	return ExpandVars(template, nil, values...)
+}
+
+// ExpandVars is Expand also replacing every {name} of the template with the
+// value of name in vars, escaped the same way
+func ExpandVars(template string, vars map[string]string, values ...string) string {
+	words := make([]string, len(values))
+	for i, value := range values {
+		words[i] = Quote(value)
+	}
+	unquoted := strings.Join(words, " ")
+	joined := strings.Join(values, " ")
+
+	var b strings.Builder
+	var quote byte // Quote the template is in at i, 0 outside of quotes
+	for i := 0; i < len(template); i++ {
+		if strings.HasPrefix(template[i:], Placeholder) {
+			if quote == 0 {
+				b.WriteString(unquoted)
+			} else {
+				writeQuoted(&b, joined, quote)
+			}
+			i += len(Placeholder) - 1
+			continue
+		}
+		if name, value, ok := matchVar(template[i:], vars); ok {
+			if quote == 0 {
+				b.WriteString(Quote(value))
+			} else {
+				writeQuoted(&b, value, quote)
+			}
+			i += len(name) + 1
+			continue
+		}
+
+		c := template[i]
+		switch {
+		case c == '\\' && quote != '\'' && i+1 < len(template):
+			// An escaped character never opens or closes quotes
+			b.WriteByte(c)
+			i++
+			c = template[i]
+		case (c == '"' || c == '\'') && quote == 0:
+			quote = c
+		case c == quote:
+			quote = 0
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// matchVar returns the name and value of the {name} of vars s starts with
+func matchVar(s string, vars map[string]string) (string, string, bool) {
+	if len(vars) == 0 || !strings.HasPrefix(s, "{") {
+		return "", "", false
+	}
+	name, _, ok := strings.Cut(s[1:], "}")
+	if !ok {
+		return "", "", false
+	}
+	value, ok := vars[name]
+	return name, value, ok
+}
+
+// writeQuoted writes value inside the quote of the template, the quotes
+// being closed around it when needed
+func writeQuoted(b *strings.Builder, value string, quote byte) {
+	quoted := Quote(value)
+	if safeWord.MatchString(quoted) {
+		b.WriteString(quoted)
+		return
+	}
+	b.WriteByte(quote)
+	b.WriteString(quoted)
+	b.WriteByte(quote)
+}
@@ -0,0 +1,113 @@
+package shell
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		values   []string
+		want     string
+	}{
+		{
+			name:     "safe value",
+			template: `tmux set-buffer -- "{}"`,
+			values:   []string{"src/main.go:12"},
+			want:     `tmux set-buffer -- "src/main.go:12"`,
+		},
+		{
+			name:     "bare placeholder",
+			template: "echo {}",
+			values:   []string{"a b"},
+			want:     "echo 'a b'",
+		},
+		{
+			name:     "double quoted placeholder",
+			template: `tmux set-buffer -- "{}"`,
+			values:   []string{"$(id)"},
+			want:     `tmux set-buffer -- ""'$(id)'""`,
+		},
+		{
+			name:     "single quoted placeholder",
+			template: "echo 'Copied {}'",
+			values:   []string{"it's"},
+			want:     `echo 'Copied ''it'\''s'''`,
+		},
+		{
+			name:     "escaped quote",
+			template: `echo "\"{}"`,
+			values:   []string{"a b"},
+			want:     `echo "\""'a b'""`,
+		},
+		{
+			name:     "several values",
+			template: `rm {} && echo "Removed {}"`,
+			values:   []string{"a.txt", "b c.txt"},
+			want:     `rm a.txt 'b c.txt' && echo "Removed "'a.txt b c.txt'""`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Expand(tt.template, tt.values...); got != tt.want {
+				t.Errorf("Expand() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandHostileInput(t *testing.T) {
+	values := []string{
+		`"; echo injected; "`,
+		`$(echo injected)`,
+		"`echo injected`",
+		`it's`,
+		`'; echo injected; '`,
+		`\"; echo injected #`,
+		`${HOME}`,
+		"a\nb",
+		"",
+	}
+	// Templates with what they print around the value
+	templates := map[string][2]string{
+		`printf %s {}`:        {"", ""},
+		`printf %s "{}"`:      {"", ""},
+		`printf %s '{}'`:      {"", ""},
+		`printf %s "<{}>"`:    {"<", ">"},
+		`printf %s 'a''b {}'`: {"ab ", ""},
+	}
+
+	for template, around := range templates {
+		for _, value := range values {
+			output, err := exec.Command("sh", "-c", Expand(template, value)).Output()
+			if err != nil {
+				t.Fatalf("Running %q with %q: %v", template, value, err)
+			}
+			want := around[0] + value + around[1]
+			if string(output) != want {
+				t.Errorf("%q with %q printed %q, want %q", template, value, output, want)
+			}
+		}
+	}
+}
+
+func TestExpandVars(t *testing.T) {
+	vars := map[string]string{"host": "me@box", "path": "/tmp/a b"}
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"scp {host}:{path} .", "scp me@box:'/tmp/a b' ."},
+		{`echo "{path} on {host}"`, `echo ""'/tmp/a b'" on me@box"`},
+		{"echo {unknown} {}", "echo {unknown} x"},
+	}
+
+	for _, tt := range tests {
+		if got := ExpandVars(tt.template, vars, "x"); got != tt.want {
+			t.Errorf("ExpandVars(%q) = %v, want %v", tt.template, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package internal
+
+import (
+	"context"
+	"regexp"
+	"slices"
+)
+
+// stackFramePattern is the name of the matches of the frames of stack traces
+const stackFramePattern = "stack_frame"
+
+// maxFrameGap is the most lines between two frames of the same stack trace,
+// such as the function line of Go traces or the source and caret lines of
+// Python ones
+const maxFrameGap = 3
+
+// stackFrameFormat recognizes the frames of the stack traces of a language.
+// Its expression has "file" and "line" groups and an optional "func" group
+type stackFrameFormat struct {
+	Language string
+	Pattern  *regexp.Regexp
+	// MostRecentLast is set when the frame of the crash ends the trace
+	MostRecentLast bool
+	// Library matches the file, or the function for Java, of the frames of
+	// the standard library and dependencies
+	Library *regexp.Regexp
+}
+
+// stackFrameFormats are the recognized stack trace formats
+var stackFrameFormats = []stackFrameFormat{
+	{
+		// 	/home/me/app/main.go:42 +0x1d
+		Language: "go",
+		Pattern:  regexp.MustCompile(`^\s+(?P<file>\S+\.go):(?P<line>\d+)(?: \+0x[0-9a-f]+)?$`),
+		Library:  regexp.MustCompile(`/go[^/]*/src/|/pkg/mod/|/libexec/src/|^<autogenerated>`),
+	},
+	{
+		//   File "/app/main.py", line 12, in handler
+		Language:       "python",
+		Pattern:        regexp.MustCompile(`^\s*File "(?P<file>[^"]+)", line (?P<line>\d+)(?:, in (?P<func>\S+))?`),
+		MostRecentLast: true,
+		Library:        regexp.MustCompile(`site-packages/|dist-packages/|/lib/python\d|^<frozen `),
+	},
+	{
+		// 	at com.example.App.main(App.java:42)
+		Language: "java",
+		Pattern:  regexp.MustCompile(`^\s*at (?P<func>[\w$.<>/@]+)\((?P<file>[\w$.-]+\.(?:java|kt|scala|groovy)):(?P<line>\d+)\)`),
+		Library:  regexp.MustCompile(`^(?:java|javax|jdk|sun|kotlin|scala)\.|^org\.(?:junit|springframework)\.`),
+	},
+	{
+		//     at handler (/app/src/index.js:10:5)
+		Language: "node",
+		Pattern:  regexp.MustCompile(`^\s*at (?:(?P<func>.+?) \()?(?P<file>[^\s()]+):(?P<line>\d+:\d+)\)?$`),
+		Library:  regexp.MustCompile(`node_modules/|^node:|^internal/`),
+	},
+}
+
+// stackFrame is a frame of a stack trace found on a line
+type stackFrame struct {
+	Match
+	format  *stackFrameFormat
+	library bool
+}
+
+// stackFrameMatches returns the file:line of every frame of the stack
+// traces of the lines in scope, also recording the frame of the crash of
+// every trace, see crashFrames
+func (s *State) stackFrameMatches(ctx context.Context) ([]Match, error) {
+	var frames []stackFrame
+	start, end := s.scopeLines()
+	for y := start; y < end; y++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if frame, ok := parseStackFrame(s.Lines[y], y); ok && !s.tooShort(stackFramePattern, frame.Text) {
+			frames = append(frames, frame)
+		}
+	}
+
+	matches := make([]Match, 0, len(frames))
+	for i, frame := range frames {
+		matches = append(matches, frame.Match)
+
+		// The last frame of a trace, which ends at a frame of another
+		// language or after a gap
+		if i+1 < len(frames) && frames[i+1].format == frame.format && frames[i+1].Y-frame.Y <= maxFrameGap+1 {
+			continue
+		}
+		first := i
+		for first > 0 && frames[first-1].format == frame.format && frames[first].Y-frames[first-1].Y <= maxFrameGap+1 {
+			first--
+		}
+		if crash, ok := crashFrame(frames[first : i+1]); ok {
+			s.crashFrames = append(s.crashFrames, crash)
+		}
+	}
+	return matches, nil
+}
+
+// isCrashFrame reports whether mat is the frame of the crash of a stack
+// trace, whose hint comes first
+func (s *State) isCrashFrame(mat Match) bool {
+	return slices.ContainsFunc(s.crashFrames, mat.Equals)
+}
+
+// crashFrame returns the frame of the user code closest to the crash among
+// the frames of a trace, the first one unless the format lists the most
+// recent call last
+func crashFrame(trace []stackFrame) (Match, bool) {
+	for i := range trace {
+		frame := trace[i]
+		if trace[0].format.MostRecentLast {
+			frame = trace[len(trace)-1-i]
+		}
+		if !frame.library {
+			return frame.Match, true
+		}
+	}
+	return Match{}, false
+}
+
+// parseStackFrame returns the frame of a stack trace on line y, as a match
+// of its file:line. Python frames, whose line number follows the file
+// further on, are matched on their file and target file:line
+func parseStackFrame(line string, y int) (stackFrame, bool) {
+	for i := range stackFrameFormats {
+		format := &stackFrameFormats[i]
+		loc := format.Pattern.FindStringSubmatchIndex(line)
+		if loc == nil {
+			continue
+		}
+		group := func(name string) (int, int) {
+			idx := format.Pattern.SubexpIndex(name)
+			if idx < 0 {
+				return -1, -1
+			}
+			return loc[2*idx], loc[2*idx+1]
+		}
+		fileStart, fileEnd := group("file")
+		lineStart, lineEnd := group("line")
+		file := line[fileStart:fileEnd]
+
+		mat := Match{X: fileStart, Y: y, Pattern: stackFramePattern}
+		if lineStart == fileEnd+1 {
+			mat.Text = line[fileStart:lineEnd]
+		} else {
+			mat.Text = file
+			mat.Target = file + ":" + line[lineStart:lineEnd]
+		}
+
+		// Java frames tell the library by the class rather than the file
+		origin := file
+		if funcStart, funcEnd := group("func"); format.Language == "java" && funcStart >= 0 {
+			origin = line[funcStart:funcEnd]
+		}
+		return stackFrame{Match: mat, format: format, library: format.Library.MatchString(origin)}, true
+	}
+	return stackFrame{}, false
+}
@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStackFrameMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		expected []string
+		crash    string
+	}{
+		{
+			name: "go",
+			lines: []string{
+				"panic: runtime error: index out of range [3] with length 3",
+				"",
+				"goroutine 1 [running]:",
+				"main.parse(...)",
+				"\t/usr/local/go/src/runtime/panic.go:114 +0x1d",
+				"main.parse(...)",
+				"\t/home/me/app/parse.go:42 +0x1d",
+				"main.main()",
+				"\t/home/me/app/main.go:9 +0x18",
+			},
+			expected: []string{"/usr/local/go/src/runtime/panic.go:114", "/home/me/app/parse.go:42", "/home/me/app/main.go:9"},
+			crash:    "/home/me/app/parse.go:42",
+		},
+		{
+			name: "python",
+			lines: []string{
+				"Traceback (most recent call last):",
+				`  File "/app/main.py", line 12, in <module>`,
+				"    run()",
+				`  File "/app/jobs.py", line 7, in run`,
+				"    json.loads(data)",
+				`  File "/usr/lib/python3.12/json/__init__.py", line 346, in loads`,
+				"    return _default_decoder.decode(s)",
+				"json.decoder.JSONDecodeError: Expecting value",
+			},
+			expected: []string{"/app/main.py:12", "/app/jobs.py:7", "/usr/lib/python3.12/json/__init__.py:346"},
+			crash:    "/app/jobs.py:7",
+		},
+		{
+			name: "java",
+			lines: []string{
+				`Exception in thread "main" java.lang.NullPointerException`,
+				"\tat java.base/java.util.Objects.requireNonNull(Objects.java:209)",
+				"\tat com.example.App.load(App.java:42)",
+				"\tat com.example.App.main(App.java:9)",
+			},
+			expected: []string{"Objects.java:209", "App.java:42", "App.java:9"},
+			crash:    "App.java:42",
+		},
+		{
+			name: "node",
+			lines: []string{
+				"TypeError: Cannot read properties of undefined (reading 'id')",
+				"    at handler (/app/src/index.js:10:5)",
+				"    at Layer.handle (/app/node_modules/express/lib/router/layer.js:95:5)",
+				"    at node:internal/process/task_queues:95:5",
+			},
+			expected: []string{"/app/src/index.js:10:5", "/app/node_modules/express/lib/router/layer.js:95:5", "node:internal/process/task_queues:95:5"},
+			crash:    "/app/src/index.js:10:5",
+		},
+		{
+			name:     "library frames only",
+			lines:    []string{"    at node:internal/main:1:1"},
+			expected: []string{"node:internal/main:1:1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := NewStateFromLines(tt.lines, "abcd", []string{})
+			matches := state.Matches(false, 0)
+
+			var got []string
+			crash := ""
+			for _, mat := range matches {
+				if mat.Pattern != stackFramePattern {
+					continue
+				}
+				got = append(got, mat.Value())
+				if mat.Hint != nil && *mat.Hint == "a" {
+					crash = mat.Value()
+				}
+			}
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected frames %q, got %q", tt.expected, got)
+			}
+			if tt.crash != "" && crash != tt.crash {
+				t.Errorf("Expected the first hint on %q, got %q", tt.crash, crash)
+			}
+		})
+	}
+}
@@ -1,14 +1,20 @@
 package internal
 
 import (
+	"cmp"
+	"context"
 	"fmt"
 	"log/slog"
+	"maps"
 	"regexp"
+	"slices"
 	"strings"
 	"sync"
-	"time"
 	"unicode"
+	"unicode/utf8"
 
+	"github.com/Hanaasagi/magonote/internal/logger"
+	"github.com/Hanaasagi/magonote/pkg/textdetection/colordetection"
 	td "github.com/Hanaasagi/magonote/pkg/textdetection/tabledetection"
 )
 
@@ -28,6 +34,9 @@ type TableDetectionConfig struct {
 type ColorDetectionConfig struct {
 }
 
+type LogDetectionConfig struct {
+}
+
 // ExclusionRule represents a rule for excluding matches
 type ExclusionRule struct {
 	Type    string // "regex" or "text"
@@ -39,6 +48,26 @@ type ExclusionConfig struct {
 	Rules []ExclusionRule
 }
 
+// PatternConfig holds per-pattern settings, keyed by pattern name
+type PatternConfig struct {
+	// HintMinLength suppresses hints for matches shorter than this many
+	// characters, such matches are still highlighted
+	HintMinLength int
+	// TrimPunctuation strips unbalanced trailing punctuation from matches,
+	// nil keeps the pattern's default
+	TrimPunctuation *bool
+	// MinLength drops regex matches shorter than this many characters, zero
+	// keeps the global minimum
+	MinLength int
+	// NotPrecededBy and NotFollowedBy are character classes the characters
+	// around matches can't be in, empty keeps the pattern's default
+	NotPrecededBy string
+	NotFollowedBy string
+	// Priority decides between overlapping matches of the same length, the
+	// highest wins, see Conflict
+	Priority int
+}
+
 // MatchPattern represents a pattern that should be matched
 type MatchPattern struct {
 	Name    string
@@ -70,12 +99,16 @@ var commonExt = []string{
 
 var commonExtPattern = strings.Join(commonExt, "|")
 
+// locationPath matches the paths of file_location, with a directory or a
+// common extension
+var locationPath = `(?:[a-z]:[\\/])?(?:(?:[.\w\-@$~]*[/\\])+[.\w\-@$]*[\w\-]|[\w\-.]+\.(?:` + commonExtPattern + `))`
+
 var ExcludePatterns = []MatchPattern{
 	// {"bash", `\x1b\[([0-9]{1,2};)?([0-9]{1,2})?m`},
 	{"bash", `[\x00-\x1F\x7F]\[([0-9]{1,2};)?([0-9]{1,2})?m`},
 }
 
-var BuiltinPatterns = []MatchPattern{
+var BuiltinPatterns = slices.Concat([]MatchPattern{
 	{"markdown_url", `\[[^]]*\]\(([^)]+)\)`},
 	{"url", `(?P<match>(https?://|git@|git://|ssh://|ftp://|file:///)[^ ]+)`},
 	{"diff_summary", `diff --git a/([.\w\-@~\[\]]+?/[.\w\-@\[\]]+) b/([.\w\-@~\[\]]+?/[.\w\-@\[\]]+)`},
@@ -85,24 +118,39 @@ var BuiltinPatterns = []MatchPattern{
 
 	{"rust_test", `^test\s+(?P<match>[^\s]+)\s+\.\.\.\s+(ok|FAILED)$`},
 	{"go_test", `^--- (PASS|FAIL):\s+(?P<match>[^\s]+)`},
-
+	// pytest: FAILED tests/test_x.py::test_y - assert 1 == 2, and tests/test_x.py::test_y PASSED with -v
+	{"pytest", `^(?:FAILED|ERROR) (?P<match>[\w./\-]+\.py::\S+)|^(?P<match>[\w./\-]+\.py::\S+) (?:PASSED|FAILED|ERROR|SKIPPED|XFAIL|XPASS)\b`},
+	// jest: "  ✕ adds numbers (5 ms)", "  ✓ renders", × and √ on Windows
+	{"jest", `^\s*[✕✓×√] (?P<match>\S.*?)(?: \(\d+(?:\.\d+)? ?m?s\))?\s*$`},
+	// cargo nextest: "        FAIL [   0.004s] (1/3) my-crate tests::it_fails"
+	{"nextest", `^\s*(?:PASS|FAIL|SIGSEGV|SIGABRT|TIMEOUT|LEAK) \[\s*[\d.]+s\] (?:\(\s*\d+/\d+\) )?\S+ (?P<match>\S+)`},
+
+	// Package specs: lodash@4.17.21, @types/node@18.0.0, github.com/foo/bar@v0.5.3
+	{"package_version", `(?P<match>(?:@[\w.\-]+/)?[\w.\-/]*[\w\-]@v?\d+\.\d+\.\d+(?:-[0-9A-Za-z.\-]+)?(?:\+[0-9A-Za-z.\-]+)?)`},
+
+	// Addresses, subnets and interfaces, see networkPatterns. Before path,
+	// which matches subnets such as 10.0.0.0/24 too
+}, networkPatterns, []MatchPattern{
+	// Compiler and grep locations: src/main.go:12:5, _client.py:1038, and
+	// src/app.ts(12,5): of tsc and MSVC, also after Windows drives
+	{"file_location", `(?i)(?P<match>` + locationPath + `:\d+(?::\d+)?)|(?P<match>` + locationPath + `\(\d+(?:,\d+)?\)):`},
 	{"path", `(?P<match>([.\w\-@$~\[\]]+)?(/[.\w\-@$\[\]]+)+)`},
 	{"color", `#[0-9a-fA-F]{6}`},
 	{"uid", `[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`},
 	{"ipfs", `Qm[0-9a-zA-Z]{44}`},
 
-	// Avoid this regex: it matches substring on strings like "webapp-editor-7fdbfbf4b-k68b7".
-	// {"sha", `[0-9a-f]{7,40}`},
-	{"sha", `(?:^|[^a-zA-Z0-9_-])(?P<match>[0-9a-f]{7,40})(?:[^a-zA-Z0-9_-]|$)`},
+	// Not inside words such as "webapp-editor-7fdbfbf4b-k68b7", see builtinLookarounds
+	{"sha", `[0-9a-f]{7,40}`},
 
-	// IPv4: 192.168.1.1:8080
-	{"ipv4_port", `\b\d{1,3}(?:\.\d{1,3}){3}:\d{1,5}\b`},
-	{"ipv4", `\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}`},
+	// Semantic versions: v1.2.3, 1.2.3-rc.1, 1.0.0+build.5
+	{"semver", `\bv?\d+\.\d+\.\d+(?:-[0-9A-Za-z.\-]+)?(?:\+[0-9A-Za-z.\-]+)?`},
 
-	// IPv6: [2001:db8::1]:443
-	{"ipv6_port", `\[[A-Fa-f0-9:]+\]:\d{1,5}`},
-	{"ipv6", `[A-f0-9:]+:+[A-f0-9:]+[%\w\d]+`},
 	{"address", `0x[0-9a-fA-F]+`},
+
+	// Environment variables: $HOME, ${GOPATH}, and PATH in the PATH=... lines of env
+	{"env_var", `\$\{[A-Za-z_]\w*\}|\$[A-Za-z_]\w*|^(?P<match>[A-Z_][A-Z0-9_]*)=`},
+	// Long flags: --verbose, --output=json, not inside words, see builtinLookarounds
+	{"flag", `--[a-zA-Z][\w-]*(?:=[^\s"'\x60]+)?`},
 	// {"file_list_item", `\S+`},
 	// {"file_list_item", `\S+(?:\s{2,}|\s*$)`},
 
@@ -112,6 +160,55 @@ var BuiltinPatterns = []MatchPattern{
 	{"date_dash", `\b\d{4}-\d{2}-\d{2}\b`},
 	{"date_slash", `\b\d{2}/\d{2}/\d{4}\b`},
 	// {"number", `[0-9]{4,}`},
+})
+
+// builtinTriggers holds texts one of which every match of a builtin pattern
+// contains. Patterns rarely hit are neither compiled nor run unless the input
+// holds one of their triggers
+var builtinTriggers = map[string][]string{
+	"markdown_url":    {"]("},
+	"url":             {"://", "git@"},
+	"diff_summary":    {"diff --git "},
+	"diff_a":          {"--- a/"},
+	"diff_b":          {"+++ b/"},
+	"docker":          {"sha256:"},
+	"rust_test":       {"test"},
+	"go_test":         {"--- PASS:", "--- FAIL:"},
+	"pytest":          {".py::"},
+	"jest":            {"✕", "✓", "×", "√"},
+	"nextest":         {"PASS [", "FAIL [", "SIGSEGV [", "SIGABRT [", "TIMEOUT [", "LEAK ["},
+	"package_version": {"@"},
+	"color":           {"#"},
+	"ipfs":            {"Qm"},
+	"ipv4_cidr":       {"/"},
+	"ipv6_cidr":       {"/"},
+	"ipv6_port":       {"]:"},
+	"address":         {"0x"},
+	"interface":       {"en", "wl", "ww", "ib", "eth", "usb", "bond", "team", "tun", "tap", "wg", "docker", "virbr", "vlan", "vmnet", "vboxnet", "lxcbr", "lxdbr", "cni", "br-"},
+	"env_var":         {"$", "="},
+	"flag":            {"--"},
+}
+
+// triggerPatterns are the names of builtinTriggers, their triggers form
+// the group of their index for triggerScanner
+var triggerPatterns = slices.Sorted(maps.Keys(builtinTriggers))
+
+// triggerScanner finds the triggers of builtinTriggers in a line
+var triggerScanner = sync.OnceValue(func() *literalScanner {
+	groups := make([][]string, len(triggerPatterns))
+	for i, name := range triggerPatterns {
+		groups[i] = builtinTriggers[name]
+	}
+	return newLiteralScanner(groups)
+})
+
+// triggerGroup returns the bit of the triggers of a builtin pattern, 0 for
+// patterns without triggers
+func triggerGroup(name string) uint64 {
+	if i, ok := slices.BinarySearch(triggerPatterns, name); ok {
+		return 1 << i
+	}
+	return 0
 }
 
 // Match represents a matched pattern in the text
@@ -121,6 +218,17 @@ type Match struct {
 	Pattern string
 	Text    string
 	Hint    *string
+	Target  string // Value output instead of Text, such as the URI of a hyperlink
+	Path    string // Path of the value of a JSON input, such as ".items[0].name"
+	Column  int    // 1-based column of the cell of a detected table, 0 for other matches
+}
+
+// Value returns the value output when the match is chosen
+func (m Match) Value() string {
+	if m.Target != "" {
+		return m.Target
+	}
+	return m.Text
 }
 
 // Equals checks if two matches are equal
@@ -210,6 +318,13 @@ func WithColorDetection() Option {
 	})
 }
 
+// WithLogDetection enables log detection, see logMatches
+func WithLogDetection() Option {
+	return optionFunc(func(s *State) {
+		s.LogDetectionConfig = &LogDetectionConfig{}
+	})
+}
+
 // WithExclusionRules configures exclusion rules
 func WithExclusionRules(rules []ExclusionRule) Option {
 	return optionFunc(func(s *State) {
@@ -219,18 +334,94 @@ func WithExclusionRules(rules []ExclusionRule) Option {
 	})
 }
 
+// WithPatternConfigs configures per-pattern settings keyed by pattern name
+// (e.g. "path", "grid", "custom")
+func WithPatternConfigs(configs map[string]PatternConfig) Option {
+	return optionFunc(func(s *State) {
+		s.PatternConfigs = configs
+	})
+}
+
+// WithMinMatchLength drops regex matches shorter than length characters,
+// such as `0x0`, unless their pattern sets its own minimum
+func WithMinMatchLength(length int) Option {
+	return optionFunc(func(s *State) {
+		s.MinMatchLength = length
+	})
+}
+
+// WithCustomAlphabets adds user defined alphabets keyed by name, they take
+// precedence over the builtin alphabets of the same name
+func WithCustomAlphabets(alphabets map[string]string) Option {
+	return optionFunc(func(s *State) {
+		s.CustomAlphabets = alphabets
+	})
+}
+
+// WithNamedPatterns adds custom patterns whose matches are reported under
+// their own name instead of "custom"
+func WithNamedPatterns(patterns []MatchPattern) Option {
+	return optionFunc(func(s *State) {
+		s.NamedPatterns = patterns
+		s.cacheValid = false
+	})
+}
+
 // State represents the current state of the application
 type State struct {
 	Lines                []string
 	Alphabet             string
+	CustomAlphabets      map[string]string
 	CustomPatterns       []string
+	NamedPatterns        []MatchPattern
+	GitPatterns          []MatchPattern
+	ProcessPatterns      []MatchPattern // See WithProcessPatterns
+	MultilinePatterns    []MatchPattern // See WithMultilinePatterns
+	RedactPrivateKeys    bool           // See WithRedactedPrivateKeys
+	SecretsConfig        *SecretsConfig // See WithSecretDetection
 	processor            TextProcessor
 	styleMatches         []Match
+	lineStyles           map[int][]colordetection.StyleSpan
 	compiledPatterns     []*CompiledPattern
 	cacheValid           bool
 	TableDetectionConfig *TableDetectionConfig
+	TableColumnNames     []string // See WithTableColumns
 	ColorDetectionConfig *ColorDetectionConfig
+	LogDetectionConfig   *LogDetectionConfig
+	autoTables           bool // See WithAutoDetection
+	autoColors           bool
 	ExclusionConfig      *ExclusionConfig
+	ProximityConfig      *ProximityConfig
+	HistoryScores        map[string]int
+	PatternConfigs       map[string]PatternConfig
+	MaxMatches           int
+	MinMatchLength       int
+	Scope                string
+	Mode                 string // See WithMode
+	WordMinLength        int
+	JSONInput            bool // See WithJSONInput
+	WrapWidth            int  // See WithWrapWidth
+	TabWidth             int  // See WithTabWidth
+	// Truncated is set when the input or the matches were cut short
+	Truncated bool
+	stats     Stats
+	// Frames of the crashes of the stack traces, see stackFrameMatches
+	crashFrames []Match
+	// Tables of the input, see detectTables
+	tables         []td.Table
+	tablesDetected bool
+	// Matches known in advance, see NewHistoryState
+	historyMatches []Match
+
+	// Trigger groups of compiledPatterns, 0 for patterns always tried, and
+	// those found on every line, see builtinTriggers
+	patternTriggers []uint64
+	lineTriggers    []uint64
+	linePatterns    []*CompiledPattern // Patterns tried on the current line
+	candidates      []submatch         // Matches of the patterns at the current offset
+
+	conflicts []Conflict // Overlapping matches resolved by the last Matches
+	secrets   []Match    // Likely credentials of the lines, see findSecrets
 }
 
 // NewState creates a new state from input text with optional configurations
@@ -252,16 +443,20 @@ func NewState(
 		CustomPatterns:       patterns,
 		processor:            processor,
 		styleMatches:         styleMatches,
+		lineStyles:           processor.LineStyles(),
 		cacheValid:           false,
 		TableDetectionConfig: nil,
 		ColorDetectionConfig: nil,
+		LogDetectionConfig:   nil,
 		ExclusionConfig:      nil,
+		TabWidth:             DefaultTabWidth,
 	}
 
 	// Apply all options
 	for _, opt := range opts {
 		opt.apply(state)
 	}
+	state.applyAutoDetection()
 
 	return state
 }
@@ -272,32 +467,77 @@ func NewStateFromLines(lines []string, alphabet string, patterns []string, opts
 	return NewState(text, alphabet, patterns, opts...)
 }
 
-// getCompiledPatterns returns cached compiled patterns or compiles them
+// getCompiledPatterns returns cached compiled patterns or compiles them.
+// Builtin patterns are left out when no line holds one of their triggers
 func (s *State) getCompiledPatterns() []*CompiledPattern {
 	if s.cacheValid {
 		return s.compiledPatterns
 	}
 
-	totalLen := len(ExcludePatterns) + len(s.CustomPatterns) + len(BuiltinPatterns)
+	scanner := triggerScanner()
+	s.lineTriggers = make([]uint64, len(s.Lines))
+	var found uint64
+	for y, line := range s.Lines {
+		s.lineTriggers[y] = scanner.scan(line)
+		found |= s.lineTriggers[y]
+	}
+
+	totalLen := len(ExcludePatterns) + len(s.CustomPatterns) + len(s.NamedPatterns) + len(s.GitPatterns) + len(s.ProcessPatterns) + len(BuiltinPatterns)
 	patterns := make([]*CompiledPattern, 0, totalLen)
+	triggers := make([]uint64, 0, totalLen)
+	add := func(name, pattern string, trigger uint64) {
+		patterns = append(patterns, globalPatternCache.GetCompiledPattern(name, pattern))
+		triggers = append(triggers, trigger)
+	}
 
 	for _, p := range ExcludePatterns {
-		patterns = append(patterns, globalPatternCache.GetCompiledPattern(p.Name, p.Pattern))
+		add(p.Name, p.Pattern, 0)
 	}
 
 	for _, p := range s.CustomPatterns {
-		patterns = append(patterns, globalPatternCache.GetCompiledPattern("custom", p))
+		add("custom", p, 0)
+	}
+
+	for _, p := range s.NamedPatterns {
+		add(p.Name, p.Pattern, 0)
+	}
+
+	for _, p := range s.GitPatterns {
+		add(p.Name, p.Pattern, 0)
+	}
+
+	for _, p := range s.ProcessPatterns {
+		add(p.Name, p.Pattern, 0)
 	}
 
 	for _, p := range BuiltinPatterns {
-		patterns = append(patterns, globalPatternCache.GetCompiledPattern(p.Name, p.Pattern))
+		trigger := triggerGroup(p.Name)
+		if trigger != 0 && found&trigger == 0 {
+			continue
+		}
+		add(p.Name, p.Pattern, trigger)
 	}
 
 	s.compiledPatterns = patterns
+	s.patternTriggers = triggers
 	s.cacheValid = true
 	return patterns
 }
 
+// patternsForLine returns the compiled patterns that may match line y,
+// without the builtin patterns whose triggers it lacks. The slice is reused
+// for the next line
+func (s *State) patternsForLine(y int, patterns []*CompiledPattern) []*CompiledPattern {
+	found := s.lineTriggers[y]
+	s.linePatterns = s.linePatterns[:0]
+	for i, pattern := range patterns {
+		if trigger := s.patternTriggers[i]; trigger == 0 || found&trigger != 0 {
+			s.linePatterns = append(s.linePatterns, pattern)
+		}
+	}
+	return s.linePatterns
+}
+
 // getLastNonWhitespaceChar returns the last non-whitespace character in a string
 func getLastNonWhitespaceChar(s string) rune {
 	for i := len(s) - 1; i >= 0; i-- {
@@ -333,8 +573,9 @@ func fixURLQuotes(url string, originalLine string, startPos int) string {
 	return url
 }
 
-// processLine processes a single line and returns matches
-func (s *State) processLine(y int, line string, patterns []*CompiledPattern) []Match {
+// processLine processes a single line and returns matches, those found
+// until ctx is done
+func (s *State) processLine(ctx context.Context, y int, line string, patterns []*CompiledPattern) []Match {
 	if len(line) == 0 {
 		return nil
 	}
@@ -343,13 +584,13 @@ func (s *State) processLine(y int, line string, patterns []*CompiledPattern) []M
 	offset := 0
 	remaining := line
 
-	for len(remaining) > 0 {
-		bestMatch := s.findBestMatch(remaining, patterns)
+	for len(remaining) > 0 && ctx.Err() == nil {
+		bestMatch := s.findBestMatch(y, line, offset, patterns)
 		if bestMatch == nil {
 			break
 		}
 
-		if bestMatch.Pattern.Name != "bash" {
+		if !isExclusion(bestMatch.Pattern.Name) {
 			captures := s.extractCaptures(bestMatch.Text, bestMatch.Pattern.Pattern)
 			for _, capture := range captures {
 				captureText := capture.Text
@@ -360,6 +601,16 @@ func (s *State) processLine(y int, line string, patterns []*CompiledPattern) []M
 					captureText = fixURLQuotes(captureText, line, absolutePos)
 				}
 
+				if s.shouldTrimPunctuation(bestMatch.Pattern.Name) {
+					captureText = TrimTrailingPunctuation(captureText)
+					if captureText == "" {
+						continue
+					}
+				}
+				if s.tooShort(bestMatch.Pattern.Name, captureText) {
+					continue
+				}
+
 				matches = append(matches, Match{
 					X:       offset + bestMatch.Index + capture.Start,
 					Y:       y,
@@ -386,27 +637,47 @@ type submatch struct {
 	Text    string
 }
 
-// findBestMatch finds the earliest match in the text
-func (s *State) findBestMatch(text string, patterns []*CompiledPattern) *submatch {
-	var bestMatch *submatch
-
+// findBestMatch finds the next match in line from offset on, the earliest
+// one unless matches of other patterns overlap it, see Conflict. Its index
+// is relative to offset
+func (s *State) findBestMatch(y int, line string, offset int, patterns []*CompiledPattern) *submatch {
+	s.candidates = s.candidates[:0]
 	for _, pattern := range patterns {
-		indices := pattern.Pattern.FindStringSubmatchIndex(text)
-		if len(indices) >= 2 {
-			match := &submatch{
-				Pattern: pattern,
-				Index:   indices[0],
-				Length:  indices[1] - indices[0],
-				Text:    text[indices[0]:indices[1]],
-			}
-
-			if bestMatch == nil || match.Index < bestMatch.Index {
-				bestMatch = match
-			}
+		start, end, ok := s.findPattern(line, offset, pattern)
+		if !ok {
+			continue
 		}
+
+		s.candidates = append(s.candidates, submatch{
+			Pattern: pattern,
+			Index:   start - offset,
+			Length:  end - start,
+			Text:    line[start:end],
+		})
 	}
 
-	return bestMatch
+	return s.resolveConflict(y, offset, s.candidates)
+}
+
+// findPattern finds the earliest match of pattern in line from offset on
+// that passes the pattern's lookaround and, for network patterns, its
+// validator. A rejected match isn't shortened, the search goes on from its
+// next character
+func (s *State) findPattern(line string, offset int, pattern *CompiledPattern) (start, end int, ok bool) {
+	for offset <= len(line) {
+		indices := pattern.Pattern.FindStringIndex(line[offset:])
+		if indices == nil {
+			return 0, 0, false
+		}
+		start, end = offset+indices[0], offset+indices[1]
+		if s.allowedAround(pattern.Name, line, start, end) && validNetworkMatch(pattern.Name, line, start, end) {
+			return start, end, true
+		}
+
+		_, size := utf8.DecodeRuneInString(line[start:])
+		offset = start + max(size, 1)
+	}
+	return 0, 0, false
 }
 
 type Capture struct {
@@ -454,18 +725,150 @@ func (s *State) extractCaptures(text string, pattern *regexp.Regexp) []Capture {
 
 // Matches returns all matches in the text
 func (s *State) Matches(reverse bool, uniqueLevel int) []Match {
-	patterns := s.getCompiledPatterns()
+	matches, err := s.MatchesContext(context.Background(), reverse, uniqueLevel)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to create alphabet: %v", err))
+	}
+	return matches
+}
+
+// MatchesContext is Matches stopping with the error of ctx once it is done,
+// to give up on huge inputs
+func (s *State) MatchesContext(ctx context.Context, reverse bool, uniqueLevel int) ([]Match, error) {
+	s.stats = Stats{}
+	s.conflicts = nil
+	s.crashFrames = nil
+	s.tables, s.tablesDetected = nil, false
+	s.secrets = nil
+	if s.SecretsConfig != nil {
+		s.secrets = s.findSecrets()
+	}
+
+	var matches []Match
+	if s.historyMatches != nil {
+		// The values of the history are known, their lines describe them
+		matches = slices.Clone(s.historyMatches)
+	} else if s.Mode == ModeLines {
+		// Lines take the place of the matches of the patterns
+		matches = s.lineMatches()
+	} else if values, ok := s.jsonMatches(); ok {
+		// So do the values of a JSON input
+		matches = values
+	} else {
+		var err error
+		if matches, err = s.patternMatches(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	matches = s.dedupOverlappingMatches(matches)
+
+	if uniqueLevel >= 2 {
+		matches = s.filterSuperUniqueMatches(matches)
+	}
+
+	if s.ExclusionConfig != nil {
+		matches = s.applyExclusionFilters(matches)
+	}
+	matches = s.dropPrivateKeyMatches(matches)
+	matches = s.dropSecretMatches(matches)
+	matches = s.dropPromptMatches(matches)
+	matches = s.scopeMatches(matches)
+	matches = s.limitMatches(matches)
 
+	if err := s.AssignHints(matches, reverse, uniqueLevel); err != nil {
+		return nil, err
+	}
+	for _, match := range matches {
+		slog.Debug("match", "match", match)
+	}
+	s.recordStats(matches)
+	return matches, nil
+}
+
+// patternMatches returns the matches of the patterns and of the detected
+// tables and styles, possibly overlapping
+func (s *State) patternMatches(ctx context.Context) ([]Match, error) {
+	patterns := s.getCompiledPatterns()
 	matches := make([]Match, 0, len(s.Lines)*2)
 
 	// 1. Add regex-based matches from plain text (highest priority)
-	regexStart := time.Now()
-	for y, line := range s.Lines {
-		lineMatches := s.processLine(y, line, patterns)
+	span := logger.StartSpan("regex extraction")
+	start, end := s.scopeLines()
+	for y := start; y < end; y++ {
+		var lineMatches []Match
+		if n := s.wrappedLines(y, end); n > 1 {
+			lineMatches = s.processWrappedLines(ctx, y, n, patterns)
+			y += n - 1
+		} else {
+			lineMatches = s.processLine(ctx, y, s.Lines[y], s.patternsForLine(y, patterns))
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		matches = append(matches, lineMatches...)
+
+		// Lines left unsearched may hold matches, so the result is truncated
+		// even if filters below bring it under the limit
+		if s.matchLimitReached(matches) && y < end-1 {
+			s.Truncated = true
+			break
+		}
+	}
+	span.End("matches_count", len(matches))
+
+	// Hyperlinks of the input point elsewhere than their text, they are
+	// always matches
+	matches = append(matches, s.processor.Hyperlinks()...)
+
+	// Frames of stack traces take precedence over the paths they hold
+	frames, err := s.stackFrameMatches(ctx)
+	if err != nil {
+		return nil, err
+	}
+	matches = s.mergeColumnMatches(matches, frames)
+
+	// So do the values of the JSON inside the input over the quoted strings
+	// and numbers they are
+	matches = s.mergeColumnMatches(matches, s.jsonRegionMatches())
+
+	if s.LogDetectionConfig != nil {
+		// Fields of log lines take precedence over the matches of the
+		// patterns, their messages only fill the text those leave
+		fields, messages, err := s.logMatches(ctx)
+		if err != nil {
+			return nil, err
+		}
+		messages = s.filterOverlappingMatches(messages, slices.Concat(matches, fields))
+		matches = s.mergeColumnMatches(matches, append(fields, messages...))
+	}
+
+	// Quotes and brackets enclose the text the patterns miss
+	enclosedMatches, err := s.enclosedMatches(ctx, matches)
+	if err != nil {
+		return nil, err
+	}
+	matches = append(matches, enclosedMatches...)
+
+	// Words are a fallback for the text the patterns miss
+	wordMatches, err := s.wordMatches(ctx)
+	if err != nil {
+		return nil, err
+	}
+	matches = append(matches, s.filterOverlappingMatches(wordMatches, matches)...)
+
+	// Columns of the tables, detected once for the column matches and filter
+	var columns []TableColumn
+	if s.TableDetectionConfig != nil {
+		columns = s.TableColumns()
+
+		// Cells of well known tables such as `docker ps` take precedence over
+		// regex matches and are named after their column
+		if columnMatches := s.getColumnMatches(columns); len(columnMatches) > 0 {
+			s.stats.TableCells += len(columnMatches)
+			matches = s.mergeColumnMatches(matches, columnMatches)
+		}
 	}
-	regexDuration := time.Since(regexStart)
-	slog.Info("regex extraction completed", "duration_ms", regexDuration.Milliseconds(), "matches_count", len(matches))
 
 	if s.ColorDetectionConfig != nil {
 		// 2. Add style-based matches, excluding overlaps with regex matches
@@ -483,57 +886,142 @@ func (s *State) Matches(reverse bool, uniqueLevel int) []Match {
 		}
 	}
 
-	if s.TableDetectionConfig != nil {
+	// Grid matches come last and would all be dropped by the match limit
+	if s.TableDetectionConfig != nil && !s.matchLimitReached(matches) {
 		// 3. Add grid-based matches, excluding overlaps with all previous matches
-		gridMatches := s.getGridMatches(matches)
+		gridMatches, err := s.getGridMatches(ctx, matches)
+		if err != nil {
+			return nil, err
+		}
 		gridMatches = s.filterOverlappingMatches(gridMatches, matches)
+		s.stats.TableCells += len(gridMatches)
 
 		matches = append(matches, gridMatches...)
 	}
 
-	if uniqueLevel >= 2 {
-		matches = s.filterSuperUniqueMatches(matches)
+	if s.TableDetectionConfig != nil && len(s.TableColumnNames) > 0 {
+		// Only the configured columns of wide tables get hints
+		matches = s.filterTableColumns(matches, columns)
 	}
 
-	if s.ExclusionConfig != nil {
-		matches = s.applyExclusionFilters(matches)
+	// Blocks spanning lines take the place of everything found inside them
+	multiline, err := s.multilineMatches(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(multiline) > 0 {
+		matches = mergeMultilineMatches(matches, multiline)
 	}
 
-	alphabet, err := NewBuiltinAlphabet(s.Alphabet)
+	return matches, nil
+}
+
+// AssignHints assigns hints from the alphabet of the state to matches in
+// place, following the hint settings of the state
+func (s *State) AssignHints(matches []Match, reverse bool, uniqueLevel int) error {
+	alphabet, err := ResolveAlphabet(s.Alphabet, s.CustomAlphabets)
 	if err != nil {
-		panic(fmt.Sprintf("Failed to create alphabet: %v", err))
+		return err
 	}
-	hints := alphabet.Hints(len(matches))
+	// Only matches long enough for their pattern receive a hint
+	hintable := s.hintableMatches(matches)
+	hints := alphabet.Hints(len(hintable))
 
-	s.assignHints(matches, hints, reverse, uniqueLevel)
+	if order := s.hintOrder(hintable, reverse); order != nil {
+		s.assignHintsInOrder(hintable, order, hints, uniqueLevel)
+	} else {
+		s.assignHints(hintable, hints, reverse, uniqueLevel)
+	}
+	s.copyHints(matches, hintable)
+	return nil
+}
+
+// tooShort reports whether text is shorter than the minimum match length of
+// the pattern
+func (s *State) tooShort(pattern, text string) bool {
+	minLength := s.MinMatchLength
+	if config, ok := s.PatternConfigs[pattern]; ok && config.MinLength > 0 {
+		minLength = config.MinLength
+	}
+	return minLength > 0 && utf8.RuneCountInString(text) < minLength
+}
+
+// hintableMatches returns the matches that should receive a hint according
+// to the per-pattern hint thresholds
+func (s *State) hintableMatches(matches []Match) []Match {
+	if len(s.PatternConfigs) == 0 {
+		return matches
+	}
+
+	hintable := make([]Match, 0, len(matches))
 	for _, match := range matches {
-		slog.Debug("match", "match", match)
+		config, ok := s.PatternConfigs[match.Pattern]
+		if ok && utf8.RuneCountInString(match.Text) < config.HintMinLength {
+			continue
+		}
+		hintable = append(hintable, match)
 	}
-	return matches
+	return hintable
 }
 
-// filterOverlappingMatches removes matches that overlap with existing matches
-func (s *State) filterOverlappingMatches(candidateMatches []Match, existingMatches []Match) []Match {
-	// Build position map for overlap detection
-	existingPositions := make(map[string]bool, len(existingMatches)*5)
-	for _, match := range existingMatches {
-		for i := 0; i < len(match.Text); i++ {
-			key := fmt.Sprintf("%d-%d", match.Y, match.X+i)
-			existingPositions[key] = true
+// copyHints copies the hints assigned to hintable back into matches,
+// both slices are in the same order
+func (s *State) copyHints(matches []Match, hintable []Match) {
+	if len(matches) == len(hintable) {
+		return // hintable is matches itself
+	}
+
+	j := 0
+	for i := range matches {
+		if j < len(hintable) && matches[i].Equals(hintable[j]) {
+			matches[i].Hint = hintable[j].Hint
+			j++
 		}
 	}
+}
+
+// matchSpan is the range of columns [start, end) of a match on line y
+type matchSpan struct {
+	y, start, end int
+}
+
+// matchSpans returns the spans of matches, sorted and with overlapping ones
+// merged, so that spans of a line are disjoint
+func matchSpans(matches []Match) []matchSpan {
+	spans := make([]matchSpan, 0, len(matches))
+	for _, match := range matches {
+		if match.Text != "" {
+			spans = append(spans, matchSpan{match.Y, match.X, match.X + len(match.Text)})
+		}
+	}
+	slices.SortFunc(spans, func(a, b matchSpan) int {
+		return cmp.Or(cmp.Compare(a.y, b.y), cmp.Compare(a.start, b.start))
+	})
+
+	merged := spans[:0]
+	for _, span := range spans {
+		if last := len(merged) - 1; last >= 0 && merged[last].y == span.y && span.start <= merged[last].end {
+			merged[last].end = max(merged[last].end, span.end)
+			continue
+		}
+		merged = append(merged, span)
+	}
+	return merged
+}
+
+// filterOverlappingMatches removes matches that overlap with existing matches
+func (s *State) filterOverlappingMatches(candidateMatches []Match, existingMatches []Match) []Match {
+	spans := matchSpans(existingMatches)
 
 	var filteredMatches []Match
 	for _, candidate := range candidateMatches {
-		// Check overlap
-		overlaps := false
-		for i := 0; i < len(candidate.Text); i++ {
-			key := fmt.Sprintf("%d-%d", candidate.Y, candidate.X+i)
-			if existingPositions[key] {
-				overlaps = true
-				break
-			}
-		}
+		// The first span of the line of the candidate ending after its start
+		// is the only one it may overlap
+		start, end := candidate.X, candidate.X+len(candidate.Text)
+		i, _ := slices.BinarySearchFunc(spans, start, func(span matchSpan, start int) int {
+			return cmp.Or(cmp.Compare(span.y, candidate.Y), cmp.Compare(span.end, start+1))
+		})
+		overlaps := start < end && i < len(spans) && spans[i].y == candidate.Y && spans[i].start < end
 
 		if !overlaps {
 			filteredMatches = append(filteredMatches, candidate)
@@ -580,6 +1068,64 @@ func (s *State) assignHints(matches []Match, hints []string, reverse bool, uniqu
 	}
 }
 
+// hintOrder returns the indices of matches in the order they receive hints,
+// shortest first, or nil for the plain reading order
+func (s *State) hintOrder(matches []Match, reverse bool) []int {
+	if s.ProximityConfig == nil && len(s.HistoryScores) == 0 && len(s.crashFrames) == 0 {
+		return nil
+	}
+
+	var order []int
+	if s.ProximityConfig != nil {
+		order = s.proximityOrder(matches)
+	} else {
+		order = make([]int, len(matches))
+		for i := range order {
+			order[i] = i
+		}
+		if reverse {
+			slices.Reverse(order)
+		}
+	}
+
+	if len(s.HistoryScores) > 0 {
+		// Previously selected values first, the most frequent ones first
+		slices.SortStableFunc(order, func(a, b int) int {
+			return cmp.Compare(s.HistoryScores[matches[b].Text], s.HistoryScores[matches[a].Text])
+		})
+	}
+
+	if len(s.crashFrames) > 0 {
+		// Then the frames of the crashes of stack traces before anything
+		rank := func(i int) int {
+			if s.isCrashFrame(matches[i]) {
+				return 0
+			}
+			return 1
+		}
+		slices.SortStableFunc(order, func(a, b int) int {
+			return cmp.Compare(rank(a), rank(b))
+		})
+	}
+
+	return order
+}
+
+// assignHintsInOrder assigns hints to matches in the given order while
+// leaving the matches themselves in reading order
+func (s *State) assignHintsInOrder(matches []Match, order []int, hints []string, uniqueLevel int) {
+	ordered := make([]Match, len(order))
+	for i, idx := range order {
+		ordered[i] = matches[idx]
+	}
+
+	s.assignHints(ordered, hints, false, uniqueLevel)
+
+	for i, idx := range order {
+		matches[idx].Hint = ordered[i].Hint
+	}
+}
+
 // assignUniqueHints assigns unique hints to matches with same text
 func (s *State) assignUniqueHints(matches []Match, hints []string) {
 	previous := make(map[string]string, len(matches)/2)
@@ -736,21 +1282,18 @@ func (s *State) hasSpacingConflict(lineNum int, selectedLines []int, minSpacing
 }
 
 // getGridMatches detects grid patterns and extracts valid words from them
-func (s *State) getGridMatches(existingMatches []Match) []Match {
-	tableStart := time.Now()
+func (s *State) getGridMatches(ctx context.Context, existingMatches []Match) ([]Match, error) {
+	span := logger.StartSpan("tabledetection")
 	inputLineCount := len(s.Lines)
 	minLines := s.TableDetectionConfig.MinLines
 	minColumns := s.TableDetectionConfig.MinColumns
 	confidenceThreshold := s.TableDetectionConfig.ConfidenceThreshold
 
 	// Use the new enhanced API with backward compatibility
-	detector := td.NewDetector(
-		td.WithMinLinesOption(minLines),
-		td.WithMinColumnsOption(minColumns),
-		td.WithConfidenceThresholdOption(confidenceThreshold),
-	)
-
-	tables, err := detector.DetectTables(s.Lines)
+	tables, err := s.detectTables(ctx)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
 	var gridMatches []Match
 	if err != nil || len(tables) == 0 {
 		// Fallback to legacy API if new API fails
@@ -759,31 +1302,28 @@ func (s *State) getGridMatches(existingMatches []Match) []Match {
 			td.WithMinColumns(minColumns),
 			td.WithConfidenceThreshold(confidenceThreshold),
 		)
-		segments := legacyDetector.DetectGrids(s.Lines)
+		segments, err := legacyDetector.DetectGridsContext(ctx, s.Lines)
+		if err != nil {
+			return nil, err
+		}
+		s.stats.Tables = len(segments)
 		gridMatches = s.processLegacySegments(segments, existingMatches)
 	} else {
+		s.stats.Tables = len(tables)
 		gridMatches = s.processNewTables(tables, existingMatches)
 	}
 
-	tableDuration := time.Since(tableStart)
-	slog.Info("tabledetection completed", "duration_ms", tableDuration.Milliseconds(), "input_lines", inputLineCount, "matches_count", len(gridMatches))
-	return gridMatches
+	span.End("input_lines", inputLineCount, "matches_count", len(gridMatches))
+	return gridMatches, nil
 }
 
-// processNewTables processes tables from the new API
+// processNewTables processes tables from the new API, the words of the
+// cells overlapping existingMatches are left out
 func (s *State) processNewTables(tables []td.Table, existingMatches []Match) []Match {
-	// Build position map for overlap detection
-	existingPositions := make(map[string]bool, len(existingMatches)*5)
-	for _, match := range existingMatches {
-		for i := 0; i < len(match.Text); i++ {
-			key := fmt.Sprintf("%d-%d", match.Y, match.X+i)
-			existingPositions[key] = true
-		}
-	}
-
+	threshold := s.tableDetectionConfig().ConfidenceThreshold
 	var gridMatches []Match
 	for _, table := range tables {
-		if table.Confidence < confidenceThreshold {
+		if table.Confidence < threshold {
 			continue
 		}
 
@@ -794,37 +1334,26 @@ func (s *State) processNewTables(tables []td.Table, existingMatches []Match) []M
 				continue
 			}
 
-			// Check overlap
-			overlaps := false
-			for i := 0; i < len(word.Text); i++ {
-				key := fmt.Sprintf("%d-%d", word.Y, word.X+i)
-				if existingPositions[key] {
-					overlaps = true
-					break
-				}
-			}
-
-			if !overlaps {
-				gridMatches = append(gridMatches, Match{
-					X:       word.X,
-					Y:       word.Y,
-					Pattern: "grid",
-					Text:    word.Text,
-					Hint:    nil,
-				})
-			}
+			gridMatches = append(gridMatches, Match{
+				X:       word.X,
+				Y:       word.Y,
+				Pattern: "grid",
+				Text:    word.Text,
+				Hint:    nil,
+				Column:  word.Column,
+			})
 		}
 	}
 
-	return gridMatches
+	return s.filterOverlappingMatches(gridMatches, existingMatches)
 }
 
 // processLegacySegments processes segments from the legacy API (fallback)
 func (s *State) processLegacySegments(segments []td.GridSegment, existingMatches []Match) []Match {
-
+	threshold := s.tableDetectionConfig().ConfidenceThreshold
 	var gridMatches []Match
 	for _, segment := range segments {
-		if segment.Confidence < confidenceThreshold {
+		if segment.Confidence < threshold {
 			continue
 		}
 
@@ -860,6 +1389,7 @@ func (s *State) extractWordsFromTable(table td.Table) []GridWord {
 					X:       cell.StartPos,
 					Y:       cell.LineIndex,
 					LineIdx: rowIdx,
+					Column:  cell.Column + 1,
 				}
 				words = append(words, word)
 			}
@@ -930,6 +1460,7 @@ type GridWord struct {
 	X       int
 	Y       int
 	LineIdx int
+	Column  int // 1-based column in the table, 0 when unknown
 }
 
 // Pre-compiled pattern for better performance
@@ -950,7 +1481,7 @@ func (s *State) applyExclusionFilters(matches []Match) []Match {
 		return matches
 	}
 
-	filterStart := time.Now()
+	span := logger.StartSpan("exclusion filter")
 	// First, find all exclusion regions in the original text
 	exclusionRegions := s.findExclusionRegions()
 	if len(exclusionRegions) == 0 {
@@ -967,12 +1498,7 @@ func (s *State) applyExclusionFilters(matches []Match) []Match {
 		}
 	}
 
-	filterDuration := time.Since(filterStart)
-	slog.Info(
-		"exclusion filter completed", "duration_ms",
-		filterDuration.Milliseconds(),
-		"filtered_count", len(matches)-len(filtered),
-	)
+	span.End("filtered_count", len(matches)-len(filtered))
 
 	return filtered
 }
@@ -1,8 +1,13 @@
 package internal
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 )
 
 func SplitLines(text string) []string {
@@ -518,6 +523,25 @@ func TestMatchIPFS(t *testing.T) {
 	}
 }
 
+func TestBuiltinTriggers(t *testing.T) {
+	for name := range builtinTriggers {
+		if !slices.ContainsFunc(BuiltinPatterns, func(p MatchPattern) bool { return p.Name == name }) {
+			t.Errorf("Expected trigger of %s to be for a builtin pattern", name)
+		}
+	}
+
+	compiled := func(text string) bool {
+		state := NewStateFromLines(SplitLines(text), "abcd", []string{})
+		return slices.ContainsFunc(state.getCompiledPatterns(), func(p *CompiledPattern) bool { return p.Name == "ipfs" })
+	}
+	if compiled("no hashes here") {
+		t.Error("Expected the ipfs pattern to be skipped without Qm in the input")
+	}
+	if !compiled("IPFS hash: QmW2HvDCgqCLJtGxVPZDMWJ5tE2PrsaS3s4VqgdgMqKBNK") {
+		t.Error("Expected the ipfs pattern to be compiled with Qm in the input")
+	}
+}
+
 // Test memory address match
 func TestMatchAddresses(t *testing.T) {
 	lines := SplitLines("Pointer at 0x7fff5fbff5c0\nAddress: 0x1234567890ABCDEF\nOther: 0x0 0xFFFFFFFF")
@@ -638,6 +662,201 @@ func TestMatchFilenames(t *testing.T) {
 	}
 }
 
+// Test compiler and grep style file locations
+func TestMatchFileLocations(t *testing.T) {
+	lines := SplitLines("internal/state.go:123:45: undefined: foo\n[_client.py:1038] ./cmd/main.go:7 10.0.0.1:3306\n" +
+		"src/app.ts(12,5): error TS2304: Cannot find name 'foo'.\n" +
+		"C:\\src\\main.cpp(7): error C2065, C:\\src\\main.c:3:10: error\n" +
+		"console.log(1,2) at localhost:8080")
+	custom := []string{}
+	results := NewStateFromLines(lines, "abcd", custom).Matches(false, 0)
+
+	expected := []string{"internal/state.go:123:45", "_client.py:1038", "./cmd/main.go:7",
+		"src/app.ts(12,5)", `C:\src\main.cpp(7)`, `C:\src\main.c:3:10`}
+	var got []string
+	for _, result := range results {
+		if result.Pattern == "file_location" {
+			got = append(got, result.Text)
+		}
+	}
+
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d file location matches, got %d: %v", len(expected), len(got), got)
+	}
+	for i, text := range expected {
+		if got[i] != text {
+			t.Errorf("Expected %q, got %q", text, got[i])
+		}
+	}
+}
+
+// Test semantic version match
+func TestMatchSemver(t *testing.T) {
+	lines := SplitLines("## v1.2.3 (2024-01-15)\nbump to 1.2.3-rc.1, then 1.0.0+build.5.\nserver 10.0.0.1 go1.24.3")
+	custom := []string{}
+	results := NewStateFromLines(lines, "abcd", custom).Matches(false, 0)
+
+	expected := []string{"v1.2.3", "1.2.3-rc.1", "1.0.0+build.5"}
+	var got []string
+	for _, result := range results {
+		if result.Pattern == "semver" {
+			got = append(got, result.Text)
+		}
+	}
+
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d semver matches, got %d: %v", len(expected), len(got), got)
+	}
+	for i, text := range expected {
+		if got[i] != text {
+			t.Errorf("Expected %q, got %q", text, got[i])
+		}
+	}
+}
+
+func TestMatchMACs(t *testing.T) {
+	lines := SplitLines("    link/ether 02:42:ac:11:00:02 brd ff:ff:ff:ff:ff:ff\n" +
+		"Physical Address: 3C-52-82-4A-1B-F0, switch aabb.ccdd.eeff.\n" +
+		"inet6 2001:db8:aa:bb:cc:dd:ee:ff/64 at 10:20:30")
+	custom := []string{}
+	results := NewStateFromLines(lines, "abcd", custom).Matches(false, 0)
+
+	expected := []string{"02:42:ac:11:00:02", "ff:ff:ff:ff:ff:ff", "3C-52-82-4A-1B-F0", "aabb.ccdd.eeff"}
+	var got []string
+	for _, result := range results {
+		if result.Pattern == "mac" {
+			got = append(got, result.Text)
+		}
+		if result.Pattern == "ipv6" && result.Y < 2 {
+			t.Errorf("Expected MAC addresses not to be matched as ipv6, got %q", result.Text)
+		}
+	}
+
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d mac matches, got %d: %v", len(expected), len(got), got)
+	}
+	for i, text := range expected {
+		if got[i] != text {
+			t.Errorf("Expected %q, got %q", text, got[i])
+		}
+	}
+}
+
+func TestMatchInterfaces(t *testing.T) {
+	lines := SplitLines("2: enp3s0: <BROADCAST,MULTICAST,UP> mtu 1500\n" +
+		"3: wlp2s0: flags, eth0.100@eth0 and br-1234abcd\n" +
+		"7: veth1a2b3c4@if6 docker0 wg0 tun0; entertain ethics\n" +
+		"inet6 fe80::1%eth1 scope link")
+	custom := []string{}
+	results := NewStateFromLines(lines, "abcd", custom).Matches(false, 0)
+
+	expected := []string{"enp3s0", "wlp2s0", "eth0.100", "eth0", "br-1234abcd", "veth1a2b3c4", "docker0", "wg0", "tun0"}
+	var got []string
+	for _, result := range results {
+		if result.Pattern == "interface" {
+			got = append(got, result.Text)
+		}
+	}
+
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d interface matches, got %d: %v", len(expected), len(got), got)
+	}
+	for i, text := range expected {
+		if got[i] != text {
+			t.Errorf("Expected %q, got %q", text, got[i])
+		}
+	}
+}
+
+func TestMatchTestNames(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		lines    []string
+		expected []string
+	}{
+		{"rust_test", []string{"test tests::it_works ... ok", "test tests::it_fails ... FAILED"}, []string{"tests::it_works", "tests::it_fails"}},
+		{"go_test", []string{"--- FAIL: TestParse (0.00s)", "--- PASS: TestFormat (0.01s)"}, []string{"TestParse", "TestFormat"}},
+		{
+			"pytest",
+			[]string{
+				"FAILED tests/test_api.py::test_login - AssertionError: assert 401 == 200",
+				"ERROR tests/test_db.py::TestPool::test_close",
+				"tests/test_math.py::test_add[1-2] PASSED                [ 50%]",
+				"collected 3 items, see docs.py:: later",
+			},
+			[]string{"tests/test_api.py::test_login", "tests/test_db.py::TestPool::test_close", "tests/test_math.py::test_add[1-2]"},
+		},
+		{
+			"jest",
+			[]string{"  Calculator", "    ✓ adds numbers (3 ms)", "    ✕ divides by zero (12 ms)", "    × fails on Windows", "Tests: 1 failed"},
+			[]string{"adds numbers", "divides by zero", "fails on Windows"},
+		},
+		{
+			"nextest",
+			[]string{
+				"        PASS [   0.003s] my-crate tests::it_works",
+				"        FAIL [   0.004s] (2/3) my-crate::bin/cli tests::it_fails",
+				"     Summary [   0.010s] 3 tests run: 2 passed, 1 failed",
+			},
+			[]string{"tests::it_works", "tests::it_fails"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			results := NewStateFromLines(tt.lines, "abcd", []string{}).Matches(false, 0)
+
+			var got []string
+			for _, result := range results {
+				if result.Pattern == tt.pattern {
+					got = append(got, result.Text)
+				}
+			}
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// Test package@version match
+func TestMatchPackageVersions(t *testing.T) {
+	lines := SplitLines("├── lodash@4.17.21\n└─┬ @types/node@18.0.0\nrequire github.com/foo/bar@v0.5.3-0.20240101-abcdef.")
+	custom := []string{}
+	results := NewStateFromLines(lines, "abcd", custom).Matches(false, 0)
+
+	expected := []string{"lodash@4.17.21", "@types/node@18.0.0", "github.com/foo/bar@v0.5.3-0.20240101-abcdef"}
+	var got []string
+	for _, result := range results {
+		if result.Pattern == "package_version" {
+			got = append(got, result.Text)
+		}
+	}
+
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d package matches, got %d: %v", len(expected), len(got), got)
+	}
+	for i, text := range expected {
+		if got[i] != text {
+			t.Errorf("Expected %q, got %q", text, got[i])
+		}
+	}
+}
+
+func TestMatchEnvVarsAndFlags(t *testing.T) {
+	lines := SplitLines("Set $EDITOR or ${XDG_CONFIG_HOME}, then run with --verbose --output=json.\nGOPATH=/root/go\nnot x--y nor $1")
+	results := NewStateFromLines(lines, "abcd", []string{}).Matches(false, 0)
+
+	expected := []string{"env_var:$EDITOR", "env_var:${XDG_CONFIG_HOME}", "flag:--verbose", "flag:--output=json", "env_var:GOPATH", "path:/root/go"}
+	var got []string
+	for _, result := range results {
+		got = append(got, result.Pattern+":"+result.Text)
+	}
+	if !slices.Equal(got, expected) {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
 // Test ISO8601 date-time match
 func TestMatchDateTimeISO8601(t *testing.T) {
 	lines := SplitLines("Created at 2023-12-01T10:30:45Z\nUpdated: 2023-12-01T10:30:45.123Z\nOther: 2023-12-01T10:30:45+08:00")
@@ -910,6 +1129,37 @@ f123456789ab   redis:alpine    stopped     6379/tcp`)
 	}
 }
 
+func TestGridMatchingConfidenceThreshold(t *testing.T) {
+	// A delimited table with empty fields, detected with a confidence of
+	// about 0.77
+	lines := []string{"name,image,status", "web,,", "db,,", "cache,redis,stopped"}
+
+	tests := []struct {
+		name      string
+		threshold float64
+		expected  []string
+	}{
+		{"threshold 0.5", 0.5, []string{"name", "image", "status", "web", "cache", "redis", "stopped"}},
+		{"threshold 0.8", 0.8, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := NewStateFromLines(lines, "abcd", []string{}, WithTableDetection(2, 2, tt.threshold))
+
+			var grid []string
+			for _, match := range state.Matches(false, 0) {
+				if match.Pattern == "grid" {
+					grid = append(grid, match.Text)
+				}
+			}
+			if !slices.Equal(grid, tt.expected) {
+				t.Errorf("Expected grid matches %q, got %q", tt.expected, grid)
+			}
+		})
+	}
+}
+
 // TestMatchURLsWithQuotes tests URL matching in quote-enclosed contexts like curl commands
 func TestMatchURLsWithQuotes(t *testing.T) {
 	// Test case from curl-case file
@@ -1492,3 +1742,192 @@ func TestExclusionEdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestHintMinLength(t *testing.T) {
+	lines := split("a/b /tmp/some/file.txt")
+	state := NewStateFromLines(lines, "abcd", []string{},
+		WithPatternConfigs(map[string]PatternConfig{
+			"path": {HintMinLength: 4},
+		}),
+	)
+
+	results := state.Matches(false, 0)
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 matches, got %d", len(results))
+	}
+
+	for _, match := range results {
+		switch match.Text {
+		case "a/b":
+			if match.Hint != nil {
+				t.Errorf("Expected no hint for '%s', got '%s'", match.Text, *match.Hint)
+			}
+		default:
+			if match.Hint == nil || *match.Hint != "a" {
+				t.Errorf("Expected hint 'a' for '%s', got %v", match.Text, match.Hint)
+			}
+		}
+	}
+}
+
+func TestMinMatchLength(t *testing.T) {
+	lines := split("0x0 0xdeadbeef at a1b2c3d and a1b2c3d4e5f6")
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		expected []string
+	}{
+		{
+			name:     "no minimum",
+			expected: []string{"0x0", "0xdeadbeef", "a1b2c3d", "a1b2c3d4e5f6"},
+		},
+		{
+			name:     "global minimum",
+			opts:     []Option{WithMinMatchLength(4)},
+			expected: []string{"0xdeadbeef", "a1b2c3d", "a1b2c3d4e5f6"},
+		},
+		{
+			name: "pattern minimum overrides the global one",
+			opts: []Option{
+				WithMinMatchLength(4),
+				WithPatternConfigs(map[string]PatternConfig{"sha": {MinLength: 10}}),
+			},
+			expected: []string{"0xdeadbeef", "a1b2c3d4e5f6"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := NewStateFromLines(lines, "abcd", []string{}, tt.opts...)
+
+			var texts []string
+			for _, match := range state.Matches(false, 0) {
+				texts = append(texts, match.Text)
+			}
+			if strings.Join(texts, " ") != strings.Join(tt.expected, " ") {
+				t.Errorf("Expected %v, got %v", tt.expected, texts)
+			}
+		})
+	}
+}
+
+func TestNamedPatterns(t *testing.T) {
+	lines := split("see PROJ-123 and /tmp/file.txt")
+	state := NewStateFromLines(lines, "abcd", []string{},
+		WithNamedPatterns([]MatchPattern{{Name: "jira", Pattern: `[A-Z]+-\d+`}}),
+	)
+
+	results := state.Matches(false, 0)
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 matches, got %d", len(results))
+	}
+	if results[0].Text != "PROJ-123" || results[0].Pattern != "jira" {
+		t.Errorf("Expected 'PROJ-123' from pattern 'jira', got '%s' from '%s'", results[0].Text, results[0].Pattern)
+	}
+	if results[1].Pattern != "path" {
+		t.Errorf("Expected pattern 'path', got '%s'", results[1].Pattern)
+	}
+}
+
+func TestMatchesContext(t *testing.T) {
+	text := strings.Repeat("10.0.0.1 192.168.0.1 /usr/local/bin\n", 200000)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := NewState(text, "abcd", nil, WithTableDetection(3, 3, 0.8)).MatchesContext(ctx, false, 0)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected matching to stop soon after the deadline, took %v", elapsed)
+	}
+}
+
+func TestFilterOverlappingMatches(t *testing.T) {
+	existing := []Match{
+		{X: 0, Y: 0, Text: "alpha"},
+		{X: 10, Y: 0, Text: "beta"},
+		{X: 3, Y: 0, Text: "ph"}, // Inside alpha
+		{X: 5, Y: 2, Text: "gamma"},
+	}
+
+	tests := []struct {
+		name      string
+		candidate Match
+		kept      bool
+	}{
+		{"before the first", Match{X: 0, Y: 1, Text: "x"}, true},
+		{"inside", Match{X: 1, Y: 0, Text: "lp"}, false},
+		{"between", Match{X: 5, Y: 0, Text: "hello"}, true},
+		{"ending on a start", Match{X: 8, Y: 0, Text: "xyz"}, false},
+		{"starting on an end", Match{X: 13, Y: 0, Text: "xyz"}, false},
+		{"after the last", Match{X: 14, Y: 0, Text: "xyz"}, true},
+		{"covering", Match{X: 0, Y: 2, Text: "0123456789ab"}, false},
+		{"same columns on another line", Match{X: 10, Y: 3, Text: "beta"}, true},
+		{"empty", Match{X: 1, Y: 0, Text: ""}, true},
+	}
+
+	state := NewStateFromLines([]string{""}, "abcd", []string{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered := state.filterOverlappingMatches([]Match{tt.candidate}, existing)
+			if kept := len(filtered) == 1; kept != tt.kept {
+				t.Errorf("Expected kept %v, got %v", tt.kept, kept)
+			}
+		})
+	}
+}
+
+func BenchmarkFilterOverlappingMatches(b *testing.B) {
+	// Cells of a wide table against the regex matches of its rows
+	var existing, candidates []Match
+	for y := range 1000 {
+		for column := range 12 {
+			cell := Match{X: column * 16, Y: y, Text: "cell-value-0123"}
+			candidates = append(candidates, cell)
+			if column%3 == 0 {
+				existing = append(existing, cell)
+			}
+		}
+	}
+	state := NewStateFromLines([]string{""}, "abcd", []string{})
+
+	b.ReportAllocs()
+	for b.Loop() {
+		state.filterOverlappingMatches(candidates, existing)
+	}
+}
+
+func BenchmarkGridMatches(b *testing.B) {
+	// The cells of a wide table against the regex matches of its rows, the
+	// table being detected once as in a run of Matches
+	var lines []string
+	var existing []Match
+	for y := range 200 {
+		var cells []string
+		for column := range 12 {
+			cell := fmt.Sprintf("value-%02d-%04d", column, y)
+			if column%3 == 0 {
+				existing = append(existing, Match{X: column * 16, Y: y, Text: cell})
+			}
+			cells = append(cells, fmt.Sprintf("%-16s", cell))
+		}
+		lines = append(lines, strings.TrimRight(strings.Join(cells, ""), " "))
+	}
+	state := NewStateFromLines(lines, "abcd", []string{}, WithTableDetection(2, 2, 0.5))
+	ctx := context.Background()
+	matches, err := state.getGridMatches(ctx, existing)
+	if err != nil || len(matches) == 0 {
+		b.Fatalf("Expected grid matches, got %d (%v)", len(matches), err)
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := state.getGridMatches(ctx, existing); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
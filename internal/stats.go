@@ -0,0 +1,33 @@
+package internal
+
+// Stats summarizes how the matches of the last MatchesContext call were
+// found, for tuning configs
+type Stats struct {
+	Lines      int
+	Patterns   map[string]int // Matches per pattern name
+	Tables     int            // Tables found by table detection
+	TableCells int            // Matches from the cells of tables and known columns
+	Hints      int            // Matches given a hint
+	Conflicts  int            // Overlapping matches resolved, see Conflict
+	Truncated  bool
+}
+
+// Stats returns the statistics of the last MatchesContext call
+func (s *State) Stats() Stats {
+	return s.stats
+}
+
+// recordStats computes the statistics of the final matches, on top of the
+// table detection results recorded while matching
+func (s *State) recordStats(matches []Match) {
+	s.stats.Lines = len(s.Lines)
+	s.stats.Truncated = s.Truncated
+	s.stats.Conflicts = len(s.conflicts)
+	s.stats.Patterns = make(map[string]int)
+	for _, match := range matches {
+		s.stats.Patterns[match.Pattern]++
+		if match.Hint != nil {
+			s.stats.Hints++
+		}
+	}
+}
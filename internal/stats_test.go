@@ -0,0 +1,23 @@
+package internal
+
+import "testing"
+
+func TestStats(t *testing.T) {
+	lines := []string{"see https://example.com and /tmp/a.log", "/tmp/b.log", ""}
+	state := NewStateFromLines(lines, "abcd", []string{}, WithMaxMatches(2))
+	state.Matches(false, 0)
+
+	stats := state.Stats()
+	if stats.Lines != 3 {
+		t.Errorf("Expected 3 lines, got %d", stats.Lines)
+	}
+	if stats.Patterns["url"] != 1 || stats.Patterns["path"] != 1 {
+		t.Errorf("Expected a url and a path, got %v", stats.Patterns)
+	}
+	if stats.Hints != 2 {
+		t.Errorf("Expected 2 hints, got %d", stats.Hints)
+	}
+	if !stats.Truncated {
+		t.Error("Expected the matches to be truncated")
+	}
+}
@@ -0,0 +1,282 @@
+package internal
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"unicode"
+
+	td "github.com/Hanaasagi/magonote/pkg/textdetection/tabledetection"
+)
+
+// TableColumn is a column of a table found in the text
+type TableColumn struct {
+	Header *Match  // Header cell, nil when the table has no header row
+	Cells  []Match // Cells from top to bottom, without the header
+}
+
+// Head returns the topmost cell of the column, where its hint is shown
+func (c TableColumn) Head() Match {
+	if c.Header != nil {
+		return *c.Header
+	}
+	return c.Cells[0]
+}
+
+// span is a range of byte columns, both ends included
+type span struct {
+	start, end int
+}
+
+// TableColumns returns the columns of the tables detected in the text. The
+// column boundaries come from table detection, the cells are then cut from
+// every line of the table so that short words dropped by detection are kept
+func (s *State) TableColumns() []TableColumn {
+	tables, err := s.detectTables(context.Background())
+	if err != nil {
+		slog.Warn("table detection failed", "error", err)
+		return nil
+	}
+
+	threshold := s.tableDetectionConfig().ConfidenceThreshold
+	var columns []TableColumn
+	for _, table := range tables {
+		if table.Confidence < threshold {
+			continue
+		}
+		columns = append(columns, s.tableColumns(table)...)
+	}
+	return columns
+}
+
+// tableDetectionConfig returns the configuration of table detection, the
+// defaults when it is disabled
+func (s *State) tableDetectionConfig() TableDetectionConfig {
+	if s.TableDetectionConfig != nil {
+		return *s.TableDetectionConfig
+	}
+	return TableDetectionConfig{
+		MinLines:            minLines,
+		MinColumns:          minColumns,
+		ConfidenceThreshold: confidenceThreshold,
+	}
+}
+
+// detectTables detects the tables of the input once per run of Matches, for
+// the grid matches, the column matches and TableColumns to share
+func (s *State) detectTables(ctx context.Context) ([]td.Table, error) {
+	if s.tablesDetected {
+		return s.tables, nil
+	}
+
+	config := s.tableDetectionConfig()
+	detector := td.NewDetector(
+		td.WithMinLinesOption(config.MinLines),
+		td.WithMinColumnsOption(config.MinColumns),
+		td.WithConfidenceThresholdOption(config.ConfidenceThreshold),
+	)
+	tables, err := detector.DetectTablesContext(ctx, s.Lines)
+	if err != nil {
+		return nil, err
+	}
+	s.tables, s.tablesDetected = tables, true
+	return tables, nil
+}
+
+// tableColumns splits the lines of a table into columns
+func (s *State) tableColumns(table td.Table) []TableColumn {
+	switch table.Mode {
+	case td.BorderMode, td.MarkdownMode, td.DelimiterMode:
+		// The cells are delimited explicitly
+		return cellColumns(table)
+	}
+
+	spans := columnSpans(table)
+	columns := make([]TableColumn, len(spans))
+
+	for y := table.StartLine; y <= table.EndLine && y < len(s.Lines); y++ {
+		cells := lineCells(s.Lines[y], y, spans)
+		header := y == table.StartLine && isHeaderRow(cells)
+		for i, cell := range cells {
+			if cell == nil {
+				continue
+			}
+			if header {
+				columns[i].Header = cell
+			} else {
+				columns[i].Cells = append(columns[i].Cells, *cell)
+			}
+		}
+	}
+
+	return slices.DeleteFunc(columns, func(c TableColumn) bool {
+		return len(c.Cells) == 0
+	})
+}
+
+// cellColumns groups the cells of a table by column. The first row is the
+// header when a rule separates it from the next row
+func cellColumns(table td.Table) []TableColumn {
+	columns := make([]TableColumn, table.NumColumns)
+	for r, row := range table.Cells {
+		header := r == 0 && len(table.Cells) > 1 && len(row) > 0 &&
+			table.Cells[1][0].LineIndex > row[0].LineIndex+1
+		for _, cell := range row {
+			m := Match{X: cell.StartPos, Y: cell.LineIndex, Pattern: "column", Text: cell.Text}
+			if header {
+				columns[cell.Column].Header = &m
+			} else {
+				columns[cell.Column].Cells = append(columns[cell.Column].Cells, m)
+			}
+		}
+	}
+
+	return slices.DeleteFunc(columns, func(c TableColumn) bool {
+		return len(c.Cells) == 0
+	})
+}
+
+// columnSpans merges the overlapping cells of a table into column spans,
+// from left to right
+func columnSpans(table td.Table) []span {
+	var cells []span
+	for _, row := range table.Cells {
+		for _, cell := range row {
+			cells = append(cells, span{cell.StartPos, cell.EndPos})
+		}
+	}
+	slices.SortFunc(cells, func(a, b span) int {
+		return a.start - b.start
+	})
+
+	var spans []span
+	for _, cell := range cells {
+		if n := len(spans); n > 0 && cell.start <= spans[n-1].end {
+			spans[n-1].end = max(spans[n-1].end, cell.end)
+			continue
+		}
+		spans = append(spans, cell)
+	}
+	return spans
+}
+
+// lineCells returns the cell of line y in every column span, nil where the
+// line has nothing. Words overlapping a span belong to its cell, words
+// between spans are ignored
+func lineCells(line string, y int, spans []span) []*Match {
+	cells := make([]*Match, len(spans))
+	for _, word := range lineWords(line) {
+		for i, sp := range spans {
+			if word.start > sp.end || word.end < sp.start {
+				continue
+			}
+			if cells[i] == nil {
+				cells[i] = &Match{X: word.start, Y: y, Pattern: "column"}
+			}
+			cells[i].Text = line[cells[i].X : word.end+1]
+			break
+		}
+	}
+	return cells
+}
+
+// lineWords returns the spans of the whitespace separated words of line
+func lineWords(line string) []span {
+	var words []span
+	start := -1
+	for i, r := range line {
+		if unicode.IsSpace(r) {
+			if start >= 0 {
+				words = append(words, span{start, i - 1})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, span{start, len(line) - 1})
+	}
+	return words
+}
+
+// isHeaderRow reports whether the cells look like column titles such as
+// `CONTAINER ID` or `%CPU`, which have letters but no lowercase ones
+func isHeaderRow(cells []*Match) bool {
+	found := false
+	for _, cell := range cells {
+		if cell == nil {
+			continue
+		}
+		if strings.ToUpper(cell.Text) != cell.Text || !strings.ContainsFunc(cell.Text, unicode.IsLetter) {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// WithTableColumns only keeps the matches of the tables having a column
+// headed by one of names, case insensitively, that are in such a column
+func WithTableColumns(names []string) Option {
+	return optionFunc(func(s *State) {
+		s.TableColumnNames = names
+	})
+}
+
+// filterTableColumns drops the matches in the headers and in the cells of
+// the columns not named in TableColumnNames of the tables having one of
+// them, among the detected columns
+func (s *State) filterTableColumns(matches []Match, columns []TableColumn) []Match {
+	tables := make(map[int][]TableColumn)
+	for _, column := range columns {
+		if column.Header != nil {
+			tables[column.Header.Y] = append(tables[column.Header.Y], column)
+		}
+	}
+
+	// Cells by line, so that a match is only compared to those of its line
+	kept := make(map[int][]Match)
+	dropped := make(map[int][]Match)
+	for _, columns := range tables {
+		if !slices.ContainsFunc(columns, s.isNamedColumn) {
+			continue
+		}
+		for _, column := range columns {
+			dropped[column.Header.Y] = append(dropped[column.Header.Y], *column.Header)
+			cells := dropped
+			if s.isNamedColumn(column) {
+				cells = kept
+			}
+			for _, cell := range column.Cells {
+				cells[cell.Y] = append(cells[cell.Y], cell)
+			}
+		}
+	}
+	if len(dropped) == 0 {
+		return matches
+	}
+
+	return slices.DeleteFunc(matches, func(mat Match) bool {
+		return !slices.ContainsFunc(kept[mat.Y], func(cell Match) bool { return containsMatch(cell, mat) }) &&
+			slices.ContainsFunc(dropped[mat.Y], func(cell Match) bool { return overlapsMatch(cell, mat) })
+	})
+}
+
+// isNamedColumn reports whether the header of column is in TableColumnNames
+func (s *State) isNamedColumn(column TableColumn) bool {
+	return column.Header != nil && slices.ContainsFunc(s.TableColumnNames, func(name string) bool {
+		return strings.EqualFold(strings.TrimSpace(column.Header.Text), name)
+	})
+}
+
+// containsMatch reports whether the text of outer contains that of inner
+func containsMatch(outer, inner Match) bool {
+	return outer.Y == inner.Y && outer.X <= inner.X && inner.X+len(inner.Text) <= outer.X+len(outer.Text)
+}
+
+// overlapsMatch reports whether the texts of a and b overlap
+func overlapsMatch(a, b Match) bool {
+	return a.Y == b.Y && a.X < b.X+len(b.Text) && b.X < a.X+len(a.Text)
+}
@@ -0,0 +1,140 @@
+package internal
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTableColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		lines   []string
+		headers []string
+		cells   [][]string
+	}{
+		{
+			name: "docker ps",
+			lines: []string{
+				"CONTAINER ID   IMAGE                      COMMAND                  NAMES",
+				"5386a67b0f15   linuxserver/ffmpeg:5.1.2   \"/ffmpegwrapper.sh b…\"   sad_austin",
+				"f3b0b352c2d5   mysql                      \"docker-entrypoint.s…\"   some-mysql",
+				"a3b0b352c2d5   redis                      \"docker-entrypoint.s…\"   some-redis",
+			},
+			headers: []string{"CONTAINER ID", "IMAGE", "COMMAND", "NAMES"},
+			cells: [][]string{
+				{"5386a67b0f15", "f3b0b352c2d5", "a3b0b352c2d5"},
+				{"linuxserver/ffmpeg:5.1.2", "mysql", "redis"},
+				{"\"/ffmpegwrapper.sh b…\"", "\"docker-entrypoint.s…\"", "\"docker-entrypoint.s…\""},
+				{"sad_austin", "some-mysql", "some-redis"},
+			},
+		},
+		{
+			name: "short words",
+			lines: []string{
+				"  PID TTY          TIME CMD",
+				" 1234 pts/0    00:00:00 bash",
+				" 5678 pts/0    00:00:01 vim",
+				" 9012 pts/0    00:00:00 ps",
+			},
+			headers: []string{"PID", "TTY", "TIME", "CMD"},
+			cells: [][]string{
+				{"1234", "5678", "9012"},
+				{"pts/0", "pts/0", "pts/0"},
+				{"00:00:00", "00:00:01", "00:00:00"},
+				{"bash", "vim", "ps"},
+			},
+		},
+		{
+			name: "bordered",
+			lines: []string{
+				"+----+-------+-----+",
+				"| id | name  | age |",
+				"+----+-------+-----+",
+				"|  1 | alice |  30 |",
+				"|  2 | bob   |  25 |",
+				"+----+-------+-----+",
+			},
+			headers: []string{"id", "name", "age"},
+			cells: [][]string{
+				{"1", "2"},
+				{"alice", "bob"},
+				{"30", "25"},
+			},
+		},
+		{
+			name:  "no table",
+			lines: []string{"lorem ipsum", "dolor sit amet"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns := NewStateFromLines(tt.lines, "abcd", []string{}).TableColumns()
+			if len(columns) != len(tt.cells) {
+				t.Fatalf("Expected %d columns, got %d: %+v", len(tt.cells), len(columns), columns)
+			}
+
+			for i, column := range columns {
+				if column.Header == nil || column.Header.Text != tt.headers[i] {
+					t.Errorf("Expected header %q, got %+v", tt.headers[i], column.Header)
+				}
+				if len(column.Cells) != len(tt.cells[i]) {
+					t.Fatalf("Expected %d cells in column %d, got %+v", len(tt.cells[i]), i, column.Cells)
+				}
+				for j, cell := range column.Cells {
+					if cell.Text != tt.cells[i][j] || tt.lines[cell.Y][cell.X:cell.X+len(cell.Text)] != cell.Text {
+						t.Errorf("Expected %q, got %q at (%d, %d)", tt.cells[i][j], cell.Text, cell.X, cell.Y)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestTableColumnsFilter(t *testing.T) {
+	lines := []string{
+		"$ ps",
+		"  PID TTY          TIME CMD",
+		" 1234 pts/0    00:00:00 bash",
+		" 5678 pts/0    00:00:01 vim",
+		" 9012 pts/0    00:00:00 ps",
+		"see /tmp/notes.txt",
+	}
+
+	state := NewStateFromLines(lines, "abcd", []string{},
+		WithTableDetection(minLines, minColumns, confidenceThreshold),
+		WithTableColumns([]string{"pid", "CMD"}),
+	)
+	var got []string
+	for _, mat := range state.Matches(false, 0) {
+		got = append(got, mat.Text)
+	}
+
+	expected := []string{"/tmp/notes.txt", "1234", "bash", "5678", "vim", "9012"}
+	if !slices.Equal(got, expected) {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestTableColumnsDetectedOnce(t *testing.T) {
+	lines := []string{
+		"  PID TTY          TIME CMD",
+		" 1234 pts/0    00:00:00 bash",
+		" 5678 pts/0    00:00:01 vim",
+	}
+	state := NewStateFromLines(lines, "abcd", []string{}, WithTableDetection(minLines, minColumns, confidenceThreshold))
+	state.Matches(false, 0)
+	if !state.tablesDetected {
+		t.Fatal("Expected the tables to be detected by Matches")
+	}
+
+	if columns := state.TableColumns(); len(columns) != 4 {
+		t.Errorf("Expected 4 columns, got %+v", columns)
+	}
+
+	// The columns come from the tables Matches detected, not detected again
+	state.tables = nil
+	if columns := state.TableColumns(); len(columns) != 0 {
+		t.Errorf("Expected the tables detected by Matches to be reused, got %+v", columns)
+	}
+}
@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// DefaultTabWidth is the distance between the tab stops of terminals
+const DefaultTabWidth = 8
+
+// WithTabWidth draws the tabs of the text up to tab stops every width
+// columns instead of DefaultTabWidth, zero or less draws them as is. The
+// lines and the matches keep their tabs
+func WithTabWidth(width int) Option {
+	return optionFunc(func(s *State) {
+		s.TabWidth = width
+	})
+}
+
+// displayColumn returns the cell the byte offset x of line is drawn at, its
+// tabs being expanded to tab stops every width columns
+func displayColumn(line string, x, width int) int {
+	return displayWidth(expandTabs(line[:min(x, len(line))], width))
+}
+
+// expandTabs replaces the tabs of text with the spaces up to the next tab
+// stop, as a terminal moves the cursor. Escape sequences take no columns
+func expandTabs(text string, width int) string {
+	if width <= 0 || !strings.Contains(text, "\t") {
+		return text
+	}
+
+	var b strings.Builder
+	b.Grow(len(text))
+	column := 0
+	for i := 0; i < len(text); {
+		switch c := text[i]; c {
+		case '\t':
+			spaces := width - column%width
+			b.WriteString(strings.Repeat(" ", spaces))
+			column += spaces
+			i++
+			continue
+		case '\n', '\r':
+			column = 0
+		case '\x1b':
+			end := escapeEnd(text, i)
+			b.WriteString(text[i:end])
+			i = end
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(text[i:])
+		b.WriteString(text[i : i+size])
+		column += runewidth.RuneWidth(r)
+		i += size
+	}
+	return b.String()
+}
+
+// escapeEnd returns the end of the escape sequence starting at i: a CSI
+// sequence up to its final byte, an OSC sequence up to BEL or ST, or a
+// single character
+func escapeEnd(text string, i int) int {
+	if i+1 >= len(text) {
+		return len(text)
+	}
+	switch text[i+1] {
+	case '[':
+		for j := i + 2; j < len(text); j++ {
+			if text[j] >= 0x40 && text[j] <= 0x7e {
+				return j + 1
+			}
+		}
+		return len(text)
+	case ']':
+		for j := i + 2; j < len(text); j++ {
+			if text[j] == '\a' {
+				return j + 1
+			}
+			if text[j] == '\x1b' && j+1 < len(text) && text[j+1] == '\\' {
+				return j + 2
+			}
+		}
+		return len(text)
+	default:
+		return i + 2
+	}
+}
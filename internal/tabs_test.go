@@ -0,0 +1,77 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestExpandTabs(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  string
+	}{
+		{name: "leading tab", text: "\tfoo", width: 8, want: "        foo"},
+		{name: "tab stops", text: "ab\tc\td", width: 4, want: "ab  c   d"},
+		{name: "every line", text: "a\tb\nab\tc", width: 4, want: "a   b\nab  c"},
+		{name: "wide runes", text: "日本\tx", width: 8, want: "日本    x"},
+		{name: "escape sequences", text: "\x1b[31mab\x1b[0m\tc", width: 4, want: "\x1b[31mab\x1b[0m  c"},
+		{name: "hyperlink", text: "\x1b]8;;https://a.b\x1b\\x\x1b]8;;\x1b\\\ty", width: 4, want: "\x1b]8;;https://a.b\x1b\\x\x1b]8;;\x1b\\   y"},
+		{name: "kept", text: "a\tb", width: 0, want: "a\tb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expandTabs(tt.text, tt.width); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestViewTabWidth(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []Option
+		column int
+	}{
+		{name: "default", column: 8},
+		{name: "width 2", opts: []Option{WithTabWidth(2)}, column: 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := NewState("host\t10.0.0.1", "qwerty", nil, tt.opts...)
+			view := NewView(
+				state, false, false, 0, false, "",
+				GetColor("default"), GetColor("default"), GetColor("default"), GetColor("default"),
+				GetColor("default"), GetColor("default"), GetColor("default"), GetColor("default"),
+			)
+
+			screen := tcell.NewSimulationScreen("UTF-8")
+			if err := screen.Init(); err != nil {
+				t.Fatalf("Failed to initialize simulation screen: %v", err)
+			}
+			defer screen.Fini()
+			screen.SetSize(40, 2)
+			view.screen = screen
+
+			view.render("")
+
+			// The match keeps the offset of the raw line, the hint lands on
+			// the cell the terminal shows the IP at
+			if len(view.matches) != 1 || view.matches[0].X != 5 || state.Lines[0] != "host\t10.0.0.1" {
+				t.Fatalf("Expected the IP at offset 5 of the raw line, got %v in %q", view.matches, state.Lines)
+			}
+			hint := []rune(*view.matches[0].Hint)
+			if r, _, _, _ := screen.GetContent(tt.column, 0); r != hint[0] {
+				t.Errorf("Expected hint %q at column %d, got %q", *view.matches[0].Hint, tt.column, r)
+			}
+			if r, _, _, _ := screen.GetContent(tt.column-1, 0); r != ' ' {
+				t.Errorf("Expected the tab drawn as spaces, got %q", r)
+			}
+		})
+	}
+}
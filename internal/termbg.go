@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// backgroundQuery asks the terminal for its background color (OSC 11)
+const backgroundQuery = "\x1b]11;?\x07"
+
+// backgroundReply matches the reply to backgroundQuery, such as
+// "\x1b]11;rgb:1e1e/1e1e/2e2e\x07", terminated by BEL or ST
+var backgroundReply = regexp.MustCompile(`\]11;rgba?:([0-9a-fA-F]{1,4})/([0-9a-fA-F]{1,4})/([0-9a-fA-F]{1,4})(?:/[0-9a-fA-F]{1,4})?(?:\x07|\x1b\\)`)
+
+// TerminalBackground returns the background color of the terminal, asking
+// it with OSC 11 and falling back to the COLORFGBG variable. ok is false
+// when neither tells
+func TerminalBackground(timeout time.Duration) (bg RGB, ok bool) {
+	bg, err := queryBackground(timeout)
+	if err == nil {
+		return bg, true
+	}
+	slog.Debug("Terminal didn't tell its background", "error", err)
+	return backgroundFromEnv(os.Getenv("COLORFGBG"))
+}
+
+// queryBackground asks the terminal on /dev/tty for its background color,
+// waiting at most timeout for the reply
+func queryBackground(timeout time.Duration) (RGB, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return RGB{}, fmt.Errorf("opening tty: %w", err)
+	}
+	defer tty.Close() // nolint: errcheck
+
+	state, err := term.MakeRaw(int(tty.Fd()))
+	if err != nil {
+		return RGB{}, fmt.Errorf("making tty raw: %w", err)
+	}
+	defer term.Restore(int(tty.Fd()), state) // nolint: errcheck
+
+	if _, err := tty.WriteString(backgroundQuery); err != nil {
+		return RGB{}, fmt.Errorf("writing query: %w", err)
+	}
+	if err := tty.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return RGB{}, fmt.Errorf("setting deadline: %w", err)
+	}
+
+	var reply []byte
+	buf := make([]byte, 64)
+	for {
+		n, err := tty.Read(buf)
+		if err != nil {
+			return RGB{}, fmt.Errorf("reading reply: %w", err)
+		}
+		reply = append(reply, buf[:n]...)
+		if rgb, ok := parseBackgroundReply(string(reply)); ok {
+			return rgb, nil
+		}
+	}
+}
+
+// parseBackgroundReply parses the reply to backgroundQuery
+func parseBackgroundReply(reply string) (RGB, bool) {
+	m := backgroundReply.FindStringSubmatch(reply)
+	if m == nil {
+		return RGB{}, false
+	}
+
+	// Channels have 1 to 4 hex digits, scaled to 8 bits
+	channel := func(hex string) uint8 {
+		v, _ := strconv.ParseUint(hex, 16, 16)
+		maxValue := uint64(1)<<(4*len(hex)) - 1
+		return uint8(v * 255 / maxValue)
+	}
+	return RGB{channel(m[1]), channel(m[2]), channel(m[3])}, true
+}
+
+// backgroundFromEnv returns the background of a COLORFGBG value such as
+// "15;0", whose last field is the palette index of the background
+func backgroundFromEnv(colorfgbg string) (RGB, bool) {
+	fields := strings.Split(colorfgbg, ";")
+	index, err := strconv.ParseUint(fields[len(fields)-1], 10, 8)
+	if err != nil {
+		return RGB{}, false
+	}
+	return paletteRGB(uint8(index)), true
+}
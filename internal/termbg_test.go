@@ -0,0 +1,45 @@
+package internal
+
+import "testing"
+
+func TestParseBackgroundReply(t *testing.T) {
+	tests := []struct {
+		reply    string
+		expected RGB
+		ok       bool
+	}{
+		{"\x1b]11;rgb:1e1e/1e1e/2e2e\x07", RGB{0x1e, 0x1e, 0x2e}, true},
+		{"\x1b]11;rgb:ffff/ffff/ffff\x1b\\", RGB{255, 255, 255}, true},
+		{"\x1b]11;rgb:f/8/0\x07", RGB{255, 136, 0}, true},
+		{"\x1b]11;rgba:0000/0000/0000/ffff\x07", RGB{0, 0, 0}, true},
+		{"\x1b]11;rgb:1e1e/1e1e", RGB{}, false},
+		{"", RGB{}, false},
+	}
+
+	for _, tt := range tests {
+		rgb, ok := parseBackgroundReply(tt.reply)
+		if ok != tt.ok || rgb != tt.expected {
+			t.Errorf("parseBackgroundReply(%q): Expected %v %v, got %v %v", tt.reply, tt.expected, tt.ok, rgb, ok)
+		}
+	}
+}
+
+func TestBackgroundFromEnv(t *testing.T) {
+	tests := []struct {
+		colorfgbg string
+		expected  RGB
+		ok        bool
+	}{
+		{"15;0", RGB{0, 0, 0}, true},
+		{"0;default;15", RGB{255, 255, 255}, true},
+		{"15;default", RGB{}, false},
+		{"", RGB{}, false},
+	}
+
+	for _, tt := range tests {
+		rgb, ok := backgroundFromEnv(tt.colorfgbg)
+		if ok != tt.ok || rgb != tt.expected {
+			t.Errorf("backgroundFromEnv(%q): Expected %v %v, got %v %v", tt.colorfgbg, tt.expected, tt.ok, rgb, ok)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// terminal is a terminal other than the one of the process, see WithTerminal
+type terminal struct {
+	device string
+	term   string
+}
+
+// Sequences written around those of tcell, which only enters the alternate
+// screen when the terminfo entry of the terminal has one and clears the
+// screen of the shell otherwise
+const (
+	saveCursor     = "\x1b7"
+	restoreCursor  = "\x1b8"
+	enterAltScreen = "\x1b[?1049h"
+	exitAltScreen  = "\x1b[?1049l"
+)
+
+// resizePollInterval is how often the size of a terminal other than the
+// one of the process is checked, SIGWINCH only reaching the processes of a
+// terminal
+const resizePollInterval = 250 * time.Millisecond
+
+// terminalScreen is a tcell screen shown on the alternate screen buffer,
+// leaving the screen and cursor of the shell as they were once finished. It
+// posts a resizeEvent whenever the size of the terminal changes, as tcell
+// drops its own resize events when its queue is full
+type terminalScreen struct {
+	tcell.Screen
+	out       io.WriteCloser                   // Terminal, for the sequences written around tcell
+	size      func() (tcell.WindowSize, error) // Size of the terminal
+	altScreen bool                             // Alternate screen entered here rather than by tcell
+	poll      bool                             // Poll the size rather than wait for SIGWINCH
+
+	stop chan struct{}
+	done chan struct{} // Closed once watchSize returns, nil until Init
+}
+
+// resizeEvent tells that the terminal of a terminalScreen was resized
+type resizeEvent struct {
+	tcell.EventTime
+}
+
+// openTerminalScreen creates the screen of term, the terminal of the process
+// when nil
+func openTerminalScreen(term *terminal) (*terminalScreen, error) {
+	device, name := "/dev/tty", os.Getenv("TERM")
+	if term != nil {
+		device = term.device
+		if term.term != "" {
+			name = term.term
+		}
+	}
+
+	info, err := tcell.LookupTerminfo(name)
+	if err != nil {
+		return nil, fmt.Errorf("looking up terminal %s: %w", name, err)
+	}
+	// tcell closes its tty once finished, before the cursor is restored
+	out, err := os.OpenFile(device, os.O_WRONLY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("opening terminal %s: %w", device, err)
+	}
+	tty, err := tcell.NewDevTtyFromDev(device)
+	if err != nil {
+		out.Close() // nolint: errcheck
+		return nil, fmt.Errorf("opening terminal %s: %w", device, err)
+	}
+	screen, err := tcell.NewTerminfoScreenFromTtyTerminfo(tty, info)
+	if err != nil {
+		out.Close() // nolint: errcheck
+		return nil, err
+	}
+
+	return &terminalScreen{
+		Screen:    screen,
+		out:       out,
+		size:      tty.WindowSize,
+		altScreen: info.EnterCA == "" && os.Getenv("TCELL_ALTSCREEN") != "disable",
+		poll:      term != nil,
+		stop:      make(chan struct{}),
+	}, nil
+}
+
+// Init saves the cursor and enters the alternate screen before tcell takes
+// over the terminal
+func (s *terminalScreen) Init() error {
+	s.write(saveCursor)
+	if s.altScreen {
+		s.write(enterAltScreen)
+	}
+	if err := s.Screen.Init(); err != nil {
+		s.restore()
+		s.out.Close() // nolint: errcheck
+		return err
+	}
+
+	size, _ := s.size()
+	s.done = make(chan struct{})
+	go s.watchSize(size)
+	return nil
+}
+
+// Fini gives the terminal back with the screen and cursor of the shell
+func (s *terminalScreen) Fini() {
+	close(s.stop)
+	s.Screen.Fini()
+	if s.done != nil {
+		<-s.done
+	}
+	s.restore()
+	s.out.Close() // nolint: errcheck
+}
+
+// restore leaves the alternate screen and restores the cursor saved by Init
+func (s *terminalScreen) restore() {
+	if s.altScreen {
+		s.write(exitAltScreen)
+	}
+	s.write(restoreCursor)
+}
+
+func (s *terminalScreen) write(seq string) {
+	if _, err := io.WriteString(s.out, seq); err != nil {
+		slog.Debug("Failed to write to the terminal", "error", err)
+	}
+}
+
+// watchSize posts a resizeEvent whenever the size of the terminal changes
+// from last, until Fini
+func (s *terminalScreen) watchSize(last tcell.WindowSize) {
+	defer close(s.done)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGWINCH)
+	defer signal.Stop(signals)
+
+	var tick <-chan time.Time
+	if s.poll {
+		ticker := time.NewTicker(resizePollInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-signals:
+		case <-tick:
+		case <-s.stop:
+			return
+		}
+
+		size, err := s.size()
+		if err != nil || size == last {
+			continue
+		}
+		last = size
+
+		ev := &resizeEvent{}
+		ev.SetEventNow()
+		// Unlike the resize events of tcell, waits for room in the queue
+		for s.PostEvent(ev) != nil {
+			select {
+			case <-time.After(10 * time.Millisecond):
+			case <-s.stop:
+				return
+			}
+		}
+	}
+}
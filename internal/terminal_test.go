@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// terminalOutput records what is written to a terminal
+type terminalOutput struct {
+	strings.Builder
+}
+
+func (o *terminalOutput) Close() error {
+	return nil
+}
+
+func TestTerminalScreenRestores(t *testing.T) {
+	tests := []struct {
+		name      string
+		altScreen bool
+		expected  string
+	}{
+		{"alternate screen of tcell", false, saveCursor + restoreCursor},
+		{"alternate screen entered", true, saveCursor + enterAltScreen + exitAltScreen + restoreCursor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &terminalOutput{}
+			screen := &terminalScreen{
+				Screen:    tcell.NewSimulationScreen("UTF-8"),
+				out:       out,
+				size:      func() (tcell.WindowSize, error) { return tcell.WindowSize{}, nil },
+				altScreen: tt.altScreen,
+				stop:      make(chan struct{}),
+			}
+			if err := screen.Init(); err != nil {
+				t.Fatalf("Failed to initialize screen: %v", err)
+			}
+			screen.Fini()
+
+			if out.String() != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestTerminalScreenResize(t *testing.T) {
+	var mu sync.Mutex
+	size := tcell.WindowSize{Width: 80, Height: 24}
+	screen := &terminalScreen{
+		Screen: tcell.NewSimulationScreen("UTF-8"),
+		out:    &terminalOutput{},
+		size: func() (tcell.WindowSize, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return size, nil
+		},
+		poll: true,
+		stop: make(chan struct{}),
+	}
+	if err := screen.Init(); err != nil {
+		t.Fatalf("Failed to initialize screen: %v", err)
+	}
+	defer screen.Fini()
+
+	mu.Lock()
+	size.Width = 40
+	mu.Unlock()
+
+	events := make(chan tcell.Event, 1)
+	go func() { events <- screen.PollEvent() }()
+	select {
+	case ev := <-events:
+		if _, ok := ev.(*resizeEvent); !ok {
+			t.Errorf("Expected a resize event, got %T", ev)
+		}
+	case <-time.After(5 * resizePollInterval):
+		t.Fatal("Expected a resize event")
+	}
+}
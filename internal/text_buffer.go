@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"github.com/adrg/xdg"
 	"github.com/gdamore/tcell/v2"
-	"github.com/mattn/go-runewidth"
 	"os"
 	"path/filepath"
 	"strings"
@@ -14,8 +13,9 @@ import (
 const extraCapacity = 16
 
 type TextCell struct {
-	Rune  rune
-	Style tcell.Style
+	Rune      rune
+	Combining []rune // Runes after Rune in its grapheme cluster
+	Style     tcell.Style
 }
 
 // TextBuffer manages text rendering with automatic wrapping
@@ -24,6 +24,7 @@ type TextBuffer struct {
 	width   int          // Terminal width
 	height  int          // Terminal height
 	maxX    int          // Maximum X coordinate for each line
+	offset  int          // Number of wrapped screen rows scrolled past
 }
 
 func (tb *TextBuffer) String() string {
@@ -32,6 +33,9 @@ func (tb *TextBuffer) String() string {
 		for _, cell := range row {
 			if cell.Rune != 0 {
 				sb.WriteRune(cell.Rune)
+				for _, r := range cell.Combining {
+					sb.WriteRune(r)
+				}
 			}
 		}
 	}
@@ -72,6 +76,19 @@ func (tb *TextBuffer) Clear() {
 // SetCell sets a character at the specified original coordinates
 // The buffer stores content without wrapping - wrapping is applied only when writing to screen
 func (tb *TextBuffer) SetCell(x, y int, r rune, style tcell.Style) {
+	tb.setCell(x, y, TextCell{Rune: r, Style: style})
+}
+
+// SetGrapheme sets a grapheme cluster at the specified original coordinates
+func (tb *TextBuffer) SetGrapheme(x, y int, cluster string, style tcell.Style) {
+	runes := []rune(cluster)
+	if len(runes) == 0 {
+		return
+	}
+	tb.setCell(x, y, TextCell{Rune: runes[0], Combining: runes[1:], Style: style})
+}
+
+func (tb *TextBuffer) setCell(x, y int, cell TextCell) {
 	// Ensure the row is wide enough
 	if len(tb.content[y]) <= x {
 		newRow := make([]TextCell, x+extraCapacity) // Add some buffer
@@ -83,23 +100,24 @@ func (tb *TextBuffer) SetCell(x, y int, r rune, style tcell.Style) {
 	}
 
 	// Store the cell at its original coordinates
-	tb.content[y][x] = TextCell{
-		Rune:  r,
-		Style: style,
+	tb.content[y][x] = cell
+}
+
+// OverlayCell sets a grapheme cluster on top of the existing cell, composing
+// its style with the style of the cell underneath
+func (tb *TextBuffer) OverlayCell(x, y int, cluster string, style tcell.Style) {
+	original := tcell.StyleDefault
+	if x < len(tb.content[y]) {
+		original = tb.content[y][x].Style
 	}
+	tb.SetGrapheme(x, y, cluster, composeStyle(original, style))
 }
 
 // SetString sets a string at the specified original coordinates
 func (tb *TextBuffer) SetString(x, y int, text string, style tcell.Style) {
 	currentX := x
-	for _, r := range text {
-		tb.SetCell(currentX, y, r, style)
-
-		// Calculate the width of the current rune
-		width := runewidth.RuneWidth(r)
-		if width <= 0 {
-			width = 1
-		}
+	for cluster, width := range graphemes(text) {
+		tb.SetGrapheme(currentX, y, cluster, style)
 		currentX += width
 	}
 }
@@ -120,7 +138,58 @@ func (tb *TextBuffer) dumpSnapshot() error {
 	return err
 }
 
-// WriteToScreen writes the buffer content to a tcell screen with automatic wrapping
+// lineWidth returns the index after the last non-empty cell of line y
+func (tb *TextBuffer) lineWidth(y int) int {
+	row := tb.content[y]
+	for x := len(row) - 1; x >= 0; x-- {
+		if row[x].Rune != 0 {
+			return x + 1
+		}
+	}
+	return 0
+}
+
+// lineRows returns the number of screen rows line y occupies after wrapping
+func (tb *TextBuffer) lineRows(y int) int {
+	width := tb.lineWidth(y)
+	if width == 0 || tb.width <= 0 {
+		return 1
+	}
+	return (width + tb.width - 1) / tb.width
+}
+
+// RowOf returns the wrapped screen row where line y starts
+func (tb *TextBuffer) RowOf(y int) int {
+	row := 0
+	for i := 0; i < y && i < len(tb.content); i++ {
+		row += tb.lineRows(i)
+	}
+	return row
+}
+
+// TotalRows returns the number of screen rows needed to display the whole buffer
+func (tb *TextBuffer) TotalRows() int {
+	return tb.RowOf(len(tb.content))
+}
+
+// MaxOffset returns the largest valid scroll offset
+func (tb *TextBuffer) MaxOffset() int {
+	return max(0, tb.TotalRows()-tb.height)
+}
+
+// SetOffset scrolls the buffer so that the given wrapped row is at the top
+// of the screen, the offset is clamped to the scrollable range
+func (tb *TextBuffer) SetOffset(offset int) {
+	tb.offset = min(max(0, offset), tb.MaxOffset())
+}
+
+// Offset returns the current scroll offset
+func (tb *TextBuffer) Offset() int {
+	return tb.offset
+}
+
+// WriteToScreen writes the visible part of the buffer content to a tcell screen
+// with automatic wrapping
 func (tb *TextBuffer) WriteToScreen(screen tcell.Screen) {
 	if tb.width <= 0 {
 		return
@@ -130,58 +199,37 @@ func (tb *TextBuffer) WriteToScreen(screen tcell.Screen) {
 		tb.dumpSnapshot() // nolint
 	}
 
-	screenY := 0
+	// Virtual row in the wrapped content, rows before offset are skipped
+	row := 0
 
 	// Process each line in order
 	for y := 0; y < len(tb.content); y++ {
-		if screenY >= tb.height {
+		if row-tb.offset >= tb.height {
 			break // Screen is full
 		}
 
-		row := tb.content[y]
-		if len(row) == 0 {
-			screenY++ // Empty line takes one screen line
+		rows := tb.lineRows(y)
+		if row+rows <= tb.offset {
+			row += rows // Line is scrolled out of view
 			continue
 		}
 
-		// Find the last non-empty cell in this row
-		maxX := -1
-		for x := 0; x < len(row); x++ {
-			cell := row[x]
-			if cell.Rune != 0 {
-				maxX = x
+		maxX := tb.lineWidth(y)
+		for x := 0; x < maxX; x++ {
+			screenY := row + x/tb.width - tb.offset
+			if screenY < 0 {
+				continue
 			}
-		}
-
-		if maxX == -1 {
-			screenY++ // No content in this line
-			continue
-		}
-
-		// Render this line with wrapping
-		for x := 0; x <= maxX; x++ {
 			if screenY >= tb.height {
 				break
 			}
 
-			cell := row[x]
-
-			// Calculate screen position considering wrapping
-			screenX := x % tb.width
-			if x > 0 && screenX == 0 {
-				// We've wrapped to a new line
-				screenY++
-				if screenY >= tb.height {
-					break
-				}
-			}
-
-			// Set content on screen
+			cell := tb.content[y][x]
 			if cell.Rune != 0 && cell.Rune != ' ' {
-				screen.SetContent(screenX, screenY, cell.Rune, nil, cell.Style)
+				screen.SetContent(x%tb.width, screenY, cell.Rune, cell.Combining, cell.Style)
 			}
 		}
 
-		screenY++ // Move to next line after processing this original line
+		row += rows // Move to next line after processing this original line
 	}
 }
@@ -232,3 +232,44 @@ func TestTextBuffer_MultipleLines(t *testing.T) {
 		}
 	}
 }
+
+func TestTextBuffer_Scrolling(t *testing.T) {
+	lines := []string{"line0", "a very long line", "line2", "line3"}
+	buffer := NewTextBuffer(lines, 5, 2)
+	for i, line := range lines {
+		buffer.SetString(0, i, line, tcell.StyleDefault)
+	}
+
+	if rows := buffer.TotalRows(); rows != 7 {
+		t.Errorf("Expected 7 total rows, got %d", rows)
+	}
+	if row := buffer.RowOf(2); row != 5 {
+		t.Errorf("Expected line 2 to start at row 5, got %d", row)
+	}
+
+	buffer.SetOffset(100)
+	if offset := buffer.Offset(); offset != 5 {
+		t.Errorf("Expected offset to be clamped to 5, got %d", offset)
+	}
+
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("Failed to initialize simulation screen: %v", err)
+	}
+	defer screen.Fini()
+	screen.SetSize(5, 2)
+
+	buffer.SetOffset(2)
+	buffer.WriteToScreen(screen)
+	screen.Show()
+
+	expected := []string{"y lon", "g lin"}
+	for y, want := range expected {
+		for x, r := range want {
+			got, _, _, _ := screen.GetContent(x, y)
+			if r != ' ' && got != r {
+				t.Errorf("Expected '%c' at (%d,%d), got '%c'", r, x, y, got)
+			}
+		}
+	}
+}
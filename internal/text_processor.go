@@ -1,10 +1,9 @@
 package internal
 
 import (
-	"log/slog"
 	"strings"
-	"time"
 
+	"github.com/Hanaasagi/magonote/internal/logger"
 	"github.com/Hanaasagi/magonote/pkg/textdetection/colordetection"
 )
 
@@ -14,6 +13,12 @@ type TextProcessor interface {
 	Process(text string) (lines []string, styleMatches []Match, err error)
 	// HasStyledContent returns true if the processor detected styled content
 	HasStyledContent() bool
+	// LineStyles returns the styled spans of the processed text, keyed by line
+	LineStyles() map[int][]colordetection.StyleSpan
+	// Hyperlinks returns the matches of the OSC 8 hyperlinks of the text
+	Hyperlinks() []Match
+	// PromptMarks returns the shell integration marks of the text, in order
+	PromptMarks() []PromptMark
 }
 
 // PlainTextProcessor handles plain text without ANSI styling
@@ -35,9 +40,26 @@ func (p *PlainTextProcessor) HasStyledContent() bool {
 	return false
 }
 
+// LineStyles returns no spans for plain text
+func (p *PlainTextProcessor) LineStyles() map[int][]colordetection.StyleSpan {
+	return nil
+}
+
+// Hyperlinks returns no links for plain text
+func (p *PlainTextProcessor) Hyperlinks() []Match {
+	return nil
+}
+
+// PromptMarks returns no marks for plain text
+func (p *PlainTextProcessor) PromptMarks() []PromptMark {
+	return nil
+}
+
 // StyledTextProcessor handles ANSI-styled text using colordetection
 type StyledTextProcessor struct {
-	result *colordetection.ParseResult
+	result     *colordetection.ParseResult
+	hyperlinks []Match
+	marks      []PromptMark
 }
 
 // NewStyledTextProcessor creates a new styled text processor
@@ -47,8 +69,10 @@ func NewStyledTextProcessor() *StyledTextProcessor {
 
 // Process analyzes styled text and extracts both plain text and style-based matches
 func (s *StyledTextProcessor) Process(text string) ([]string, []Match, error) {
-	colorStart := time.Now()
+	colorSpan := logger.StartSpan("colordetection")
 	inputLength := len(text)
+	text, s.marks = extractMarks(text)
+	text, s.hyperlinks = extractHyperlinks(text)
 	result, err := colordetection.ParseText(text)
 	if err != nil {
 		return nil, nil, err
@@ -72,8 +96,7 @@ func (s *StyledTextProcessor) Process(text string) ([]string, []Match, error) {
 		}
 	}
 
-	colorDuration := time.Since(colorStart)
-	slog.Info("colordetection completed", "duration_ms", colorDuration.Milliseconds(), "input_length", inputLength, "matches_count", len(styleMatches))
+	colorSpan.End("input_length", inputLength, "matches_count", len(styleMatches))
 	return lines, styleMatches, nil
 }
 
@@ -82,10 +105,36 @@ func (s *StyledTextProcessor) HasStyledContent() bool {
 	return s.result != nil && s.result.HasStyledContent()
 }
 
+// LineStyles returns the spans with visible styling, keyed by line
+func (s *StyledTextProcessor) LineStyles() map[int][]colordetection.StyleSpan {
+	if s.result == nil {
+		return nil
+	}
+	styles := make(map[int][]colordetection.StyleSpan)
+	for line, spans := range s.result.GetStyledSpansByLine() {
+		for _, span := range spans {
+			if span.HasStyling() {
+				styles[line] = append(styles[line], span)
+			}
+		}
+	}
+	return styles
+}
+
+// Hyperlinks returns the matches of the OSC 8 hyperlinks of the text
+func (s *StyledTextProcessor) Hyperlinks() []Match {
+	return s.hyperlinks
+}
+
+// PromptMarks returns the shell integration marks of the text, in order
+func (s *StyledTextProcessor) PromptMarks() []PromptMark {
+	return s.marks
+}
+
 // CreateTextProcessor automatically selects the appropriate processor based on content
 func CreateTextProcessor(text string) TextProcessor {
 	// Quick check for ANSI escape sequences
-	if strings.ContainsAny(text, "\033\x1b") {
+	if colordetection.HasANSI(text) {
 		return NewStyledTextProcessor()
 	}
 	return NewPlainTextProcessor()
@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// trimPunctuationPatterns lists the patterns whose matches have trailing
+// punctuation trimmed unless configured otherwise
+var trimPunctuationPatterns = map[string]bool{
+	"url":             true,
+	"path":            true,
+	"ipv4":            true,
+	"ipv4_port":       true,
+	"ipv6":            true,
+	"ipv6_port":       true,
+	"semver":          true,
+	"package_version": true,
+	"word":            true,
+	"flag":            true,
+}
+
+// bracketPairs maps closing brackets to their opening counterpart
+var bracketPairs = map[rune]rune{
+	')': '(',
+	']': '[',
+	'}': '{',
+	'>': '<',
+}
+
+// shouldTrimPunctuation reports whether trailing punctuation is trimmed for the pattern
+func (s *State) shouldTrimPunctuation(pattern string) bool {
+	if config, ok := s.PatternConfigs[pattern]; ok && config.TrimPunctuation != nil {
+		return *config.TrimPunctuation
+	}
+	return trimPunctuationPatterns[pattern]
+}
+
+// TrimTrailingPunctuation strips trailing characters that are unlikely to
+// belong to the match, such as a sentence ending `.` or the `)` closing a
+// parenthesis the match was written in. Quotes are left to fixURLQuotes
+// which knows the surrounding text. Closing brackets are kept when they
+// are balanced within the match, e.g. https://en.wikipedia.org/wiki/Go_(game)
+func TrimTrailingPunctuation(text string) string {
+	for len(text) > 0 {
+		last, size := utf8.DecodeLastRuneInString(text)
+		rest := text[:len(text)-size]
+
+		switch {
+		case strings.ContainsRune(",.;:!?", last):
+			// Keep runs like `..` or `::` that are part of the match
+			prev, _ := utf8.DecodeLastRuneInString(rest)
+			if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) && bracketPairs[prev] == 0 {
+				return text
+			}
+		case bracketPairs[last] != 0:
+			if strings.Count(text, string(bracketPairs[last])) >= strings.Count(text, string(last)) {
+				return text
+			}
+		default:
+			return text
+		}
+
+		text = rest
+	}
+	return text
+}
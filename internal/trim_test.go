@@ -0,0 +1,47 @@
+package internal
+
+import "testing"
+
+func TestTrimTrailingPunctuation(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"https://example.com/a).", "https://example.com/a"},
+		{"https://example.com,", "https://example.com"},
+		{"https://en.wikipedia.org/wiki/Go_(game)", "https://en.wikipedia.org/wiki/Go_(game)"},
+		{"https://en.wikipedia.org/wiki/Go_(game)).", "https://en.wikipedia.org/wiki/Go_(game)"},
+		{"/tmp/file.txt.", "/tmp/file.txt"},
+		{"/tmp/dir/..", "/tmp/dir/.."},
+		{"/tmp/[abc]", "/tmp/[abc]"},
+		{"/tmp/abc]", "/tmp/abc"},
+		{"192.168.1.1:", "192.168.1.1"},
+		{"fe80::", "fe80::"},
+		{"/tmp/日本語。", "/tmp/日本語。"},
+		{"/tmp/日本語.", "/tmp/日本語"},
+	}
+
+	for _, tt := range tests {
+		if got := TrimTrailingPunctuation(tt.input); got != tt.want {
+			t.Errorf("TrimTrailingPunctuation(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestTrimPunctuationConfig(t *testing.T) {
+	lines := split("see (https://example.com/docs).")
+
+	results := NewStateFromLines(lines, "abcd", []string{}).Matches(false, 0)
+	if len(results) != 1 || results[0].Text != "https://example.com/docs" {
+		t.Errorf("Expected trimmed URL, got %v", results)
+	}
+
+	disabled := false
+	state := NewStateFromLines(lines, "abcd", []string{},
+		WithPatternConfigs(map[string]PatternConfig{"url": {TrimPunctuation: &disabled}}),
+	)
+	results = state.Matches(false, 0)
+	if len(results) != 1 || results[0].Text != "https://example.com/docs)." {
+		t.Errorf("Expected untrimmed URL, got %v", results)
+	}
+}
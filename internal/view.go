@@ -1,12 +1,17 @@
 package internal
 
 import (
+	"cmp"
+	"fmt"
 	"log/slog"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/Hanaasagi/magonote/internal/logger"
 	"github.com/fatih/color"
 	"github.com/gdamore/tcell/v2"
 	"github.com/mattn/go-runewidth"
@@ -22,8 +27,179 @@ type View struct {
 	matches    []Match
 	colors     ViewColors
 	chosen     []ChosenMatch
+	choices    []int // Number of matches of chosen added by each choice
 	screen     tcell.Screen
 	textBuffer *TextBuffer // Buffer for handling text wrapping
+	review     *ReviewConfig
+	keys       KeyBindings
+	scroll     int  // Number of wrapped rows scrolled past
+	follow     bool // Scroll to keep the selected match visible on next render
+
+	// Modifiers applied to the next chosen match
+	pendingOpen      bool
+	pendingUppercase bool
+	pendingRun       bool
+	pendingPattern   bool // Choose every match of the pattern of the next hint
+
+	// Select by typing the start of the match text instead of its hint
+	prefixSelect bool
+
+	// Hint paging gives hints to a page of matches at a time, so that they
+	// stay one or two characters long
+	hintPages bool
+	page      int
+
+	statusBar bool // Show the status bar on the bottom line
+
+	// Hints of matches on lines with right-to-left text go in the gutter of
+	// the line with BidiGutter, gutter holding their columns by match
+	// position while rendering
+	bidi   string
+	gutter map[[2]int]int
+
+	// Column mode shows a hint per table column instead of per match,
+	// matches holds the column heads while it is active
+	columns      []TableColumn
+	columnMode   bool
+	savedMatches []Match
+	savedSkip    int
+
+	// Hints are reassigned to allMatches without the hidden patterns when
+	// filtering patterns
+	reverse        bool
+	uniqueLevel    int
+	allMatches     []Match
+	hiddenPatterns map[string]bool
+
+	// Searching adds the matches of a typed regex to allMatches, which
+	// searchBase holds without them
+	searching   bool
+	search      string // Regex whose matches are shown
+	searchInput string // Regex typed in the prompt, search while it compiles
+	searchSaved string // Search restored when the typed one is cancelled
+	searchBase  []Match
+
+	// Colors given to UpdateColors, applied by listen
+	colorUpdates chan ViewColors
+
+	terminal *terminal // Terminal to show the view on, the process's when nil
+	recorder func(RecordedEvent)
+	replay   []RecordedEvent // Events replayed on a simulated screen, see WithReplay
+
+	err error // Panic recovered by Present
+}
+
+// viewOptions holds optional settings shared by View and ListView
+type viewOptions struct {
+	review        *ReviewConfig
+	keys          KeyBindings
+	patternColors map[string]PatternColor
+	prefixSelect  bool
+	hintPages     bool
+	statusBar     bool
+	statusColors  [2]Color // Foreground and background of the status bar
+	columnColors  []Color
+	vimKeys       bool
+	bidi          string
+	terminal      *terminal
+	recorder      func(RecordedEvent)
+	replay        []RecordedEvent
+}
+
+// ViewOption defines a functional option for configuring View and ListView
+type ViewOption interface {
+	apply(*viewOptions)
+}
+
+// viewOptionFunc is a function that implements ViewOption interface
+type viewOptionFunc func(*viewOptions)
+
+func (f viewOptionFunc) apply(o *viewOptions) {
+	f(o)
+}
+
+// WithReview asks the user to confirm multi-selections before they are
+// expanded into the given command template
+func WithReview(command string) ViewOption {
+	return viewOptionFunc(func(o *viewOptions) {
+		o.review = &ReviewConfig{Command: command}
+	})
+}
+
+// WithKeyBindings overrides the default key bindings, actions that are
+// not supported by a view are ignored
+func WithKeyBindings(bindings KeyBindings) ViewOption {
+	return viewOptionFunc(func(o *viewOptions) {
+		o.keys = bindings
+	})
+}
+
+// WithPatternColors sets the colors of hints per pattern name, hints of
+// other patterns use the default hint colors
+func WithPatternColors(colors map[string]PatternColor) ViewOption {
+	return viewOptionFunc(func(o *viewOptions) {
+		o.patternColors = colors
+	})
+}
+
+// WithPrefixSelect selects matches by typing the start of their text instead
+// of their hint. A match is chosen as soon as the typed prefix is unambiguous,
+// enter chooses the first remaining match. Only supported by View
+func WithPrefixSelect() ViewOption {
+	return viewOptionFunc(func(o *viewOptions) {
+		o.prefixSelect = true
+	})
+}
+
+// WithHintPages gives hints to a page of matches at a time when there are
+// more matches than hints of two characters, see ActionNextHintPage. Only
+// supported by View
+func WithHintPages() ViewOption {
+	return viewOptionFunc(func(o *viewOptions) {
+		o.hintPages = true
+	})
+}
+
+// WithStatusBar shows the mode, the number of matches, the hidden patterns
+// and the typed keys on the bottom line of the screen. Only supported by View
+func WithStatusBar(foreground, background Color) ViewOption {
+	return viewOptionFunc(func(o *viewOptions) {
+		o.statusBar = true
+		o.statusColors = [2]Color{foreground, background}
+	})
+}
+
+// WithColumnColors colors the hints of the cells of detected tables by
+// column, see ViewColors.WithColumns. Only supported by View
+func WithColumnColors(colors []Color) ViewOption {
+	return viewOptionFunc(func(o *viewOptions) {
+		o.columnColors = colors
+	})
+}
+
+// WithVimKeys navigates the list with j/k/gg/G, chooses a row by typing its
+// number before enter and filters the list after /. Only supported by
+// ListView
+func WithVimKeys() ViewOption {
+	return viewOptionFunc(func(o *viewOptions) {
+		o.vimKeys = true
+	})
+}
+
+// WithTerminal shows the View on the terminal device, such as the terminal
+// of a client of a server, described by the term name. An empty term uses
+// $TERM. Only supported by View
+func WithTerminal(device, term string) ViewOption {
+	return viewOptionFunc(func(o *viewOptions) {
+		o.terminal = &terminal{device: device, term: term}
+	})
+}
+
+// PatternColor overrides the hint colors for a pattern, nil colors fall
+// back to the default hint colors
+type PatternColor struct {
+	Foreground Color
+	Background Color
 }
 
 // ViewColors groups all color-related fields
@@ -36,13 +212,105 @@ type ViewColors struct {
 	background       Color
 	hintForeground   Color
 	hintBackground   Color
+	statusForeground Color
+	statusBackground Color
+	patterns         map[string]PatternColor
+	columns          []Color // Hint foregrounds of the columns of tables in turn
 }
 
 // ChosenMatch represents a match that has been selected by the user
 type ChosenMatch struct {
 	Text           string
+	Visible        string // Text of the match as shown, Text being the target of a hyperlink
+	Pattern        string // Name of the pattern that produced the match
+	X              int    // Column of the match in characters, 0-based
+	Y              int    // Line of the match, 0-based
+	Line           string // Full text of the line containing the match
+	Index          int    // Position of the match among all matches, 0-based
 	Uppercase      bool
 	ShouldOpenFile bool
+	RunAction      bool   // Run the default action of the pattern instead of outputting
+	Path           string // Path of the value of a JSON input, see Match
+}
+
+// newChosenMatch creates a ChosenMatch carrying the context of mat
+func newChosenMatch(state *State, mat Match, index int) ChosenMatch {
+	line := state.Lines[mat.Y]
+	return ChosenMatch{
+		Text:    mat.Value(),
+		Visible: mat.Text,
+		Pattern: mat.Pattern,
+		X:       utf8.RuneCountInString(line[:min(mat.X, len(line))]),
+		Y:       mat.Y,
+		Line:    line,
+		Index:   index,
+		Path:    mat.Path,
+	}
+}
+
+// NewViewColors groups the colors of a View, for UpdateColors. Patterns
+// override the hint colors per pattern name
+func NewViewColors(
+	selectForegroundColor Color,
+	selectBackgroundColor Color,
+	multiForegroundColor Color,
+	multiBackgroundColor Color,
+	foregroundColor Color,
+	backgroundColor Color,
+	hintForegroundColor Color,
+	hintBackgroundColor Color,
+	patterns map[string]PatternColor,
+) ViewColors {
+	return ViewColors{
+		selectForeground: selectForegroundColor,
+		selectBackground: selectBackgroundColor,
+		multiForeground:  multiForegroundColor,
+		multiBackground:  multiBackgroundColor,
+		foreground:       foregroundColor,
+		background:       backgroundColor,
+		hintForeground:   hintForegroundColor,
+		hintBackground:   hintBackgroundColor,
+		patterns:         patterns,
+	}
+}
+
+// WithStatus returns the colors with those of the status bar
+func (c ViewColors) WithStatus(foreground, background Color) ViewColors {
+	c.statusForeground = foreground
+	c.statusBackground = background
+	return c
+}
+
+// WithColumns returns the colors with the hints of the cells of detected
+// tables taking the foregrounds of colors in turn, column after column
+func (c ViewColors) WithColumns(colors []Color) ViewColors {
+	c.columns = colors
+	return c
+}
+
+// hintColorsOf returns the foreground and background of the hint of mat:
+// those configured for its pattern, else the color of its table column,
+// else the hint colors
+func (c ViewColors) hintColorsOf(mat *Match) (Color, Color) {
+	foreground, background := c.hintForeground, c.hintBackground
+	if mat.Column > 0 && len(c.columns) > 0 {
+		foreground = c.columns[(mat.Column-1)%len(c.columns)]
+	}
+	if pc, ok := c.patterns[mat.Pattern]; ok {
+		if pc.Foreground != nil {
+			foreground = pc.Foreground
+		}
+		if pc.Background != nil {
+			background = pc.Background
+		}
+	}
+	return foreground, background
+}
+
+// colorsEvent carries the colors given to UpdateColors to the event loop
+type colorsEvent struct {
+	tcell.EventTime
+	colors ViewColors
 }
 
 // CaptureEvent represents the result of the user interaction
@@ -69,6 +337,7 @@ func NewView(
 	backgroundColor Color,
 	hintForegroundColor Color,
 	hintBackgroundColor Color,
+	opts ...ViewOption,
 ) *View {
 	matches := state.Matches(reverse, uniqueLevel)
 	skip := 0
@@ -76,7 +345,18 @@ func NewView(
 		skip = len(matches) - 1
 	}
 
-	return &View{
+	options := &viewOptions{bidi: BidiGutter}
+	for _, opt := range opts {
+		opt.apply(options)
+	}
+
+	keys := DefaultViewKeyBindings()
+	if options.prefixSelect {
+		// Typed prefixes such as those of paths start with the search key
+		delete(keys, ActionSearch)
+	}
+
+	view := &View{
 		state:      state,
 		skip:       skip,
 		multi:      multi,
@@ -84,18 +364,38 @@ func NewView(
 		position:   position,
 		matches:    matches,
 		textBuffer: nil, // Will be initialized when screen is available
-		colors: ViewColors{
-			selectForeground: selectForegroundColor,
-			selectBackground: selectBackgroundColor,
-			multiForeground:  multiForegroundColor,
-			multiBackground:  multiBackgroundColor,
-			foreground:       foregroundColor,
-			background:       backgroundColor,
-			hintForeground:   hintForegroundColor,
-			hintBackground:   hintBackgroundColor,
-		},
+		colors: NewViewColors(
+			selectForegroundColor,
+			selectBackgroundColor,
+			multiForegroundColor,
+			multiBackgroundColor,
+			foregroundColor,
+			backgroundColor,
+			hintForegroundColor,
+			hintBackgroundColor,
+			options.patternColors,
+		).WithStatus(options.statusColors[0], options.statusColors[1]).WithColumns(options.columnColors),
 		chosen: make([]ChosenMatch, 0),
+		review: options.review,
+		keys:   keys.Override(options.keys),
+		follow: true,
+
+		prefixSelect: options.prefixSelect,
+		hintPages:    options.hintPages,
+		statusBar:    options.statusBar,
+		bidi:         options.bidi,
+
+		reverse:     reverse,
+		uniqueLevel: uniqueLevel,
+		allMatches:  matches,
+
+		colorUpdates: make(chan ViewColors, 1),
+		terminal:     options.terminal,
+		recorder:     options.recorder,
+		replay:       options.replay,
 	}
+	view.showHintPage(view.hintPageOf(skip))
+	return view
 }
 
 // Navigation methods
@@ -103,12 +403,37 @@ func (v *View) Prev() {
 	if v.skip > 0 {
 		v.skip--
 	}
+	v.follow = true
 }
 
 func (v *View) Next() {
 	if v.skip < len(v.matches)-1 {
 		v.skip++
 	}
+	v.follow = true
+}
+
+// ScrollBy scrolls the viewport by the given number of rows, negative values
+// scroll up. The offset is clamped on the next render
+func (v *View) ScrollBy(rows int) {
+	v.scroll = max(0, v.scroll+rows)
+	v.follow = false
+}
+
+// pageSize returns the number of rows visible on the screen
+func (v *View) pageSize() int {
+	_, height := v.textAreaSize()
+	return height
+}
+
+// textAreaSize returns the size of the screen left to the text, without the
+// status bar
+func (v *View) textAreaSize() (width, height int) {
+	width, height = v.screen.Size()
+	if v.statusBar || v.searching {
+		height--
+	}
+	return width, max(1, height)
 }
 
 // makeHintText formats the hint text based on contrast setting
@@ -125,11 +450,11 @@ func (v *View) render(typedHint string) {
 
 	// Initialize text buffer if not already done
 	if v.textBuffer == nil {
-		width, height := v.screen.Size()
+		width, height := v.textAreaSize()
 		v.textBuffer = NewTextBuffer(v.state.Lines, width, height)
 	} else {
 		// Update buffer size if screen size changed
-		width, height := v.screen.Size()
+		width, height := v.textAreaSize()
 		if v.textBuffer.width != width || v.textBuffer.height != height {
 			v.textBuffer = NewTextBuffer(v.state.Lines, width, height)
 		} else {
@@ -149,12 +474,193 @@ func (v *View) render(typedHint string) {
 	// Display all matches with appropriate highlighting
 	v.renderMatches(selected, typedHint)
 
+	// Keep the viewport in range and the selected match visible
+	v.updateScroll(selected)
+
 	// Write buffer content to screen
 	v.textBuffer.WriteToScreen(v.screen)
 
+	v.renderScrollIndicator()
+	v.renderTruncationIndicator()
+	v.renderHintPageIndicator()
+	v.renderStatusBar(typedHint)
+
 	v.screen.Show()
 }
 
+// updateScroll applies the scroll offset to the text buffer, moving the
+// viewport to the selected match if the selection changed
+func (v *View) updateScroll(selected *Match) {
+	if v.follow && selected != nil {
+		top := v.textBuffer.RowOf(selected.Y)
+		bottom := top + v.textBuffer.lineRows(selected.Y)
+		if top < v.scroll {
+			v.scroll = top
+		} else if bottom > v.scroll+v.textBuffer.height {
+			v.scroll = bottom - v.textBuffer.height
+		}
+		v.follow = false
+	}
+
+	v.textBuffer.SetOffset(v.scroll)
+	v.scroll = v.textBuffer.Offset()
+}
+
+// truncationIndicator is shown when the input or the matches were cut short
+const truncationIndicator = "truncated"
+
+// renderScrollIndicator shows the visible row range in the bottom right corner
+// when the content is taller than the screen
+func (v *View) renderScrollIndicator() {
+	total := v.textBuffer.TotalRows()
+	height := v.textBuffer.height
+	if total <= height {
+		return
+	}
+
+	indicator := fmt.Sprintf(" %d-%d/%d ", v.scroll+1, min(v.scroll+height, total), total)
+	style := tcell.StyleDefault.
+		Foreground(colorToTcell(v.colors.hintForeground)).
+		Background(colorToTcell(v.colors.hintBackground)).
+		Reverse(true)
+
+	x := max(0, v.textBuffer.width-len(indicator))
+	for _, r := range indicator {
+		v.screen.SetContent(x, height-1, r, nil, style)
+		x++
+	}
+}
+
+// renderTruncationIndicator warns in the bottom left corner that the input
+// or the matches were cut short
+func (v *View) renderTruncationIndicator() {
+	if !v.state.Truncated {
+		return
+	}
+
+	style := tcell.StyleDefault.
+		Foreground(colorToTcell(v.colors.hintForeground)).
+		Background(colorToTcell(v.colors.hintBackground)).
+		Reverse(true)
+
+	x := 0
+	for _, r := range " " + truncationIndicator + " " {
+		v.screen.SetContent(x, v.textBuffer.height-1, r, nil, style)
+		x++
+	}
+}
+
+// renderHintPageIndicator shows the page of hints in the bottom left corner,
+// after the truncation indicator, when matches are paged
+func (v *View) renderHintPageIndicator() {
+	pages := v.hintPageCount()
+	if pages <= 1 {
+		return
+	}
+
+	style := tcell.StyleDefault.
+		Foreground(colorToTcell(v.colors.hintForeground)).
+		Background(colorToTcell(v.colors.hintBackground)).
+		Reverse(true)
+
+	x := 0
+	if v.state.Truncated {
+		x = len(truncationIndicator) + 3
+	}
+	for _, r := range fmt.Sprintf(" hints %d/%d ", v.page+1, pages) {
+		v.screen.SetContent(x, v.textBuffer.height-1, r, nil, style)
+		x++
+	}
+}
+
+// renderStatusBar shows the mode, the number of matches, the hidden
+// patterns and the typed keys on the line below the text, or the search
+// prompt while searching
+func (v *View) renderStatusBar(typedHint string) {
+	if !v.searching {
+		v.screen.HideCursor()
+	}
+	if !v.statusBar && !v.searching {
+		return
+	}
+
+	text := v.statusText(typedHint)
+	if v.searching {
+		text = "/" + v.searchInput
+		v.screen.ShowCursor(runewidth.StringWidth(text), v.textBuffer.height)
+		if v.searchInput != v.search {
+			text += "  (invalid regex)"
+		}
+	}
+
+	// The search prompt is shown without the status colors when there is no
+	// status bar
+	style := tcell.StyleDefault
+	if v.statusBar {
+		style = style.
+			Foreground(colorToTcell(v.colors.statusForeground)).
+			Background(colorToTcell(v.colors.statusBackground))
+	}
+
+	width, _ := v.screen.Size()
+	y := v.textBuffer.height
+	x := 0
+	for _, r := range text {
+		w := max(1, runewidth.RuneWidth(r))
+		if x+w > width {
+			break
+		}
+		v.screen.SetContent(x, y, r, nil, style)
+		x += w
+	}
+	for ; x < width; x++ {
+		v.screen.SetContent(x, y, ' ', nil, style)
+	}
+}
+
+// statusText returns the text of the status bar
+func (v *View) statusText(typedHint string) string {
+	mode := "single"
+	if v.multi {
+		mode = "multi"
+	}
+	parts := []string{mode}
+
+	switch {
+	case v.columnMode:
+		parts = append(parts, fmt.Sprintf("%d columns", len(v.matches)))
+	case len(v.matches) != len(v.allMatches):
+		parts = append(parts, fmt.Sprintf("%d of %d matches", len(v.matches), len(v.allMatches)))
+	default:
+		parts = append(parts, fmt.Sprintf("%d matches", len(v.matches)))
+	}
+	if v.multi && len(v.chosen) > 0 {
+		parts = append(parts, fmt.Sprintf("%d chosen", len(v.chosen)))
+	}
+
+	var hidden []string
+	for pattern, hide := range v.hiddenPatterns {
+		if hide {
+			hidden = append(hidden, pattern)
+		}
+	}
+	if len(hidden) > 0 {
+		slices.Sort(hidden)
+		parts = append(parts, "hidden: "+strings.Join(hidden, ", "))
+	}
+
+	if v.search != "" {
+		parts = append(parts, "search: /"+v.search+"/")
+	}
+	if v.pendingPattern {
+		parts = append(parts, "select pattern")
+	}
+	if typedHint != "" {
+		parts = append(parts, "typed: "+typedHint)
+	}
+	return " " + strings.Join(parts, " | ")
+}
+
 // renderTextLines renders the original text lines
 func (v *View) renderTextLines() {
 	for y, line := range v.state.Lines {
@@ -164,7 +670,38 @@ func (v *View) renderTextLines() {
 		}
 
 		// Use the text buffer to handle wrapping
-		v.textBuffer.SetString(0, y, cleanLine, tcell.StyleDefault)
+		v.textBuffer.SetString(0, y, expandTabs(cleanLine, v.state.TabWidth), tcell.StyleDefault)
+		v.renderLineStyles(y, cleanLine)
+	}
+	v.renderMaskedSecrets()
+}
+
+// renderMaskedSecrets draws asterisks over the secrets of the text, in the
+// style of the text they hide
+func (v *View) renderMaskedSecrets() {
+	for _, secret := range v.state.maskedSecrets() {
+		line := v.state.Lines[secret.Y]
+		x := displayColumn(line, secret.X, v.state.TabWidth)
+		for range displayColumn(line, secret.X+len(secret.Text), v.state.TabWidth) - x {
+			v.textBuffer.OverlayCell(x, secret.Y, "*", tcell.StyleDefault)
+			x++
+		}
+	}
+}
+
+// renderLineStyles redraws the styled spans of a line with their original
+// style, so that matches are drawn on top of the text as the pane shows it
+func (v *View) renderLineStyles(y int, line string) {
+	for _, span := range v.state.lineStyles[y] {
+		start, end := span.StartCol, min(span.EndCol, len(line))
+		if start >= end {
+			continue
+		}
+		// Spans are drawn from the expanded line, a tab depending on the
+		// column it starts at
+		prefix := expandTabs(line[:start], v.state.TabWidth)
+		text := expandTabs(line[:end], v.state.TabWidth)[len(prefix):]
+		v.textBuffer.SetString(displayWidth(prefix), y, text, spanStyle(span.Style))
 	}
 }
 
@@ -175,6 +712,17 @@ func (v *View) renderMatches(selected *Match, typedHint string) {
 		chosenMap[chosen.Text] = true
 	}
 
+	// Show the cells of the selected column as selected
+	if v.columnMode && v.skip < len(v.columns) {
+		style := tcell.StyleDefault.
+			Foreground(colorToTcell(v.colors.selectForeground)).
+			Background(colorToTcell(v.colors.selectBackground))
+		for _, cell := range v.columns[v.skip].Cells {
+			v.renderSingleMatch(&cell, style, typedHint)
+		}
+	}
+
+	v.gutter = v.gutterHints()
 	for _, mat := range v.matches {
 		style := v.getMatchStyle(&mat, selected, chosenMap)
 		v.renderSingleMatch(&mat, style, typedHint)
@@ -183,7 +731,7 @@ func (v *View) renderMatches(selected *Match, typedHint string) {
 
 // getMatchStyle determines the appropriate style for a match
 func (v *View) getMatchStyle(mat *Match, selected *Match, chosenMap map[string]bool) tcell.Style {
-	if chosenMap[mat.Text] {
+	if chosenMap[mat.Value()] {
 		return tcell.StyleDefault.
 			Foreground(colorToTcell(v.colors.multiForeground)).
 			Background(colorToTcell(v.colors.multiBackground))
@@ -202,35 +750,77 @@ func (v *View) getMatchStyle(mat *Match, selected *Match, chosenMap map[string]b
 
 // renderSingleMatch renders a single match with its hint
 func (v *View) renderSingleMatch(mat *Match, style tcell.Style, typedHint string) {
-	// Calculate display position accounting for wide characters
+	// Calculate display position accounting for wide characters and tabs
 	line := v.state.Lines[mat.Y]
-	prefix := line[:mat.X]
-
-	// Calculate the actual display position by summing up character widths
-	offset := 0
-	for _, r := range prefix {
-		width := runewidth.RuneWidth(r)
-		if width <= 0 {
-			width = 1
+	offset := displayColumn(line, mat.X, v.state.TabWidth)
+
+	// Display the match text, going on with the next lines for matches
+	// spanning wrapped lines or holding line breaks
+	text := v.makeHintText(v.state.maskSecrets(mat.Y, mat.X, mat.Text))
+	currentX, y := offset, mat.Y
+	lineEnd := displayColumn(line, len(line), v.state.TabWidth)
+	wraps := mat.X+len(mat.Text) > len(line) && !strings.Contains(mat.Text, "\n")
+	for cluster, width := range graphemes(text) {
+		if cluster == "\n" {
+			y, currentX = y+1, 0
+			continue
 		}
-		offset += width
-	}
-
-	// Display the match text
-	text := v.makeHintText(mat.Text)
-	currentX := offset
-	for _, r := range text {
-		v.textBuffer.SetCell(currentX, mat.Y, r, style)
-		width := runewidth.RuneWidth(r)
-		if width <= 0 {
-			width = 1
+		if wraps && currentX >= lineEnd && y+1 < len(v.state.Lines) {
+			y++
+			currentX, lineEnd = 0, displayColumn(v.state.Lines[y], len(v.state.Lines[y]), v.state.TabWidth)
+		}
+		if cluster == "\t" && v.state.TabWidth > 0 {
+			// Tabs take the cells up to the next tab stop, as in the text
+			for range v.state.TabWidth - currentX%v.state.TabWidth {
+				v.textBuffer.OverlayCell(currentX, y, " ", style)
+				currentX++
+			}
+			continue
 		}
+		v.textBuffer.OverlayCell(currentX, y, cluster, style)
 		currentX += width
 	}
 
+	if v.prefixSelect {
+		v.renderTypedPrefix(mat, offset, typedHint)
+		return
+	}
+
 	// Display the hint if available
 	if mat.Hint != nil {
-		v.renderHint(mat, offset, text, typedHint)
+		// Hints of multi-line matches go by their first line
+		first, _, _ := strings.Cut(text, "\n")
+		v.renderHint(mat, offset, first, typedHint)
+	}
+}
+
+// renderTypedPrefix highlights the typed prefix of a match in prefix select
+// mode, where the match text itself takes the place of the hint
+func (v *View) renderTypedPrefix(mat *Match, offset int, typed string) {
+	if typed == "" || !hasPrefixFold(mat.Text, typed) {
+		return
+	}
+
+	style := tcell.StyleDefault.
+		Foreground(colorToTcell(v.colors.hintForeground)).
+		Background(colorToTcell(v.colors.hintBackground))
+
+	currentX := offset
+	if v.contrast {
+		currentX++
+	}
+	// The typed prefix ends after as many runes as were typed
+	end, n := len(mat.Text), utf8.RuneCountInString(typed)
+	for i := range mat.Text {
+		if n == 0 {
+			end = i
+			break
+		}
+		n--
+	}
+	for cluster, width := range graphemes(mat.Text[:end]) {
+		v.textBuffer.OverlayCell(currentX, mat.Y, cluster, style)
+		currentX += width
 	}
 }
 
@@ -241,19 +831,19 @@ func (v *View) renderHint(mat *Match, offset int, text string, typedHint string)
 	// Calculate hint position
 	extraPosition := v.calculateHintPosition(text, hint)
 	finalPosition := max(0, offset+extraPosition)
+	if x, ok := v.gutter[[2]int{mat.X, mat.Y}]; ok {
+		finalPosition = x
+	}
 
 	// Display the hint
 	hintText := v.makeHintText(hint)
 	currentX := finalPosition
-	hintRunes := []rune(hintText)
-	for i, r := range hintRunes {
-		hintStyle := v.getHintStyle(hint, typedHint, i)
-		v.textBuffer.SetCell(currentX, mat.Y, r, hintStyle)
-		width := runewidth.RuneWidth(r)
-		if width <= 0 {
-			width = 1
-		}
+	i := 0
+	for cluster, width := range graphemes(hintText) {
+		hintStyle := v.getHintStyle(mat, typedHint, i)
+		v.textBuffer.OverlayCell(currentX, mat.Y, cluster, hintStyle)
 		currentX += width
+		i++
 	}
 }
 
@@ -261,16 +851,7 @@ func (v *View) renderHint(mat *Match, offset int, text string, typedHint string)
 func (v *View) calculateHintPosition(text, hint string) int {
 	switch v.position {
 	case "right":
-		// Calculate actual display width of the text
-		textWidth := 0
-		for _, r := range text {
-			width := runewidth.RuneWidth(r)
-			if width <= 0 {
-				width = 1
-			}
-			textWidth += width
-		}
-		return textWidth - len([]rune(hint))
+		return displayWidth(text) - len([]rune(hint))
 	case "off_left":
 		offset := -len([]rune(hint))
 		if v.contrast {
@@ -278,26 +859,19 @@ func (v *View) calculateHintPosition(text, hint string) int {
 		}
 		return offset
 	case "off_right":
-		// Calculate actual display width of the text
-		textWidth := 0
-		for _, r := range text {
-			width := runewidth.RuneWidth(r)
-			if width <= 0 {
-				width = 1
-			}
-			textWidth += width
-		}
-		return textWidth
+		return displayWidth(text)
 	default: // "left"
 		return 0
 	}
 }
 
 // getHintStyle determines the style for hint characters
-func (v *View) getHintStyle(hint, typedHint string, charIndex int) tcell.Style {
+func (v *View) getHintStyle(mat *Match, typedHint string, charIndex int) tcell.Style {
+	hint := *mat.Hint
+	foreground, background := v.colors.hintColorsOf(mat)
 	baseStyle := tcell.StyleDefault.
-		Foreground(colorToTcell(v.colors.hintForeground)).
-		Background(colorToTcell(v.colors.hintBackground))
+		Foreground(colorToTcell(foreground)).
+		Background(colorToTcell(background))
 
 	// Highlight matching portion of the hint
 	if strings.HasPrefix(hint, typedHint) && charIndex < len([]rune(typedHint)) {
@@ -317,24 +891,33 @@ func (v *View) listen() CaptureEvent {
 
 	typedHint := ""
 	hasUppercase := false
-	longestHint := v.findLongestHint()
 
-	renderStart := time.Now()
+	span := logger.StartSpan("first render")
 	v.render(typedHint)
-	firstRenderDuration := time.Since(renderStart)
-	slog.Info("first render completed", "duration_ms", firstRenderDuration.Milliseconds())
+	span.End()
 
 	for {
 		ev := v.screen.PollEvent()
 
 		switch ev := ev.(type) {
 		case *tcell.EventKey:
-			action := v.handleKeyEvent(ev, &typedHint, &hasUppercase, longestHint)
+			// Hints change when switching to column mode
+			action := v.handleKeyEvent(ev, &typedHint, &hasUppercase, v.findLongestHint())
 			if action != nil {
 				return *action
 			}
-		case *tcell.EventResize:
+		case *tcell.EventMouse:
+			switch ev.Buttons() {
+			case tcell.WheelUp:
+				v.ScrollBy(-3)
+			case tcell.WheelDown:
+				v.ScrollBy(3)
+			}
+		case *tcell.EventResize, *resizeEvent:
 			v.screen.Sync()
+			v.follow = true
+		case *colorsEvent:
+			v.colors = ev.colors
 		case *tcell.EventError:
 			return ExitEvent
 		}
@@ -357,26 +940,373 @@ func (v *View) findLongestHint() string {
 
 // handleKeyEvent processes a key event and returns an action if needed
 func (v *View) handleKeyEvent(ev *tcell.EventKey, typedHint *string, hasUppercase *bool, longestHint string) *CaptureEvent {
+	if v.searching {
+		v.handleSearchKey(ev)
+		return nil
+	}
+	if action, ok := v.keys.Lookup(KeyFromEvent(ev)); ok {
+		return v.handleAction(action, typedHint, hasUppercase)
+	}
+
 	switch ev.Key() {
-	case tcell.KeyEscape, tcell.KeyCtrlC:
-		return v.handleEscapeKey(typedHint, hasUppercase)
-	case tcell.KeyUp, tcell.KeyLeft:
-		v.Prev()
-	case tcell.KeyDown, tcell.KeyRight:
-		v.Next()
 	case tcell.KeyBackspace, tcell.KeyBackspace2:
 		return v.handleBackspace(typedHint, hasUppercase)
-	case tcell.KeyEnter:
-		return v.handleEnter()
 	case tcell.KeyRune:
 		return v.handleRuneKey(ev, typedHint, hasUppercase, longestHint)
 	}
 	return nil
 }
 
+// handleAction performs a bound action
+func (v *View) handleAction(action Action, typedHint *string, hasUppercase *bool) *CaptureEvent {
+	switch action {
+	case ActionQuit:
+		return v.handleEscapeKey(typedHint, hasUppercase)
+	case ActionConfirm:
+		return v.handleEnter()
+	case ActionToggleMulti:
+		return v.handleSpaceKey()
+	case ActionUp:
+		v.Prev()
+	case ActionDown:
+		v.Next()
+	case ActionScrollUp:
+		v.ScrollBy(-v.pageSize() / 2)
+	case ActionScrollDown:
+		v.ScrollBy(v.pageSize() / 2)
+	case ActionPageUp:
+		v.ScrollBy(-v.pageSize())
+	case ActionPageDown:
+		v.ScrollBy(v.pageSize())
+	case ActionOpenEditor:
+		v.pendingOpen = !v.pendingOpen
+	case ActionRunAction:
+		v.pendingRun = !v.pendingRun
+	case ActionUppercaseSelect:
+		v.pendingUppercase = !v.pendingUppercase
+	case ActionToggleColumns:
+		*typedHint = ""
+		*hasUppercase = false
+		v.toggleColumns()
+	case ActionFilterPatterns:
+		*typedHint = ""
+		*hasUppercase = false
+		v.filterPatterns()
+	case ActionNextHintPage:
+		*typedHint = ""
+		*hasUppercase = false
+		v.showHintPage(v.page + 1)
+	case ActionUndoSelection:
+		*typedHint = ""
+		*hasUppercase = false
+		v.undoChoice()
+	case ActionSelectAll:
+		*typedHint = ""
+		*hasUppercase = false
+		v.chooseAll(func(Match) bool { return true })
+	case ActionSelectPattern:
+		v.pendingPattern = !v.pendingPattern && !v.columnMode
+	case ActionSearch:
+		*typedHint = ""
+		*hasUppercase = false
+		v.startSearch()
+	}
+	return nil
+}
+
+// startSearch shows the search prompt, whose regex is matched as it is
+// typed. It isn't available in column mode
+func (v *View) startSearch() {
+	if v.columnMode {
+		return
+	}
+	if v.searchBase == nil {
+		v.searchBase = v.allMatches
+	}
+	v.searching = true
+	v.searchInput = v.search
+	v.searchSaved = v.search
+}
+
+// handleSearchKey edits the search, enter keeps its matches and escape goes
+// back to those shown before
+func (v *View) handleSearchKey(ev *tcell.EventKey) {
+	switch ev.Key() {
+	case tcell.KeyEnter:
+		v.searching = false
+	case tcell.KeyEscape, tcell.KeyCtrlC:
+		v.searching = false
+		v.applySearch(v.searchSaved)
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		_, size := utf8.DecodeLastRuneInString(v.searchInput)
+		v.applySearch(v.searchInput[:len(v.searchInput)-size])
+	case tcell.KeyCtrlU:
+		v.applySearch("")
+	case tcell.KeyRune:
+		v.applySearch(v.searchInput + string(ev.Rune()))
+	}
+}
+
+// applySearch adds the matches of the regex search to those of the
+// patterns and reassigns the hints. The matches stay those of the last valid
+// regex when search doesn't compile
+func (v *View) applySearch(search string) {
+	v.searchInput = search
+
+	matches := v.searchBase
+	if search != "" {
+		re, err := regexp.Compile(search)
+		if err != nil {
+			return
+		}
+		matches = v.state.mergeSearchMatches(v.searchBase, v.state.searchMatches(re))
+	}
+	v.search = search
+	v.allMatches = matches
+	v.applyPatternFilter(v.hiddenPatterns)
+}
+
+// filterPatterns lets the user pick the patterns to show hints for. It is
+// not available in column mode, whose hints are on columns
+func (v *View) filterPatterns() {
+	if v.columnMode || v.screen == nil {
+		return
+	}
+
+	hidden, ok := NewPatternFilter(v.screen, v.allMatches, v.hiddenPatterns, v.colors).Present()
+	if ok {
+		v.applyPatternFilter(hidden)
+	}
+}
+
+// applyPatternFilter shows the matches of the patterns that aren't hidden
+// and reassigns their hints, so that they get the shortest ones
+func (v *View) applyPatternFilter(hidden map[string]bool) {
+	matches := filterPatterns(v.allMatches, hidden)
+	if len(matches) == 0 {
+		return
+	}
+	if err := v.state.AssignHints(matches, v.reverse, v.uniqueLevel); err != nil {
+		slog.Error("assigning hints", "error", err)
+		return
+	}
+
+	v.matches = matches
+	v.hiddenPatterns = hidden
+	v.skip = 0
+	if v.reverse {
+		v.skip = len(matches) - 1
+	}
+	v.follow = true
+	v.showHintPage(v.hintPageOf(v.skip))
+}
+
+// hintPageLength is the longest hint of a page of hints
+const hintPageLength = 2
+
+// hintPageSize returns the number of matches of a page of hints, 0 when
+// matches aren't paged
+func (v *View) hintPageSize() int {
+	if !v.hintPages || v.columnMode || v.prefixSelect {
+		return 0
+	}
+	alphabet, err := ResolveAlphabet(v.state.Alphabet, v.state.CustomAlphabets)
+	if err != nil {
+		return 0
+	}
+	return alphabet.Capacity(hintPageLength)
+}
+
+// hintPageCount returns the number of pages of hints, 1 when matches aren't
+// paged
+func (v *View) hintPageCount() int {
+	size := v.hintPageSize()
+	if size == 0 {
+		return 1
+	}
+	return max(1, (len(v.matches)+size-1)/size)
+}
+
+// hintPageOf returns the page of hints of the match at index
+func (v *View) hintPageOf(index int) int {
+	if size := v.hintPageSize(); size > 0 {
+		return index / size
+	}
+	return 0
+}
+
+// showHintPage gives hints to the matches of page only, wrapping around
+// after the last page, and selects its first match
+func (v *View) showHintPage(page int) {
+	pages := v.hintPageCount()
+	if pages <= 1 {
+		return
+	}
+	size := v.hintPageSize()
+	v.page = page % pages
+
+	for i := range v.matches {
+		v.matches[i].Hint = nil
+	}
+	start := v.page * size
+	end := min(start+size, len(v.matches))
+	if err := v.state.AssignHints(v.matches[start:end], v.reverse, v.uniqueLevel); err != nil {
+		slog.Error("assigning hints", "error", err)
+		return
+	}
+
+	v.skip = start
+	if v.reverse {
+		v.skip = end - 1
+	}
+	v.follow = true
+}
+
+// toggleColumns switches between hints on matches and hints on the columns
+// of the tables in the text. Nothing changes when there is no table
+func (v *View) toggleColumns() {
+	if v.columnMode {
+		v.matches, v.skip = v.savedMatches, v.savedSkip
+		v.savedMatches = nil
+		v.columnMode = false
+		v.follow = true
+		return
+	}
+
+	if v.columns == nil {
+		v.columns = v.state.TableColumns()
+	}
+	if len(v.columns) == 0 {
+		slog.Info("no table columns found")
+		return
+	}
+
+	heads := make([]Match, len(v.columns))
+	for i, column := range v.columns {
+		heads[i] = column.Head()
+	}
+	alphabet, err := ResolveAlphabet(v.state.Alphabet, v.state.CustomAlphabets)
+	if err != nil {
+		slog.Error("resolving alphabet", "error", err)
+		return
+	}
+	v.state.assignHints(heads, alphabet.Hints(len(heads)), false, 0)
+
+	v.savedMatches, v.savedSkip = v.matches, v.skip
+	v.matches, v.skip = heads, 0
+	v.columnMode = true
+	v.follow = true
+}
+
+// appendChosen records a chosen match, in column mode the chosen column
+// head stands for every cell of its column
+func (v *View) appendChosen(chosen ChosenMatch) {
+	if !v.columnMode {
+		v.chosen = append(v.chosen, chosen)
+		v.choices = append(v.choices, 1)
+		return
+	}
+
+	cells := v.columns[chosen.Index].Cells
+	for i, cell := range cells {
+		c := newChosenMatch(v.state, cell, i)
+		c.Uppercase = chosen.Uppercase
+		c.ShouldOpenFile = chosen.ShouldOpenFile
+		c.RunAction = chosen.RunAction
+		v.chosen = append(v.chosen, c)
+	}
+	v.choices = append(v.choices, len(cells))
+}
+
+// chooseAll chooses the matches keep accepts that aren't chosen yet, in
+// document order and as a single choice, switching to multi mode. It isn't
+// available in column mode
+func (v *View) chooseAll(keep func(Match) bool) {
+	if v.columnMode {
+		return
+	}
+
+	type position struct {
+		x, y int
+		text string
+	}
+	chosen := make(map[position]bool, len(v.chosen))
+	for _, c := range v.chosen {
+		chosen[position{c.X, c.Y, c.Text}] = true
+	}
+
+	order := make([]int, 0, len(v.matches))
+	for i, mat := range v.matches {
+		if keep(mat) {
+			order = append(order, i)
+		}
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Or(
+			cmp.Compare(v.matches[a].Y, v.matches[b].Y),
+			cmp.Compare(v.matches[a].X, v.matches[b].X),
+		)
+	})
+
+	n := 0
+	for _, i := range order {
+		c := newChosenMatch(v.state, v.matches[i], i)
+		if chosen[position{c.X, c.Y, c.Text}] {
+			continue
+		}
+		c.Uppercase = v.pendingUppercase
+		c.ShouldOpenFile = v.pendingOpen
+		c.RunAction = v.pendingRun
+		v.chosen = append(v.chosen, c)
+		n++
+	}
+	if n > 0 {
+		v.choices = append(v.choices, n)
+	}
+
+	v.pendingOpen = false
+	v.pendingRun = false
+	v.pendingUppercase = false
+	v.multi = true
+}
+
+// choosePattern chooses every match of pattern, see chooseAll
+func (v *View) choosePattern(pattern string) {
+	v.pendingPattern = false
+	v.chooseAll(func(mat Match) bool { return mat.Pattern == pattern })
+}
+
+// undoChoice removes the matches of the last choice from the chosen ones,
+// a whole column in column mode
+func (v *View) undoChoice() {
+	if len(v.choices) == 0 {
+		return
+	}
+	last := v.choices[len(v.choices)-1]
+	v.choices = v.choices[:len(v.choices)-1]
+	v.chosen = v.chosen[:len(v.chosen)-last]
+}
+
 // handleEscapeKey handles escape key press
 func (v *View) handleEscapeKey(typedHint *string, hasUppercase *bool) *CaptureEvent {
-	if v.multi && *typedHint != "" {
+	if v.pendingOpen || v.pendingUppercase || v.pendingRun || v.pendingPattern {
+		v.pendingOpen = false
+		v.pendingUppercase = false
+		v.pendingRun = false
+		v.pendingPattern = false
+		return nil
+	}
+	if v.columnMode {
+		*typedHint = ""
+		*hasUppercase = false
+		v.toggleColumns()
+		return nil
+	}
+	if v.search != "" && *typedHint == "" {
+		v.applySearch("")
+		return nil
+	}
+	if (v.multi || v.prefixSelect) && *typedHint != "" {
 		*typedHint = ""
 		*hasUppercase = false
 		return nil
@@ -388,20 +1318,31 @@ func (v *View) handleEscapeKey(typedHint *string, hasUppercase *bool) *CaptureEv
 // handleBackspace handles backspace key press
 func (v *View) handleBackspace(typedHint *string, hasUppercase *bool) *CaptureEvent {
 	if len(*typedHint) > 0 {
-		*typedHint = (*typedHint)[:len(*typedHint)-1]
+		_, size := utf8.DecodeLastRuneInString(*typedHint)
+		*typedHint = (*typedHint)[:len(*typedHint)-size]
 		*hasUppercase = false
+		return nil
 	}
+	// Nothing typed, take back the last choice of multi mode
+	v.undoChoice()
 	return nil
 }
 
 // handleEnter handles enter key press
 func (v *View) handleEnter() *CaptureEvent {
+	if v.pendingPattern && v.skip < len(v.matches) {
+		v.choosePattern(v.matches[v.skip].Pattern)
+		return nil
+	}
 	if v.skip < len(v.matches) {
-		v.chosen = append(v.chosen, ChosenMatch{
-			Text:           v.matches[v.skip].Text,
-			Uppercase:      false,
-			ShouldOpenFile: false,
-		})
+		chosen := newChosenMatch(v.state, v.matches[v.skip], v.skip)
+		chosen.Uppercase = v.pendingUppercase
+		chosen.ShouldOpenFile = v.pendingOpen
+		chosen.RunAction = v.pendingRun
+		v.appendChosen(chosen)
+		v.pendingOpen = false
+		v.pendingRun = false
+		v.pendingUppercase = false
 
 		if !v.multi {
 			action := HintEvent
@@ -415,8 +1356,13 @@ func (v *View) handleEnter() *CaptureEvent {
 func (v *View) handleRuneKey(ev *tcell.EventKey, typedHint *string, hasUppercase *bool, longestHint string) *CaptureEvent {
 	ch := string(ev.Rune())
 
+	// Space is never part of a hint
 	if ch == " " {
-		return v.handleSpaceKey()
+		return nil
+	}
+
+	if v.prefixSelect {
+		return v.handlePrefixRune(ch, typedHint)
 	}
 
 	lowerCh := strings.ToLower(ch)
@@ -426,14 +1372,24 @@ func (v *View) handleRuneKey(ev *tcell.EventKey, typedHint *string, hasUppercase
 	*typedHint += lowerCh
 
 	// Check for hint match
-	for _, mat := range v.matches {
+	for i, mat := range v.matches {
 		if mat.Hint != nil && *mat.Hint == *typedHint {
-			v.chosen = append(v.chosen, ChosenMatch{
-				Text:      mat.Text,
-				Uppercase: *hasUppercase,
-				// ShouldOpenFile: *hasUppercase && isLikelyFilePath(mat.Text),
-				ShouldOpenFile: *hasUppercase,
-			})
+			if v.pendingPattern {
+				v.choosePattern(mat.Pattern)
+				*typedHint = ""
+				*hasUppercase = false
+				return nil
+			}
+
+			chosen := newChosenMatch(v.state, mat, i)
+			chosen.Uppercase = *hasUppercase || v.pendingUppercase
+			// chosen.ShouldOpenFile = *hasUppercase && isLikelyFilePath(mat.Text)
+			chosen.ShouldOpenFile = *hasUppercase || v.pendingOpen
+			chosen.RunAction = v.pendingRun
+			v.appendChosen(chosen)
+			v.pendingOpen = false
+			v.pendingRun = false
+			v.pendingUppercase = false
 
 			if v.multi {
 				*typedHint = ""
@@ -455,6 +1411,55 @@ func (v *View) handleRuneKey(ev *tcell.EventKey, typedHint *string, hasUppercase
 	return nil
 }
 
+// handlePrefixRune extends the typed prefix in prefix select mode. Keys that
+// match nothing are ignored, and the match is chosen once all remaining
+// matches share the same text
+func (v *View) handlePrefixRune(ch string, typed *string) *CaptureEvent {
+	candidates := v.prefixCandidates(*typed + ch)
+	if len(candidates) == 0 {
+		return nil
+	}
+	*typed += ch
+
+	// Select the first candidate so enter can choose it
+	v.skip = candidates[0]
+	v.follow = true
+
+	text := v.matches[candidates[0]].Text
+	for _, i := range candidates[1:] {
+		if v.matches[i].Text != text {
+			return nil
+		}
+	}
+
+	*typed = ""
+	return v.handleEnter()
+}
+
+// prefixCandidates returns the indices of the matches starting with typed,
+// ignoring case
+func (v *View) prefixCandidates(typed string) []int {
+	var candidates []int
+	for i, mat := range v.matches {
+		if hasPrefixFold(mat.Text, typed) {
+			candidates = append(candidates, i)
+		}
+	}
+	return candidates
+}
+
+// hasPrefixFold reports whether s begins with prefix, ignoring case
+func hasPrefixFold(s, prefix string) bool {
+	n := utf8.RuneCountInString(prefix)
+	for i := range s {
+		if n == 0 {
+			return strings.EqualFold(s[:i], prefix)
+		}
+		n--
+	}
+	return n == 0 && strings.EqualFold(s, prefix)
+}
+
 // handleSpaceKey handles space key press
 func (v *View) handleSpaceKey() *CaptureEvent {
 	if v.multi {
@@ -465,14 +1470,17 @@ func (v *View) handleSpaceKey() *CaptureEvent {
 	return nil
 }
 
-// Present displays the UI and returns the chosen matches
-func (v *View) Present() []ChosenMatch {
+// Present displays the UI and returns the chosen matches. A panic of the UI
+// is recovered once the screen is restored and reported by Err
+func (v *View) Present() (chosen []ChosenMatch) {
+	defer recoverView("view", &v.err)
+
 	// fast path
 	if len(v.matches) == 0 {
 		return []ChosenMatch{}
 	}
 
-	screen, err := tcell.NewScreen()
+	screen, err := v.openScreen()
 	if err != nil {
 		slog.Error("Failed to create tcell screen", "error", err)
 		return []ChosenMatch{}
@@ -485,6 +1493,7 @@ func (v *View) Present() []ChosenMatch {
 
 	v.screen = screen
 	defer screen.Fini()
+	defer v.forwardColorUpdates(screen)()
 
 	screen.SetStyle(tcell.StyleDefault)
 	screen.EnableMouse()
@@ -495,9 +1504,58 @@ func (v *View) Present() []ChosenMatch {
 		return []ChosenMatch{}
 	}
 
+	if v.review != nil && v.multi && len(v.chosen) > 0 {
+		review := NewReview(screen, v.chosen, v.review.Command, v.colors)
+		return review.Present()
+	}
+
 	return v.chosen
 }
 
+// UpdateColors replaces the colors of the View, redrawing it if it is being
+// presented. Unlike the other methods it can be called from any goroutine,
+// such as one watching the config file
+func (v *View) UpdateColors(colors ViewColors) {
+	for {
+		select {
+		case v.colorUpdates <- colors:
+			return
+		default:
+		}
+		// Replace the update not applied yet
+		select {
+		case <-v.colorUpdates:
+		default:
+		}
+	}
+}
+
+// forwardColorUpdates posts the colors given to UpdateColors to screen, for
+// listen to apply them, until the returned stop is called
+func (v *View) forwardColorUpdates(screen tcell.Screen) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case colors := <-v.colorUpdates:
+				ev := &colorsEvent{colors: colors}
+				ev.SetEventNow()
+				if err := screen.PostEvent(ev); err != nil {
+					slog.Debug("Dropping color update", "error", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// Err returns the panic recovered by Present, if any
+func (v *View) Err() error {
+	return v.err
+}
+
 // Pre-compiled pattern for RGB color matching
 var rgbColorPattern = regexp.MustCompile(`\x1b\[38;2;(\d+);(\d+);(\d+)m`)
 
@@ -507,6 +1565,9 @@ func colorToTcell(c Color) tcell.Color {
 		if cw.isRGB {
 			return tcell.NewRGBColor(int32(cw.r), int32(cw.g), int32(cw.b))
 		}
+		if cw.is256 {
+			return tcell.PaletteColor(int(cw.index))
+		}
 
 		// Map color attributes to tcell colors
 		colorMap := map[color.Attribute]tcell.Color{
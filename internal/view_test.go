@@ -1,8 +1,12 @@
 package internal
 
 import (
+	"fmt"
+	"slices"
 	"strings"
 	"testing"
+
+	"github.com/gdamore/tcell/v2"
 )
 
 func split(output string) []string {
@@ -45,3 +49,643 @@ func TestHintText(t *testing.T) {
 		t.Errorf("Expected '[a]', got '%s'", result)
 	}
 }
+
+func TestViewScrollFollowsSelection(t *testing.T) {
+	lines := make([]string, 0, 20)
+	for i := 0; i < 19; i++ {
+		lines = append(lines, "lorem ipsum")
+	}
+	lines = append(lines, "lorem 127.0.0.1 lorem")
+	state := NewStateFromLines(lines, "abcd", []string{})
+
+	view := NewView(
+		state,
+		false,               // multi
+		false,               // reverse
+		0,                   // uniqueLevel
+		false,               // contrast
+		"",                  // position
+		GetColor("default"), // selectForegroundColor
+		GetColor("default"), // selectBackgroundColor
+		GetColor("default"), // multiForegroundColor
+		GetColor("default"), // multiBackgroundColor
+		GetColor("default"), // foregroundColor
+		GetColor("default"), // backgroundColor
+		GetColor("default"), // hintForegroundColor
+		GetColor("default"), // hintBackgroundColor
+	)
+
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("Failed to initialize simulation screen: %v", err)
+	}
+	defer screen.Fini()
+	screen.SetSize(40, 5)
+	view.screen = screen
+
+	view.render("")
+	if view.scroll != 15 {
+		t.Errorf("Expected viewport to follow the match at the bottom, got scroll %d", view.scroll)
+	}
+
+	view.ScrollBy(-10)
+	view.render("")
+	if view.scroll != 5 {
+		t.Errorf("Expected scroll 5, got %d", view.scroll)
+	}
+
+	view.ScrollBy(-100)
+	view.render("")
+	if view.scroll != 0 {
+		t.Errorf("Expected scroll to be clamped to 0, got %d", view.scroll)
+	}
+}
+
+func TestViewColorsPerPattern(t *testing.T) {
+	colors := ViewColors{
+		hintForeground: GetColor("yellow"),
+		hintBackground: GetColor("black"),
+		patterns: map[string]PatternColor{
+			"url":  {Foreground: GetColor("blue")},
+			"grid": {Background: GetColor("red")},
+		},
+	}.WithColumns([]Color{GetColor("cyan")})
+
+	tests := []struct {
+		mat        Match
+		foreground string
+		background string
+	}{
+		{Match{Pattern: "url"}, "blue", "black"},
+		{Match{Pattern: "path"}, "yellow", "black"},
+		{Match{Pattern: "grid", Column: 1}, "cyan", "red"},
+	}
+	for _, tt := range tests {
+		fg, bg := colors.hintColorsOf(&tt.mat)
+		if fg.GetFgColor() != GetColor(tt.foreground).GetFgColor() || bg.GetFgColor() != GetColor(tt.background).GetFgColor() {
+			t.Errorf("Expected %s on %s hints for %s, got %v on %v", tt.foreground, tt.background, tt.mat.Pattern, fg, bg)
+		}
+	}
+}
+
+func TestViewColorsPerColumn(t *testing.T) {
+	colors := ViewColors{hintForeground: GetColor("yellow")}.
+		WithColumns([]Color{GetColor("red"), GetColor("cyan")})
+
+	tests := []struct {
+		column   int
+		expected string
+	}{
+		{0, "yellow"},
+		{1, "red"},
+		{2, "cyan"},
+		{3, "red"},
+	}
+	for _, tt := range tests {
+		fg, _ := colors.hintColorsOf(&Match{Pattern: "grid", Column: tt.column})
+		if fg.GetFgColor() != GetColor(tt.expected).GetFgColor() {
+			t.Errorf("Expected %s hints for column %d, got %v", tt.expected, tt.column, fg)
+		}
+	}
+
+	lines := []string{
+		"NAME   STATUS   AGE",
+		"web    Running  3d",
+		"db     Pending  5m",
+		"cache  Running  1h",
+	}
+	state := NewStateFromLines(lines, "abcd", []string{}, WithTableDetection(minLines, minColumns, confidenceThreshold))
+	columns := map[int]bool{}
+	for _, mat := range state.Matches(false, 0) {
+		if mat.Pattern == "grid" {
+			columns[mat.Column] = true
+		}
+	}
+	if len(columns) != 3 || columns[0] {
+		t.Errorf("Expected the cells in columns 1 to 3, got %v", columns)
+	}
+}
+
+func TestViewUpdateColors(t *testing.T) {
+	state := NewStateFromLines([]string{"lorem 127.0.0.1"}, "abcd", []string{})
+	view := NewView(
+		state,
+		false,               // multi
+		false,               // reverse
+		0,                   // uniqueLevel
+		false,               // contrast
+		"",                  // position
+		GetColor("default"), // selectForegroundColor
+		GetColor("default"), // selectBackgroundColor
+		GetColor("default"), // multiForegroundColor
+		GetColor("default"), // multiBackgroundColor
+		GetColor("default"), // foregroundColor
+		GetColor("default"), // backgroundColor
+		GetColor("default"), // hintForegroundColor
+		GetColor("default"), // hintBackgroundColor
+	)
+
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("Failed to initialize simulation screen: %v", err)
+	}
+	defer screen.Fini()
+
+	stale := NewViewColors(nil, nil, nil, nil, GetColor("blue"), nil, nil, nil, nil)
+	fresh := NewViewColors(nil, nil, nil, nil, GetColor("red"), nil, nil, nil, nil)
+	view.UpdateColors(stale)
+	view.UpdateColors(fresh)
+	defer view.forwardColorUpdates(screen)()
+
+	ev, ok := screen.PollEvent().(*colorsEvent)
+	if !ok {
+		t.Fatalf("Expected a colors event, got %T", ev)
+	}
+	if ev.colors.foreground.GetFgColor() != GetColor("red").GetFgColor() {
+		t.Errorf("Expected the latest colors, got %+v", ev.colors)
+	}
+}
+
+func TestNewChosenMatch(t *testing.T) {
+	lines := split("日本 /tmp/file.txt")
+	state := NewStateFromLines(lines, "abcd", []string{})
+	matches := state.Matches(false, 0)
+	if len(matches) != 1 {
+		t.Fatalf("Expected 1 match, got %d", len(matches))
+	}
+
+	chosen := newChosenMatch(state, matches[0], 0)
+	if chosen.X != 3 || chosen.Y != 0 {
+		t.Errorf("Expected match at (3, 0), got (%d, %d)", chosen.X, chosen.Y)
+	}
+	if chosen.Line != lines[0] || chosen.Pattern != "path" {
+		t.Errorf("Expected line '%s' from pattern 'path', got '%s' from '%s'", lines[0], chosen.Line, chosen.Pattern)
+	}
+}
+
+func TestChosenMatchVisible(t *testing.T) {
+	// Frontends check the selection is still on screen with the visible
+	// text, which the pane shows whatever the output is
+	tests := []struct {
+		name    string
+		text    string
+		pattern string
+		value   string
+	}{
+		{"hyperlink", "see \x1b]8;;https://example.com/docs\x1b\\the docs\x1b]8;;\x1b\\ now", "hyperlink", "https://example.com/docs"},
+		{"json escapes", `{"name": "caf\u00e9 \"au lait\""}`, "json", `café "au lait"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := NewState(tt.text, "abcd", []string{})
+			matches := state.Matches(false, 0)
+			i := slices.IndexFunc(matches, func(m Match) bool { return m.Pattern == tt.pattern })
+			if i < 0 {
+				t.Fatalf("Expected a %s match, got %+v", tt.pattern, matches)
+			}
+
+			chosen := newChosenMatch(state, matches[i], i)
+			if chosen.Text != tt.value {
+				t.Errorf("Expected output %q, got %q", tt.value, chosen.Text)
+			}
+			if chosen.Visible == chosen.Text || !strings.Contains(state.Lines[chosen.Y], chosen.Visible) {
+				t.Errorf("Expected visible text %q in line %q, apart from the output", chosen.Visible, state.Lines[chosen.Y])
+			}
+		})
+	}
+}
+
+func TestViewPrefixSelect(t *testing.T) {
+	lines := split("10.0.0.1 10.0.0.2 /tmp/a.txt /tmp/a.txt")
+
+	tests := []struct {
+		name string
+		keys string
+		want string
+	}{
+		{name: "unambiguous prefix", keys: "/", want: "/tmp/a.txt"},
+		{name: "narrowed prefix", keys: "10.0.0.2", want: "10.0.0.2"},
+		{name: "keys matching nothing are ignored", keys: "1x0.0.0.1", want: "10.0.0.1"},
+		{name: "ambiguous prefix", keys: "10.", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := NewStateFromLines(lines, "abcd", []string{})
+			view := NewView(
+				state, false, false, 0, false, "",
+				GetColor("default"), GetColor("default"), GetColor("default"), GetColor("default"),
+				GetColor("default"), GetColor("default"), GetColor("default"), GetColor("default"),
+				WithPrefixSelect(),
+			)
+
+			typed := ""
+			hasUppercase := false
+			var event *CaptureEvent
+			for _, r := range tt.keys {
+				ev := tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone)
+				if event = view.handleKeyEvent(ev, &typed, &hasUppercase, view.findLongestHint()); event != nil {
+					break
+				}
+			}
+
+			got := ""
+			if len(view.chosen) > 0 {
+				got = view.chosen[0].Text
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q to be chosen, got %q", tt.want, got)
+			}
+			if (event != nil) != (tt.want != "") {
+				t.Errorf("Expected the view to exit only on a choice, got %v", event)
+			}
+		})
+	}
+}
+
+func TestViewColumnMode(t *testing.T) {
+	lines := []string{
+		"  PID TTY          TIME CMD",
+		" 1234 pts/0    00:00:00 bash",
+		" 5678 pts/0    00:00:01 vim",
+	}
+	state := NewStateFromLines(lines, "abcd", []string{})
+	view := NewView(
+		state, false, false, 0, false, "",
+		GetColor("default"), GetColor("default"), GetColor("default"), GetColor("default"),
+		GetColor("default"), GetColor("default"), GetColor("default"), GetColor("default"),
+	)
+	matches := view.matches
+
+	typed := ""
+	hasUppercase := false
+	view.handleAction(ActionToggleColumns, &typed, &hasUppercase)
+	if !view.columnMode || len(view.matches) != 4 {
+		t.Fatalf("Expected a hint per column, got %+v", view.matches)
+	}
+	if view.matches[0].Text != "PID" || *view.matches[0].Hint != "a" {
+		t.Errorf("Expected hint 'a' on the PID header, got %+v", view.matches[0])
+	}
+
+	// Escape leaves column mode first
+	view.handleAction(ActionQuit, &typed, &hasUppercase)
+	if view.columnMode || len(view.matches) != len(matches) {
+		t.Errorf("Expected the matches back after leaving column mode, got %+v", view.matches)
+	}
+
+	view.handleAction(ActionToggleColumns, &typed, &hasUppercase)
+	ev := tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone)
+	if event := view.handleKeyEvent(ev, &typed, &hasUppercase, view.findLongestHint()); event == nil || *event != HintEvent {
+		t.Fatalf("Expected the column to be chosen, got %v", event)
+	}
+	if len(view.chosen) != 2 || view.chosen[0].Text != "1234" || view.chosen[1].Text != "5678" {
+		t.Errorf("Expected every cell of the PID column, got %+v", view.chosen)
+	}
+}
+
+func TestViewHintPages(t *testing.T) {
+	var ips []string
+	for i := range 40 {
+		ips = append(ips, fmt.Sprintf("10.0.0.%d", i))
+	}
+	state := NewStateFromLines([]string{strings.Join(ips, " ")}, "abcd", []string{})
+	view := NewView(
+		state, false, false, 0, false, "",
+		GetColor("default"), GetColor("default"), GetColor("default"), GetColor("default"),
+		GetColor("default"), GetColor("default"), GetColor("default"), GetColor("default"),
+		WithHintPages(),
+	)
+
+	// hinted returns the indexes of the matches with a hint, checking that
+	// hints are at most two characters long
+	hinted := func() []int {
+		var indexes []int
+		for i, match := range view.matches {
+			if match.Hint == nil {
+				continue
+			}
+			if len(*match.Hint) > 2 {
+				t.Errorf("Expected hints of at most 2 characters, got %q", *match.Hint)
+			}
+			indexes = append(indexes, i)
+		}
+		return indexes
+	}
+
+	if pages := view.hintPageCount(); pages != 3 {
+		t.Fatalf("Expected 3 pages of 16 hints, got %d", pages)
+	}
+	if indexes := hinted(); len(indexes) != 16 || indexes[0] != 0 {
+		t.Errorf("Expected hints on the first 16 matches, got %v", indexes)
+	}
+
+	typed := ""
+	hasUppercase := false
+	view.handleAction(ActionNextHintPage, &typed, &hasUppercase)
+	view.handleAction(ActionNextHintPage, &typed, &hasUppercase)
+	if indexes := hinted(); len(indexes) != 8 || indexes[0] != 32 {
+		t.Errorf("Expected hints on the last 8 matches, got %v", indexes)
+	}
+	if view.skip != 32 {
+		t.Errorf("Expected the first match of the page to be selected, got %d", view.skip)
+	}
+
+	ev := tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone)
+	if event := view.handleKeyEvent(ev, &typed, &hasUppercase, view.findLongestHint()); event == nil || *event != HintEvent {
+		t.Fatalf("Expected a match of the page to be chosen, got %v", event)
+	}
+	if len(view.chosen) != 1 || view.chosen[0].Index < 32 {
+		t.Errorf("Expected a match of the last page, got %+v", view.chosen)
+	}
+
+	view.handleAction(ActionNextHintPage, &typed, &hasUppercase)
+	if indexes := hinted(); len(indexes) != 16 || indexes[0] != 0 {
+		t.Errorf("Expected the first page after the last one, got %v", indexes)
+	}
+}
+
+func TestViewStatusBar(t *testing.T) {
+	state := NewStateFromLines([]string{"see https://example.com at /tmp/a.txt", "lorem", "ipsum"}, "abcd", []string{})
+	view := NewView(
+		state, true, false, 0, false, "",
+		GetColor("default"), GetColor("default"), GetColor("default"), GetColor("default"),
+		GetColor("default"), GetColor("default"), GetColor("default"), GetColor("default"),
+		WithStatusBar(GetColor("black"), GetColor("white")),
+	)
+
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("Failed to initialize simulation screen: %v", err)
+	}
+	defer screen.Fini()
+	screen.SetSize(60, 3)
+	view.screen = screen
+
+	statusLine := func() string {
+		var b strings.Builder
+		for x := range 60 {
+			r, _, _, _ := screen.GetContent(x, 2)
+			b.WriteRune(r)
+		}
+		return strings.TrimRight(b.String(), " ")
+	}
+
+	view.render("")
+	if got, want := statusLine(), " multi | 2 matches"; got != want {
+		t.Errorf("Expected status %q, got %q", want, got)
+	}
+	if view.textBuffer.height != 2 {
+		t.Errorf("Expected the status bar to take a line from the text, got height %d", view.textBuffer.height)
+	}
+
+	view.applyPatternFilter(map[string]bool{"path": true})
+	view.render("b")
+	if got, want := statusLine(), " multi | 1 of 2 matches | hidden: path | typed: b"; got != want {
+		t.Errorf("Expected status %q, got %q", want, got)
+	}
+}
+
+func TestViewSearch(t *testing.T) {
+	state := NewStateFromLines([]string{"job-1234 failed, see /tmp/a.txt"}, "abcd", []string{})
+	view := NewView(
+		state, false, false, 0, false, "",
+		GetColor("default"), GetColor("default"), GetColor("default"), GetColor("default"),
+		GetColor("default"), GetColor("default"), GetColor("default"), GetColor("default"),
+	)
+
+	typed := ""
+	hasUppercase := false
+	press := func(keys ...tcell.Key) {
+		for _, key := range keys {
+			view.handleKeyEvent(tcell.NewEventKey(key, 0, tcell.ModNone), &typed, &hasUppercase, view.findLongestHint())
+		}
+	}
+	typeText := func(text string) {
+		for _, r := range text {
+			view.handleKeyEvent(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone), &typed, &hasUppercase, view.findLongestHint())
+		}
+	}
+	texts := func() []string {
+		var texts []string
+		for _, mat := range view.matches {
+			if mat.Hint == nil {
+				t.Errorf("Expected %q to have a hint", mat.Text)
+			}
+			texts = append(texts, mat.Pattern+":"+mat.Text)
+		}
+		return texts
+	}
+
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("Failed to initialize simulation screen: %v", err)
+	}
+	defer screen.Fini()
+	screen.SetSize(40, 2)
+	view.screen = screen
+
+	typeText(`/job-\d+`)
+	if !view.searching {
+		t.Fatal("Expected / to start a search")
+	}
+	view.render(typed)
+	if r, _, _, _ := screen.GetContent(0, 1); r != '/' {
+		t.Errorf("Expected the search prompt on the bottom line, got %q", r)
+	}
+	if got, want := texts(), []string{"search:job-1234", "path:/tmp/a.txt"}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// The last valid regex stays, and enter keeps it
+	typeText("(")
+	if view.search != `job-\d+` || len(view.matches) != 2 {
+		t.Errorf("Expected the matches of the last valid regex, got %v", view.matches)
+	}
+	press(tcell.KeyEnter)
+	if view.searching || view.search != `job-\d+` {
+		t.Errorf("Expected the search to be kept, got %q", view.search)
+	}
+
+	// Search matches replace the matches they overlap
+	typeText("/")
+	press(tcell.KeyCtrlU)
+	typeText(`a\.txt`)
+	if got, want := texts(), []string{"search:a.txt"}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// Escape goes back to the search before
+	press(tcell.KeyEscape)
+	if got, want := texts(), []string{"search:job-1234", "path:/tmp/a.txt"}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// And outside of the prompt, drops it
+	press(tcell.KeyEscape)
+	if got, want := texts(), []string{"path:/tmp/a.txt"}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestViewUndoChoice(t *testing.T) {
+	lines := []string{
+		"  PID TTY          TIME CMD",
+		" 1234 pts/0    00:00:00 bash",
+		" 5678 pts/0    00:00:01 vim",
+	}
+	state := NewStateFromLines(lines, "abcd", []string{})
+	view := NewView(
+		state, true, false, 0, false, "",
+		GetColor("default"), GetColor("default"), GetColor("default"), GetColor("default"),
+		GetColor("default"), GetColor("default"), GetColor("default"), GetColor("default"),
+	)
+
+	typed := ""
+	hasUppercase := false
+	press := func(key tcell.Key, r rune) {
+		view.handleKeyEvent(tcell.NewEventKey(key, r, tcell.ModNone), &typed, &hasUppercase, view.findLongestHint())
+	}
+
+	press(tcell.KeyEnter, 0)
+	view.handleAction(ActionToggleColumns, &typed, &hasUppercase)
+	press(tcell.KeyRune, 'a')
+	if len(view.chosen) != 3 {
+		t.Fatalf("Expected a match and the cells of a column, got %+v", view.chosen)
+	}
+
+	// The column is a single choice
+	press(tcell.KeyBackspace2, 0)
+	if len(view.chosen) != 1 {
+		t.Errorf("Expected the column to be taken back, got %+v", view.chosen)
+	}
+
+	// Backspace erases the typed hint before taking back choices
+	typed = "b"
+	press(tcell.KeyBackspace2, 0)
+	if typed != "" || len(view.chosen) != 1 {
+		t.Errorf("Expected the typed hint to be erased, got %q and %+v", typed, view.chosen)
+	}
+
+	view.handleAction(ActionUndoSelection, &typed, &hasUppercase)
+	view.handleAction(ActionUndoSelection, &typed, &hasUppercase)
+	if len(view.chosen) != 0 {
+		t.Errorf("Expected no chosen match, got %+v", view.chosen)
+	}
+}
+
+func TestViewChooseAll(t *testing.T) {
+	lines := []string{
+		"10.0.0.1 https://a.example.com",
+		"https://b.example.com 10.0.0.2",
+	}
+
+	tests := []struct {
+		name     string
+		actions  []Action
+		hintOf   string // Text of the match whose hint is typed after the actions
+		expected []string
+	}{
+		{
+			name:     "all",
+			actions:  []Action{ActionSelectAll},
+			expected: []string{"10.0.0.1", "https://a.example.com", "https://b.example.com", "10.0.0.2"},
+		},
+		{
+			name:     "pattern of a hint",
+			actions:  []Action{ActionSelectPattern},
+			hintOf:   "10.0.0.2",
+			expected: []string{"10.0.0.1", "10.0.0.2"},
+		},
+		{
+			name:     "pattern of the selected match",
+			actions:  []Action{ActionSelectPattern, ActionConfirm},
+			expected: []string{"https://a.example.com", "https://b.example.com"},
+		},
+		{
+			name:     "cancelled pattern",
+			actions:  []Action{ActionSelectPattern, ActionQuit},
+			hintOf:   "10.0.0.2",
+			expected: []string{"10.0.0.2"},
+		},
+		{
+			name:     "undone",
+			actions:  []Action{ActionSelectAll, ActionUndoSelection},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := NewStateFromLines(lines, "abcd", []string{})
+			view := NewView(
+				state, false, false, 0, false, "",
+				GetColor("default"), GetColor("default"), GetColor("default"), GetColor("default"),
+				GetColor("default"), GetColor("default"), GetColor("default"), GetColor("default"),
+			)
+			view.skip = slices.IndexFunc(view.matches, func(m Match) bool { return m.Text == "https://a.example.com" })
+
+			typed := ""
+			hasUppercase := false
+			for _, action := range tt.actions {
+				view.handleAction(action, &typed, &hasUppercase)
+			}
+			if tt.hintOf != "" {
+				i := slices.IndexFunc(view.matches, func(m Match) bool { return m.Text == tt.hintOf })
+				for _, r := range *view.matches[i].Hint {
+					view.handleKeyEvent(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone), &typed, &hasUppercase, view.findLongestHint())
+				}
+			}
+
+			var got []string
+			for _, chosen := range view.chosen {
+				got = append(got, chosen.Text)
+			}
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestViewKeepsOriginalStyles(t *testing.T) {
+	state := NewState("\x1b[1;31merror\x1b[0m at 127.0.0.1", "abcd", []string{})
+
+	view := NewView(
+		state,
+		false,               // multi
+		false,               // reverse
+		0,                   // uniqueLevel
+		false,               // contrast
+		"",                  // position
+		GetColor("default"), // selectForegroundColor
+		GetColor("default"), // selectBackgroundColor
+		GetColor("default"), // multiForegroundColor
+		GetColor("default"), // multiBackgroundColor
+		GetColor("default"), // foregroundColor
+		GetColor("default"), // backgroundColor
+		GetColor("default"), // hintForegroundColor
+		GetColor("default"), // hintBackgroundColor
+	)
+
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("Failed to initialize simulation screen: %v", err)
+	}
+	defer screen.Fini()
+	screen.SetSize(40, 5)
+	view.screen = screen
+
+	view.render("")
+
+	r, _, style, _ := screen.GetContent(0, 0)
+	fg, _, attrs := style.Decompose()
+	if r != 'e' || attrs&tcell.AttrBold == 0 || fg == tcell.ColorDefault {
+		t.Errorf("Expected a bold colored 'e', got %q with %v", r, style)
+	}
+
+	r, _, style, _ = screen.GetContent(6, 0)
+	if r != 'a' || style != tcell.StyleDefault {
+		t.Errorf("Expected an unstyled 'a', got %q with %v", r, style)
+	}
+}
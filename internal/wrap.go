@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"context"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// WithWrapWidth joins every line as wide as width with the next one when
+// matching, as the terminal wrapped lines longer than its width when they
+// were captured without joining them. Matches spanning the break are found
+// whole, on the line they start. Zero or less leaves lines as they are
+func WithWrapWidth(width int) Option {
+	return optionFunc(func(s *State) {
+		s.WrapWidth = width
+	})
+}
+
+// wrappedLines returns the number of lines from y up to end that make up a
+// single line wrapped by the terminal, 1 when y isn't wrapped
+func (s *State) wrappedLines(y, end int) int {
+	if s.WrapWidth <= 0 {
+		return 1
+	}
+	n := 1
+	for y+n < end && runewidth.StringWidth(expandTabs(s.Lines[y+n-1], s.TabWidth)) == s.WrapWidth {
+		n++
+	}
+	return n
+}
+
+// processWrappedLines processes the n lines from y joined back together,
+// the matches being moved to the line and offset they start at. Every
+// pattern is tried, their triggers may be split by the wrap
+func (s *State) processWrappedLines(ctx context.Context, y, n int, patterns []*CompiledPattern) []Match {
+	lines := s.Lines[y : y+n]
+	conflicts := len(s.conflicts)
+	matches := s.processLine(ctx, y, strings.Join(lines, ""), patterns)
+	for i := range matches {
+		for j := 0; j < n-1 && matches[i].X >= len(lines[j]); j++ {
+			matches[i].X -= len(lines[j])
+			matches[i].Y++
+		}
+	}
+	for i := conflicts; i < len(s.conflicts); i++ {
+		for j := 0; j < n-1 && s.conflicts[i].X >= len(lines[j]); j++ {
+			s.conflicts[i].X -= len(lines[j])
+			s.conflicts[i].Y++
+		}
+	}
+	return matches
+}
@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestWrapWidth(t *testing.T) {
+	// A pane 30 cells wide wrapped the URL, the IP is on the wrapped line
+	text := "see https://example.com/a/very\n/long/path and 10.0.0.1\nnext line"
+
+	state := NewState(text, "qwerty", nil, WithWrapWidth(30))
+	matches := state.Matches(false, 0)
+
+	expected := []Match{
+		{X: 4, Y: 0, Pattern: "url", Text: "https://example.com/a/very/long/path"},
+		{X: 15, Y: 1, Pattern: "ipv4", Text: "10.0.0.1"},
+	}
+	if len(matches) != len(expected) {
+		t.Fatalf("Expected %d matches, got %v", len(expected), matches)
+	}
+	for i, want := range expected {
+		got := matches[i]
+		if got.X != want.X || got.Y != want.Y || got.Pattern != want.Pattern || got.Text != want.Text {
+			t.Errorf("Expected match %d to be %v, got %v", i, want, got)
+		}
+	}
+
+	// Without the width the URL is cut at the wrap
+	matches = NewState(text, "qwerty", nil).Matches(false, 0)
+	if len(matches) == 0 || matches[0].Text != "https://example.com/a/very" {
+		t.Errorf("Expected the URL to be cut without a wrap width, got %v", matches)
+	}
+}
+
+func TestViewWrappedMatch(t *testing.T) {
+	state := NewState("see https://example.com/a/very\n/long/path\nnext line", "qwerty", nil, WithWrapWidth(30))
+	view := NewView(
+		state, false, false, 0, false, "",
+		GetColor("red"), GetColor("black"), GetColor("default"), GetColor("default"),
+		GetColor("default"), GetColor("default"), GetColor("default"), GetColor("default"),
+	)
+
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("Failed to initialize simulation screen: %v", err)
+	}
+	defer screen.Fini()
+	screen.SetSize(30, 5)
+	view.screen = screen
+
+	view.render("")
+
+	// The match goes on with the wrapped line rather than pushing it down
+	_, _, matchStyle, _ := screen.GetContent(10, 0)
+	r, _, style, _ := screen.GetContent(0, 1)
+	if r != '/' || style != matchStyle {
+		t.Errorf("Expected the wrapped part of the match in %v, got %q with %v", matchStyle, r, style)
+	}
+	r, _, style, _ = screen.GetContent(0, 2)
+	if r != 'n' || style == matchStyle {
+		t.Errorf("Expected the next line unhighlighted on the third row, got %q with %v", r, style)
+	}
+}
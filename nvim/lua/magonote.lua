@@ -0,0 +1,118 @@
+-- Pick text from a neovim terminal with magonote, shown in a floating window
+-- over the terminal so the hints sit on the text they select
+local M = {}
+
+M.config = {
+  -- Path of the magonote binary
+  bin = "magonote",
+  -- Extra arguments given to magonote, such as { "--multi" }
+  args = {},
+  -- Lines of scrollback picked from above the visible ones
+  scrollback = 0,
+  -- Called with the list of selected values, sends them to the terminal by
+  -- default
+  on_select = nil,
+}
+
+-- setup overrides the default config with opts
+function M.setup(opts)
+  M.config = vim.tbl_extend("force", M.config, opts or {})
+end
+
+-- send writes the selection to the terminal of buf, or puts it at the cursor
+-- of other buffers
+local function send(buf, selection)
+  local text = table.concat(selection, " ")
+  if vim.bo[buf].buftype == "terminal" then
+    vim.api.nvim_chan_send(vim.bo[buf].channel, text)
+    return
+  end
+  vim.api.nvim_put({ text }, "c", true, true)
+end
+
+-- open_term runs cmd in the current buffer as a terminal
+local function open_term(cmd, opts)
+  if vim.fn.has("nvim-0.11") == 1 then
+    opts.term = true
+    return vim.fn.jobstart(cmd, opts)
+  end
+  return vim.fn.termopen(cmd, opts)
+end
+
+-- pick shows the visible lines of the current window in magonote and calls
+-- on_select with what was selected. opts override the config for this pick
+function M.pick(opts)
+  local config = vim.tbl_extend("force", M.config, opts or {})
+  local win = vim.api.nvim_get_current_win()
+  local buf = vim.api.nvim_win_get_buf(win)
+
+  local first = math.max(vim.fn.line("w0", win) - config.scrollback, 1)
+  local last = vim.fn.line("w$", win)
+  local lines = vim.api.nvim_buf_get_lines(buf, first - 1, last, false)
+
+  local input = vim.fn.tempname()
+  local output = vim.fn.tempname()
+  vim.fn.writefile(lines, input)
+
+  local float_buf = vim.api.nvim_create_buf(false, true)
+  local float_win = vim.api.nvim_open_win(float_buf, true, {
+    relative = "win",
+    win = win,
+    row = 0,
+    col = 0,
+    width = vim.api.nvim_win_get_width(win),
+    height = vim.api.nvim_win_get_height(win),
+    style = "minimal",
+    zindex = 250,
+  })
+
+  local cmd = { config.bin, "--input-file", input, "--target", output }
+  if vim.bo[buf].buftype == "terminal" then
+    -- The terminal wraps long lines at the width of the window
+    local info = vim.fn.getwininfo(win)[1]
+    vim.list_extend(cmd, { "--wrap-width", tostring(info.width - info.textoff) })
+  end
+  vim.list_extend(cmd, config.args)
+
+  open_term(cmd, {
+    on_exit = function(_, code)
+      vim.schedule(function()
+        if vim.api.nvim_win_is_valid(float_win) then
+          vim.api.nvim_win_close(float_win, true)
+        end
+        if vim.api.nvim_buf_is_valid(float_buf) then
+          vim.api.nvim_buf_delete(float_buf, { force = true })
+        end
+
+        local selection = {}
+        if code == 0 and vim.fn.filereadable(output) == 1 then
+          selection = vim.tbl_filter(function(line)
+            return line ~= ""
+          end, vim.fn.readfile(output))
+        end
+        vim.fn.delete(input)
+        vim.fn.delete(output)
+
+        if code ~= 0 then
+          vim.notify("magonote exited with " .. code, vim.log.levels.ERROR)
+          return
+        end
+        if #selection == 0 then
+          return
+        end
+
+        if vim.api.nvim_win_is_valid(win) then
+          vim.api.nvim_set_current_win(win)
+        end
+        if config.on_select then
+          config.on_select(selection)
+        elseif vim.api.nvim_buf_is_valid(buf) then
+          send(buf, selection)
+        end
+      end)
+    end,
+  })
+  vim.cmd.startinsert()
+end
+
+return M
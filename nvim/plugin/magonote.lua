@@ -0,0 +1,13 @@
+if vim.g.loaded_magonote then
+  return
+end
+vim.g.loaded_magonote = true
+
+-- :Magonote [args] picks from the current window, args being given to magonote
+vim.api.nvim_create_user_command("Magonote", function(cmd)
+  local opts = {}
+  if #cmd.fargs > 0 then
+    opts.args = cmd.fargs
+  end
+  require("magonote").pick(opts)
+end, { nargs = "*", desc = "Pick text with magonote" })
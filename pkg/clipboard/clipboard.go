@@ -274,6 +274,18 @@ func HasSystemClipboard() bool {
 	return findSystemClipboardTool() != ""
 }
 
+// SystemTool returns the system clipboard tool in use, empty if none is
+// installed
+func SystemTool() string {
+	return findSystemClipboardTool()
+}
+
+// SystemTools returns the system clipboard tools looked for on this platform,
+// in order of preference
+func SystemTools() []string {
+	return getClipboardTools()
+}
+
 // Available returns which clipboard targets are available
 func Available() map[string]bool {
 	return map[string]bool{
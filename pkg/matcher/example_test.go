@@ -0,0 +1,37 @@
+package matcher_test
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/Hanaasagi/magonote/pkg/matcher"
+)
+
+func ExampleFind() {
+	matches, err := matcher.Find("see https://example.com and /tmp/a.txt")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, m := range matches {
+		fmt.Printf("%s %s %s\n", m.Hint, m.Pattern, m.Text)
+	}
+	// Output:
+	// a url https://example.com
+	// s path /tmp/a.txt
+}
+
+func ExampleAssignHints() {
+	// Hints for matches found by other means
+	matches := []matcher.Match{{Text: "alpha"}, {Text: "beta"}}
+	if err := matcher.AssignHints(matches, matcher.WithAlphabet("colemak")); err != nil {
+		log.Fatal(err)
+	}
+
+	for _, m := range matches {
+		fmt.Printf("%s %s\n", m.Hint, m.Text)
+	}
+	// Output:
+	// a alpha
+	// r beta
+}
@@ -0,0 +1,227 @@
+// Package matcher finds the pieces of text magonote offers for selection,
+// such as URLs, paths, hashes and IP addresses, and assigns them hints. It
+// lets other Go programs embed the matching of magonote without its
+// terminal interface. Timings are logged at the info level with log/slog,
+// set a default logger to route or silence them
+package matcher
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/Hanaasagi/magonote/internal"
+)
+
+// DefaultAlphabet is the alphabet hints are made of unless WithAlphabet is
+// given
+const DefaultAlphabet = "qwerty"
+
+// Table detection settings of WithTableDetection, those of the
+// `[plugins.tabledetection]` example configuration
+const (
+	tableMinLines            = 3
+	tableMinColumns          = 3
+	tableConfidenceThreshold = 0.8
+)
+
+// Match is a piece of text found by a pattern
+type Match struct {
+	X       int    // Byte offset of the match in its line
+	Y       int    // Line of the match
+	Pattern string // Name of the pattern, such as "url", "path" or "custom"
+	Text    string // Matched text
+	Hint    string // Hint selecting the match, empty when it has none
+}
+
+// Option configures Find and AssignHints
+type Option interface {
+	apply(*options)
+}
+
+// optionFunc is a function that implements Option interface
+type optionFunc func(*options)
+
+func (f optionFunc) apply(o *options) {
+	f(o)
+}
+
+type options struct {
+	alphabet  string
+	alphabets map[string]string
+	patterns  []string
+	named     []internal.MatchPattern
+	reverse   bool
+	unique    bool
+	tables    bool
+	styles    bool
+}
+
+// WithAlphabet sets the alphabet hints are made of, a builtin alphabet such
+// as "qwerty" or "colemak" or one added with WithCustomAlphabet
+func WithAlphabet(name string) Option {
+	return optionFunc(func(o *options) {
+		o.alphabet = name
+	})
+}
+
+// WithCustomAlphabet adds an alphabet of the given letters usable by name
+// with WithAlphabet
+func WithCustomAlphabet(name, letters string) Option {
+	return optionFunc(func(o *options) {
+		if o.alphabets == nil {
+			o.alphabets = map[string]string{}
+		}
+		o.alphabets[name] = letters
+	})
+}
+
+// WithPatterns adds regular expressions whose matches are reported under the
+// "custom" pattern. Only the group named "match", or else the capture
+// groups, are matched when the expression has some
+func WithPatterns(patterns ...string) Option {
+	return optionFunc(func(o *options) {
+		o.patterns = append(o.patterns, patterns...)
+	})
+}
+
+// WithNamedPattern adds a regular expression whose matches are reported
+// under name
+func WithNamedPattern(name, pattern string) Option {
+	return optionFunc(func(o *options) {
+		o.named = append(o.named, internal.MatchPattern{Name: name, Pattern: pattern})
+	})
+}
+
+// WithReverse assigns the shortest hints to the last matches instead of the
+// first ones
+func WithReverse() Option {
+	return optionFunc(func(o *options) {
+		o.reverse = true
+	})
+}
+
+// WithUnique assigns the same hint to matches of the same text
+func WithUnique() Option {
+	return optionFunc(func(o *options) {
+		o.unique = true
+	})
+}
+
+// WithTableDetection also matches the cells of tables, such as the output of
+// `docker ps` or `kubectl get pods`
+func WithTableDetection() Option {
+	return optionFunc(func(o *options) {
+		o.tables = true
+	})
+}
+
+// WithStyleDetection also matches text styled with ANSI escape sequences,
+// such as the highlighted words of `grep --color`
+func WithStyleDetection() Option {
+	return optionFunc(func(o *options) {
+		o.styles = true
+	})
+}
+
+// newOptions applies opts over the defaults and validates them
+func newOptions(opts []Option) (*options, error) {
+	o := &options{alphabet: DefaultAlphabet}
+	for _, opt := range opts {
+		opt.apply(o)
+	}
+
+	if _, err := internal.ResolveAlphabet(o.alphabet, o.alphabets); err != nil {
+		return nil, fmt.Errorf("resolving alphabet: %w", err)
+	}
+	for _, pattern := range o.patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("compiling pattern %q: %w", pattern, err)
+		}
+	}
+	for _, pattern := range o.named {
+		if _, err := regexp.Compile(pattern.Pattern); err != nil {
+			return nil, fmt.Errorf("compiling pattern %s: %w", pattern.Name, err)
+		}
+	}
+	return o, nil
+}
+
+// state creates the internal state matching text
+func (o *options) state(text string) *internal.State {
+	var opts []internal.Option
+	if len(o.alphabets) > 0 {
+		opts = append(opts, internal.WithCustomAlphabets(o.alphabets))
+	}
+	if len(o.named) > 0 {
+		opts = append(opts, internal.WithNamedPatterns(o.named))
+	}
+	if o.tables {
+		opts = append(opts, internal.WithTableDetection(tableMinLines, tableMinColumns, tableConfidenceThreshold))
+	}
+	if o.styles {
+		opts = append(opts, internal.WithColorDetection())
+	}
+	return internal.NewState(text, o.alphabet, o.patterns, opts...)
+}
+
+// uniqueLevel returns the unique level of the internal state
+func (o *options) uniqueLevel() int {
+	if o.unique {
+		return 1
+	}
+	return 0
+}
+
+// Find returns the matches of text in reading order, with their hints
+func Find(text string, opts ...Option) ([]Match, error) {
+	return FindContext(context.Background(), text, opts...)
+}
+
+// FindContext is Find stopping with the error of ctx once it is done, to
+// bound the time spent on untrusted or huge inputs
+func FindContext(ctx context.Context, text string, opts ...Option) ([]Match, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := o.state(text).MatchesContext(ctx, o.reverse, o.uniqueLevel())
+	if err != nil {
+		return nil, fmt.Errorf("finding matches: %w", err)
+	}
+	matches := make([]Match, len(found))
+	for i, m := range found {
+		matches[i] = Match{X: m.X, Y: m.Y, Pattern: m.Pattern, Text: m.Text}
+		if m.Hint != nil {
+			matches[i].Hint = *m.Hint
+		}
+	}
+	return matches, nil
+}
+
+// AssignHints assigns hints to matches in place, replacing their previous
+// hints. Matches are expected in reading order, as returned by Find, and may
+// be filtered or come from elsewhere
+func AssignHints(matches []Match, opts ...Option) error {
+	o, err := newOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	assigned := make([]internal.Match, len(matches))
+	for i, m := range matches {
+		assigned[i] = internal.Match{X: m.X, Y: m.Y, Pattern: m.Pattern, Text: m.Text}
+	}
+	if err := o.state("").AssignHints(assigned, o.reverse, o.uniqueLevel()); err != nil {
+		return fmt.Errorf("assigning hints: %w", err)
+	}
+
+	for i, m := range assigned {
+		matches[i].Hint = ""
+		if m.Hint != nil {
+			matches[i].Hint = *m.Hint
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package matcher
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		opts     []Option
+		expected []Match
+	}{
+		{
+			name: "builtin patterns",
+			text: "see https://example.com and /tmp/a.txt\ncommit 4f2a9c1d8e7b",
+			expected: []Match{
+				{X: 4, Y: 0, Pattern: "url", Text: "https://example.com", Hint: "a"},
+				{X: 28, Y: 0, Pattern: "path", Text: "/tmp/a.txt", Hint: "s"},
+				{X: 7, Y: 1, Pattern: "sha", Text: "4f2a9c1d8e7b", Hint: "d"},
+			},
+		},
+		{
+			name: "reverse",
+			text: "10.0.0.1 10.0.0.2",
+			opts: []Option{WithReverse()},
+			expected: []Match{
+				{X: 0, Y: 0, Pattern: "ipv4", Text: "10.0.0.1", Hint: "s"},
+				{X: 9, Y: 0, Pattern: "ipv4", Text: "10.0.0.2", Hint: "a"},
+			},
+		},
+		{
+			name: "named pattern and custom alphabet",
+			text: "fixed in JIRA-42",
+			opts: []Option{WithNamedPattern("ticket", `JIRA-\d+`), WithCustomAlphabet("mine", "jk"), WithAlphabet("mine")},
+			expected: []Match{
+				{X: 9, Y: 0, Pattern: "ticket", Text: "JIRA-42", Hint: "j"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := Find(tt.text, tt.opts...)
+			if err != nil {
+				t.Fatalf("Find returned error: %v", err)
+			}
+			if len(matches) != len(tt.expected) {
+				t.Fatalf("Expected %d matches, got %v", len(tt.expected), matches)
+			}
+			for i, expected := range tt.expected {
+				if matches[i] != expected {
+					t.Errorf("Expected %+v, got %+v", expected, matches[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFindInvalidOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "unknown alphabet", opts: []Option{WithAlphabet("nope")}},
+		{name: "invalid pattern", opts: []Option{WithPatterns(`(`)}},
+		{name: "invalid named pattern", opts: []Option{WithNamedPattern("broken", `[`)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Find("text", tt.opts...); err == nil {
+				t.Error("Expected an error, got nil")
+			}
+		})
+	}
+}
+
+func TestAssignHints(t *testing.T) {
+	matches := []Match{{Text: "a", Hint: "old"}, {Text: "b"}, {Text: "a"}}
+	if err := AssignHints(matches, WithUnique()); err != nil {
+		t.Fatalf("AssignHints returned error: %v", err)
+	}
+
+	expected := []string{"a", "s", "a"}
+	for i, hint := range expected {
+		if matches[i].Hint != hint {
+			t.Errorf("Expected hint %q for match %d, got %q", hint, i, matches[i].Hint)
+		}
+	}
+}
+
+func TestFindContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := FindContext(ctx, "https://example.com"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
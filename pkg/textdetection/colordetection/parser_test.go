@@ -302,3 +302,25 @@ func TestParseText_SpecialCharacters(t *testing.T) {
 		t.Errorf("Expected 1 style span for special characters, got %d", len(result.StyleSpans))
 	}
 }
+
+func FuzzParseText(f *testing.F) {
+	for _, seed := range []string{
+		"plain", "\x1b[31mred\x1b[0m\n\x1b[1;4mbold\x1b[m", "\x1b[38;5;196mx\x1b[48;2;1;2;3my",
+		"\x1b[", "\x1b[38;5;", "\x1b[38;2;1m", "\x1b[999999999999m", "\xff\x1b[31m\xfe",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, text string) {
+		result, err := ParseText(text)
+		if err != nil {
+			return
+		}
+		lines := strings.Split(result.PlainText, "\n")
+		for _, span := range result.StyleSpans {
+			if span.StartLine < 0 || span.StartLine >= len(lines) {
+				t.Fatalf("Expected the line of %q in [0, %d), got %d", span.Text, len(lines), span.StartLine)
+			}
+		}
+	})
+}
@@ -0,0 +1,37 @@
+package colordetection
+
+import (
+	"regexp"
+	"strings"
+)
+
+// escapeSequence matches CSI sequences such as colors and cursor movement,
+// OSC sequences such as hyperlinks, character set designations and the
+// other two-byte escapes
+var escapeSequence = regexp.MustCompile(`\x1b(\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b]*(\x07|\x1b\\)|[()*+][0-9A-Za-z]|[@-Z\\-_])`)
+
+// HasANSI reports whether text contains escape sequences
+func HasANSI(text string) bool {
+	return strings.IndexByte(text, '\x1b') >= 0
+}
+
+// StripLine removes the escape sequences of a line. offsets maps every byte
+// of the plain line to its byte offset in line, offsets[len(plain)] is
+// len(line)
+func StripLine(line string) (plain string, offsets []int) {
+	var b strings.Builder
+	start := 0
+	for _, loc := range escapeSequence.FindAllStringIndex(line, -1) {
+		for i := start; i < loc[0]; i++ {
+			offsets = append(offsets, i)
+		}
+		b.WriteString(line[start:loc[0]])
+		start = loc[1]
+	}
+	for i := start; i < len(line); i++ {
+		offsets = append(offsets, i)
+	}
+	b.WriteString(line[start:])
+
+	return b.String(), append(offsets, len(line))
+}
@@ -0,0 +1,56 @@
+package colordetection
+
+import (
+	"testing"
+)
+
+func TestStripLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		plain string
+	}{
+		{name: "plain", line: "no styling", plain: "no styling"},
+		{name: "colors", line: "\x1b[1;32mok\x1b[0m done", plain: "ok done"},
+		{name: "hyperlink", line: "\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\", plain: "link"},
+		{name: "cursor", line: "a\x1b[2Kb\x1b(B", plain: "ab"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plain, offsets := StripLine(tt.line)
+			if plain != tt.plain {
+				t.Fatalf("Expected %q, got %q", tt.plain, plain)
+			}
+			if len(offsets) != len(plain)+1 || offsets[len(plain)] != len(tt.line) {
+				t.Fatalf("Expected %d offsets ending at %d, got %v", len(plain)+1, len(tt.line), offsets)
+			}
+			for i := range plain {
+				if tt.line[offsets[i]] != plain[i] {
+					t.Errorf("Expected %q at offset %d, got %q", plain[i], offsets[i], tt.line[offsets[i]])
+				}
+			}
+		})
+	}
+}
+
+func FuzzStripLine(f *testing.F) {
+	for _, seed := range []string{
+		"no styling", "\x1b[1;32mok\x1b[0m done", "\x1b]8;;https://example.com\x1b\\link",
+		"\x1b", "\x1b[", "\x1b]8;;", "a\x1b1b", "\xff\x1b[\xfe",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, line string) {
+		plain, offsets := StripLine(line)
+		if len(offsets) != len(plain)+1 || offsets[len(plain)] != len(line) {
+			t.Fatalf("Expected %d offsets ending at %d, got %v", len(plain)+1, len(line), offsets)
+		}
+		for i := range len(plain) {
+			if line[offsets[i]] != plain[i] {
+				t.Fatalf("Expected %q at offset %d, got %q", plain[i], offsets[i], line[offsets[i]])
+			}
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package logdetection
+
+import "strings"
+
+// keyKinds maps the usual keys of the level, timestamp and message of
+// logfmt and JSON logs to their kind
+var keyKinds = map[string]Kind{
+	"level":      KindLevel,
+	"lvl":        KindLevel,
+	"severity":   KindLevel,
+	"loglevel":   KindLevel,
+	"time":       KindTimestamp,
+	"ts":         KindTimestamp,
+	"timestamp":  KindTimestamp,
+	"@timestamp": KindTimestamp,
+	"msg":        KindMessage,
+	"message":    KindMessage,
+	"@message":   KindMessage,
+}
+
+// keyKind returns the kind of the field with key
+func keyKind(key string) Kind {
+	if kind, ok := keyKinds[strings.ToLower(key)]; ok {
+		return kind
+	}
+	return KindField
+}
+
+// wellKnown reports whether fields hold a level, a timestamp or a message,
+// telling a log line from any list of key=value pairs
+func wellKnown(fields []Field) bool {
+	for _, field := range fields {
+		if field.Kind != KindField {
+			return true
+		}
+	}
+	return false
+}
+
+// Detect recognizes line as a log line of one of the formats
+func Detect(line string) (Record, bool) {
+	for _, detect := range []func(string) (Record, bool){detectJSON, detectLogfmt, detectSyslog} {
+		if record, ok := detect(line); ok {
+			return record, true
+		}
+	}
+	return Record{}, false
+}
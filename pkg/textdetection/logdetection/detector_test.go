@@ -0,0 +1,68 @@
+package logdetection
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		format Format
+		fields []string
+	}{
+		{
+			name:   "logfmt",
+			line:   `time=2024-05-01T10:00:00Z level=warn msg="disk \"/var\" full" used=97% path=`,
+			format: FormatLogfmt,
+			fields: []string{"timestamp:2024-05-01T10:00:00Z@5", "level:warn@32", `message:disk \"/var\" full@42`, "field:97%@67", "field:@76"},
+		},
+		{
+			name:   "json",
+			line:   `{"ts": 1714557600.5, "level": "error", "msg": "boom", "ctx": {"id": "x"}, "ok": true, "port": 80}`,
+			format: FormatJSON,
+			fields: []string{"timestamp:1714557600.5@7", "level:error@31", "message:boom@47", "field:80@94"},
+		},
+		{
+			name:   "syslog",
+			line:   "Oct  1 22:14:15 web sshd[4242]: Accepted publickey for root",
+			format: FormatSyslog,
+			fields: []string{"timestamp:Oct  1 22:14:15@0", "field:web@16", "field:sshd@20", "field:4242@25", "message:Accepted publickey for root@32"},
+		},
+		{
+			name:   "syslog 5424",
+			line:   "<34>1 2003-10-11T22:14:15.003Z mymachine su - ID47 - 'su root' failed",
+			format: FormatSyslog,
+			fields: []string{"timestamp:2003-10-11T22:14:15.003Z@6", "field:mymachine@31", "field:su@41", "message:'su root' failed@53"},
+		},
+		{name: "pairs without level, time or message", line: "a=1 b=2"},
+		{name: "single pair", line: "level=info"},
+		{name: "text after pairs", line: "level=info msg=ok and more"},
+		{name: "json without log keys", line: `{"id": 1, "name": "x"}`},
+		{name: "plain text", line: "Oct 11 is a date"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record, ok := Detect(tt.line)
+			if ok != (tt.fields != nil) {
+				t.Fatalf("Expected detected %v, got %v", tt.fields != nil, ok)
+			}
+			if record.Format != tt.format {
+				t.Errorf("Expected format %q, got %q", tt.format, record.Format)
+			}
+			var fields []string
+			for _, field := range record.Fields {
+				if tt.line[field.Start:field.End] != field.Value {
+					t.Errorf("Expected value %q at %d, got %q", field.Value, field.Start, tt.line[field.Start:field.End])
+				}
+				fields = append(fields, fmt.Sprintf("%s:%s@%d", field.Kind, field.Value, field.Start))
+			}
+			if !slices.Equal(fields, tt.fields) {
+				t.Errorf("Expected fields %q, got %q", tt.fields, fields)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package logdetection
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// detectJSON recognizes a line holding a JSON object with a level, a
+// timestamp or a message. Its fields are the string and number values of
+// the object, those of the objects and arrays it holds are left out
+func detectJSON(line string) (Record, bool) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "{") || !strings.HasSuffix(trimmed, "}") || !json.Valid([]byte(trimmed)) {
+		return Record{}, false
+	}
+
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.UseNumber()
+
+	var fields []Field
+	depth, key := 0, ""
+	expectKey := false
+	for {
+		before := int(dec.InputOffset())
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		end := int(dec.InputOffset())
+
+		if delim, ok := tok.(json.Delim); ok {
+			if delim == '{' || delim == '[' {
+				depth++
+			} else {
+				depth--
+			}
+			expectKey = depth == 1
+			continue
+		}
+		if depth != 1 {
+			continue
+		}
+		if expectKey {
+			key, expectKey = tok.(string), false
+			continue
+		}
+		expectKey = true
+
+		switch tok.(type) {
+		case string, json.Number:
+			// The value starts after the separators and spaces before it
+			start := end - len(strings.TrimLeft(line[before:end], " \t,:"))
+			if _, ok := tok.(string); ok {
+				start, end = start+1, end-1
+			}
+			if start < end {
+				fields = append(fields, Field{Kind: keyKind(key), Key: key, Value: line[start:end], Start: start, End: end})
+			}
+		}
+	}
+	if !wellKnown(fields) {
+		return Record{}, false
+	}
+	return Record{Format: FormatJSON, Fields: fields}, true
+}
@@ -0,0 +1,40 @@
+package logdetection
+
+import (
+	"regexp"
+	"strings"
+)
+
+// logfmtPair matches a key=value pair of logfmt and the spaces after it. The
+// value is bare, double quoted with backslash escapes, or empty
+var logfmtPair = regexp.MustCompile(`^([A-Za-z_@][\w.@-]*)=(?:"((?:[^"\\]|\\.)*)"|([^\s"]*))(?:\s+|$)`)
+
+// detectLogfmt recognizes a line made of at least two key=value pairs,
+// among which a level, a timestamp or a message
+func detectLogfmt(line string) (Record, bool) {
+	offset := len(line) - len(strings.TrimLeft(line, " \t"))
+	var fields []Field
+	for offset < len(line) {
+		loc := logfmtPair.FindStringSubmatchIndex(line[offset:])
+		if loc == nil {
+			return Record{}, false
+		}
+		key := line[offset+loc[2] : offset+loc[3]]
+		start, end := loc[6], loc[7]
+		if loc[4] >= 0 {
+			start, end = loc[4], loc[5]
+		}
+		fields = append(fields, Field{
+			Kind:  keyKind(key),
+			Key:   key,
+			Value: line[offset+start : offset+end],
+			Start: offset + start,
+			End:   offset + end,
+		})
+		offset += loc[1]
+	}
+	if len(fields) < 2 || !wellKnown(fields) {
+		return Record{}, false
+	}
+	return Record{Format: FormatLogfmt, Fields: fields}, true
+}
@@ -0,0 +1,43 @@
+package logdetection
+
+import "regexp"
+
+// syslogLine matches the lines of RFC 3164 syslog, as written to
+// /var/log/syslog and by journalctl, with an optional priority:
+// "Oct 11 22:14:15 host app[123]: message"
+var syslogLine = regexp.MustCompile(`^(?:<\d{1,3}>)?(?P<timestamp>[A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2}) (?P<host>\S+) (?P<app>[^\s:\[]+)(?:\[(?P<pid>\d+)\])?: (?P<message>.*\S)`)
+
+// syslog5424Line matches the lines of RFC 5424 syslog:
+// "<34>1 2003-10-11T22:14:15.003Z host app 123 ID47 - message"
+var syslog5424Line = regexp.MustCompile(`^<\d{1,3}>1 (?P<timestamp>\S+) (?P<host>\S+) (?P<app>\S+) (?P<pid>\S+) \S+ (?:-|(?:\[[^\]]*\])+)(?: (?P<message>.*\S))?`)
+
+// syslogKinds are the kinds of the groups of the syslog expressions
+var syslogKinds = map[string]Kind{
+	"timestamp": KindTimestamp,
+	"host":      KindField,
+	"app":       KindField,
+	"pid":       KindField,
+	"message":   KindMessage,
+}
+
+// detectSyslog recognizes a syslog line. Its fields are the timestamp, the
+// host, the application, its process ID and the message
+func detectSyslog(line string) (Record, bool) {
+	for _, re := range []*regexp.Regexp{syslogLine, syslog5424Line} {
+		loc := re.FindStringSubmatchIndex(line)
+		if loc == nil {
+			continue
+		}
+		var fields []Field
+		for i, name := range re.SubexpNames() {
+			start, end := loc[2*i], loc[2*i+1]
+			// "-" is RFC 5424 for no value
+			if name == "" || start < 0 || line[start:end] == "-" {
+				continue
+			}
+			fields = append(fields, Field{Kind: syslogKinds[name], Key: name, Value: line[start:end], Start: start, End: end})
+		}
+		return Record{Format: FormatSyslog, Fields: fields}, true
+	}
+	return Record{}, false
+}
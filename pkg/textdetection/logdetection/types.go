@@ -0,0 +1,37 @@
+// Package logdetection recognizes structured log lines, in logfmt, as JSON
+// objects or in syslog format, and splits them into their fields
+package logdetection
+
+// Format is the format of a recognized log line
+type Format string
+
+const (
+	FormatLogfmt Format = "logfmt" // time=... level=info msg="..."
+	FormatJSON   Format = "json"   // {"time": "...", "level": "info", "msg": "..."}
+	FormatSyslog Format = "syslog" // Oct 11 22:14:15 host app[123]: ...
+)
+
+// Kind tells what a field holds
+type Kind string
+
+const (
+	KindLevel     Kind = "level"
+	KindTimestamp Kind = "timestamp"
+	KindMessage   Kind = "message"
+	KindField     Kind = "field" // Any other key=value pair
+)
+
+// Field is a field of a log line
+type Field struct {
+	Kind  Kind
+	Key   string
+	Value string // Value as written in the line, without its quotes
+	Start int    // Byte offset of Value in the line
+	End   int
+}
+
+// Record is a recognized log line
+type Record struct {
+	Format Format
+	Fields []Field
+}
@@ -0,0 +1,47 @@
+package tabledetection
+
+import (
+	"github.com/Hanaasagi/magonote/pkg/textdetection/colordetection"
+)
+
+// ============================================================================
+// ANSI Preprocessing
+// ============================================================================
+
+// stripANSI returns the lines without escape sequences, as laid out on
+// screen, and the byte offsets of the plain lines in the original ones. The
+// offsets are nil when no line has escape sequences
+func stripANSI(lines []string) ([]string, [][]int) {
+	styled := false
+	for _, line := range lines {
+		if colordetection.HasANSI(line) {
+			styled = true
+			break
+		}
+	}
+	if !styled {
+		return lines, nil
+	}
+
+	plain := make([]string, len(lines))
+	offsets := make([][]int, len(lines))
+	for i, line := range lines {
+		plain[i], offsets[i] = colordetection.StripLine(line)
+	}
+	return plain, offsets
+}
+
+// restoreANSI moves the cells of tables detected on stripped lines to their
+// positions in the original lines. Cell texts stay plain, so they differ
+// from the original when escape sequences style a part of the cell
+func restoreANSI(tables []Table, offsets [][]int) {
+	for _, table := range tables {
+		for _, row := range table.Cells {
+			for i := range row {
+				lineOffsets := offsets[row[i].LineIndex]
+				row[i].StartPos = lineOffsets[row[i].StartPos]
+				row[i].EndPos = lineOffsets[row[i].EndPos]
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package tabledetection
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Hanaasagi/magonote/pkg/textdetection/colordetection"
+)
+
+func TestStyledTable(t *testing.T) {
+	lines := []string{
+		"\x1b[1mNAME\x1b[0m      \x1b[1mSTATUS\x1b[0m    \x1b[1mAGE\x1b[0m",
+		"web-1     \x1b[32mRunning\x1b[0m   5d",
+		"web-2     \x1b[31mFailed\x1b[0m    12d",
+		"worker    \x1b[32mRunning\x1b[0m   3h",
+	}
+
+	tables, err := NewDetector().DetectTables(lines)
+	if err != nil {
+		t.Fatalf("Detector returned error: %v", err)
+	}
+	if len(tables) != 1 {
+		t.Fatalf("Expected 1 table, got %d", len(tables))
+	}
+
+	table := tables[0]
+	if table.NumColumns != 3 || table.NumRows != 4 {
+		t.Errorf("Expected 4 rows of 3 columns, got %d rows of %d", table.NumRows, table.NumColumns)
+	}
+	if got, _ := table.GetColumnTexts(1); strings.Join(got, ",") != "STATUS,Running,Failed,Running" {
+		t.Errorf("Expected the status column, got %v", got)
+	}
+
+	for _, row := range table.Cells {
+		for _, cell := range row {
+			plain, _ := colordetection.StripLine(lines[cell.LineIndex][cell.StartPos : cell.EndPos+1])
+			if plain != cell.Text {
+				t.Errorf("Expected %q at [%d-%d], got %q", cell.Text, cell.StartPos, cell.EndPos, plain)
+			}
+		}
+	}
+}
+
+func TestDetectTablesIncompleteEscape(t *testing.T) {
+	// A lone escape isn't stripped, it mustn't be stripped again forever
+	lines := []string{
+		"NAME      STATUS    AGE",
+		"web-1     \x1b1Running   5d",
+		"web-2     Failed    12d",
+	}
+
+	if _, err := NewDetector().DetectTables(lines); err != nil {
+		t.Fatalf("Detector returned error: %v", err)
+	}
+}
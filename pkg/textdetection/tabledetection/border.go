@@ -0,0 +1,197 @@
+package tabledetection
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// ============================================================================
+// Border Strategy Implementation
+// ============================================================================
+
+// BorderStrategy detects tables whose columns are delimited by explicit
+// borders, such as the `+----+` and `|` tables of the mysql client, the
+// `----+----` tables of psql and Unicode box-drawing tables. Rows are the
+// lines between the rules and cells are the text between the separators
+type BorderStrategy struct {
+	config DetectionConfig
+}
+
+// NewBorderStrategy creates a new border detection strategy
+func NewBorderStrategy(config DetectionConfig) *BorderStrategy {
+	strategyConfig := config
+	strategyConfig.TokenizationMode = BorderMode
+
+	return &BorderStrategy{config: strategyConfig}
+}
+
+// DetectTables implements DetectionStrategy interface
+func (bs *BorderStrategy) DetectTables(lines []string) ([]Table, error) {
+	var tables []Table
+
+	start := -1
+	for i := 0; i <= len(lines); i++ {
+		if i < len(lines) && (isBorderRule(lines[i]) || isBorderRow(lines[i])) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+
+		if start >= 0 {
+			if table, ok := bs.buildTable(lines, start, i-1); ok {
+				tables = append(tables, table)
+			}
+			start = -1
+		}
+	}
+
+	return tables, nil
+}
+
+// buildTable turns a block of rule and row lines into a table, rejecting
+// blocks without a rule, such as a single shell pipeline, and blocks whose
+// rows disagree on the number of columns
+func (bs *BorderStrategy) buildTable(lines []string, startLine, endLine int) (Table, bool) {
+	if endLine-startLine+1 < bs.config.MinLines {
+		return Table{}, false
+	}
+
+	hasRule := false
+	separators := -1
+	var cells [][]Cell
+	var columnPositions []int
+
+	for i := startLine; i <= endLine; i++ {
+		line := lines[i]
+		if isBorderRule(line) {
+			hasRule = true
+			continue
+		}
+
+		if n := countSeparators(line); separators < 0 {
+			separators = n
+		} else if n != separators {
+			return Table{}, false
+		}
+
+		row := borderCells(line, len(cells), i)
+		if len(row) == 0 {
+			continue
+		}
+		if len(cells) == 0 {
+			for _, cell := range row {
+				columnPositions = append(columnPositions, cell.StartPos)
+			}
+		}
+		cells = append(cells, row)
+	}
+
+	numColumns := 0
+	for _, row := range cells {
+		for _, cell := range row {
+			numColumns = max(numColumns, cell.Column+1)
+		}
+	}
+
+	if !hasRule || len(cells) == 0 || numColumns < bs.config.MinColumns {
+		return Table{}, false
+	}
+
+	return Table{
+		StartLine:  startLine,
+		EndLine:    endLine,
+		NumRows:    len(cells),
+		NumColumns: numColumns,
+		// Explicit borders leave no doubt about the table structure
+		Confidence: 1.0,
+		Mode:       BorderMode,
+		Cells:      cells,
+		Metadata: &TableMetadata{
+			DetectionStrategy: bs.GetName(),
+			TokenizationMode:  BorderMode,
+			ColumnPositions:   columnPositions,
+			Explanation:       explicitExplanation("borders"),
+		},
+	}, true
+}
+
+// GetName returns the strategy name
+func (bs *BorderStrategy) GetName() string {
+	return "border"
+}
+
+// GetConfiguration returns the strategy configuration
+func (bs *BorderStrategy) GetConfiguration() DetectionConfig {
+	return bs.config
+}
+
+// isBorderSeparator reports whether r separates the cells of a row
+func isBorderSeparator(r rune) bool {
+	return r == '|' || r == '│' || r == '┃' || r == '║'
+}
+
+// isBorderRule reports whether line is a horizontal rule such as `+----+`,
+// `----+----`, `|---|---|` or `├────┼────┤`
+func isBorderRule(line string) bool {
+	line = strings.TrimSpace(line)
+	if utf8.RuneCountInString(line) < 3 {
+		return false
+	}
+
+	hasDash := false
+	for _, r := range line {
+		switch {
+		case r == '-' || r == '=' || r == '─' || r == '━' || r == '═':
+			hasDash = true
+		case r == '+' || r == ':' || r == ' ' || isBorderSeparator(r):
+		case r >= 0x2500 && r <= 0x257F: // Box drawing block
+		default:
+			return false
+		}
+	}
+	return hasDash
+}
+
+// isBorderRow reports whether line is a row of a bordered table
+func isBorderRow(line string) bool {
+	return countSeparators(line) > 0
+}
+
+// countSeparators returns the number of cell separators in line
+func countSeparators(line string) int {
+	return strings.Count(line, "|") + strings.Count(line, "│") +
+		strings.Count(line, "┃") + strings.Count(line, "║")
+}
+
+// borderCells splits a row line at its separators. The text before the
+// first and after the last separator is a cell unless it is blank, as in
+// psql output which has no outer border. Empty cells keep their column but
+// are not returned
+func borderCells(line string, row, lineIndex int) []Cell {
+	var segments [][2]int
+	start := 0
+	for i, r := range line {
+		if isBorderSeparator(r) {
+			segments = append(segments, [2]int{start, i})
+			start = i + utf8.RuneLen(r)
+		}
+	}
+	segments = append(segments, [2]int{start, len(line)})
+
+	// Outer borders
+	if strings.TrimSpace(line[segments[0][0]:segments[0][1]]) == "" {
+		segments = segments[1:]
+	}
+	if n := len(segments); n > 0 && strings.TrimSpace(line[segments[n-1][0]:segments[n-1][1]]) == "" {
+		segments = segments[:n-1]
+	}
+
+	var cells []Cell
+	for column, segment := range segments {
+		if cell, ok := segmentCell(line, segment, row, column, lineIndex); ok {
+			cells = append(cells, cell)
+		}
+	}
+	return cells
+}
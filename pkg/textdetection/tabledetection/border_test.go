@@ -0,0 +1,160 @@
+package tabledetection
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBorderTables(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		startLine int
+		endLine   int
+		rows      [][]string
+	}{
+		{
+			name: "mysql client",
+			input: `
+mysql> select id, name from users;
++----+-------+
+| id | name  |
++----+-------+
+|  1 | alice |
+|  2 | bob   |
++----+-------+
+2 rows in set (0.00 sec)`,
+			startLine: 1,
+			endLine:   6,
+			rows:      [][]string{{"id", "name"}, {"1", "alice"}, {"2", "bob"}},
+		},
+		{
+			name: "psql",
+			input: `
+ id | name  | email
+----+-------+-------------------
+  1 | alice | alice@example.com
+  2 | bob   | bob@example.com
+(2 rows)`,
+			startLine: 0,
+			endLine:   3,
+			rows: [][]string{
+				{"id", "name", "email"},
+				{"1", "alice", "alice@example.com"},
+				{"2", "bob", "bob@example.com"},
+			},
+		},
+		{
+			name: "box drawing",
+			input: `
+┌────┬─────────┐
+│ id │ name    │
+├────┼─────────┤
+│ 1  │ alice   │
+│ 2  │ bob     │
+└────┴─────────┘`,
+			startLine: 0,
+			endLine:   5,
+			rows:      [][]string{{"id", "name"}, {"1", "alice"}, {"2", "bob"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := strings.Split(strings.TrimPrefix(tt.input, "\n"), "\n")
+			tables, err := NewDetector().DetectTables(lines)
+			if err != nil {
+				t.Fatalf("Detector returned error: %v", err)
+			}
+
+			var table *Table
+			for i := range tables {
+				if tables[i].Mode == BorderMode {
+					table = &tables[i]
+				}
+			}
+			if table == nil {
+				t.Fatalf("Expected a bordered table, got %v", tables)
+			}
+
+			if table.StartLine != tt.startLine || table.EndLine != tt.endLine {
+				t.Errorf("Expected lines %d-%d, got %d-%d", tt.startLine, tt.endLine, table.StartLine, table.EndLine)
+			}
+			if table.NumRows != len(tt.rows) {
+				t.Fatalf("Expected %d rows, got %d", len(tt.rows), table.NumRows)
+			}
+			for r, want := range tt.rows {
+				got, _ := table.GetRowTexts(r)
+				if strings.Join(got, ",") != strings.Join(want, ",") {
+					t.Errorf("Expected row %d to be %v, got %v", r, want, got)
+				}
+				for _, cell := range table.Cells[r] {
+					if line := lines[cell.LineIndex]; line[cell.StartPos:cell.EndPos+1] != cell.Text {
+						t.Errorf("Expected %q at [%d-%d], got %q", cell.Text, cell.StartPos, cell.EndPos, line[cell.StartPos:cell.EndPos+1])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBorderTablesRejected(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name: "shell pipelines",
+			input: `
+$ cat access.log | grep 404 | wc -l
+$ ps aux | grep nginx | head`,
+		},
+		{
+			name: "ragged rows",
+			input: `
++----+
+| a | b |
+| c |`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := strings.Split(strings.TrimPrefix(tt.input, "\n"), "\n")
+			tables, err := NewBorderStrategy(DefaultConfig()).DetectTables(lines)
+			if err != nil {
+				t.Fatalf("Strategy returned error: %v", err)
+			}
+			if len(tables) != 0 {
+				t.Errorf("Expected no bordered table, got %v", tables)
+			}
+		})
+	}
+}
+
+func TestBorderTablesWithAlignedTable(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(`
+Name    Age  City
+John    25   NYC
+Alice   30   LA
+
++----+-------+
+| id | name  |
++----+-------+
+|  1 | alice |
++----+-------+`), "\n")
+
+	tables, err := NewDetector().DetectTables(lines)
+	if err != nil {
+		t.Fatalf("Detector returned error: %v", err)
+	}
+	if len(tables) != 2 {
+		t.Fatalf("Expected 2 tables, got %v", tables)
+	}
+	if tables[0].Mode == BorderMode || tables[0].StartLine != 0 {
+		t.Errorf("Expected the aligned table first, got %v", tables[0])
+	}
+	if tables[1].Mode != BorderMode || tables[1].StartLine != 4 {
+		t.Errorf("Expected the bordered table second, got %v", tables[1])
+	}
+}
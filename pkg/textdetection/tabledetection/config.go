@@ -19,6 +19,10 @@ const (
 
 	// DefaultMaxColumnVariance is the maximum allowed variance in column positions
 	DefaultMaxColumnVariance = 2
+
+	// DefaultMaxBufferedLines is the number of lines the streaming API buffers
+	// before detecting tables without waiting for a blank line
+	DefaultMaxBufferedLines = 1000
 )
 
 // Dual-Round Detection Configuration
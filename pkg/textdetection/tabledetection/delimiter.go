@@ -0,0 +1,189 @@
+package tabledetection
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ============================================================================
+// Delimiter Strategy Implementation
+// ============================================================================
+
+// delimiters are the field separators tried by DelimiterStrategy, in order
+var delimiters = []byte{'\t', ','}
+
+// DelimiterStrategy detects CSV and TSV data: runs of lines splitting into
+// the same number of fields on a tab or a comma. The delimiter is detected
+// per run, double-quoted CSV fields may contain the delimiter. Commas and
+// tabs also show up in text that isn't a table, so the confidence drops
+// with the share of empty fields instead of being certain as for borders
+type DelimiterStrategy struct {
+	config DetectionConfig
+}
+
+// NewDelimiterStrategy creates a new delimiter detection strategy
+func NewDelimiterStrategy(config DetectionConfig) *DelimiterStrategy {
+	strategyConfig := config
+	strategyConfig.TokenizationMode = DelimiterMode
+
+	return &DelimiterStrategy{config: strategyConfig}
+}
+
+// DetectTables implements DetectionStrategy interface
+func (ds *DelimiterStrategy) DetectTables(lines []string) ([]Table, error) {
+	var tables []Table
+
+	for i := 0; i < len(lines); {
+		table, ok := ds.detectAt(lines, i)
+		if !ok {
+			i++
+			continue
+		}
+		tables = append(tables, table)
+		i = table.EndLine + 1
+	}
+
+	return tables, nil
+}
+
+// detectAt returns the longest table starting at line start, trying every
+// delimiter
+func (ds *DelimiterStrategy) detectAt(lines []string, start int) (Table, bool) {
+	var best Table
+	found := false
+
+	for _, delimiter := range delimiters {
+		fields := len(delimitedFields(lines[start], delimiter))
+		if fields < max(ds.config.MinColumns, 2) {
+			continue
+		}
+
+		end := start
+		for end+1 < len(lines) && len(delimitedFields(lines[end+1], delimiter)) == fields {
+			end++
+		}
+		if end-start+1 < ds.config.MinLines || isProse(lines[start:end+1], delimiter) {
+			continue
+		}
+
+		if !found || end > best.EndLine {
+			best = ds.buildTable(lines, start, end, delimiter, fields)
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+// buildTable collects the fields of the lines as cells
+func (ds *DelimiterStrategy) buildTable(lines []string, startLine, endLine int, delimiter byte, numColumns int) Table {
+	var cells [][]Cell
+	var columnPositions []int
+	filled := 0
+
+	for i := startLine; i <= endLine; i++ {
+		var row []Cell
+		for column, segment := range delimitedFields(lines[i], delimiter) {
+			// Select the value of quoted fields without the quotes
+			text := strings.TrimSpace(lines[i][segment[0]:segment[1]])
+			if len(text) >= 2 && text[0] == '"' && text[len(text)-1] == '"' {
+				offset := strings.Index(lines[i][segment[0]:], `"`)
+				segment = [2]int{segment[0] + offset + 1, segment[0] + offset + len(text) - 1}
+			}
+
+			if cell, ok := segmentCell(lines[i], segment, len(cells), column, i); ok {
+				row = append(row, cell)
+			}
+		}
+		if len(row) == 0 {
+			continue
+		}
+		filled += len(row)
+		if len(cells) == 0 {
+			for _, cell := range row {
+				columnPositions = append(columnPositions, cell.StartPos)
+			}
+		}
+		cells = append(cells, row)
+	}
+
+	return Table{
+		StartLine:  startLine,
+		EndLine:    endLine,
+		NumRows:    len(cells),
+		NumColumns: numColumns,
+		Confidence: delimiterConfidence(filled, (endLine-startLine+1)*numColumns),
+		Mode:       DelimiterMode,
+		Cells:      cells,
+		Metadata: &TableMetadata{
+			DetectionStrategy: ds.GetName(),
+			TokenizationMode:  DelimiterMode,
+			ColumnPositions:   columnPositions,
+			Explanation:       explicitExplanation(fmt.Sprintf("%q", delimiter)),
+		},
+	}
+}
+
+// GetName returns the strategy name
+func (ds *DelimiterStrategy) GetName() string {
+	return "delimiter"
+}
+
+// GetConfiguration returns the strategy configuration
+func (ds *DelimiterStrategy) GetConfiguration() DetectionConfig {
+	return ds.config
+}
+
+// delimiterConfidence ranges from 0.5 for tables of empty fields to 0.9 for
+// tables without any
+func delimiterConfidence(filled, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 0.5 + 0.4*float64(filled)/float64(total)
+}
+
+// delimitedFields returns the byte ranges of the fields of line, delimiters
+// inside double quotes do not separate fields. Blank lines and lines with an
+// unterminated quote, whose fields can't be told apart, have no fields
+func delimitedFields(line string, delimiter byte) [][2]int {
+	if strings.TrimSpace(line) == "" {
+		return nil
+	}
+
+	var fields [][2]int
+	start := 0
+	quoted := false
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"':
+			quoted = !quoted
+		case delimiter:
+			if !quoted {
+				fields = append(fields, [2]int{start, i})
+				start = i + 1
+			}
+		}
+	}
+	if quoted {
+		return nil
+	}
+	return append(fields, [2]int{start, len(line)})
+}
+
+// isProse reports whether the commas of lines read like sentences, where
+// every comma is followed by a space
+func isProse(lines []string, delimiter byte) bool {
+	if delimiter != ',' {
+		return false
+	}
+	for _, line := range lines {
+		fields := delimitedFields(line, delimiter)
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(line[field[0]:field[1]], " ") {
+				return false
+			}
+		}
+	}
+	return true
+}
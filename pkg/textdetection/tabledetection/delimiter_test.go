@@ -0,0 +1,168 @@
+package tabledetection
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDelimiterTables(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		startLine int
+		endLine   int
+		rows      [][]string
+	}{
+		{
+			name: "csv",
+			input: `
+$ cat users.csv
+id,name,email
+1,alice,alice@example.com
+2,bob,bob@example.com
+$`,
+			startLine: 1,
+			endLine:   3,
+			rows: [][]string{
+				{"id", "name", "email"},
+				{"1", "alice", "alice@example.com"},
+				{"2", "bob", "bob@example.com"},
+			},
+		},
+		{
+			name:      "tsv",
+			input:     "id\tname\trole\n1\talice\tadmin\n2\tbob\tuser",
+			startLine: 0,
+			endLine:   2,
+			rows:      [][]string{{"id", "name", "role"}, {"1", "alice", "admin"}, {"2", "bob", "user"}},
+		},
+		{
+			name: "quoted fields",
+			input: `
+name,address,zip
+alice,"1 Main St, Springfield",12345
+bob,,54321`,
+			startLine: 0,
+			endLine:   2,
+			rows: [][]string{
+				{"name", "address", "zip"},
+				{"alice", "1 Main St, Springfield", "12345"},
+				{"bob", "54321"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := strings.Split(strings.TrimPrefix(tt.input, "\n"), "\n")
+			tables, err := NewDetector().DetectTables(lines)
+			if err != nil {
+				t.Fatalf("Detector returned error: %v", err)
+			}
+			if len(tables) != 1 || tables[0].Mode != DelimiterMode {
+				t.Fatalf("Expected a delimited table, got %v", tables)
+			}
+
+			table := tables[0]
+			if table.StartLine != tt.startLine || table.EndLine != tt.endLine {
+				t.Errorf("Expected lines %d-%d, got %d-%d", tt.startLine, tt.endLine, table.StartLine, table.EndLine)
+			}
+			if table.NumRows != len(tt.rows) {
+				t.Fatalf("Expected %d rows, got %d", len(tt.rows), table.NumRows)
+			}
+			for r, want := range tt.rows {
+				got, _ := table.GetRowTexts(r)
+				if strings.Join(got, "|") != strings.Join(want, "|") {
+					t.Errorf("Expected row %d to be %v, got %v", r, want, got)
+				}
+				for _, cell := range table.Cells[r] {
+					if line := lines[cell.LineIndex]; line[cell.StartPos:cell.EndPos+1] != cell.Text {
+						t.Errorf("Expected %q at [%d-%d], got %q", cell.Text, cell.StartPos, cell.EndPos, line[cell.StartPos:cell.EndPos+1])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDelimiterTablesRejected(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name: "prose",
+			input: `
+First, open the file, then save it.
+Next, close it, then exit.
+Finally, rest, then repeat.`,
+		},
+		{
+			name: "inconsistent fields",
+			input: `
+a,b,c
+d,e
+f,g,h,i`,
+		},
+		{
+			name: "unterminated quotes",
+			input: `
+say "a, b
+and c, d
+or e, f"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := strings.Split(strings.TrimPrefix(tt.input, "\n"), "\n")
+			tables, err := NewDelimiterStrategy(DefaultConfig()).DetectTables(lines)
+			if err != nil {
+				t.Fatalf("Strategy returned error: %v", err)
+			}
+			if len(tables) != 0 {
+				t.Errorf("Expected no delimited table, got %v", tables)
+			}
+		})
+	}
+}
+
+func TestDelimiterTablesConfidence(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected float64
+	}{
+		{"filled", "id,name\n1,alice\n2,bob", 0.9},
+		{"empty fields", "id,name\n1,\n2,", 0.5 + 0.4*4/6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tables, err := NewDelimiterStrategy(DefaultConfig()).DetectTables(strings.Split(tt.input, "\n"))
+			if err != nil {
+				t.Fatalf("Strategy returned error: %v", err)
+			}
+			if len(tables) != 1 {
+				t.Fatalf("Expected a delimited table, got %v", tables)
+			}
+			if got := tables[0].Confidence; got < tt.expected-1e-9 || got > tt.expected+1e-9 {
+				t.Errorf("Expected confidence %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDelimiterTablesBelowThreshold(t *testing.T) {
+	lines := []string{"id,name", "1,", "2,"}
+
+	tables, err := NewDetector(WithConfidenceThresholdOption(0.8)).DetectTables(lines)
+	if err != nil {
+		t.Fatalf("Detector returned error: %v", err)
+	}
+	for _, table := range tables {
+		if table.Mode == DelimiterMode {
+			t.Errorf("Expected no delimited table below the threshold, got %v", table)
+		}
+	}
+}
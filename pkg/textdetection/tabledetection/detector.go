@@ -1,7 +1,10 @@
 package tabledetection
 
 import (
+	"context"
 	"fmt"
+	"slices"
+	"sort"
 )
 
 // ============================================================================
@@ -11,9 +14,15 @@ import (
 // Detector provides the main interface for table detection with improved API
 type Detector struct {
 	config     DetectionConfig
+	explicit   []DetectionStrategy // Strategies for tables with explicit delimiters
 	strategies []DetectionStrategy
 	analyzer   *TableAnalyzer
 	extractor  *WordExtractor
+
+	// Streaming state of Feed and Flush
+	pending      []string  // Lines since the last table boundary
+	pendingStart int       // Input line number of pending[0]
+	continuation *Detector // Detects tables of two lines for terminates
 }
 
 // NewDetector creates a new detector with the specified configuration
@@ -82,8 +91,22 @@ func WithTokenizationModeOption(mode TokenizationMode) DetectorOption {
 	}
 }
 
+// WithMaxBufferedLinesOption sets the number of lines Feed buffers before
+// detecting tables without waiting for a blank line
+func WithMaxBufferedLinesOption(lines int) DetectorOption {
+	return func(config *DetectionConfig) {
+		config.MaxBufferedLines = lines
+	}
+}
+
 // initializeStrategies sets up detection strategies
 func (d *Detector) initializeStrategies() {
+	// Tables with explicit delimiters are unambiguous and detected before
+	// the others, markdown first as its delimiter row is also a border rule
+	d.explicit = append(d.explicit, NewMarkdownStrategy(d.config))
+	d.explicit = append(d.explicit, NewBorderStrategy(d.config))
+	d.explicit = append(d.explicit, NewDelimiterStrategy(d.config))
+
 	// Add dual-round strategy as the primary strategy
 	d.strategies = append(d.strategies, NewDualRoundStrategy(d.config))
 
@@ -92,20 +115,62 @@ func (d *Detector) initializeStrategies() {
 	d.strategies = append(d.strategies, NewSingleRoundStrategy(d.config, MultiSpaceMode))
 }
 
-// DetectTables implements the main detection interface
+// DetectTables implements the main detection interface. Lines may contain
+// ANSI escape sequences, as captured with `tmux capture-pane -e`, tables are
+// laid out on the text without them and cell positions index the original
+// lines
 func (d *Detector) DetectTables(lines []string) ([]Table, error) {
+	return d.DetectTablesContext(context.Background(), lines)
+}
+
+// DetectTablesContext is DetectTables stopping with the error of ctx once it
+// is done, checked between lines and blocks of lines
+func (d *Detector) DetectTablesContext(ctx context.Context, lines []string) ([]Table, error) {
 	if len(lines) < d.config.MinLines {
 		return nil, nil
 	}
 
+	if plain, offsets := stripANSI(lines); offsets != nil {
+		// Escapes that aren't complete sequences stay in the plain lines,
+		// they are detected on as they are rather than stripped again
+		tables, err := d.detectTables(ctx, plain)
+		restoreANSI(tables, offsets)
+		return tables, err
+	}
+	return d.detectTables(ctx, lines)
+}
+
+// detectTables is DetectTablesContext on lines without escape sequences
+func (d *Detector) detectTables(ctx context.Context, lines []string) ([]Table, error) {
 	var allTables []Table
+
+	// Tables with explicit delimiters are kept when confident enough, the
+	// following strategies only look at the remaining lines
+	for _, strategy := range d.explicit {
+		tables, err := detectWithContext(ctx, strategy, lines)
+		if err != nil {
+			return nil, err
+		}
+		tables = slices.DeleteFunc(tables, func(table Table) bool {
+			return table.Confidence < d.config.ConfidenceThreshold
+		})
+		for i := range tables {
+			d.enhanceTableWithMetadata(&tables[i], lines, strategy)
+		}
+		allTables = append(allTables, tables...)
+		lines = maskTables(lines, tables)
+	}
+
 	var bestStrategy DetectionStrategy
 	var bestResults []Table
 	highestConfidence := 0.0
 
 	// Try each strategy and keep the best results
 	for _, strategy := range d.strategies {
-		tables, err := strategy.DetectTables(lines)
+		tables, err := detectWithContext(ctx, strategy, lines)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		if err != nil {
 			continue
 		}
@@ -131,9 +196,37 @@ func (d *Detector) DetectTables(lines []string) ([]Table, error) {
 		allTables = append(allTables, bestResults...)
 	}
 
+	sort.SliceStable(allTables, func(i, j int) bool {
+		return allTables[i].StartLine < allTables[j].StartLine
+	})
 	return allTables, nil
 }
 
+// detectWithContext runs strategy, through DetectTablesContext when it
+// supports cancellation
+func detectWithContext(ctx context.Context, strategy DetectionStrategy, lines []string) ([]Table, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if strategy, ok := strategy.(ContextDetectionStrategy); ok {
+		return strategy.DetectTablesContext(ctx, lines)
+	}
+	return strategy.DetectTables(lines)
+}
+
+// maskTables returns a copy of lines with the lines of tables blanked out,
+// keeping the line numbers of the rest
+func maskTables(lines []string, tables []Table) []string {
+	masked := make([]string, len(lines))
+	copy(masked, lines)
+	for _, table := range tables {
+		for i := table.StartLine; i <= table.EndLine && i < len(masked); i++ {
+			masked[i] = ""
+		}
+	}
+	return masked
+}
+
 // enhanceTableWithMetadata adds comprehensive metadata to detected tables
 func (d *Detector) enhanceTableWithMetadata(table *Table, lines []string, strategy DetectionStrategy) {
 	if table.Metadata == nil {
@@ -142,6 +235,9 @@ func (d *Detector) enhanceTableWithMetadata(table *Table, lines []string, strate
 			TokenizationMode:  table.Mode,
 		}
 	}
+	if table.Metadata.DetectionStrategy == "" {
+		table.Metadata.DetectionStrategy = strategy.GetName()
+	}
 
 	// Calculate quality metrics if not already present
 	if table.Metadata.QualityMetrics == nil {
@@ -186,6 +282,11 @@ func NewDualRoundStrategy(baseConfig DetectionConfig) *DualRoundStrategy {
 
 // DetectTables implements DetectionStrategy interface
 func (drs *DualRoundStrategy) DetectTables(lines []string) ([]Table, error) {
+	return drs.DetectTablesContext(context.Background(), lines)
+}
+
+// DetectTablesContext implements ContextDetectionStrategy interface
+func (drs *DualRoundStrategy) DetectTablesContext(ctx context.Context, lines []string) ([]Table, error) {
 	// Use existing DualRoundDetector for the actual detection
 	detector := NewDualRoundDetector(
 		WithMinLines(drs.config.MinLines),
@@ -193,9 +294,9 @@ func (drs *DualRoundStrategy) DetectTables(lines []string) ([]Table, error) {
 		WithAlignmentThreshold(drs.config.AlignmentThreshold),
 	)
 
-	segments := detector.DetectGrids(lines)
-	if len(segments) == 0 {
-		return nil, nil
+	segments, err := detector.DetectGridsContext(ctx, lines)
+	if err != nil || len(segments) == 0 {
+		return nil, err
 	}
 
 	// Convert GridSegments to Tables
@@ -248,6 +349,11 @@ func NewSingleRoundStrategy(config DetectionConfig, mode TokenizationMode) *Sing
 
 // DetectTables implements DetectionStrategy interface
 func (srs *SingleRoundStrategy) DetectTables(lines []string) ([]Table, error) {
+	return srs.DetectTablesContext(context.Background(), lines)
+}
+
+// DetectTablesContext implements ContextDetectionStrategy interface
+func (srs *SingleRoundStrategy) DetectTablesContext(ctx context.Context, lines []string) ([]Table, error) {
 	// Use existing GridDetector for the actual detection
 	detector := NewGridDetector(
 		WithMinLines(srs.config.MinLines),
@@ -258,9 +364,9 @@ func (srs *SingleRoundStrategy) DetectTables(lines []string) ([]Table, error) {
 		WithTokenizationMode(srs.mode),
 	)
 
-	segments := detector.DetectGrids(lines)
-	if len(segments) == 0 {
-		return nil, nil
+	segments, err := detector.DetectGridsContext(ctx, lines)
+	if err != nil || len(segments) == 0 {
+		return nil, err
 	}
 
 	// Convert GridSegments to Tables
@@ -0,0 +1,83 @@
+package tabledetection
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ============================================================================
+// Confidence Explanation
+// ============================================================================
+
+// ConfidenceExplanation breaks down how the confidence of a table was
+// scored. The base score is the average score of the aligned columns, it is
+// reduced by the variance penalty, multiplied by the row consistency and
+// raised by the column and line bonuses when above 0.4. Adjustments made
+// after scoring, such as merging segments, are listed in order
+type ConfidenceExplanation struct {
+	Columns         []ColumnExplanation    `json:"columns"`
+	BaseScore       float64                `json:"base_score"`       // Average score of the aligned columns
+	AverageVariance float64                `json:"average_variance"` // Average position variance of the aligned columns
+	VariancePenalty float64                `json:"variance_penalty"` // Subtracted when the variance exceeds the maximum
+	RowConsistency  float64                `json:"row_consistency"`  // Share of rows with the most common token count
+	ColumnBonus     float64                `json:"column_bonus"`     // Added for columns beyond the minimum
+	LineBonus       float64                `json:"line_bonus"`       // Added for lines beyond the minimum
+	Adjustments     []ConfidenceAdjustment `json:"adjustments"`
+	Confidence      float64                `json:"confidence"` // Final confidence
+}
+
+// ColumnExplanation is the alignment of a single column
+type ColumnExplanation struct {
+	Position  int     `json:"position"`  // Expected column position
+	Alignment string  `json:"alignment"` // Classified alignment, empty when the position is off the classified edge
+	Score     float64 `json:"score"`     // Share of rows with a token aligned to the position
+	Variance  float64 `json:"variance"`  // Variance of the aligned token positions
+}
+
+// ConfidenceAdjustment is a change of confidence made after scoring
+type ConfidenceAdjustment struct {
+	Reason     string  `json:"reason"`
+	Confidence float64 `json:"confidence"` // Confidence after the adjustment
+}
+
+// adjust records an adjustment and applies its confidence
+func (e *ConfidenceExplanation) adjust(confidence float64, format string, args ...any) {
+	e.Adjustments = append(e.Adjustments, ConfidenceAdjustment{
+		Reason:     fmt.Sprintf(format, args...),
+		Confidence: confidence,
+	})
+	e.Confidence = confidence
+}
+
+// String formats the explanation for logs and test output
+func (e ConfidenceExplanation) String() string {
+	var b strings.Builder
+	for i, column := range e.Columns {
+		fmt.Fprintf(&b, "column %d at %d %s: score=%.3f variance=%.3f\n", i, column.Position, column.Alignment, column.Score, column.Variance)
+	}
+	fmt.Fprintf(&b, "base=%.3f variance=%.3f penalty=-%.3f consistency=x%.3f column_bonus=+%.3f line_bonus=+%.3f\n",
+		e.BaseScore, e.AverageVariance, e.VariancePenalty, e.RowConsistency, e.ColumnBonus, e.LineBonus)
+	for _, adjustment := range e.Adjustments {
+		fmt.Fprintf(&b, "%s: %.3f\n", adjustment.Reason, adjustment.Confidence)
+	}
+	fmt.Fprintf(&b, "confidence=%.3f", e.Confidence)
+	return b.String()
+}
+
+// explicitExplanation explains the confidence of tables whose columns are
+// delimited explicitly, which is never in doubt
+func explicitExplanation(delimiters string) *ConfidenceExplanation {
+	e := &ConfidenceExplanation{}
+	e.adjust(1.0, "columns delimited by %s", delimiters)
+	return e
+}
+
+// Explain returns how the confidence of the table was scored. Tables built
+// by hand or by strategies that record no breakdown only report their
+// confidence
+func (t Table) Explain() ConfidenceExplanation {
+	if t.Metadata != nil && t.Metadata.Explanation != nil {
+		return *t.Metadata.Explanation
+	}
+	return ConfidenceExplanation{Confidence: t.Confidence}
+}
@@ -0,0 +1,73 @@
+package tabledetection
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		columns     int
+		adjustments bool
+	}{
+		{
+			name: "aligned",
+			input: `
+Name    Age  City
+John    25   NYC
+Alice   30   LA
+Bob     35   SF`,
+			columns: 3,
+		},
+		{
+			name: "bordered",
+			input: `
++----+-------+
+| id | name  |
++----+-------+
+|  1 | alice |
+|  2 | bob   |
++----+-------+`,
+			adjustments: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := strings.Split(strings.TrimPrefix(tt.input, "\n"), "\n")
+			tables, err := NewDetector().DetectTables(lines)
+			if err != nil {
+				t.Fatalf("Detector returned error: %v", err)
+			}
+			if len(tables) != 1 {
+				t.Fatalf("Expected 1 table, got %d", len(tables))
+			}
+
+			table := tables[0]
+			explanation := table.Explain()
+			if explanation.Confidence != table.Confidence {
+				t.Errorf("Expected confidence %.3f, got %.3f\n%s", table.Confidence, explanation.Confidence, explanation)
+			}
+			if len(explanation.Columns) != tt.columns {
+				t.Errorf("Expected %d explained columns, got %d\n%s", tt.columns, len(explanation.Columns), explanation)
+			}
+			if (len(explanation.Adjustments) > 0) != tt.adjustments {
+				t.Errorf("Expected adjustments %v, got %v", tt.adjustments, explanation.Adjustments)
+			}
+			for _, column := range explanation.Columns {
+				if column.Score <= 0 || column.Score > 1 {
+					t.Errorf("Expected column score in (0, 1], got %.3f", column.Score)
+				}
+			}
+		})
+	}
+}
+
+func TestExplainWithoutBreakdown(t *testing.T) {
+	table := Table{Confidence: 0.9}
+	if got := table.Explain().Confidence; got != 0.9 {
+		t.Errorf("Expected confidence 0.9, got %.3f", got)
+	}
+}
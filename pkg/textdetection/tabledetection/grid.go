@@ -1,6 +1,7 @@
 package tabledetection
 
 import (
+	"context"
 	"sort"
 	"strings"
 )
@@ -13,6 +14,12 @@ const (
 	SingleSpaceMode TokenizationMode = iota
 	// MultiSpaceMode splits only on 2+ consecutive spaces
 	MultiSpaceMode
+	// BorderMode splits on explicit column borders such as `|` and `│`
+	BorderMode
+	// MarkdownMode splits on the pipes of markdown tables
+	MarkdownMode
+	// DelimiterMode splits on the commas or tabs of CSV and TSV data
+	DelimiterMode
 )
 
 // Public types and interfaces
@@ -26,6 +33,9 @@ type GridSegment struct {
 	Confidence float64          // Confidence score of this being a grid (0.0 to 1.0)
 	Mode       TokenizationMode // Which tokenization mode was used
 	Metadata   *SegmentMetadata // Additional information about this segment
+
+	// Explanation breaks down how Confidence was scored
+	Explanation *ConfidenceExplanation
 }
 
 // SegmentMetadata contains detailed information about how a segment was detected
@@ -38,12 +48,32 @@ type SegmentMetadata struct {
 
 // ColumnAlignment contains alignment information for a single column
 type ColumnAlignment struct {
-	Position    int     // Column start position
+	Position    int     // Position of the aligned edge
 	Width       int     // Average column width
 	Alignment   string  // "left", "right", "center"
 	Consistency float64 // How consistent this column's alignment is (0.0-1.0)
 }
 
+// Column alignments
+const (
+	AlignLeft   = "left"
+	AlignRight  = "right"
+	AlignCenter = "center"
+)
+
+// alignedEdge returns the position of the token edge a column with the
+// given alignment lines up on
+func alignedEdge(token Token, alignment string) int {
+	switch alignment {
+	case AlignRight:
+		return token.End
+	case AlignCenter:
+		return (token.Start + token.End) / 2
+	default:
+		return token.Start
+	}
+}
+
 // DualRoundDetector performs two-round grid detection with different tokenization strategies
 type DualRoundDetector struct {
 	firstRoundDetector  *GridDetector
@@ -148,13 +178,23 @@ func NewDualRoundDetector(opts ...GridOption) *DualRoundDetector {
 
 // DetectGrids analyzes text lines and returns segments that appear to have grid-like alignment
 func (gd *GridDetector) DetectGrids(lines []string) []GridSegment {
+	segments, _ := gd.DetectGridsContext(context.Background(), lines)
+	return segments
+}
+
+// DetectGridsContext is DetectGrids stopping with the error of ctx once it is
+// done
+func (gd *GridDetector) DetectGridsContext(ctx context.Context, lines []string) ([]GridSegment, error) {
 	if len(lines) < gd.minLines {
-		return nil
+		return nil, nil
 	}
 
 	// Tokenize each line and build layout vectors
 	analyzer := newLayoutAnalyzer(gd)
-	lineData := analyzer.analyzeLines(lines)
+	lineData, err := analyzer.analyzeLinesContext(ctx, lines)
+	if err != nil {
+		return nil, err
+	}
 
 	// Find candidate blocks using sliding window
 	blockFinder := newBlockFinder(gd)
@@ -164,22 +204,38 @@ func (gd *GridDetector) DetectGrids(lines []string) []GridSegment {
 	processor := newBlockProcessor(gd)
 	var segments []GridSegment
 	for _, block := range candidateBlocks {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		if segment := processor.processBlock(block, lineData); segment != nil {
 			segments = append(segments, *segment)
 		}
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	// Intelligent segment merging and optimization
 	segments = gd.mergeConsecutiveSegments(segments, lines)
 	segments = gd.optimizeSegments(segments, lines)
 
-	return segments
+	return segments, nil
 }
 
 // DetectGrids performs dual-round grid detection and returns the optimal results
 func (drd *DualRoundDetector) DetectGrids(lines []string) []GridSegment {
+	segments, _ := drd.DetectGridsContext(context.Background(), lines)
+	return segments
+}
+
+// DetectGridsContext is DetectGrids stopping with the error of ctx once it is
+// done
+func (drd *DualRoundDetector) DetectGridsContext(ctx context.Context, lines []string) ([]GridSegment, error) {
 	// First round: Multi-space tokenization
-	firstRoundResults := drd.firstRoundDetector.DetectGrids(lines)
+	firstRoundResults, err := drd.firstRoundDetector.DetectGridsContext(ctx, lines)
+	if err != nil {
+		return nil, err
+	}
 	for i := range firstRoundResults {
 		firstRoundResults[i].Mode = MultiSpaceMode
 		if firstRoundResults[i].Metadata == nil {
@@ -190,7 +246,10 @@ func (drd *DualRoundDetector) DetectGrids(lines []string) []GridSegment {
 	}
 
 	// Second round: Single-space tokenization
-	secondRoundResults := drd.secondRoundDetector.DetectGrids(lines)
+	secondRoundResults, err := drd.secondRoundDetector.DetectGridsContext(ctx, lines)
+	if err != nil {
+		return nil, err
+	}
 	for i := range secondRoundResults {
 		secondRoundResults[i].Mode = SingleSpaceMode
 		if secondRoundResults[i].Metadata == nil {
@@ -201,7 +260,7 @@ func (drd *DualRoundDetector) DetectGrids(lines []string) []GridSegment {
 	}
 
 	// Merge results using the configured strategy
-	return drd.mergeStrategy.MergeResults(firstRoundResults, secondRoundResults, lines)
+	return drd.mergeStrategy.MergeResults(firstRoundResults, secondRoundResults, lines), nil
 }
 
 // MergeResults implements the default strategy for combining detection results
@@ -418,6 +477,8 @@ func (dms *DefaultMergeStrategy) hasGoodGranularity(segment GridSegment, origina
 // Internal types and structures
 
 // Token represents a single token with its position information
+// Token is a word or compound word of a line. Start and End are inclusive
+// display columns
 type Token struct {
 	Text  string
 	Start int
@@ -467,22 +528,44 @@ func newLayoutAnalyzer(detector *GridDetector) *layoutAnalyzer {
 	}
 }
 
+// analyzeLines tokenizes the lines by display column, so columns of wide
+// characters line up as they do on screen. Token texts are taken from the
+// original lines
 func (la *layoutAnalyzer) analyzeLines(lines []string) []LineData {
+	lineData, _ := la.analyzeLinesContext(context.Background(), lines)
+	return lineData
+}
+
+// analyzeLinesContext is analyzeLines stopping with the error of ctx once it
+// is done
+func (la *layoutAnalyzer) analyzeLinesContext(ctx context.Context, lines []string) ([]LineData, error) {
 	lineData := make([]LineData, len(lines))
+	displayLines := make([]string, len(lines))
+	for i, line := range lines {
+		displayLines[i] = displayLine(line)
+	}
 
 	for i, line := range lines {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		if la.shouldSkipLine(line) {
 			continue
 		}
 
-		tokens := la.tokenizer.tokenize(lines, i)
+		tokens := la.tokenizer.tokenize(displayLines, i)
+		if displayLines[i] != line {
+			for j := range tokens {
+				tokens[j].Text = displayText(line, tokens[j].Start, tokens[j].End)
+			}
+		}
 		lineData[i] = LineData{
 			tokens: tokens,
 			layout: la.buildLayout(tokens),
 		}
 	}
 
-	return lineData
+	return lineData, nil
 }
 
 func (la *layoutAnalyzer) shouldSkipLine(line string) bool {
@@ -760,7 +843,8 @@ func (bp *blockProcessor) processBlock(block CandidateBlock, lineData []LineData
 	}
 
 	// Calculate confidence
-	confidence := bp.scorer.calculateConfidence(blockTokens, columns)
+	explanation := bp.scorer.explainConfidence(blockTokens, columns)
+	confidence := explanation.Confidence
 	if confidence < bp.detector.confidenceThreshold {
 		return nil
 	}
@@ -776,6 +860,10 @@ func (bp *blockProcessor) processBlock(block CandidateBlock, lineData []LineData
 		EndLine:    block.EndLine,
 		Columns:    columns,
 		Confidence: confidence,
+		Metadata: &SegmentMetadata{
+			AlignmentData: bp.detectAlignments(blockTokens),
+		},
+		Explanation: explanation,
 	}
 }
 
@@ -813,27 +901,58 @@ func (bp *blockProcessor) detectColumns(blockTokens [][]Token) []int {
 }
 
 func (bp *blockProcessor) findOptimalPosition(blockTokens [][]Token, col int) int {
-	leftPositions := []int{}
-	rightPositions := []int{}
+	alignment, ok := bp.classifyColumn(blockTokens, col)
+	if !ok {
+		return -1
+	}
+	return alignment.Position
+}
+
+// detectAlignments classifies the alignment of every column of the block
+func (bp *blockProcessor) detectAlignments(blockTokens [][]Token) []ColumnAlignment {
+	var alignments []ColumnAlignment
+	for col := 0; ; col++ {
+		alignment, ok := bp.classifyColumn(blockTokens, col)
+		if !ok {
+			return alignments
+		}
+		alignments = append(alignments, alignment)
+	}
+}
+
+// classifyColumn finds the edge the tokens of a column line up on. Text is
+// usually left-aligned and numbers right-aligned, so left wins ties, then
+// right. Columns with less than two tokens cannot be classified
+func (bp *blockProcessor) classifyColumn(blockTokens [][]Token, col int) (ColumnAlignment, bool) {
+	positions := map[string][]int{}
+	width := 0
 
 	for _, tokens := range blockTokens {
 		if col < len(tokens) {
-			leftPositions = append(leftPositions, tokens[col].Start)
-			rightPositions = append(rightPositions, tokens[col].End)
+			for _, alignment := range []string{AlignLeft, AlignRight, AlignCenter} {
+				positions[alignment] = append(positions[alignment], alignedEdge(tokens[col], alignment))
+			}
+			width += tokens[col].End - tokens[col].Start + 1
 		}
 	}
 
-	if len(leftPositions) < 2 {
-		return -1
+	count := len(positions[AlignLeft])
+	if count < 2 {
+		return ColumnAlignment{}, false
 	}
 
-	leftScore := bp.calculatePositionScore(leftPositions)
-	rightScore := bp.calculatePositionScore(rightPositions)
-
-	if leftScore >= rightScore {
-		return bp.getMedianPosition(leftPositions)
+	best := ColumnAlignment{Alignment: AlignLeft, Consistency: -1}
+	for _, alignment := range []string{AlignLeft, AlignRight, AlignCenter} {
+		if score := bp.calculatePositionScore(positions[alignment]); score > best.Consistency {
+			best = ColumnAlignment{
+				Position:    bp.getMedianPosition(positions[alignment]),
+				Width:       width / count,
+				Alignment:   alignment,
+				Consistency: score,
+			}
+		}
 	}
-	return bp.getMedianPosition(rightPositions)
+	return best, true
 }
 
 func (bp *blockProcessor) calculatePositionScore(positions []int) float64 {
@@ -884,18 +1003,40 @@ func newConfidenceScorer(detector *GridDetector) *confidenceScorer {
 }
 
 func (cs *confidenceScorer) calculateConfidence(blockTokens [][]Token, columns []int) float64 {
+	return cs.explainConfidence(blockTokens, columns).Confidence
+}
+
+// explainConfidence scores the alignment of the columns and records how
+// each part contributed to the confidence
+func (cs *confidenceScorer) explainConfidence(blockTokens [][]Token, columns []int) *ConfidenceExplanation {
+	explanation := &ConfidenceExplanation{}
 	if len(blockTokens) == 0 || len(columns) == 0 {
-		return 0.0
+		return explanation
 	}
 
 	// Calculate alignment consistency for each column
 	totalScore := 0.0
 	validColumns := 0
 	alignmentVariances := []float64{}
+	processor := newBlockProcessor(cs.detector)
 
 	for colIdx, expectedPos := range columns {
-		score := cs.calculateColumnScore(blockTokens, colIdx, expectedPos)
-		variance := cs.calculateColumnVariance(blockTokens, colIdx, expectedPos)
+		// Score against the classified edge when the column is positioned on
+		// it, columns from merged or optimized segments may not be
+		alignment := ""
+		if classified, ok := processor.classifyColumn(blockTokens, colIdx); ok &&
+			abs(classified.Position-expectedPos) <= cs.detector.maxColumnVariance {
+			alignment = classified.Alignment
+		}
+
+		score := cs.calculateColumnScore(blockTokens, colIdx, expectedPos, alignment)
+		variance := cs.calculateColumnVariance(blockTokens, colIdx, expectedPos, alignment)
+		explanation.Columns = append(explanation.Columns, ColumnExplanation{
+			Position:  expectedPos,
+			Alignment: alignment,
+			Score:     score,
+			Variance:  variance,
+		})
 
 		if score > 0 {
 			totalScore += score
@@ -905,10 +1046,11 @@ func (cs *confidenceScorer) calculateConfidence(blockTokens [][]Token, columns [
 	}
 
 	if validColumns == 0 {
-		return 0.0
+		return explanation
 	}
 
 	confidence := totalScore / float64(validColumns)
+	explanation.BaseScore = confidence
 
 	// Penalize high alignment variance (weak alignment)
 	avgVariance := 0.0
@@ -916,36 +1058,44 @@ func (cs *confidenceScorer) calculateConfidence(blockTokens [][]Token, columns [
 		avgVariance += variance
 	}
 	avgVariance /= float64(len(alignmentVariances))
+	explanation.AverageVariance = avgVariance
 
 	// Strong penalty for high variance - weak alignment should get low confidence
 	maxAcceptableVariance := float64(cs.detector.maxColumnVariance)
 	if avgVariance > maxAcceptableVariance {
 		variancePenalty := min(0.5, (avgVariance-maxAcceptableVariance)/(maxAcceptableVariance*2))
 		confidence -= variancePenalty
+		explanation.VariancePenalty = variancePenalty
 	}
 
 	// Additional penalty for inconsistent column counts across rows
 	consistency := cs.calculateRowConsistency(blockTokens)
 	confidence *= consistency
+	explanation.RowConsistency = consistency
 
 	// Apply bonuses only if base confidence is reasonable
 	if confidence > 0.4 {
-		confidence += cs.calculateColumnBonus(len(columns))
-		confidence += cs.calculateLineBonus(len(blockTokens))
+		explanation.ColumnBonus = cs.calculateColumnBonus(len(columns))
+		explanation.LineBonus = cs.calculateLineBonus(len(blockTokens))
+		confidence += explanation.ColumnBonus
+		confidence += explanation.LineBonus
 	}
 
-	return max(0.0, min(1.0, confidence))
+	explanation.Confidence = max(0.0, min(1.0, confidence))
+	return explanation
 }
 
-func (cs *confidenceScorer) calculateColumnScore(blockTokens [][]Token, colIdx int, expectedPos int) float64 {
+func (cs *confidenceScorer) calculateColumnScore(blockTokens [][]Token, colIdx int, expectedPos int, alignment string) float64 {
 	alignedLines := 0
 
 	for _, tokens := range blockTokens {
 		if colIdx < len(tokens) {
 			leftDiff := abs(tokens[colIdx].Start - expectedPos)
 			rightDiff := abs(tokens[colIdx].End - expectedPos)
+			edgeDiff := abs(alignedEdge(tokens[colIdx], alignment) - expectedPos)
 
-			if leftDiff <= cs.detector.maxColumnVariance || rightDiff <= cs.detector.maxColumnVariance {
+			if leftDiff <= cs.detector.maxColumnVariance || rightDiff <= cs.detector.maxColumnVariance ||
+				(alignment == AlignCenter && edgeDiff <= cs.detector.maxColumnVariance) {
 				alignedLines++
 			}
 		}
@@ -954,11 +1104,16 @@ func (cs *confidenceScorer) calculateColumnScore(blockTokens [][]Token, colIdx i
 	return float64(alignedLines) / float64(len(blockTokens))
 }
 
-func (cs *confidenceScorer) calculateColumnVariance(blockTokens [][]Token, colIdx int, expectedPos int) float64 {
+func (cs *confidenceScorer) calculateColumnVariance(blockTokens [][]Token, colIdx int, expectedPos int, alignment string) float64 {
 	var positions []int
 
 	for _, tokens := range blockTokens {
 		if colIdx < len(tokens) {
+			if alignment != "" {
+				positions = append(positions, alignedEdge(tokens[colIdx], alignment))
+				continue
+			}
+
 			// Use the better aligned position (left or right)
 			leftDiff := abs(tokens[colIdx].Start - expectedPos)
 			rightDiff := abs(tokens[colIdx].End - expectedPos)
@@ -1151,24 +1306,28 @@ func (gd *GridDetector) mergeSegments(seg1, seg2 GridSegment, originalLines []st
 
 	processor := newBlockProcessor(gd)
 	mergedColumns := processor.detectColumns(blockTokens)
-	mergedConfidence := gd.calculateMergedConfidence(seg1, seg2, mergedColumns, blockTokens)
+	explanation := gd.explainMergedConfidence(seg1, seg2, mergedColumns, blockTokens)
 
 	return GridSegment{
 		Lines:      allLines,
 		StartLine:  seg1.StartLine,
 		EndLine:    seg2.EndLine,
 		Columns:    mergedColumns,
-		Confidence: mergedConfidence,
+		Confidence: explanation.Confidence,
 		Mode:       seg1.Mode, // Preserve the mode from first segment
-		Metadata:   &SegmentMetadata{DetectionSource: "merged"},
+		Metadata: &SegmentMetadata{
+			DetectionSource: "merged",
+			AlignmentData:   processor.detectAlignments(blockTokens),
+		},
+		Explanation: explanation,
 	}
 }
 
-// calculateMergedConfidence computes confidence for a merged segment
-func (gd *GridDetector) calculateMergedConfidence(seg1, seg2 GridSegment, columns []int, blockTokens [][]Token) float64 {
+// explainMergedConfidence computes confidence for a merged segment
+func (gd *GridDetector) explainMergedConfidence(seg1, seg2 GridSegment, columns []int, blockTokens [][]Token) *ConfidenceExplanation {
 	// Use the confidence scorer to calculate new confidence
 	scorer := newConfidenceScorer(gd)
-	baseConfidence := scorer.calculateConfidence(blockTokens, columns)
+	explanation := scorer.explainConfidence(blockTokens, columns)
 
 	// Bonus for successful merging (indicates good column alignment)
 	mergingBonus := 0.1
@@ -1177,7 +1336,12 @@ func (gd *GridDetector) calculateMergedConfidence(seg1, seg2 GridSegment, column
 	avgOriginalConfidence := (seg1.Confidence + seg2.Confidence) / 2
 
 	// Take the better of recalculated confidence or averaged confidence with bonus
-	return max(baseConfidence, avgOriginalConfidence+mergingBonus)
+	if avgOriginalConfidence+mergingBonus > explanation.Confidence {
+		explanation.adjust(avgOriginalConfidence+mergingBonus,
+			"merged segments: average of %.3f and %.3f plus %.1f merging bonus",
+			seg1.Confidence, seg2.Confidence, mergingBonus)
+	}
+	return explanation
 }
 
 // optimizeSegments refines column detection for segments that might benefit from optimization
@@ -1216,7 +1380,8 @@ func (gd *GridDetector) optimizeSegmentColumns(segment GridSegment, originalLine
 		}
 
 		scorer := newConfidenceScorer(gd)
-		baseConfidence := scorer.calculateConfidence(blockTokens, majorColumns)
+		explanation := scorer.explainConfidence(blockTokens, majorColumns)
+		baseConfidence := explanation.Confidence
 
 		// ENHANCED CONFIDENCE CALCULATION for successful column optimization
 		// Apply significant bonus for column count reduction and structural improvement
@@ -1244,10 +1409,15 @@ func (gd *GridDetector) optimizeSegmentColumns(segment GridSegment, originalLine
 
 		// Apply optimization if confidence improves OR if we get significant structural improvement
 		if newConfidence > acceptanceThreshold {
+			explanation.adjust(newConfidence, "reduced %d columns to %d: optimization bonus %.3f",
+				len(segment.Columns), len(majorColumns), optimizationBonus)
 			segment.Columns = majorColumns
 			segment.Confidence = newConfidence
+			segment.Explanation = explanation
 			if segment.Metadata != nil {
 				segment.Metadata.DetectionSource = "optimized"
+				// The major columns span several of the classified ones
+				segment.Metadata.AlignmentData = nil
 			}
 		}
 	}
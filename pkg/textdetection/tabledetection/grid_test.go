@@ -1,6 +1,9 @@
 package tabledetection
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"strings"
 	"testing"
 )
@@ -181,6 +184,40 @@ Bob     22   SF`), "\n"),
 	}
 }
 
+// TestWideCharacterTable tests that columns after CJK file names line up by
+// display width rather than by bytes
+func TestWideCharacterTable(t *testing.T) {
+	input := strings.Split(strings.TrimSpace(`
+NAME              SIZE  MODE
+下载文件夹目录    1024  rw
+photos            2048  rw
+测试用例集合       512  ro
+src                128  rw`), "\n")
+
+	tables, err := NewDetector().DetectTables(input)
+	if err != nil {
+		t.Fatalf("Detector returned error: %v", err)
+	}
+	if len(tables) != 1 {
+		t.Fatalf("Expected 1 table, got %d", len(tables))
+	}
+
+	validateTable(t, tables[0], ExpectedTable{
+		StartLine:       0,
+		EndLine:         4,
+		Lines:           input,
+		Columns:         []int{0, 21, 24},
+		MinConfidence:   0.8,
+		NumRows:         5,
+		NumColumns:      3,
+		MinColumnsCount: 3,
+	}, input, 0)
+
+	if cell, err := tables[0].GetCell(1, 0); err != nil || cell.Text != "下载文件夹目录" {
+		t.Errorf("Expected cell %q, got %v (%v)", "下载文件夹目录", cell, err)
+	}
+}
+
 // TestDockerPsOutput tests detection of complex Docker PS command output
 // This test validates extraction of structured data from real-world command output
 func TestDockerPsOutput(t *testing.T) {
@@ -202,7 +239,7 @@ e354d62bbe17   postgres:latest         "docker-entrypoint.s…"   13 months ago
 				StartLine:       0,
 				EndLine:         1,
 				Lines:           input,
-				Columns:         []int{0, 15, 39, 66, 82, 94, 189}, // Column positions for visual columns
+				Columns:         []int{0, 15, 39, 64, 80, 92, 187}, // Display columns, "…" takes one
 				MinConfidence:   0.5,
 				NumRows:         2,
 				NumColumns:      7, // Algorithm correctly detects 7 visual columns
@@ -296,7 +333,7 @@ e354d62bbe17   postgres:latest         "docker-entrypoint.s…"   13 months ago
 	if actualColumns == expectedVisualColumns {
 		t.Logf("SUCCESS: Detected expected %d columns", expectedVisualColumns)
 		// Validate if column positions are reasonable (with tolerance)
-		expectedColumnPositions := []int{0, 15, 39, 66, 82, 94, 189}
+		expectedColumnPositions := []int{0, 15, 39, 64, 80, 92, 187}
 		for i, expectedPos := range expectedColumnPositions {
 			if i < len(tableColumns) {
 				actualPos := tableColumns[i]
@@ -469,7 +506,7 @@ github.com/adrg/xdg v0.5.3/go.mod h1:nlTsY+NNiCBGCK2tpm09vRqfVzrc2fLmXGpBLF0zlTQ
 aa145ac35bbc   mysql:latest      "docker-entrypoint.s…"   13 months ago   Up 2 days
 e354d62bbe17   postgres:latest   "docker-entrypoint.s…"   13 months ago   Up 2 days
 `), "\n"),
-				Columns:         []int{0, 15, 33, 60, 76}, // Column positions for visual columns
+				Columns:         []int{0, 15, 33, 58, 74}, // Display columns, "…" takes one
 				MinConfidence:   0.6,
 				NumRows:         2,
 				NumColumns:      5, // Algorithm correctly detects 5 visual columns
@@ -1360,6 +1397,120 @@ archive.zip    2023-01-13 14:22  45.3MB
 	}
 }
 
+// TestColumnAlignment tests that right-aligned numbers and centered columns
+// are classified and positioned on the edge they line up on
+func TestColumnAlignment(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		alignments []string
+		positions  []int
+	}{
+		{
+			name: "right-aligned sizes",
+			input: `
+NAME       SIZE  MODE
+a.txt         1  rw
+b.bin       100  rw
+c.iso     10000  ro
+d.tar        42  rw`,
+			alignments: []string{AlignLeft, AlignRight, AlignLeft},
+			positions:  []int{0, 14, 17},
+		},
+		{
+			name: "centered column",
+			input: `
+LEFT     MIDDLE     RIGHT
+a          x            1
+bb        yyy          22
+ccc      zzzzz        333
+dddd       w         4444`,
+			alignments: []string{AlignLeft, AlignCenter, AlignRight},
+			positions:  []int{0, 11, 24},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := strings.Split(strings.TrimPrefix(tt.input, "\n"), "\n")
+			tables, err := NewDetector().DetectTables(lines)
+			if err != nil {
+				t.Fatalf("Detector returned error: %v", err)
+			}
+			if len(tables) != 1 {
+				t.Fatalf("Expected 1 table, got %d", len(tables))
+			}
+
+			alignmentData := tables[0].Metadata.AlignmentData
+			if len(alignmentData) != len(tt.alignments) {
+				t.Fatalf("Expected %d column alignments, got %v", len(tt.alignments), alignmentData)
+			}
+			for i, alignment := range alignmentData {
+				if alignment.Alignment != tt.alignments[i] || alignment.Position != tt.positions[i] {
+					t.Errorf("Expected column %d %s at %d, got %s at %d",
+						i, tt.alignments[i], tt.positions[i], alignment.Alignment, alignment.Position)
+				}
+			}
+			if tables[0].Confidence < 0.8 {
+				t.Errorf("Expected confidence at least 0.8, got %.3f\n%s", tables[0].Confidence, tables[0].Explain())
+			}
+		})
+	}
+}
+
+// TestDetectionCanceled tests that detection stops with the error of a
+// canceled context
+func TestDetectionCanceled(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(`
+Name    Age  City
+John    25   NYC
+Alice   30   LA`), "\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if tables, err := NewDetector().DetectTablesContext(ctx, lines); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from Detector, got %v and %d tables", err, len(tables))
+	}
+	if segments, err := NewDualRoundDetector().DetectGridsContext(ctx, lines); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from DualRoundDetector, got %v and %d segments", err, len(segments))
+	}
+	if tables, err := NewDetector().DetectTablesContext(context.Background(), lines); err != nil || len(tables) != 1 {
+		t.Errorf("Expected 1 table, got %d (%v)", len(tables), err)
+	}
+}
+
+func BenchmarkDualRoundDetector(b *testing.B) {
+	dockerPS := []string{"CONTAINER ID   IMAGE                      COMMAND                  CREATED        STATUS                   PORTS                  NAMES"}
+	for i := range 100 {
+		dockerPS = append(dockerPS, fmt.Sprintf("%012x   registry.example.com/app:%d.%d   \"/entrypoint.sh sh\"     %2d hours ago   Up %2d hours (healthy)   0.0.0.0:%d->80/tcp   app_%d",
+			0x5386a67b0f15+i*7919, i%4, i%10, i%24, i%24, 8000+i, i))
+	}
+	var lsOutput []string
+	for i := range 200 {
+		lsOutput = append(lsOutput, fmt.Sprintf("-rw-r--r--  1 dev  staff  %7d Oct %2d 10:%02d file_%d.go",
+			i*1031%1000000, 1+i%28, i%60, i))
+	}
+
+	fixtures := []struct {
+		name  string
+		lines []string
+	}{
+		{"docker_ps", dockerPS},
+		{"ls", lsOutput},
+	}
+	for _, fixture := range fixtures {
+		b.Run(fixture.name, func(b *testing.B) {
+			detector := NewDualRoundDetector()
+
+			b.ReportAllocs()
+			for b.Loop() {
+				detector.DetectGrids(fixture.lines)
+			}
+		})
+	}
+}
+
 // Helper types for cell validation
 type ExpectedCell struct {
 	Text     string
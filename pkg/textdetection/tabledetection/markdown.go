@@ -0,0 +1,194 @@
+package tabledetection
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// ============================================================================
+// Markdown Strategy Implementation
+// ============================================================================
+
+// markdownDelimiterCell matches a cell of the delimiter row, such as `---`,
+// `:---` or `:---:`
+var markdownDelimiterCell = regexp.MustCompile(`^\s*:?-+:?\s*$`)
+
+// MarkdownStrategy detects GitHub-flavored markdown tables: a header row,
+// a delimiter row like `---|:---:` and the body rows up to the first line
+// without a pipe. The outer pipes are optional and escaped pipes or pipes
+// in code spans do not separate cells
+type MarkdownStrategy struct {
+	config DetectionConfig
+}
+
+// NewMarkdownStrategy creates a new markdown table detection strategy
+func NewMarkdownStrategy(config DetectionConfig) *MarkdownStrategy {
+	strategyConfig := config
+	strategyConfig.TokenizationMode = MarkdownMode
+
+	return &MarkdownStrategy{config: strategyConfig}
+}
+
+// DetectTables implements DetectionStrategy interface
+func (ms *MarkdownStrategy) DetectTables(lines []string) ([]Table, error) {
+	var tables []Table
+
+	for i := 0; i+1 < len(lines); i++ {
+		header := markdownSegments(lines[i])
+		if len(header) == 0 || !isMarkdownDelimiter(lines[i+1], len(header)) {
+			continue
+		}
+
+		end := i + 1
+		for end+1 < len(lines) && strings.TrimSpace(lines[end+1]) != "" && len(markdownSegments(lines[end+1])) > 0 {
+			end++
+		}
+
+		if table, ok := ms.buildTable(lines, i, end, len(header)); ok {
+			tables = append(tables, table)
+		}
+		i = end
+	}
+
+	return tables, nil
+}
+
+// buildTable collects the cells of the header and body rows, the delimiter
+// row is skipped. As in GitHub-flavored markdown, rows have as many cells as
+// the header, excess cells are ignored
+func (ms *MarkdownStrategy) buildTable(lines []string, startLine, endLine, numColumns int) (Table, bool) {
+	if endLine-startLine+1 < ms.config.MinLines || numColumns < ms.config.MinColumns {
+		return Table{}, false
+	}
+
+	var cells [][]Cell
+	for i := startLine; i <= endLine; i++ {
+		if i == startLine+1 {
+			continue
+		}
+
+		var row []Cell
+		for column, segment := range markdownSegments(lines[i]) {
+			if column >= numColumns {
+				break
+			}
+			if cell, ok := segmentCell(lines[i], segment, len(cells), column, i); ok {
+				row = append(row, cell)
+			}
+		}
+		if len(row) > 0 {
+			cells = append(cells, row)
+		}
+	}
+	// Rows of empty cells alone are no table
+	if len(cells) == 0 {
+		return Table{}, false
+	}
+
+	var columnPositions []int
+	for _, cell := range cells[0] {
+		columnPositions = append(columnPositions, cell.StartPos)
+	}
+
+	return Table{
+		StartLine:  startLine,
+		EndLine:    endLine,
+		NumRows:    len(cells),
+		NumColumns: numColumns,
+		Confidence: 1.0,
+		Mode:       MarkdownMode,
+		Cells:      cells,
+		Metadata: &TableMetadata{
+			DetectionStrategy: ms.GetName(),
+			TokenizationMode:  MarkdownMode,
+			ColumnPositions:   columnPositions,
+			Explanation:       explicitExplanation("markdown pipes"),
+		},
+	}, true
+}
+
+// GetName returns the strategy name
+func (ms *MarkdownStrategy) GetName() string {
+	return "markdown"
+}
+
+// GetConfiguration returns the strategy configuration
+func (ms *MarkdownStrategy) GetConfiguration() DetectionConfig {
+	return ms.config
+}
+
+// isMarkdownDelimiter reports whether line is the delimiter row of a table
+// with the given number of header cells
+func isMarkdownDelimiter(line string, columns int) bool {
+	segments := markdownSegments(line)
+	if len(segments) != columns {
+		return false
+	}
+	for _, segment := range segments {
+		if !markdownDelimiterCell.MatchString(line[segment[0]:segment[1]]) {
+			return false
+		}
+	}
+	return true
+}
+
+// markdownSegments returns the byte ranges of the cells of a table row, or
+// nil when the line has no unescaped pipe outside code spans. The optional
+// leading and trailing pipes do not start cells
+func markdownSegments(line string) [][2]int {
+	var pipes []int
+	inCode := false
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '`':
+			inCode = !inCode
+		case '|':
+			if !inCode {
+				pipes = append(pipes, i)
+			}
+		}
+	}
+	if len(pipes) == 0 {
+		return nil
+	}
+
+	var segments [][2]int
+	start := 0
+	for _, pipe := range pipes {
+		segments = append(segments, [2]int{start, pipe})
+		start = pipe + 1
+	}
+	segments = append(segments, [2]int{start, len(line)})
+
+	trimmed := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmed, "|") {
+		segments = segments[1:]
+	}
+	if strings.HasSuffix(trimmed, "|") && !strings.HasSuffix(trimmed, "\\|") && len(segments) > 0 {
+		segments = segments[:len(segments)-1]
+	}
+	return segments
+}
+
+// segmentCell trims the spaces around a cell, empty cells are not returned
+func segmentCell(line string, segment [2]int, row, column, lineIndex int) (Cell, bool) {
+	text := line[segment[0]:segment[1]]
+	trimmed := strings.TrimLeftFunc(text, unicode.IsSpace)
+	startPos := segment[0] + len(text) - len(trimmed)
+	trimmed = strings.TrimRightFunc(trimmed, unicode.IsSpace)
+	if trimmed == "" {
+		return Cell{}, false
+	}
+
+	return Cell{
+		Text:      trimmed,
+		Row:       row,
+		Column:    column,
+		LineIndex: lineIndex,
+		StartPos:  startPos,
+		EndPos:    startPos + len(trimmed) - 1,
+	}, true
+}
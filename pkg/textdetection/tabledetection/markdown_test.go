@@ -0,0 +1,121 @@
+package tabledetection
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownTables(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		startLine int
+		endLine   int
+		rows      [][]string
+	}{
+		{
+			name: "outer pipes",
+			input: `
+## Users
+
+| id | name  | role  |
+|----|:------|------:|
+| 1  | alice | admin |
+| 2  | bob   |       |
+
+Some text.`,
+			startLine: 2,
+			endLine:   5,
+			rows:      [][]string{{"id", "name", "role"}, {"1", "alice", "admin"}, {"2", "bob"}},
+		},
+		{
+			name: "no outer pipes",
+			input: `
+flag | default | description
+--- | --- | ---
+-a | qwerty | alphabet
+-f | %H | format`,
+			startLine: 0,
+			endLine:   3,
+			rows: [][]string{
+				{"flag", "default", "description"},
+				{"-a", "qwerty", "alphabet"},
+				{"-f", "%H", "format"},
+			},
+		},
+		{
+			name: "escaped pipes and code spans",
+			input: `
+| op | example | note |
+|----|---------|------|
+| or | ` + "`a || b`" + ` | a \| b |
+| extra | cells | are | ignored |`,
+			startLine: 0,
+			endLine:   3,
+			rows: [][]string{
+				{"op", "example", "note"},
+				{"or", "`a || b`", "a \\| b"},
+				{"extra", "cells", "are"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := strings.Split(strings.TrimPrefix(tt.input, "\n"), "\n")
+			tables, err := NewDetector().DetectTables(lines)
+			if err != nil {
+				t.Fatalf("Detector returned error: %v", err)
+			}
+			if len(tables) != 1 || tables[0].Mode != MarkdownMode {
+				t.Fatalf("Expected a markdown table, got %v", tables)
+			}
+
+			table := tables[0]
+			if table.StartLine != tt.startLine || table.EndLine != tt.endLine {
+				t.Errorf("Expected lines %d-%d, got %d-%d", tt.startLine, tt.endLine, table.StartLine, table.EndLine)
+			}
+			if table.NumRows != len(tt.rows) {
+				t.Fatalf("Expected %d rows without the delimiter row, got %d", len(tt.rows), table.NumRows)
+			}
+			for r, want := range tt.rows {
+				got, _ := table.GetRowTexts(r)
+				if strings.Join(got, ",") != strings.Join(want, ",") {
+					t.Errorf("Expected row %d to be %v, got %v", r, want, got)
+				}
+				for _, cell := range table.Cells[r] {
+					if line := lines[cell.LineIndex]; line[cell.StartPos:cell.EndPos+1] != cell.Text {
+						t.Errorf("Expected %q at [%d-%d], got %q", cell.Text, cell.StartPos, cell.EndPos, line[cell.StartPos:cell.EndPos+1])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestMarkdownTablesRejected(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(`
+| not | a | table |
+| --- | --- |
+$ echo a | tr a b`), "\n")
+
+	tables, err := NewMarkdownStrategy(DefaultConfig()).DetectTables(lines)
+	if err != nil {
+		t.Fatalf("Strategy returned error: %v", err)
+	}
+	if len(tables) != 0 {
+		t.Errorf("Expected no markdown table, got %v", tables)
+	}
+}
+
+func TestMarkdownTablesWithoutText(t *testing.T) {
+	lines := []string{"|  |  |", "|---|---|", "|  |  |", "|  |  |"}
+
+	tables, err := NewMarkdownStrategy(DefaultConfig()).DetectTables(lines)
+	if err != nil {
+		t.Fatalf("Strategy returned error: %v", err)
+	}
+	if len(tables) != 0 {
+		t.Errorf("Expected no markdown table without cells, got %v", tables)
+	}
+}
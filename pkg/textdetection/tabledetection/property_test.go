@@ -0,0 +1,221 @@
+package tabledetection
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/Hanaasagi/magonote/pkg/textdetection/colordetection"
+)
+
+// Header styles of the generated tables
+const (
+	headerNone = iota
+	headerUpper
+	headerTitle
+	headerBold // Upper case in bold, styled with escape sequences
+)
+
+// syntheticTable is a generated table with the structure the detector
+// should recover
+type syntheticTable struct {
+	lines   []string
+	columns []int      // Start of every column in the lines
+	cells   [][]string // Text of the cells by row, the header included
+}
+
+// randomCell returns a value without spaces, of a kind found in the
+// columns of command output
+func randomCell(r *rand.Rand, kind int) string {
+	switch kind {
+	case 0:
+		return fmt.Sprintf("%d", r.IntN(100000))
+	case 1:
+		return fmt.Sprintf("10.%d.%d.%d", r.IntN(256), r.IntN(256), r.IntN(256))
+	case 2:
+		return fmt.Sprintf("2025-%02d-%02d", 1+r.IntN(12), 1+r.IntN(28))
+	case 3:
+		return fmt.Sprintf("%x", r.Uint32())
+	default:
+		words := []string{"web", "api", "db", "cache", "worker", "queue", "proxy"}
+		return fmt.Sprintf("%s-%d", words[r.IntN(len(words))], r.IntN(100))
+	}
+}
+
+// randomTable returns a left-aligned table of random values, with random
+// padding between the columns and a random header style
+func randomTable(r *rand.Rand) syntheticTable {
+	numColumns, numRows := 2+r.IntN(5), 3+r.IntN(12)
+	header := r.IntN(4)
+
+	var rows [][]string
+	if header != headerNone {
+		names := []string{"name", "id", "address", "created", "status", "count", "owner"}
+		row := make([]string, numColumns)
+		for c := range row {
+			row[c] = names[(c+r.IntN(2))%len(names)]
+			if header == headerTitle {
+				row[c] = strings.ToUpper(row[c][:1]) + row[c][1:]
+			} else {
+				row[c] = strings.ToUpper(row[c])
+			}
+		}
+		rows = append(rows, row)
+	}
+	kinds := make([]int, numColumns)
+	for c := range kinds {
+		kinds[c] = r.IntN(5)
+	}
+	for range numRows {
+		row := make([]string, numColumns)
+		for c := range row {
+			row[c] = randomCell(r, kinds[c])
+		}
+		rows = append(rows, row)
+	}
+
+	// Columns start after the widest cell of the previous one and a gap of
+	// at least two spaces, so that they stay apart
+	columns := []int{r.IntN(3)}
+	for c := 1; c < numColumns; c++ {
+		width := 0
+		for _, row := range rows {
+			width = max(width, len(row[c-1]))
+		}
+		columns = append(columns, columns[c-1]+width+2+r.IntN(4))
+	}
+
+	table := syntheticTable{columns: columns, cells: rows}
+	for i, row := range rows {
+		var sb strings.Builder
+		width := 0
+		for c, cell := range row {
+			sb.WriteString(strings.Repeat(" ", columns[c]-width))
+			if i == 0 && header == headerBold {
+				sb.WriteString("\x1b[1m" + cell + "\x1b[0m")
+			} else {
+				sb.WriteString(cell)
+			}
+			width = columns[c] + len(cell)
+		}
+		table.lines = append(table.lines, sb.String())
+	}
+	return table
+}
+
+// checkCellPositions fails unless every cell of tables indexes validly into
+// lines and holds the text it is at
+func checkCellPositions(t *testing.T, tables []Table, lines []string) {
+	t.Helper()
+	for _, table := range tables {
+		for _, row := range table.Cells {
+			for _, cell := range row {
+				if cell.LineIndex < 0 || cell.LineIndex >= len(lines) {
+					t.Fatalf("Expected LineIndex in [0, %d), got %s", len(lines), cell)
+				}
+				line := lines[cell.LineIndex]
+				if cell.Text == "" {
+					continue
+				}
+				if cell.StartPos < 0 || cell.StartPos > cell.EndPos || cell.EndPos >= len(line) {
+					t.Fatalf("Expected positions in [0, %d) of %q, got %s", len(line), line, cell)
+				}
+				// The cells of styled lines may hold their plain text
+				got, _ := colordetection.StripLine(line[cell.StartPos : cell.EndPos+1])
+				if expected, _ := colordetection.StripLine(cell.Text); strings.TrimSpace(got) != expected {
+					t.Fatalf("Expected %q at [%d-%d] of %q, got %q", expected, cell.StartPos, cell.EndPos, line, got)
+				}
+			}
+		}
+	}
+}
+
+func TestPropertyRecoversSyntheticTables(t *testing.T) {
+	for seed := range uint64(200) {
+		r := rand.New(rand.NewPCG(seed, 0))
+		table := randomTable(r)
+
+		tables, err := NewDetector().DetectTables(table.lines)
+		if err != nil {
+			t.Fatalf("Seed %d: detector returned error: %v", seed, err)
+		}
+		checkCellPositions(t, tables, table.lines)
+
+		input := strings.Join(table.lines, "\n")
+		if len(tables) != 1 {
+			t.Errorf("Seed %d: expected 1 table, got %d in\n%s", seed, len(tables), input)
+			continue
+		}
+		got := tables[0]
+		if got.NumRows != len(table.lines) || got.NumColumns != len(table.columns) {
+			t.Errorf("Seed %d: expected %d rows of %d columns, got %d rows of %d in\n%s",
+				seed, len(table.lines), len(table.columns), got.NumRows, got.NumColumns, input)
+			continue
+		}
+		// Columns may start a cell off where the detector aligns them on
+		// their padding
+		for c, expected := range table.columns {
+			if position := got.GetColumnPositions()[c]; position < expected-1 || position > expected+1 {
+				t.Errorf("Seed %d: expected column %d at %d, got %v in\n%s", seed, c, expected, got.GetColumnPositions(), input)
+				break
+			}
+		}
+		// Words shorter than MinWordLength are left out of the cells
+		for i, row := range table.cells {
+			found := make(map[int]string)
+			for _, cell := range got.Cells[i] {
+				found[cell.Column] = cell.Text
+			}
+			for c, text := range row {
+				if len(text) >= MinWordLength && found[c] != text {
+					t.Errorf("Seed %d: expected %q in row %d column %d, got %v", seed, text, i, c, got.Cells[i])
+				}
+			}
+		}
+	}
+}
+
+func TestPropertyCellPositionsOnNoise(t *testing.T) {
+	// Tables among prose, blank lines and ragged lines must still index
+	// validly into the input, whatever is detected
+	prose := []string{
+		"",
+		"Processing the requests of the last hour",
+		"   warning: retrying in 5s (attempt 2/3)",
+		"done.",
+	}
+	for seed := range uint64(200) {
+		r := rand.New(rand.NewPCG(seed, 1))
+
+		var lines []string
+		for range 1 + r.IntN(3) {
+			table := randomTable(r)
+			// Ragged rows drop their last cells
+			for i := range table.lines {
+				if r.IntN(8) == 0 && len(table.columns) > 2 {
+					table.lines[i] = strings.TrimRight(table.lines[i][:table.columns[len(table.columns)-1]], " ")
+				}
+			}
+			lines = append(lines, table.lines...)
+			lines = append(lines, prose[r.IntN(len(prose))])
+		}
+
+		for name, detect := range map[string]func([]string) ([]Table, error){
+			"Detector": NewDetector().DetectTables,
+			"DualRoundDetector": func(lines []string) ([]Table, error) {
+				var tables []Table
+				for _, segment := range NewDualRoundDetector().DetectGrids(lines) {
+					tables = append(tables, Table{Cells: NewWordExtractor().ExtractCells(segment)})
+				}
+				return tables, nil
+			},
+		} {
+			tables, err := detect(lines)
+			if err != nil {
+				t.Fatalf("Seed %d: %s returned error: %v", seed, name, err)
+			}
+			checkCellPositions(t, tables, lines)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package tabledetection
+
+import (
+	"slices"
+	"strings"
+)
+
+// continuationLines is the number of buffered lines a fed line is checked
+// against to tell whether it continues their table
+const continuationLines = 3
+
+// ============================================================================
+// Streaming API
+// ============================================================================
+
+// Feed adds the next line of the input and returns the tables it terminates,
+// numbered by their line in the whole input. A blank line terminates every
+// buffered table, as does a line that doesn't continue a table with the
+// lines before it. Tables not terminated are returned once MaxBufferedLines
+// lines are buffered: the tables in the older half of the buffer are
+// returned and their lines released, a table crossing the middle of the
+// buffer is returned as far as it goes. A Detector streams one input at a
+// time
+func (d *Detector) Feed(line string) []Table {
+	if strings.TrimSpace(line) == "" {
+		tables := d.detectPending()
+		d.release(len(d.pending) + 1)
+		return tables
+	}
+	if d.terminates(line) {
+		tables := d.detectPending()
+		d.release(len(d.pending))
+		d.pending = append(d.pending, line)
+		return tables
+	}
+
+	d.pending = append(d.pending, line)
+	if len(d.pending) < max(d.config.MaxBufferedLines, d.config.MinLines) {
+		return nil
+	}
+
+	cut := d.pendingStart + len(d.pending)/2
+	var tables []Table
+	for _, table := range d.detectPending() {
+		if table.StartLine >= cut {
+			// Detected again with the following lines
+			break
+		}
+		tables = append(tables, table)
+		cut = max(cut, table.EndLine+1)
+	}
+	d.release(cut - d.pendingStart)
+	return tables
+}
+
+// Flush returns the tables of the lines buffered at the end of the input and
+// resets the stream, the next fed line is line 0 again
+func (d *Detector) Flush() []Table {
+	tables := d.detectPending()
+	d.pending = nil
+	d.pendingStart = 0
+	return tables
+}
+
+// terminates reports whether line doesn't continue a table with the last
+// buffered lines. Only those are detected on, so that feeding a long table
+// doesn't detect it again for every line
+func (d *Detector) terminates(line string) bool {
+	if len(d.pending) == 0 {
+		return false
+	}
+	if d.continuation == nil {
+		config := d.config
+		config.MinLines = 2
+		d.continuation = NewDetector(func(c *DetectionConfig) { *c = config })
+	}
+
+	window := append(slices.Clone(d.pending[max(0, len(d.pending)-continuationLines):]), line)
+	tables, err := d.continuation.DetectTables(window)
+	if err != nil {
+		return false
+	}
+	last := len(window) - 1
+	return !slices.ContainsFunc(tables, func(table Table) bool {
+		return table.StartLine < last && table.EndLine >= last
+	})
+}
+
+// detectPending detects the tables of the buffered lines
+func (d *Detector) detectPending() []Table {
+	if len(d.pending) == 0 {
+		return nil
+	}
+
+	tables, err := d.DetectTables(d.pending)
+	if err != nil {
+		return nil
+	}
+	for i := range tables {
+		offsetTable(&tables[i], d.pendingStart)
+	}
+	return tables
+}
+
+// release drops the first n lines of the input from the buffer, n may count
+// a blank line that was never buffered
+func (d *Detector) release(n int) {
+	d.pendingStart += n
+	if n >= len(d.pending) {
+		d.pending = d.pending[:0]
+		return
+	}
+	d.pending = append(d.pending[:0], d.pending[n:]...)
+}
+
+// offsetTable moves a table detected in a part of the input by offset lines
+func offsetTable(table *Table, offset int) {
+	table.StartLine += offset
+	table.EndLine += offset
+	for _, row := range table.Cells {
+		for i := range row {
+			row[i].LineIndex += offset
+		}
+	}
+}
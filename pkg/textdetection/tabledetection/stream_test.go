@@ -0,0 +1,116 @@
+package tabledetection
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectorFeed(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(`
+Name    Age  City
+John    25   NYC
+Alice   30   LA
+Bob     22   SF
+
++----+-------+
+| id | name  |
++----+-------+
+|  1 | alice |
++----+-------+`), "\n")
+
+	detector := NewDetector()
+	var streamed []Table
+	for i, line := range lines {
+		tables := detector.Feed(line)
+		if i < 4 && len(tables) > 0 {
+			t.Errorf("Expected no table before line %d is terminated, got %v", i, tables)
+		}
+		if i == 4 && len(tables) != 1 {
+			t.Errorf("Expected the blank line to terminate the first table, got %v", tables)
+		}
+		streamed = append(streamed, tables...)
+	}
+	streamed = append(streamed, detector.Flush()...)
+
+	want, err := NewDetector().DetectTables(lines)
+	if err != nil {
+		t.Fatalf("Detector returned error: %v", err)
+	}
+	if len(streamed) != len(want) {
+		t.Fatalf("Expected %d tables, got %d: %v", len(want), len(streamed), streamed)
+	}
+	for i := range want {
+		if streamed[i].StartLine != want[i].StartLine || streamed[i].EndLine != want[i].EndLine {
+			t.Errorf("Expected table at lines %d-%d, got %d-%d", want[i].StartLine, want[i].EndLine, streamed[i].StartLine, streamed[i].EndLine)
+		}
+		if got, exp := streamed[i].Cells[0][0].LineIndex, want[i].Cells[0][0].LineIndex; got != exp {
+			t.Errorf("Expected first cell on line %d, got %d", exp, got)
+		}
+	}
+}
+
+func TestDetectorFeedTerminatedTable(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(`
+id,name,role
+1,alice,admin
+2,bob,user
+this line ends the first table
+and so does this one
+id,host,port
+1,db,5432
+2,cache,6379`), "\n")
+
+	detector := NewDetector()
+	var streamed []Table
+	emittedAt := -1
+	for i, line := range lines {
+		tables := detector.Feed(line)
+		if len(tables) > 0 && emittedAt < 0 {
+			emittedAt = i
+		}
+		streamed = append(streamed, tables...)
+	}
+	streamed = append(streamed, detector.Flush()...)
+
+	if emittedAt != 3 {
+		t.Errorf("Expected the first table once the line after it is fed at line 3, got line %d", emittedAt)
+	}
+	if len(streamed) != 2 {
+		t.Fatalf("Expected 2 tables, got %v", streamed)
+	}
+	if streamed[0].StartLine != 0 || streamed[0].EndLine != 2 {
+		t.Errorf("Expected the first table at lines 0-2, got %d-%d", streamed[0].StartLine, streamed[0].EndLine)
+	}
+	if streamed[1].StartLine != 5 || streamed[1].EndLine != 7 || streamed[1].Cells[2][0].LineIndex != 7 {
+		t.Errorf("Expected the second table at lines 5-7, got %v", streamed[1])
+	}
+}
+
+func TestDetectorFeedBufferLimit(t *testing.T) {
+	lines := []string{"id,name", "1,alice", "2,bob", "3,carol", "4,dave", "5,erin", "6,frank", "7,grace"}
+
+	detector := NewDetector(WithMaxBufferedLinesOption(6))
+	var streamed []Table
+	emittedAt := -1
+	for i, line := range lines {
+		tables := detector.Feed(line)
+		if len(tables) > 0 && emittedAt < 0 {
+			emittedAt = i
+		}
+		streamed = append(streamed, tables...)
+	}
+	streamed = append(streamed, detector.Flush()...)
+
+	if emittedAt != 5 {
+		t.Errorf("Expected the first table once the buffer is full at line 5, got line %d", emittedAt)
+	}
+	if len(streamed) != 2 {
+		t.Fatalf("Expected 2 tables, got %v", streamed)
+	}
+	if streamed[0].StartLine != 0 || streamed[0].EndLine != 5 {
+		t.Errorf("Expected the table up to the full buffer at lines 0-5, got %d-%d", streamed[0].StartLine, streamed[0].EndLine)
+	}
+	if streamed[1].StartLine != 6 || streamed[1].EndLine != 7 {
+		t.Errorf("Expected the rest of the table at lines 6-7, got %d-%d", streamed[1].StartLine, streamed[1].EndLine)
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"sort"
 	"strings"
 	"unicode"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
 )
 
 // ============================================================================
@@ -593,3 +596,58 @@ func (at *AdaptiveTokenizer) validateMergedAlignment(mergedTokens []Token, targe
 
 	return true
 }
+
+// ============================================================================
+// Display Width
+// ============================================================================
+
+// displayLine replaces the non-ASCII runes of line by as many single-byte
+// placeholders as the columns they take on screen, so that byte offsets in
+// the result are display columns of line. Zero-width runes are dropped
+func displayLine(line string) string {
+	ascii := true
+	for i := 0; i < len(line); i++ {
+		if line[i] >= utf8.RuneSelf {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return line
+	}
+
+	var b strings.Builder
+	for _, r := range line {
+		switch width := runewidth.RuneWidth(r); {
+		case r < utf8.RuneSelf:
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			b.WriteString(strings.Repeat(" ", width))
+		default:
+			b.WriteString(strings.Repeat("_", width))
+		}
+	}
+	return b.String()
+}
+
+// displayText returns the text of line shown in the display columns start to
+// end, inclusive
+func displayText(line string, start, end int) string {
+	from, to := len(line), len(line)
+	column := 0
+	for i, r := range line {
+		width := runewidth.RuneWidth(r)
+		if column > end && width > 0 {
+			to = i
+			break
+		}
+		if from == len(line) && column+width > start {
+			from = i
+		}
+		column += width
+	}
+	if from > to {
+		return ""
+	}
+	return line[from:to]
+}
@@ -1,6 +1,7 @@
 package tabledetection
 
 import (
+	"context"
 	"fmt"
 	"strings"
 )
@@ -140,6 +141,9 @@ func (t Table) String() string {
 	mode := map[TokenizationMode]string{
 		SingleSpaceMode: "SingleSpace",
 		MultiSpaceMode:  "MultiSpace",
+		BorderMode:      "Border",
+		MarkdownMode:    "Markdown",
+		DelimiterMode:   "Delimiter",
 	}[t.Mode]
 
 	return fmt.Sprintf("Table[%d-%d]: %d×%d, mode=%s, confidence=%.3f",
@@ -148,11 +152,12 @@ func (t Table) String() string {
 
 // TableMetadata contains detailed information about how a table was detected
 type TableMetadata struct {
-	DetectionStrategy string            `json:"detection_strategy"` // Strategy used ("dual_round", "single_round", etc.)
-	TokenizationMode  TokenizationMode  `json:"tokenization_mode"`  // Mode used for tokenization
-	ColumnPositions   []int             `json:"column_positions"`   // Character positions where columns start
-	AlignmentData     []ColumnAlignment `json:"alignment_data"`     // Alignment information for each column
-	QualityMetrics    *QualityMetrics   `json:"quality_metrics"`    // Quality assessment metrics
+	DetectionStrategy string                 `json:"detection_strategy"` // Strategy used ("dual_round", "single_round", etc.)
+	TokenizationMode  TokenizationMode       `json:"tokenization_mode"`  // Mode used for tokenization
+	ColumnPositions   []int                  `json:"column_positions"`   // Character positions where columns start
+	AlignmentData     []ColumnAlignment      `json:"alignment_data"`     // Alignment information for each column
+	QualityMetrics    *QualityMetrics        `json:"quality_metrics"`    // Quality assessment metrics
+	Explanation       *ConfidenceExplanation `json:"explanation"`        // How the confidence was scored
 }
 
 // QualityMetrics provides detailed quality assessment of the detected table
@@ -180,6 +185,16 @@ type DetectionStrategy interface {
 	GetConfiguration() DetectionConfig
 }
 
+// ContextDetectionStrategy is a DetectionStrategy whose detection can be
+// canceled
+type ContextDetectionStrategy interface {
+	DetectionStrategy
+
+	// DetectTablesContext is DetectTables stopping with the error of ctx
+	// once it is done
+	DetectTablesContext(ctx context.Context, lines []string) ([]Table, error)
+}
+
 // NewTokenizationStrategy defines the interface for different tokenization approaches
 // (Named differently to avoid conflict with existing interface)
 type NewTokenizationStrategy interface {
@@ -223,6 +238,7 @@ type DetectionConfig struct {
 	ConfidenceThreshold float64          `json:"confidence_threshold"` // Minimum confidence to consider as grid
 	MaxColumnVariance   int              `json:"max_column_variance"`  // Maximum allowed variance in column positions
 	TokenizationMode    TokenizationMode `json:"tokenization_mode"`    // Tokenization strategy to use
+	MaxBufferedLines    int              `json:"max_buffered_lines"`   // Lines buffered by Feed before detecting without a blank line
 }
 
 // DefaultConfig returns a configuration with default values
@@ -234,6 +250,7 @@ func DefaultConfig() DetectionConfig {
 		ConfidenceThreshold: DefaultConfidenceThreshold,
 		MaxColumnVariance:   DefaultMaxColumnVariance,
 		TokenizationMode:    SingleSpaceMode,
+		MaxBufferedLines:    DefaultMaxBufferedLines,
 	}
 }
 
@@ -281,6 +298,15 @@ func ConvertGridSegmentToTable(segment GridSegment) Table {
 			AlignmentData:     segment.Metadata.AlignmentData,
 		}
 	}
+	if segment.Explanation != nil {
+		if table.Metadata == nil {
+			table.Metadata = &TableMetadata{
+				TokenizationMode: segment.Mode,
+				ColumnPositions:  segment.Columns,
+			}
+		}
+		table.Metadata.Explanation = segment.Explanation
+	}
 
 	// Convert to cell structure
 	table.Cells = make([][]Cell, table.NumRows)
@@ -323,6 +349,7 @@ func ConvertTableToGridSegment(table Table) GridSegment {
 			AlignmentData:    table.Metadata.AlignmentData,
 		}
 		segment.Columns = table.Metadata.ColumnPositions
+		segment.Explanation = table.Metadata.Explanation
 
 		// Convert cells back to tokens
 		segment.Metadata.OriginalTokens = make([][]Token, table.NumRows)
@@ -45,6 +45,11 @@ add_param() {
 add_param command        string
 add_param upcase-command string
 add_param multi-command  string
+add_param multi-confirm  boolean
 add_param osc52          boolean
+add_param revalidate     boolean
+add_param capture        string
+add_param command-timeout string
+add_param wait-timeout    string
 
-"${BINARY}" "${PARAMS[@]}" || true
+"${BINARY}" "${PARAMS[@]}" "$@" || true
@@ -0,0 +1,130 @@
+package e2e
+
+import (
+	"testing"
+
+	"github.com/Hanaasagi/magonote/test/e2e/framework"
+)
+
+// TestCLIContract pins the documented flags and output formats that
+// frontends rely on. Changing an expectation here is a breaking change
+// and needs a major version bump.
+func TestCLIContract(t *testing.T) {
+	f := framework.NewFramework()
+
+	twoIPs := "192.168.1.1\n10.0.0.1"
+
+	testCases := []framework.TestCase{
+		{
+			Name:           "Format - Text And Uppercase Flag",
+			Input:          "192.168.1.1",
+			Args:           []string{"--format", "%U:%H"},
+			Keys:           "a",
+			ExpectedOutput: "false:192.168.1.1",
+		},
+		{
+			Name:           "Format - Pattern Name",
+			Input:          "192.168.1.1",
+			Args:           []string{"-f", "%P=%H"},
+			Keys:           "a",
+			ExpectedOutput: "ipv4=192.168.1.1",
+		},
+		{
+			Name:           "Format - Coordinates And Index",
+			Input:          "host 10.0.0.1 up",
+			Args:           []string{"-f", "<%Y:%X:%N>"},
+			Keys:           "a",
+			ExpectedOutput: "<1:6:1>",
+		},
+		{
+			Name:           "Format - Line Text",
+			Input:          "host 10.0.0.1 up",
+			Args:           []string{"-f", "[%L]"},
+			Keys:           "a",
+			ExpectedOutput: "[host 10.0.0.1 up]",
+		},
+		{
+			Name:           "Alphabet",
+			Input:          twoIPs,
+			Args:           []string{"--alphabet", "dvorak", "-f", "=%H="},
+			Keys:           "o",
+			ExpectedOutput: "=10.0.0.1=",
+		},
+		{
+			Name:           "Reverse",
+			Input:          twoIPs,
+			Args:           []string{"--reverse", "-f", "=%H="},
+			Keys:           "a",
+			ExpectedOutput: "=10.0.0.1=",
+		},
+		{
+			Name:           "Multi",
+			Input:          twoIPs,
+			Args:           []string{"-m", "-f", "=%H="},
+			Keys:           "as ",
+			ExpectedOutput: "=192.168.1.1=\r\n=10.0.0.1=",
+		},
+		{
+			Name:           "Unique",
+			Input:          "10.0.0.1 10.0.0.1 192.168.1.1",
+			Args:           []string{"-u", "-f", "=%H="},
+			Keys:           "s",
+			ExpectedOutput: "=192.168.1.1=",
+		},
+		{
+			Name:           "Regexp",
+			Input:          "build 4711 finished",
+			Args:           []string{"-x", `build (\d+)`, "-f", "=%H="},
+			Keys:           "a",
+			ExpectedOutput: "=4711=",
+		},
+		{
+			Name:           "Named Regexp",
+			Input:          "fixes JIRA-123",
+			Args:           []string{"--regexp-named", `ticket:JIRA-\d+`, "-f", "%P=%H"},
+			Keys:           "a",
+			ExpectedOutput: "ticket=JIRA-123",
+		},
+		{
+			Name:           "Extra Exclusion",
+			Input:          "192.168.1.1 10.0.0.1",
+			Args:           []string{"--extra-exclusion", `192\.168\.\d+\.\d+`, "-f", "=%H="},
+			Keys:           "a",
+			ExpectedOutput: "=10.0.0.1=",
+		},
+		{
+			Name:           "Contrast And Position",
+			Input:          "192.168.1.1",
+			Args:           []string{"--contrast", "--position", "right", "-f", "=%H="},
+			Keys:           "a",
+			ExpectedOutput: "=192.168.1.1=",
+		},
+		{
+			Name:           "Version",
+			Input:          "",
+			Args:           []string{"--version"},
+			ExpectedOutput: "magonote version: ",
+		},
+		{
+			Name:           "Require Compatible Version",
+			Input:          "",
+			Args:           []string{"--require-version", "0.2", "--version"},
+			ExpectedOutput: "magonote version: ",
+		},
+		{
+			Name:           "Require Incompatible Version",
+			Input:          "",
+			Args:           []string{"--require-version", "99.0.0"},
+			ExpectedOutput: "incompatible with required version 99.0.0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			result := f.RunTest(tc)
+			if !result.Passed {
+				t.Errorf("Test failed: %s, output: %q", result.Error, result.Output)
+			}
+		})
+	}
+}
@@ -35,6 +35,20 @@ func findProjectRoot(startDir string) string {
 	return ""
 }
 
+// Keys that can be used in TestCase.Script
+const (
+	KeyEnter  = "\r"
+	KeyEscape = "\x1b"
+	KeySpace  = " "
+	KeyTab    = "\t"
+	KeyUp     = "\x1b[A"
+	KeyDown   = "\x1b[B"
+)
+
+// stepDelay separates the steps of a script, so that a lone escape isn't
+// read as the start of the next key
+const stepDelay = 100 * time.Millisecond
+
 // Framework provides utilities for running e2e tests
 type Framework struct {
 	BinaryPath string
@@ -45,9 +59,16 @@ type Framework struct {
 type TestCase struct {
 	Name           string
 	Input          string
+	Fixture        string // File of the fixtures directory read instead of Input
+	Config         string // Content of the configuration file, none if empty
 	Args           []string
-	Keys           string
+	Keys           string   // Typed at once and followed by a newline
+	Script         []string // Keystrokes typed one step at a time after Keys
 	ExpectedOutput string
+	// ExpectedTarget is the exact content of the file the selection is
+	// written to with -t, checked once magonote exits. ExpectedOutput is
+	// ignored when it is set
+	ExpectedTarget string
 	Timeout        time.Duration
 }
 
@@ -126,23 +147,52 @@ func (f *Framework) RunTest(testCase TestCase) TestResult {
 		return result
 	}
 
-	tmpFile, err := os.CreateTemp("", "magonote-test-*.txt")
-	if err != nil {
-		result.Error = fmt.Sprintf("failed to create temp file: %v", err)
-		result.Elapsed = time.Since(start)
-		return result
+	inputPath := filepath.Join("fixtures", testCase.Fixture)
+	if testCase.Fixture == "" {
+		tmpFile, err := os.CreateTemp("", "magonote-test-*.txt")
+		if err != nil {
+			result.Error = fmt.Sprintf("failed to create temp file: %v", err)
+			result.Elapsed = time.Since(start)
+			return result
+		}
+		defer os.Remove(tmpFile.Name())
+		defer tmpFile.Close()
+
+		if _, err := tmpFile.WriteString(testCase.Input); err != nil {
+			result.Error = fmt.Sprintf("failed to write to temp file: %v", err)
+			result.Elapsed = time.Since(start)
+			return result
+		}
+		tmpFile.Close()
+		inputPath = tmpFile.Name()
 	}
-	defer os.Remove(tmpFile.Name())
-	defer tmpFile.Close()
 
-	if _, err := tmpFile.WriteString(testCase.Input); err != nil {
-		result.Error = fmt.Sprintf("failed to write to temp file: %v", err)
-		result.Elapsed = time.Since(start)
-		return result
+	configPath := "NONE"
+	if testCase.Config != "" {
+		configFile, err := os.CreateTemp("", "magonote-config-*.toml")
+		if err != nil {
+			result.Error = fmt.Sprintf("failed to create config file: %v", err)
+			result.Elapsed = time.Since(start)
+			return result
+		}
+		defer os.Remove(configFile.Name())
+		defer configFile.Close()
+
+		if _, err := configFile.WriteString(testCase.Config); err != nil {
+			result.Error = fmt.Sprintf("failed to write config file: %v", err)
+			result.Elapsed = time.Since(start)
+			return result
+		}
+		configFile.Close()
+		configPath = configFile.Name()
 	}
-	tmpFile.Close()
 
-	args := append([]string{"-i", tmpFile.Name(), "--config", "NONE"}, testCase.Args...)
+	// Hints must not depend on selections of previous runs
+	args := append([]string{"-i", inputPath, "--config", configPath, "--no-history"}, testCase.Args...)
+
+	if testCase.ExpectedTarget != "" {
+		return f.runWithTarget(testCase, args, start)
+	}
 
 	cmd := exec.Command(f.BinaryPath, args...)
 
@@ -158,14 +208,10 @@ func (f *Framework) RunTest(testCase TestCase) TestResult {
 	// Wait for program initialization
 	time.Sleep(200 * time.Millisecond)
 
-	// Send keys
-	if testCase.Keys != "" {
-		_, err = ptmx.Write([]byte(testCase.Keys + "\n"))
-		if err != nil {
-			result.Error = fmt.Sprintf("failed to send keys: %v", err)
-			result.Elapsed = time.Since(start)
-			return result
-		}
+	if err := sendKeys(ptmx, testCase); err != nil {
+		result.Error = err.Error()
+		result.Elapsed = time.Since(start)
+		return result
 	}
 
 	// Set timeout
@@ -225,6 +271,94 @@ func (f *Framework) RunTest(testCase TestCase) TestResult {
 	return result
 }
 
+// sendKeys types the keys and then the script of testCase
+func sendKeys(w io.Writer, testCase TestCase) error {
+	if testCase.Keys != "" {
+		if _, err := w.Write([]byte(testCase.Keys + "\n")); err != nil {
+			return fmt.Errorf("failed to send keys: %w", err)
+		}
+	}
+
+	for _, step := range testCase.Script {
+		if _, err := w.Write([]byte(step)); err != nil {
+			return fmt.Errorf("failed to send script step %q: %w", step, err)
+		}
+		time.Sleep(stepDelay)
+	}
+	return nil
+}
+
+// runWithTarget runs magonote with args writing the selection to a file,
+// and compares the file with the expected target once magonote exits
+func (f *Framework) runWithTarget(testCase TestCase, args []string, start time.Time) TestResult {
+	result := TestResult{Name: testCase.Name}
+
+	targetDir, err := os.MkdirTemp("", "magonote-target-*")
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to create target dir: %v", err)
+		result.Elapsed = time.Since(start)
+		return result
+	}
+	defer os.RemoveAll(targetDir)
+	target := filepath.Join(targetDir, "selection")
+
+	cmd := exec.Command(f.BinaryPath, append(args, "-t", target)...)
+	ptmx, err := pty.Start(cmd)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to start command: %v", err)
+		result.Elapsed = time.Since(start)
+		return result
+	}
+	defer ptmx.Close()
+
+	// The screen has to be drained for magonote to make progress
+	go io.Copy(io.Discard, ptmx) // nolint: errcheck
+
+	exitCh := make(chan error, 1)
+	go func() {
+		exitCh <- cmd.Wait()
+	}()
+
+	// Wait for program initialization
+	time.Sleep(200 * time.Millisecond)
+
+	if err := sendKeys(ptmx, testCase); err != nil {
+		result.Error = err.Error()
+		result.Elapsed = time.Since(start)
+		return result
+	}
+
+	timeout := testCase.Timeout
+	if timeout == 0 {
+		timeout = f.Timeout
+	}
+	select {
+	case err := <-exitCh:
+		if err != nil {
+			result.Error = fmt.Sprintf("magonote failed: %v", err)
+			result.Elapsed = time.Since(start)
+			return result
+		}
+	case <-time.After(timeout):
+		_ = cmd.Process.Kill()
+		result.Error = "test timed out"
+		result.Elapsed = time.Since(start)
+		return result
+	}
+
+	content, err := os.ReadFile(target)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to read target file: %v", err)
+	} else if string(content) != testCase.ExpectedTarget {
+		result.Error = fmt.Sprintf("expected target %q, got %q", testCase.ExpectedTarget, content)
+	} else {
+		result.Passed = true
+	}
+	result.Output = string(content)
+	result.Elapsed = time.Since(start)
+	return result
+}
+
 // RunTests executes multiple test cases
 func (f *Framework) RunTests(testCases []TestCase) []TestResult {
 	results := make([]TestResult, len(testCases))
@@ -0,0 +1,67 @@
+package e2e
+
+import (
+	"testing"
+
+	"github.com/Hanaasagi/magonote/test/e2e/framework"
+)
+
+// TestInteractiveCases drives the interactive paths with scripted keystrokes
+// and checks what is written to the target file
+func TestInteractiveCases(t *testing.T) {
+	f := framework.NewFramework()
+
+	testCases := []framework.TestCase{
+		{
+			Name:           "Single Selection - Fixture",
+			Fixture:        "ip.txt",
+			Script:         []string{"a"},
+			ExpectedTarget: "127.10.0.1",
+		},
+		{
+			Name:           "Multi Selection - Toggle And Confirm",
+			Input:          "192.168.1.1\n10.0.0.1\n172.16.0.1",
+			Args:           []string{"--multi"},
+			Script:         []string{"a", "d", framework.KeySpace},
+			ExpectedTarget: "192.168.1.1\n172.16.0.1",
+		},
+		{
+			Name:           "Multi Selection - Order Of Picks",
+			Input:          "192.168.1.1\n10.0.0.1\n172.16.0.1",
+			Args:           []string{"--multi"},
+			Script:         []string{"s", "a", framework.KeySpace},
+			ExpectedTarget: "10.0.0.1\n192.168.1.1",
+		},
+		{
+			Name:           "Uppercase Selection",
+			Input:          "192.168.1.1\n10.0.0.1",
+			Config:         "[keys]\nuppercase-select = [\"ctrl-x\"]\n",
+			Args:           []string{"-f", "%U:%H"},
+			Script:         []string{"\x18", "s"},
+			ExpectedTarget: "true:10.0.0.1",
+		},
+		{
+			Name:           "Lowercase Selection",
+			Input:          "192.168.1.1\n10.0.0.1",
+			Args:           []string{"-f", "%U:%H"},
+			Script:         []string{"s"},
+			ExpectedTarget: "false:10.0.0.1",
+		},
+		{
+			Name:           "Pattern Of Fixture Match",
+			Fixture:        "docker_ps.txt",
+			Args:           []string{"-f", "%P"},
+			Script:         []string{"a"},
+			ExpectedTarget: "docker_id",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			result := f.RunTest(tc)
+			if !result.Passed {
+				t.Errorf("Test failed: %s", result.Error)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+#!/usr/bin/env bash
+#
+# Compares the benchmarks of the working tree against those of a base
+# revision and fails when one got slower than the threshold:
+#
+#   BASE=master COUNT=6 THRESHOLD=10 tools/bench-compare.sh
+#
+# The results of the base are stored in .bench by commit, so that they are
+# only measured once. Benchmarks missing from the base are reported as new.
+
+set -euo pipefail
+
+# sort and join must agree on the order of the names
+export LC_ALL=C
+
+BASE=${BASE:-master}
+COUNT=${COUNT:-6}
+THRESHOLD=${THRESHOLD:-10}   # Percent of ns/op
+BENCH=${BENCH:-.}            # Regexp of the benchmarks to run
+PACKAGES=(./internal ./pkg/textdetection/tabledetection)
+BENCH_DIR=.bench
+
+run_benchmarks() {
+  local dir="$1" output="$2"
+  (cd "$dir" && go test -run '^$' -bench "$BENCH" -benchmem -count "$COUNT" "${PACKAGES[@]}") |
+    grep -E '^(goos|goarch|pkg|cpu|Benchmark)' >"$output"
+}
+
+# medians prints the median ns/op of every benchmark of a result file
+medians() {
+  awk '/^Benchmark/ { for (i = 3; i <= NF; i++) if ($(i) == "ns/op") print $1, $(i - 1) }' "$1" |
+    sort -k1,1 -k2,2g |
+    awk '
+      function flush() { if (n) print name, (n % 2 ? v[(n + 1) / 2] : (v[n / 2] + v[n / 2 + 1]) / 2) }
+      $1 != name { flush(); name = $1; n = 0 }
+      { v[++n] = $2 }
+      END { flush() }'
+}
+
+mkdir -p "$BENCH_DIR"
+base_sha=$(git rev-parse --verify "$BASE^{commit}")
+baseline="$BENCH_DIR/baseline-$base_sha.txt"
+current="$BENCH_DIR/current.txt"
+
+if [[ ! -s "$baseline" ]]; then
+  echo "Measuring $BASE ($base_sha)..."
+  worktree=$(mktemp -d)
+  trap 'git worktree remove --force "$worktree"' EXIT
+  git worktree add --quiet --detach "$worktree" "$base_sha"
+  run_benchmarks "$worktree" "$baseline"
+fi
+
+echo "Measuring the working tree..."
+run_benchmarks . "$current"
+
+if command -v benchstat >/dev/null; then
+  benchstat "$baseline" "$current"
+  echo
+fi
+
+join -a 2 <(medians "$baseline") <(medians "$current") |
+  awk -v threshold="$THRESHOLD" '
+    NF == 2 { printf "%-50s %14s %14.0f %9s\n", $1, "new", $2, ""; next }
+    {
+      delta = ($3 - $2) / $2 * 100
+      mark = delta > threshold ? "  SLOWER" : ""
+      printf "%-50s %14.0f %14.0f %+8.1f%%%s\n", $1, $2, $3, delta, mark
+      if (delta > threshold) failed++
+    }
+    BEGIN { printf "%-50s %14s %14s %9s\n", "benchmark (median ns/op)", "base", "current", "delta" }
+    END {
+      if (failed) {
+        printf "\n%d benchmark(s) more than %s%% slower than the base\n", failed, threshold
+        exit 1
+      }
+    }'
@@ -4,7 +4,7 @@ set -euo pipefail
 
 LOCAL_BIN="$HOME/.local/bin"
 BUILD_DIR="$(pwd)/build"
-TARGETS=("magonote" "magonote-tmux")
+TARGETS=("magonote" "magonote-tmux" "magonote-screen")
 
 # Uninstall mode
 if [[ "${1-}" == "--uninstall" ]]; then